@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/matheusbuniotto/goagent/internal/lineedit"
+	"github.com/matheusbuniotto/goagent/pkg/agent"
+)
+
+// newApprovalPolicy monta a política a partir das flags -approval (modo padrão para
+// ferramentas que alteram o sistema) e -approve (regras por ferramenta).
+func newApprovalPolicy(fallback, rules string, approver agent.Approver) (*agent.ApprovalPolicy, error) {
+	mode, err := agent.ParseApprovalMode(fallback)
+	if err != nil {
+		return nil, err
+	}
+	perTool, err := agent.ParseApprovalRules(rules)
+	if err != nil {
+		return nil, err
+	}
+	policy := agent.NewApprovalPolicy(mode, approver)
+	for name, mode := range perTool {
+		policy.SetMode(name, mode)
+	}
+	return policy, nil
+}
+
+// terminalApprover mostra a chamada exata e pergunta ao usuário se ela pode ser executada.
+func terminalApprover(editor *lineedit.Editor) agent.Approver {
+	return func(ctx context.Context, call agent.ToolCall) (agent.ApprovalDecision, error) {
+		fmt.Printf("\u001b[93m⚠️  GoAgent quer executar '%s':\u001b[0m\n", call.Name)
+		fmt.Println(formatToolArgs(call.Args))
+		for {
+			answer, err := editor.Prompt("\u001b[93mPermitir? [s]im / [n]ão / [a] sempre nesta sessão / [x] nunca nesta sessão:\u001b[0m ")
+			if err != nil {
+				return agent.DecisionDeny, fmt.Errorf("aprovação não respondida: %v", err)
+			}
+			switch strings.ToLower(strings.TrimSpace(answer)) {
+			case "s", "sim", "y", "yes":
+				return agent.DecisionAllow, nil
+			case "n", "não", "nao", "no", "":
+				return agent.DecisionDeny, nil
+			case "a":
+				return agent.DecisionAllowSession, nil
+			case "x":
+				return agent.DecisionDenySession, nil
+			}
+		}
+	}
+}
+
+// formatToolArgs mostra os argumentos de uma chamada campo a campo. Textos com várias
+// linhas (como o conteúdo de um arquivo) aparecem literalmente, sem escapes do JSON.
+func formatToolArgs(args string) string {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(args), &fields); err != nil || len(fields) == 0 {
+		return "  " + args
+	}
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, key := range keys {
+		var text string
+		if err := json.Unmarshal(fields[key], &text); err == nil && strings.Contains(text, "\n") {
+			fmt.Fprintf(&b, "  %s:\n", key)
+			for _, line := range strings.Split(text, "\n") {
+				fmt.Fprintf(&b, "    │ %s\n", line)
+			}
+			continue
+		}
+		fmt.Fprintf(&b, "  %s: %s\n", key, fields[key])
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
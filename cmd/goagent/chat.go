@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/matheusbuniotto/goagent/internal/builtin"
+	"github.com/matheusbuniotto/goagent/internal/lineedit"
+	"github.com/matheusbuniotto/goagent/internal/session"
+	"github.com/matheusbuniotto/goagent/pkg/agent"
+)
+
+// chatOptions são as flags de `goagent chat`.
+type chatOptions struct {
+	selectMenu         bool
+	provider           string
+	model              string
+	agentType          string
+	reasoningDetail    int
+	reasoningTimestamp bool
+	resume             string
+	approval           string
+	approve            string
+	dryRun             bool
+	workspace          string
+	allowRead          string
+	allowCmd           string
+	denyCmd            string
+	allowDomain        string
+}
+
+// chatFlags cria o FlagSet do chat.
+func chatFlags() (*flag.FlagSet, *chatOptions) {
+	opts := &chatOptions{}
+	fs := newFlagSet("chat")
+	fs.BoolVar(&opts.selectMenu, "select", false, "Modo interativo para escolher provedor")
+	fs.StringVar(&opts.provider, "provider", "", "Provedor: gemini, openai ou openrouter (padrão: auto-detecção por chave de API)")
+	fs.StringVar(&opts.model, "model", "", "ID do modelo (aceita também o nome do provedor, por compatibilidade)")
+	fs.StringVar(&opts.agentType, "agent", "default", "Tipo de agente: default, reasoning, planner ou react")
+	fs.IntVar(&opts.reasoningDetail, "reasoning-detail", 2, "Nível de detalhe do reasoning (1=básico, 2=médio, 3=detalhado)")
+	fs.BoolVar(&opts.reasoningTimestamp, "reasoning-timestamp", true, "Mostrar timestamp no reasoning")
+	fs.StringVar(&opts.approval, "approval", "ask", "Modo para ferramentas que alteram o sistema: allow, ask ou deny")
+	fs.StringVar(&opts.approve, "approve", "", "Regras por ferramenta, ex: write_file=allow,create_directory=deny")
+	fs.BoolVar(&opts.dryRun, "dry-run", false, "Simula as ferramentas que alteram o sistema e mostra o plano de alterações ao sair")
+	fs.StringVar(&opts.workspace, "workspace", ".", "Diretório raiz das ferramentas de arquivo")
+	fs.StringVar(&opts.allowRead, "allow-read", "", "Diretórios extras liberados só para leitura, separados por vírgula")
+	fs.StringVar(&opts.allowCmd, "allow-cmd", "", "Comandos extras que o run_command executa sem aprovação, ex: \"make,go build\"")
+	fs.StringVar(&opts.denyCmd, "deny-cmd", "", "Comandos extras que o run_command recusa sempre, ex: \"rm,git push\"")
+	fs.StringVar(&opts.allowDomain, "allow-domain", "", "Domínios extras que o fetch_url acessa sem aprovação, ex: \"docs.rs,api.exemplo.com\"")
+	fs.StringVar(&opts.resume, "resume", "", "Retoma uma sessão salva pelo ID (veja goagent sessions list)")
+	return fs, opts
+}
+
+// chatCommand implementa `goagent chat`, o REPL interativo.
+func chatCommand(args []string) int {
+	fs, opts := chatFlags()
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return exitOK
+		}
+		return exitUsage
+	}
+
+	mode, err := parseAgentMode(opts.agentType)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "\u001b[91mErro: %v\u001b[0m\n", err)
+		return exitUsage
+	}
+
+	// Compatibilidade: antes dos subcomandos, -model recebia o nome do provedor.
+	switch opts.model {
+	case "gemini", "openai", "openrouter":
+		if opts.provider == "" {
+			opts.provider, opts.model = opts.model, ""
+		}
+	}
+
+	store, err := session.DefaultStore()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "\u001b[91mErro: %v\u001b[0m\n", err)
+		return exitFailure
+	}
+	var sess *session.Session
+	if opts.resume != "" {
+		if sess, err = store.Load(opts.resume); err != nil {
+			fmt.Fprintf(os.Stderr, "\u001b[91mErro: %v\u001b[0m\n", err)
+			return exitFailure
+		}
+		if opts.provider == "" {
+			opts.provider, opts.model = sess.Provider, sess.Model
+		}
+	}
+
+	keys := loadAPIKeys()
+	provider := opts.provider
+	if opts.selectMenu {
+		provider = selectProvider()
+	}
+	if provider == "" || provider == "auto" {
+		// Auto-detecção por chave de API (prioridade: OpenRouter > Gemini > OpenAI)
+		fmt.Println("\u001b[92mNenhum provedor especificado, detectando automaticamente por chave de API...\u001b[0m")
+		if provider, err = keys.detectProvider(); err != nil {
+			fmt.Fprintf(os.Stderr, "\u001b[91mErro: %v\u001b[0m\n", err)
+			return exitFailure
+		}
+	}
+
+	llmClient, model, err := newInteractiveClient(keys, provider, opts.model)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "\u001b[91mErro: %v\u001b[0m\n", err)
+		return exitFailure
+	}
+
+	if sess == nil {
+		sess = session.New(provider, model)
+	} else {
+		sess.Provider, sess.Model = provider, model
+	}
+
+	editor := lineedit.New(os.Stdin, os.Stdout)
+	if base, err := session.DefaultDir(); err == nil {
+		if err := editor.LoadHistory(filepath.Join(base, "history")); err != nil {
+			fmt.Printf("\u001b[93mAviso: %v\u001b[0m\n", err)
+		}
+	}
+
+	policy, err := newApprovalPolicy(opts.approval, opts.approve, terminalApprover(editor))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "\u001b[91mErro: %v\u001b[0m\n", err)
+		return exitUsage
+	}
+	workspace, err := setupWorkspace(opts.workspace, opts.allowRead)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "\u001b[91mErro: %v\u001b[0m\n", err)
+		return exitUsage
+	}
+	setupCommands(opts.allowCmd, opts.denyCmd)
+	setupFetch(opts.allowDomain)
+
+	theAgent := agent.NewAgent(llmClient, newTools(true))
+	theAgent.SetHistory(sess.History)
+	theAgent.SetTaskPlan(sess.TaskPlan)
+	theAgent.SetTrajectory(sess.Trajectory)
+	theAgent.SetApprovalPolicy(policy)
+	theAgent.SetDryRun(opts.dryRun)
+	theAgent.SetContextProvider(builtin.TodoContext)
+
+	r := &repl{
+		agent:    theAgent,
+		keys:     keys,
+		store:    store,
+		sess:     sess,
+		provider: provider,
+		model:    model,
+		mode:     mode,
+		costs:    make(map[string]agent.Usage),
+	}
+	r.interrupt = &interrupts{onExit: r.saveLastState}
+	r.attachCheckpoints()
+	r.attachTodos()
+	switch r.mode {
+	case modeReasoning:
+		fmt.Printf("\u001b[92mModo Reasoning ativado (detalhe: %d, timestamp: %v).\u001b[0m\n", opts.reasoningDetail, opts.reasoningTimestamp)
+	case modePlanner:
+		fmt.Println("\u001b[92mModo Planner ativado: cada pedido vira um plano numerado, executado e verificado passo a passo (veja /steps).\u001b[0m")
+	case modeReAct:
+		fmt.Println("\u001b[92mModo ReAct ativado: cada resposta traz Thought e Action ou Final Answer (veja /trajectory).\u001b[0m")
+	}
+
+	fmt.Printf("\u001b[90mWorkspace: %s\u001b[0m\n", workspace.Root())
+	if opts.dryRun {
+		fmt.Println("\u001b[93mModo dry-run: nenhuma alteração será feita; veja o plano com /plan.\u001b[0m")
+	}
+	if opts.resume != "" {
+		fmt.Printf("\u001b[92mSessão '%s' retomada (%d mensagens).\u001b[0m\n", sess.ID, len(sess.History))
+	}
+	fmt.Println("\u001b[92mChat com GoAgent (ctrl-c cancela o turno, 2x para sair, /help para comandos, \\ ou \"\"\" para várias linhas)\u001b[0m")
+
+	stop := r.interrupt.watch()
+	defer stop()
+
+	ctx := context.Background()
+	for {
+		line, err := editor.ReadLine("\u001b[94mHumano\u001b[0m: ")
+		if err == lineedit.ErrInterrupted {
+			// No prompt o terminal está em modo raw e o ctrl-c chega como tecla, não como sinal.
+			if r.interrupt.pressed() {
+				break
+			}
+			fmt.Println("\u001b[93m(ctrl-c de novo para sair)\u001b[0m")
+			continue
+		}
+		if err != nil {
+			if err != io.EOF {
+				fmt.Printf("\u001b[91mErro ao ler entrada: %v\u001b[0m\n", err)
+			}
+			break
+		}
+		if r.handleLine(ctx, line) {
+			break
+		}
+	}
+	r.save()
+	if theAgent.DryRun() {
+		fmt.Println(agent.FormatPlan(theAgent.PlannedChanges()))
+	}
+	return exitOK
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/matheusbuniotto/goagent/internal/llm"
+)
+
+// version é sobrescrita no build: go build -ldflags "-X main.version=v1.2.3"
+var version = "dev"
+
+// command descreve um subcomando da CLI. flags é usado tanto para o parse quanto para
+// gerar os scripts de autocompletar, por isso deve criar um FlagSet novo a cada chamada.
+type command struct {
+	name        string
+	summary     string
+	usage       string
+	subcommands []subcommand
+	flags       func() *flag.FlagSet
+	run         func(args []string) int
+}
+
+// subcommand é uma ação de segundo nível, como `tools list`.
+type subcommand struct {
+	name    string
+	summary string
+}
+
+// commands é preenchida em init para evitar um ciclo de inicialização com printUsage.
+var commands []*command
+
+func init() {
+	commands = []*command{
+		{
+			name:    "chat",
+			summary: "Inicia o chat interativo (padrão quando nenhum subcomando é informado)",
+			usage:   "goagent chat [flags]",
+			flags:   func() *flag.FlagSet { fs, _ := chatFlags(); return fs },
+			run:     chatCommand,
+		},
+		{
+			name:    "run",
+			summary: "Executa uma única tarefa sem interação e imprime a resposta final",
+			usage:   "goagent run -p \"tarefa\" [flags]\n  echo \"tarefa\" | goagent run [flags]",
+			flags:   func() *flag.FlagSet { fs, _ := runFlags(); return fs },
+			run:     runCommand,
+		},
+		{
+			name:    "tools",
+			summary: "Lista e descreve as ferramentas disponíveis para o agente",
+			usage:   "goagent tools list\n  goagent tools describe <nome>",
+			subcommands: []subcommand{
+				{"list", "Lista as ferramentas"},
+				{"describe", "Mostra a descrição completa de uma ferramenta"},
+			},
+			run: toolsCommand,
+		},
+		{
+			name:        "models",
+			summary:     "Lista os modelos conhecidos de cada provedor",
+			usage:       "goagent models list",
+			subcommands: []subcommand{{"list", "Lista os modelos"}},
+			run:         modelsCommand,
+		},
+		{
+			name:    "sessions",
+			summary: "Gerencia as conversas salvas",
+			usage:   "goagent sessions list\n  goagent sessions show <id>\n  goagent sessions rm <id>...\n  goagent sessions revert <id> [checkpoint]",
+			subcommands: []subcommand{
+				{"list", "Lista as sessões salvas"},
+				{"show", "Mostra o histórico de uma sessão"},
+				{"rm", "Remove sessões"},
+				{"revert", "Lista os checkpoints ou volta os arquivos a um deles"},
+			},
+			run: sessionsCommand,
+		},
+		{
+			name:    "completion",
+			summary: "Gera o script de autocompletar para bash, zsh ou fish",
+			usage:   "goagent completion bash|zsh|fish",
+			subcommands: []subcommand{
+				{"bash", "Script para bash"},
+				{"zsh", "Script para zsh"},
+				{"fish", "Script para fish"},
+			},
+			run: completionCommand,
+		},
+		{
+			name:    "version",
+			summary: "Mostra a versão do GoAgent",
+			usage:   "goagent version",
+			run:     versionCommand,
+		},
+	}
+}
+
+// findCommand retorna o subcomando pelo nome ou nil.
+func findCommand(name string) *command {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd
+		}
+	}
+	return nil
+}
+
+// printUsage mostra a ajuda geral da CLI.
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "GoAgent - agente de IA no terminal")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Uso:")
+	fmt.Fprintln(w, "  goagent <subcomando> [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Subcomandos:")
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, cmd := range commands {
+		fmt.Fprintf(tw, "  %s\t%s\n", cmd.name, cmd.summary)
+	}
+	tw.Flush()
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Use \"goagent help <subcomando>\" ou \"goagent <subcomando> -h\" para mais detalhes.")
+}
+
+// newFlagSet cria um FlagSet cuja ajuda mostra o uso, as ações e as flags do subcomando.
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		cmd := findCommand(name)
+		if cmd == nil {
+			fs.PrintDefaults()
+			return
+		}
+		printCommandHelp(fs.Output(), cmd, fs)
+	}
+	return fs
+}
+
+// printCommandHelp mostra a ajuda de um subcomando.
+func printCommandHelp(w io.Writer, cmd *command, fs *flag.FlagSet) {
+	fmt.Fprintf(w, "%s\n\nUso:\n  %s\n", cmd.summary, cmd.usage)
+	if len(cmd.subcommands) > 0 {
+		fmt.Fprintln(w, "\nAções:")
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		for _, sub := range cmd.subcommands {
+			fmt.Fprintf(tw, "  %s\t%s\n", sub.name, sub.summary)
+		}
+		tw.Flush()
+	}
+	if fs != nil {
+		hasFlags := false
+		fs.VisitAll(func(*flag.Flag) { hasFlags = true })
+		if hasFlags {
+			fmt.Fprintln(w, "\nFlags:")
+			fs.SetOutput(w)
+			fs.PrintDefaults()
+		}
+	}
+}
+
+// parseSubcommand trata a ajuda e separa a ação (ex: "list") dos argumentos restantes
+// para subcomandos sem flags próprias. ok é false quando o chamador deve retornar code.
+func parseSubcommand(name string, args []string) (action string, rest []string, code int, ok bool) {
+	cmd := findCommand(name)
+	if len(args) == 0 || args[0] == "-h" || args[0] == "-help" || args[0] == "--help" || args[0] == "help" {
+		out := os.Stdout
+		code = exitOK
+		if len(args) == 0 && len(cmd.subcommands) > 0 {
+			out, code = os.Stderr, exitUsage
+		}
+		printCommandHelp(out, cmd, nil)
+		return "", nil, code, false
+	}
+	for _, sub := range cmd.subcommands {
+		if sub.name == args[0] {
+			return args[0], args[1:], exitOK, true
+		}
+	}
+	fmt.Fprintf(os.Stderr, "ação desconhecida '%s' para '%s'\n\n", args[0], name)
+	printCommandHelp(os.Stderr, cmd, nil)
+	return "", nil, exitUsage, false
+}
+
+// modelsCommand implementa `goagent models list`.
+func modelsCommand(args []string) int {
+	if _, _, code, ok := parseSubcommand("models", args); !ok {
+		return code
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "PROVEDOR\tMODELO\tNOME\tCUSTO")
+	for _, m := range llm.PredefinedModels {
+		fmt.Fprintf(tw, "openrouter\t%s\t%s\t%s\n", m.ID, m.Name, m.CostLevel)
+	}
+	fmt.Fprintf(tw, "gemini\t%s\tGemini 2.0 Flash Lite\tBaixo\n", llm.DefaultGeminiModel)
+	fmt.Fprintf(tw, "openai\t%s\tGPT-4.1 Nano\tBaixo\n", llm.DefaultOpenAIModel)
+	tw.Flush()
+	return exitOK
+}
+
+// versionCommand implementa `goagent version`.
+func versionCommand(args []string) int {
+	if len(args) > 0 {
+		printCommandHelp(os.Stdout, findCommand("version"), nil)
+		if args[0] == "-h" || args[0] == "--help" {
+			return exitOK
+		}
+		return exitUsage
+	}
+	fmt.Printf("goagent %s (%s, %s/%s)\n", version, runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	return exitOK
+}
+
+// joinNames concatena nomes com espaço, usado na geração de scripts de autocompletar.
+func joinNames[T any](items []T, name func(T) string) string {
+	names := make([]string, len(items))
+	for i, item := range items {
+		names[i] = name(item)
+	}
+	return strings.Join(names, " ")
+}
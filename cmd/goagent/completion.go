@@ -0,0 +1,151 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// completionCommand implementa `goagent completion bash|zsh|fish`.
+func completionCommand(args []string) int {
+	shell, _, code, ok := parseSubcommand("completion", args)
+	if !ok {
+		return code
+	}
+
+	switch shell {
+	case "bash":
+		fmt.Print(bashCompletion())
+	case "zsh":
+		fmt.Print(zshCompletion())
+	case "fish":
+		fmt.Print(fishCompletion())
+	default:
+		return exitUsage
+	}
+	return exitOK
+}
+
+// commandFlags retorna os nomes das flags de um subcomando, já com o prefixo "-".
+func commandFlags(cmd *command) []string {
+	if cmd.flags == nil {
+		return nil
+	}
+	var names []string
+	cmd.flags().VisitAll(func(f *flag.Flag) {
+		names = append(names, "-"+f.Name)
+	})
+	return names
+}
+
+func commandName(c *command) string      { return c.name }
+func subcommandName(s subcommand) string { return s.name }
+
+// bashCompletion gera o script para bash. Uso: source <(goagent completion bash)
+func bashCompletion() string {
+	var b strings.Builder
+	b.WriteString("# Autocompletar do goagent para bash\n")
+	b.WriteString("# Uso: source <(goagent completion bash)\n")
+	b.WriteString("_goagent() {\n")
+	b.WriteString("    local cur=\"${COMP_WORDS[COMP_CWORD]}\"\n")
+	b.WriteString("    if [[ $COMP_CWORD -eq 1 ]]; then\n")
+	fmt.Fprintf(&b, "        COMPREPLY=( $(compgen -W \"%s\" -- \"$cur\") )\n", joinNames(commands, commandName))
+	b.WriteString("        return\n")
+	b.WriteString("    fi\n")
+	b.WriteString("    case \"${COMP_WORDS[1]}\" in\n")
+	for _, cmd := range commands {
+		if len(cmd.subcommands) > 0 {
+			fmt.Fprintf(&b, "        %s)\n", cmd.name)
+			b.WriteString("            if [[ $COMP_CWORD -eq 2 ]]; then\n")
+			fmt.Fprintf(&b, "                COMPREPLY=( $(compgen -W \"%s\" -- \"$cur\") )\n", joinNames(cmd.subcommands, subcommandName))
+			b.WriteString("            fi\n")
+			b.WriteString("            ;;\n")
+		} else if flags := commandFlags(cmd); len(flags) > 0 {
+			fmt.Fprintf(&b, "        %s)\n", cmd.name)
+			fmt.Fprintf(&b, "            COMPREPLY=( $(compgen -W \"%s\" -- \"$cur\") )\n", strings.Join(flags, " "))
+			b.WriteString("            ;;\n")
+		}
+	}
+	b.WriteString("    esac\n")
+	b.WriteString("}\n")
+	b.WriteString("complete -o default -F _goagent goagent\n")
+	return b.String()
+}
+
+// zshCompletion gera o script para zsh. Uso: source <(goagent completion zsh)
+func zshCompletion() string {
+	var b strings.Builder
+	b.WriteString("#compdef goagent\n")
+	b.WriteString("# Autocompletar do goagent para zsh\n")
+	b.WriteString("# Uso: source <(goagent completion zsh)\n")
+	b.WriteString("_goagent() {\n")
+	b.WriteString("    if (( CURRENT == 2 )); then\n")
+	b.WriteString("        local -a cmds\n")
+	b.WriteString("        cmds=(\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(&b, "            '%s:%s'\n", cmd.name, zshEscape(cmd.summary))
+	}
+	b.WriteString("        )\n")
+	b.WriteString("        _describe 'subcomando' cmds\n")
+	b.WriteString("        return\n")
+	b.WriteString("    fi\n")
+	b.WriteString("    case ${words[2]} in\n")
+	for _, cmd := range commands {
+		if len(cmd.subcommands) > 0 {
+			fmt.Fprintf(&b, "        %s)\n", cmd.name)
+			b.WriteString("            if (( CURRENT == 3 )); then\n")
+			b.WriteString("                local -a actions\n")
+			b.WriteString("                actions=(\n")
+			for _, sub := range cmd.subcommands {
+				fmt.Fprintf(&b, "                    '%s:%s'\n", sub.name, zshEscape(sub.summary))
+			}
+			b.WriteString("                )\n")
+			b.WriteString("                _describe 'ação' actions\n")
+			b.WriteString("            fi\n")
+			b.WriteString("            ;;\n")
+		} else if flags := commandFlags(cmd); len(flags) > 0 {
+			fmt.Fprintf(&b, "        %s)\n", cmd.name)
+			fmt.Fprintf(&b, "            compadd -- %s\n", strings.Join(flags, " "))
+			b.WriteString("            ;;\n")
+		}
+	}
+	b.WriteString("    esac\n")
+	b.WriteString("}\n")
+	b.WriteString("compdef _goagent goagent\n")
+	return b.String()
+}
+
+// fishCompletion gera o script para fish. Uso: goagent completion fish | source
+func fishCompletion() string {
+	var b strings.Builder
+	b.WriteString("# Autocompletar do goagent para fish\n")
+	b.WriteString("# Uso: goagent completion fish | source\n")
+	b.WriteString("complete -c goagent -f\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(&b, "complete -c goagent -n '__fish_use_subcommand' -a %s -d '%s'\n", cmd.name, fishEscape(cmd.summary))
+	}
+	for _, cmd := range commands {
+		if len(cmd.subcommands) > 0 {
+			cond := fmt.Sprintf("__fish_seen_subcommand_from %s; and not __fish_seen_subcommand_from %s", cmd.name, joinNames(cmd.subcommands, subcommandName))
+			for _, sub := range cmd.subcommands {
+				fmt.Fprintf(&b, "complete -c goagent -n '%s' -a %s -d '%s'\n", cond, sub.name, fishEscape(sub.summary))
+			}
+		}
+		if cmd.flags == nil {
+			continue
+		}
+		cmd.flags().VisitAll(func(f *flag.Flag) {
+			fmt.Fprintf(&b, "complete -c goagent -n '__fish_seen_subcommand_from %s' -o %s -d '%s'\n", cmd.name, f.Name, fishEscape(f.Usage))
+		})
+	}
+	return b.String()
+}
+
+func zshEscape(s string) string {
+	s = strings.ReplaceAll(s, "'", "'\\''")
+	return strings.ReplaceAll(s, ":", "\\:")
+}
+
+func fishEscape(s string) string {
+	return strings.ReplaceAll(s, "'", "\\'")
+}
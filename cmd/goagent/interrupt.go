@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// doubleInterruptWindow é o intervalo em que um segundo ctrl-c encerra o chat.
+const doubleInterruptWindow = 2 * time.Second
+
+// interrupts transforma ctrl-c em cancelamento do turno em andamento. O primeiro ctrl-c
+// cancela a requisição ou ferramenta atual e volta ao prompt; um segundo dentro de
+// doubleInterruptWindow encerra o programa depois de salvar a sessão.
+type interrupts struct {
+	mu     sync.Mutex
+	cancel context.CancelFunc // cancela o turno em andamento; nil no prompt
+	last   time.Time
+	onExit func() // salva o estado antes de sair
+}
+
+// watch passa a tratar SIGINT e SIGTERM. A função retornada para de observar os sinais.
+func (in *interrupts) watch() (stop func()) {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case sig := <-sigs:
+				if sig == syscall.SIGTERM {
+					in.exit()
+				}
+				in.handleSignal()
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(sigs)
+		close(done)
+	}
+}
+
+// startTurn cria o contexto de um turno, que será cancelado pelo próximo ctrl-c.
+func (in *interrupts) startTurn(parent context.Context) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(parent)
+	in.mu.Lock()
+	in.cancel = cancel
+	in.mu.Unlock()
+	return ctx, func() {
+		in.mu.Lock()
+		in.cancel = nil
+		in.mu.Unlock()
+		cancel()
+	}
+}
+
+// handleSignal trata um SIGINT recebido enquanto o terminal está em modo normal.
+func (in *interrupts) handleSignal() {
+	if in.pressed() {
+		fmt.Println()
+		in.exit()
+	}
+
+	in.mu.Lock()
+	cancel := in.cancel
+	in.mu.Unlock()
+	if cancel != nil {
+		fmt.Println("\n\u001b[93m⏹  Cancelando o turno... (ctrl-c de novo para sair)\u001b[0m")
+		cancel()
+		return
+	}
+	fmt.Println("\n\u001b[93m(ctrl-c de novo para sair)\u001b[0m")
+}
+
+// pressed registra um ctrl-c e informa se ele é o segundo dentro da janela.
+func (in *interrupts) pressed() (double bool) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	now := time.Now()
+	double = !in.last.IsZero() && now.Sub(in.last) < doubleInterruptWindow
+	in.last = now
+	return double
+}
+
+// exit salva o estado e encerra o programa.
+func (in *interrupts) exit() {
+	if in.onExit != nil {
+		in.onExit()
+	}
+	fmt.Println("\u001b[92mSessão salva. Até mais!\u001b[0m")
+	os.Exit(exitOK)
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/matheusbuniotto/goagent/pkg/agent"
+)
+
+// Modos do agente aceitos por -agent e /mode.
+const (
+	modeDefault   = "default"
+	modeReasoning = "reasoning"
+	modePlanner   = "planner"
+	modeReAct     = "react"
+)
+
+// agentModes lista os modos na ordem em que aparecem na ajuda.
+var agentModes = []string{modeDefault, modeReasoning, modePlanner, modeReAct}
+
+// parseAgentMode valida o modo do agente, aceitando as abreviações r e p.
+func parseAgentMode(s string) (string, error) {
+	switch s {
+	case "", modeDefault:
+		return modeDefault, nil
+	case modeReasoning, "r":
+		return modeReasoning, nil
+	case modePlanner, "p", "plan":
+		return modePlanner, nil
+	case modeReAct:
+		return modeReAct, nil
+	}
+	return "", fmt.Errorf("tipo de agente inválido '%s' (use default, reasoning, planner ou react)", s)
+}
+
+// runAgentMode executa um pedido do usuário no modo escolhido.
+func runAgentMode(ctx context.Context, a *agent.Agent, mode, prompt string) (string, error) {
+	switch mode {
+	case modeReasoning:
+		return a.RunOnceWithReasoning(ctx, prompt)
+	case modePlanner:
+		return a.RunOnceWithPlan(ctx, prompt)
+	case modeReAct:
+		return a.RunOnceWithReAct(ctx, prompt)
+	}
+	return a.RunOnce(ctx, prompt)
+}
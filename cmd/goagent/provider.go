@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/matheusbuniotto/goagent/internal/llm"
+)
+
+// apiKeys agrupa as chaves de API lidas do ambiente.
+type apiKeys struct {
+	openai     string
+	gemini     string
+	openrouter string
+}
+
+// loadAPIKeys carrega chaves de API de variáveis de ambiente.
+func loadAPIKeys() apiKeys {
+	return apiKeys{
+		openai:     os.Getenv("OPENAI_API_KEY"),
+		gemini:     os.Getenv("GEMINI_API_KEY"),
+		openrouter: os.Getenv("OPENROUTER_API_KEY"),
+	}
+}
+
+// detectProvider escolhe o provedor pela chave de API disponível
+// (prioridade: OpenRouter > Gemini > OpenAI).
+func (k apiKeys) detectProvider() (string, error) {
+	switch {
+	case k.openrouter != "":
+		return "openrouter", nil
+	case k.gemini != "":
+		return "gemini", nil
+	case k.openai != "":
+		return "openai", nil
+	}
+	return "", fmt.Errorf("nenhuma chave de API encontrada. Por favor, defina OPENROUTER_API_KEY, GEMINI_API_KEY ou OPENAI_API_KEY")
+}
+
+// newLLMClient cria o cliente do provedor sem nenhuma interação com o usuário.
+// model vazio significa o modelo padrão do provedor. Retorna o modelo efetivamente usado.
+func newLLMClient(keys apiKeys, provider, model string) (llm.LLMClient, string, error) {
+	if model == "" {
+		model = llm.DefaultModel(provider)
+	}
+
+	switch provider {
+	case "gemini":
+		if keys.gemini == "" {
+			return nil, "", fmt.Errorf("Gemini selecionado, mas a chave GEMINI_API_KEY não foi encontrada")
+		}
+		return llm.NewGeminiClientWithModel(keys.gemini, model), model, nil
+
+	case "openai":
+		if keys.openai == "" {
+			return nil, "", fmt.Errorf("OpenAI selecionado, mas a chave OPENAI_API_KEY não foi encontrada")
+		}
+		return llm.NewOpenAIClientWithModel(keys.openai, model), model, nil
+
+	case "openrouter":
+		if keys.openrouter == "" {
+			return nil, "", fmt.Errorf("OpenRouter selecionado, mas a chave OPENROUTER_API_KEY não foi encontrada")
+		}
+		return llm.NewOpenRouterClientWithModel(keys.openrouter, model), model, nil
+	}
+	return nil, "", fmt.Errorf("provedor desconhecido '%s'", provider)
+}
+
+// providerLabel retorna o nome do provedor para exibição.
+func providerLabel(provider string) string {
+	switch provider {
+	case "gemini":
+		return "Google Gemini"
+	case "openai":
+		return "OpenAI"
+	case "openrouter":
+		return "OpenRouter"
+	}
+	return provider
+}
+
+// newInteractiveClient cria o cliente para o chat: informa o provedor escolhido e, no
+// OpenRouter sem modelo definido, mostra o menu de modelos. Retorna o modelo escolhido.
+func newInteractiveClient(keys apiKeys, provider, model string) (llm.LLMClient, string, error) {
+	if provider == "openrouter" && model == "" && keys.openrouter != "" {
+		fmt.Printf("\u001b[92m✅ Usando cliente %s\u001b[0m\n", providerLabel(provider))
+		return newLLMClient(keys, provider, llm.SelectOpenRouterModel())
+	}
+
+	client, model, err := newLLMClient(keys, provider, model)
+	if err != nil {
+		return nil, "", err
+	}
+	fmt.Printf("\u001b[92m✅ Usando cliente %s\u001b[0m\n", providerLabel(provider))
+	return client, model, nil
+}
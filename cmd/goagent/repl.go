@@ -0,0 +1,538 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/matheusbuniotto/goagent/internal/builtin"
+	"github.com/matheusbuniotto/goagent/internal/checkpoint"
+	"github.com/matheusbuniotto/goagent/internal/llm"
+	"github.com/matheusbuniotto/goagent/internal/session"
+	"github.com/matheusbuniotto/goagent/internal/todo"
+	"github.com/matheusbuniotto/goagent/pkg/agent"
+)
+
+// repl é o estado do chat interativo: o agente, a sessão que está sendo gravada e as
+// configurações que os comandos de barra podem alterar no meio da conversa.
+type repl struct {
+	agent     *agent.Agent
+	keys      apiKeys
+	store     *session.Store
+	sess      *session.Session
+	provider  string
+	model     string
+	mode      string                 // default, reasoning, planner ou react
+	costs     map[string]agent.Usage // consumo acumulado por modelo
+	interrupt *interrupts
+	saveMu    sync.Mutex // protege sess, que também é gravada ao sair pelo ctrl-c
+}
+
+// slashCommand é um comando tratado pelo REPL em vez de ser enviado ao LLM.
+type slashCommand struct {
+	name    string
+	args    string
+	summary string
+	run     func(r *repl, args []string) (quit bool)
+}
+
+// slashCommands é preenchida em init porque /help a referencia.
+var slashCommands []slashCommand
+
+func init() {
+	slashCommands = []slashCommand{
+		{"/help", "", "Mostra esta ajuda", (*repl).cmdHelp},
+		{"/reset", "", "Limpa o histórico e inicia uma nova sessão", (*repl).cmdReset},
+		{"/history", "[n]", "Mostra o histórico (ou as últimas n mensagens)", (*repl).cmdHistory},
+		{"/save", "", "Salva a sessão atual e mostra o ID", (*repl).cmdSave},
+		{"/load", "<id>", "Carrega uma sessão salva", (*repl).cmdLoad},
+		{"/model", "[id]", "Mostra ou troca o modelo mantendo o histórico", (*repl).cmdModel},
+		{"/provider", "[nome]", "Mostra ou troca o provedor (gemini, openai, openrouter)", (*repl).cmdProvider},
+		{"/tools", "[nome]", "Lista as ferramentas ou ativa/desativa uma delas", (*repl).cmdTools},
+		{"/undo", "[n]", "Desfaz a última alteração de arquivos (ou volta ao checkpoint n)", (*repl).cmdUndo},
+		{"/checkpoints", "", "Lista os checkpoints de arquivos da sessão", (*repl).cmdCheckpoints},
+		{"/todo", "[clear]", "Mostra ou limpa a lista de tarefas do agente", (*repl).cmdTodo},
+		{"/plan", "[clear]", "Mostra ou descarta o plano de alterações do modo dry-run", (*repl).cmdPlan},
+		{"/approval", "[ferramenta allow|ask|deny]", "Mostra ou altera a política de aprovação", (*repl).cmdApproval},
+		{"/mode", "[default|reasoning|planner|react]", "Mostra ou troca o modo do agente", (*repl).cmdMode},
+		{"/reasoning", "on|off", "Liga ou desliga o modo reasoning", (*repl).cmdReasoning},
+		{"/steps", "", "Mostra o plano do modo planner e o status de cada passo", (*repl).cmdSteps},
+		{"/trajectory", "[save <arquivo>]", "Mostra ou exporta em JSON a trajetória do modo react", (*repl).cmdTrajectory},
+		{"/cost", "", "Mostra o consumo de tokens e o custo estimado", (*repl).cmdCost},
+		{"/exit", "", "Sai do chat", (*repl).cmdExit},
+	}
+}
+
+// handleLine processa uma linha digitada: comandos de barra são executados localmente e
+// o restante é enviado ao agente. Retorna true quando o usuário pede para sair.
+func (r *repl) handleLine(ctx context.Context, line string) (quit bool) {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return false
+	}
+	if strings.HasPrefix(line, "/") {
+		return r.runSlashCommand(line)
+	}
+
+	turnCtx, done := r.interrupt.startTurn(ctx)
+	defer done()
+
+	before := r.agent.Usage()
+	_, err := runAgentMode(turnCtx, r.agent, r.mode, line)
+	r.costs[r.model] = r.costs[r.model].Add(r.agent.Usage().Sub(before))
+	switch {
+	case errors.Is(err, context.Canceled):
+		fmt.Println("\u001b[93mTurno cancelado. O histórico foi mantido.\u001b[0m")
+	case err != nil:
+		fmt.Printf("\u001b[91mErro: %v\u001b[0m\n", err)
+	}
+	r.save()
+	return false
+}
+
+// runSlashCommand executa um comando de barra.
+func (r *repl) runSlashCommand(line string) (quit bool) {
+	fields := strings.Fields(line)
+	for _, cmd := range slashCommands {
+		if cmd.name == fields[0] {
+			return cmd.run(r, fields[1:])
+		}
+	}
+	fmt.Printf("\u001b[91mComando desconhecido '%s'. Use /help para ver os comandos.\u001b[0m\n", fields[0])
+	return false
+}
+
+// save grava a sessão atual com o histórico do agente. Sessões vazias não são gravadas.
+func (r *repl) save() {
+	history := r.agent.History()
+	if len(history) == 0 {
+		return
+	}
+	r.saveMu.Lock()
+	defer r.saveMu.Unlock()
+	r.sess.History = history
+	r.sess.Provider, r.sess.Model = r.provider, r.model
+	if todos := builtin.TodoList(); todos != nil {
+		r.sess.Todos = todos.Items()
+	}
+	r.sess.TaskPlan = r.agent.TaskPlan()
+	r.sess.Trajectory = r.agent.Trajectory()
+	if err := r.store.Save(r.sess); err != nil {
+		fmt.Printf("\u001b[91mErro ao salvar sessão: %v\u001b[0m\n", err)
+	}
+}
+
+// saveLastState grava a sessão como estava no último save. É chamado pela goroutine de
+// sinais ao sair, quando um turno ainda pode estar alterando o histórico do agente.
+func (r *repl) saveLastState() {
+	r.saveMu.Lock()
+	defer r.saveMu.Unlock()
+	if len(r.sess.History) == 0 {
+		return
+	}
+	if err := r.store.Save(r.sess); err != nil {
+		fmt.Printf("\u001b[91mErro ao salvar sessão: %v\u001b[0m\n", err)
+	}
+}
+
+// attachCheckpoints faz as ferramentas gravarem checkpoints na sessão atual.
+func (r *repl) attachCheckpoints() {
+	store, err := checkpointStore(r.sess.ID)
+	if err != nil {
+		fmt.Printf("\u001b[93mAviso: checkpoints desativados: %v\u001b[0m\n", err)
+		builtin.SetCheckpointStore(nil)
+		return
+	}
+	builtin.SetCheckpointStore(store)
+}
+
+// attachTodos carrega a lista de tarefas da sessão atual e a mostra a cada alteração.
+func (r *repl) attachTodos() {
+	todos := todo.New(r.sess.Todos)
+	todos.OnChange(func(l *todo.List) {
+		fmt.Printf("\u001b[96m%s\u001b[0m\n", l)
+	})
+	builtin.SetTodoList(todos)
+}
+
+func (r *repl) cmdHelp(args []string) bool {
+	fmt.Println("Comandos disponíveis:")
+	for _, cmd := range slashCommands {
+		usage := cmd.name
+		if cmd.args != "" {
+			usage += " " + cmd.args
+		}
+		fmt.Printf("  %-36s %s\n", usage, cmd.summary)
+	}
+	return false
+}
+
+func (r *repl) cmdReset(args []string) bool {
+	r.save()
+	r.agent.SetHistory(nil)
+	r.agent.SetTaskPlan(nil)
+	r.agent.SetTrajectory(nil)
+	r.saveMu.Lock()
+	r.sess = session.New(r.provider, r.model)
+	r.saveMu.Unlock()
+	r.attachCheckpoints()
+	r.attachTodos()
+	fmt.Printf("\u001b[92mHistórico limpo. Nova sessão '%s'.\u001b[0m\n", r.sess.ID)
+	return false
+}
+
+func (r *repl) cmdHistory(args []string) bool {
+	history := r.agent.History()
+	if len(args) > 0 {
+		var n int
+		if _, err := fmt.Sscanf(args[0], "%d", &n); err != nil || n <= 0 {
+			fmt.Println("\u001b[91mUso: /history [n]\u001b[0m")
+			return false
+		}
+		if n < len(history) {
+			history = history[len(history)-n:]
+		}
+	}
+	if len(history) == 0 {
+		fmt.Println("Histórico vazio.")
+		return false
+	}
+	for _, msg := range history {
+		fmt.Printf("\u001b[90m[%s]\u001b[0m %s\n", msg.Role, msg.Content)
+	}
+	return false
+}
+
+func (r *repl) cmdSave(args []string) bool {
+	if len(r.agent.History()) == 0 {
+		fmt.Println("Sessão vazia, nada a salvar.")
+		return false
+	}
+	r.save()
+	fmt.Printf("\u001b[92mSessão salva: %s\u001b[0m\n", r.sess.ID)
+	return false
+}
+
+func (r *repl) cmdLoad(args []string) bool {
+	if len(args) != 1 {
+		fmt.Println("\u001b[91mUso: /load <id>\u001b[0m")
+		return false
+	}
+	sess, err := r.store.Load(args[0])
+	if err != nil {
+		fmt.Printf("\u001b[91mErro: %v\u001b[0m\n", err)
+		return false
+	}
+	r.save()
+	r.saveMu.Lock()
+	r.sess = sess
+	r.saveMu.Unlock()
+	r.attachCheckpoints()
+	r.attachTodos()
+	r.agent.SetHistory(sess.History)
+	r.agent.SetTaskPlan(sess.TaskPlan)
+	r.agent.SetTrajectory(sess.Trajectory)
+	fmt.Printf("\u001b[92mSessão '%s' carregada (%d mensagens).\u001b[0m\n", sess.ID, len(sess.History))
+	return false
+}
+
+func (r *repl) cmdModel(args []string) bool {
+	if len(args) == 0 {
+		fmt.Printf("Modelo atual: %s (%s)\n", r.model, providerLabel(r.provider))
+		if r.provider == "openrouter" {
+			fmt.Println("Modelos conhecidos:")
+			for _, m := range llm.PredefinedModels {
+				fmt.Printf("  %-36s %s\n", m.ID, m.Name)
+			}
+		}
+		return false
+	}
+	r.switchClient(r.provider, args[0])
+	return false
+}
+
+func (r *repl) cmdProvider(args []string) bool {
+	if len(args) == 0 {
+		fmt.Printf("Provedor atual: %s (modelo %s)\n", providerLabel(r.provider), r.model)
+		return false
+	}
+	r.switchClient(args[0], "")
+	return false
+}
+
+// switchClient troca provedor e/ou modelo sem perder o histórico da conversa.
+func (r *repl) switchClient(provider, model string) {
+	client, model, err := newLLMClient(r.keys, provider, model)
+	if err != nil {
+		fmt.Printf("\u001b[91mErro: %v\u001b[0m\n", err)
+		return
+	}
+	r.agent.SetLLMClient(client)
+	r.provider, r.model = provider, model
+	fmt.Printf("\u001b[92m✅ Usando %s (%s). Histórico mantido.\u001b[0m\n", model, providerLabel(provider))
+}
+
+func (r *repl) cmdTools(args []string) bool {
+	if len(args) == 0 {
+		for _, name := range r.agent.ToolNames() {
+			status := "\u001b[92mativa\u001b[0m"
+			if !r.agent.ToolEnabled(name) {
+				status = "\u001b[91mdesativada\u001b[0m"
+			}
+			fmt.Printf("  %-30s %s\n", name, status)
+		}
+		fmt.Println("Use /tools <nome> para ativar/desativar.")
+		return false
+	}
+	name := args[0]
+	enabled := !r.agent.ToolEnabled(name)
+	if err := r.agent.SetToolEnabled(name, enabled); err != nil {
+		fmt.Printf("\u001b[91mErro: %v\u001b[0m\n", err)
+		return false
+	}
+	if enabled {
+		fmt.Printf("\u001b[92mFerramenta '%s' ativada.\u001b[0m\n", name)
+	} else {
+		fmt.Printf("\u001b[93mFerramenta '%s' desativada.\u001b[0m\n", name)
+	}
+	return false
+}
+
+func (r *repl) cmdUndo(args []string) bool {
+	store := builtin.CheckpointStore()
+	if store == nil {
+		fmt.Println("Checkpoints desativados nesta sessão.")
+		return false
+	}
+
+	var reverted []*checkpoint.Checkpoint
+	var err error
+	switch len(args) {
+	case 0:
+		var cp *checkpoint.Checkpoint
+		if cp, err = store.Undo(); cp != nil {
+			reverted = append(reverted, cp)
+		}
+	case 1:
+		var id int
+		if id, err = strconv.Atoi(strings.TrimPrefix(args[0], "#")); err != nil {
+			fmt.Println("\u001b[91mUso: /undo [n]\u001b[0m")
+			return false
+		}
+		reverted, err = store.Revert(id)
+	default:
+		fmt.Println("\u001b[91mUso: /undo [n]\u001b[0m")
+		return false
+	}
+
+	root := r.workspaceRoot()
+	var undone []string
+	for _, cp := range reverted {
+		fmt.Printf("\u001b[92mDesfeito %s\u001b[0m\n", describeCheckpoint(cp, root))
+		undone = append(undone, describeCheckpoint(cp, root))
+	}
+	if errors.Is(err, checkpoint.ErrEmpty) {
+		fmt.Println("Nada para desfazer.")
+	} else if err != nil {
+		fmt.Printf("\u001b[91mErro: %v\u001b[0m\n", err)
+	}
+
+	if len(undone) > 0 {
+		// O modelo precisa saber que os arquivos voltaram ao estado anterior.
+		history := append(r.agent.History(), agent.Message{
+			Role:    "system",
+			Content: "O usuário desfez estas alterações de arquivos, que voltaram ao estado anterior:\n" + strings.Join(undone, "\n"),
+		})
+		r.agent.SetHistory(history)
+	}
+	return false
+}
+
+func (r *repl) cmdCheckpoints(args []string) bool {
+	store := builtin.CheckpointStore()
+	if store == nil {
+		fmt.Println("Checkpoints desativados nesta sessão.")
+		return false
+	}
+	list, err := store.List()
+	if err != nil {
+		fmt.Printf("\u001b[91mErro: %v\u001b[0m\n", err)
+		return false
+	}
+	if len(list) == 0 {
+		fmt.Println("Nenhum checkpoint nesta sessão.")
+		return false
+	}
+	root := r.workspaceRoot()
+	for _, cp := range list {
+		fmt.Printf("  %s\n", describeCheckpoint(cp, root))
+	}
+	return false
+}
+
+// workspaceRoot retorna a raiz do workspace das ferramentas, ou "" se não houver.
+func (r *repl) workspaceRoot() string {
+	w, err := builtin.CurrentWorkspace()
+	if err != nil {
+		return ""
+	}
+	return w.Root()
+}
+
+func (r *repl) cmdTodo(args []string) bool {
+	todos := builtin.TodoList()
+	if len(args) == 1 && args[0] == "clear" {
+		todos.Clear()
+		fmt.Println("\u001b[92mLista de tarefas limpa.\u001b[0m")
+		return false
+	}
+	fmt.Println(todos)
+	return false
+}
+
+func (r *repl) cmdPlan(args []string) bool {
+	if !r.agent.DryRun() {
+		fmt.Println("O modo dry-run está desligado (use goagent chat -dry-run).")
+		return false
+	}
+	if len(args) == 1 && args[0] == "clear" {
+		r.agent.ClearPlan()
+		fmt.Println("\u001b[92mPlano descartado.\u001b[0m")
+		return false
+	}
+	fmt.Println(agent.FormatPlan(r.agent.PlannedChanges()))
+	return false
+}
+
+func (r *repl) cmdApproval(args []string) bool {
+	policy := r.agent.ApprovalPolicy()
+	if policy == nil {
+		fmt.Println("Nenhuma política de aprovação configurada.")
+		return false
+	}
+	if len(args) == 0 {
+		fmt.Println("Política de aprovação:")
+		for _, rule := range policy.Rules() {
+			fmt.Printf("  %s\n", rule)
+		}
+		return false
+	}
+	if len(args) != 2 {
+		fmt.Println("\u001b[91mUso: /approval <ferramenta> allow|ask|deny\u001b[0m")
+		return false
+	}
+	mode, err := agent.ParseApprovalMode(args[1])
+	if err != nil {
+		fmt.Printf("\u001b[91mErro: %v\u001b[0m\n", err)
+		return false
+	}
+	policy.SetMode(args[0], mode)
+	fmt.Printf("\u001b[92mAprovação de '%s': %s\u001b[0m\n", args[0], mode)
+	return false
+}
+
+func (r *repl) cmdMode(args []string) bool {
+	if len(args) == 0 {
+		fmt.Printf("Modo atual: %s. Uso: /mode %s\n", r.mode, strings.Join(agentModes, "|"))
+		return false
+	}
+	mode, err := parseAgentMode(args[0])
+	if err != nil {
+		fmt.Printf("\u001b[91mErro: %v\u001b[0m\n", err)
+		return false
+	}
+	r.mode = mode
+	fmt.Printf("\u001b[92mModo do agente: %s\u001b[0m\n", mode)
+	return false
+}
+
+func (r *repl) cmdReasoning(args []string) bool {
+	if len(args) != 1 || (args[0] != "on" && args[0] != "off") {
+		state := "off"
+		if r.mode == modeReasoning {
+			state = "on"
+		}
+		fmt.Printf("Reasoning: %s. Uso: /reasoning on|off\n", state)
+		return false
+	}
+	r.mode = modeDefault
+	if args[0] == "on" {
+		r.mode = modeReasoning
+	}
+	fmt.Printf("\u001b[92mModo reasoning: %s\u001b[0m\n", args[0])
+	return false
+}
+
+func (r *repl) cmdSteps(args []string) bool {
+	plan := r.agent.TaskPlan()
+	if plan == nil {
+		fmt.Println("Nenhum plano nesta sessão (use /mode planner).")
+		return false
+	}
+	fmt.Printf("Pedido: %s\n%s\n", plan.Goal, plan)
+	return false
+}
+
+func (r *repl) cmdTrajectory(args []string) bool {
+	trajectory := r.agent.Trajectory()
+	if trajectory == nil {
+		fmt.Println("Nenhuma trajetória nesta sessão (use /mode react).")
+		return false
+	}
+	switch {
+	case len(args) == 0:
+		fmt.Println(trajectory)
+	case len(args) == 2 && args[0] == "save":
+		data, err := json.MarshalIndent(trajectory, "", "  ")
+		if err == nil {
+			err = os.WriteFile(args[1], append(data, '\n'), 0644)
+		}
+		if err != nil {
+			fmt.Printf("\u001b[91mErro ao exportar a trajetória: %v\u001b[0m\n", err)
+			return false
+		}
+		fmt.Printf("\u001b[92mTrajetória salva em %s (%d passos).\u001b[0m\n", args[1], len(trajectory.Steps))
+	default:
+		fmt.Println("\u001b[91mUso: /trajectory [save <arquivo>]\u001b[0m")
+	}
+	return false
+}
+
+func (r *repl) cmdCost(args []string) bool {
+	if len(r.costs) == 0 {
+		fmt.Println("Nenhuma chamada ao LLM nesta execução.")
+		return false
+	}
+	models := make([]string, 0, len(r.costs))
+	for model := range r.costs {
+		models = append(models, model)
+	}
+	sort.Strings(models)
+
+	var total agent.Usage
+	var totalCost float64
+	for _, model := range models {
+		usage := r.costs[model]
+		total = total.Add(usage)
+		line := fmt.Sprintf("  %-36s %4d chamadas  %8d entrada  %8d saída", model, usage.Calls, usage.PromptTokens, usage.CompletionTokens)
+		if cost, ok := llm.EstimateCost(model, usage); ok {
+			totalCost += cost
+			line += fmt.Sprintf("  ~US$ %.4f", cost)
+		} else {
+			line += "  (preço desconhecido)"
+		}
+		fmt.Println(line)
+	}
+	fmt.Printf("  %-36s %4d chamadas  %8d entrada  %8d saída  ~US$ %.4f\n", "Total", total.Calls, total.PromptTokens, total.CompletionTokens, totalCost)
+	return false
+}
+
+func (r *repl) cmdExit(args []string) bool {
+	return true
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/matheusbuniotto/goagent/internal/builtin"
+	"github.com/matheusbuniotto/goagent/internal/todo"
+	"github.com/matheusbuniotto/goagent/pkg/agent"
+)
+
+// Códigos de saída do modo não interativo.
+const (
+	exitOK        = 0 // Tarefa concluída
+	exitFailure   = 1 // Erro do provedor ou de configuração
+	exitUsage     = 2 // Uso incorreto da linha de comando
+	exitLoopGuard = 3 // O agente excedeu o limite de iterações
+
+	exitInterrupted = 130 // Interrompido por ctrl-c (convenção 128 + SIGINT)
+)
+
+// runResult é o formato de saída de `goagent run --output json`.
+type runResult struct {
+	Answer     string                `json:"answer"`
+	Provider   string                `json:"provider,omitempty"`
+	Model      string                `json:"model,omitempty"`
+	Plan       []agent.PlannedChange `json:"plan,omitempty"`
+	TaskPlan   *agent.TaskPlan       `json:"task_plan,omitempty"`
+	Trajectory *agent.Trajectory     `json:"trajectory,omitempty"`
+	Error      string                `json:"error,omitempty"`
+}
+
+// runOptions são as flags de `goagent run`.
+type runOptions struct {
+	prompt        string
+	provider      string
+	model         string
+	agentType     string
+	output        string
+	maxIterations int
+	verbose       bool
+	approval      string
+	approve       string
+	dryRun        bool
+	workspace     string
+	allowRead     string
+	allowCmd      string
+	denyCmd       string
+	allowDomain   string
+}
+
+// runFlags cria o FlagSet do modo não interativo.
+func runFlags() (*flag.FlagSet, *runOptions) {
+	opts := &runOptions{}
+	fs := newFlagSet("run")
+	fs.StringVar(&opts.prompt, "p", "", "Tarefa a ser executada (se omitido, lê da entrada padrão)")
+	fs.StringVar(&opts.provider, "provider", "", "Provedor: gemini, openai ou openrouter (padrão: auto-detecção por chave de API)")
+	fs.StringVar(&opts.model, "model", "", "ID do modelo (padrão depende do provedor, ex: openai/gpt-4.1-nano no OpenRouter)")
+	fs.StringVar(&opts.agentType, "agent", "default", "Tipo de agente: default, reasoning, planner ou react")
+	fs.StringVar(&opts.output, "output", "text", "Formato da saída: text ou json")
+	fs.IntVar(&opts.maxIterations, "max-iterations", agent.DefaultMaxIterations, "Máximo de chamadas ao LLM antes de abortar")
+	fs.StringVar(&opts.approval, "approval", "deny", "Modo para ferramentas que alteram o sistema: allow ou deny (ask equivale a deny, pois não há quem aprove)")
+	fs.StringVar(&opts.approve, "approve", "", "Regras por ferramenta, ex: write_file=allow,create_directory=deny")
+	fs.BoolVar(&opts.dryRun, "dry-run", false, "Simula as ferramentas que alteram o sistema e imprime o plano de alterações")
+	fs.StringVar(&opts.workspace, "workspace", ".", "Diretório raiz das ferramentas de arquivo")
+	fs.StringVar(&opts.allowRead, "allow-read", "", "Diretórios extras liberados só para leitura, separados por vírgula")
+	fs.StringVar(&opts.allowCmd, "allow-cmd", "", "Comandos extras que o run_command executa sem aprovação, ex: \"make,go build\"")
+	fs.StringVar(&opts.denyCmd, "deny-cmd", "", "Comandos extras que o run_command recusa sempre, ex: \"rm,git push\"")
+	fs.StringVar(&opts.allowDomain, "allow-domain", "", "Domínios extras que o fetch_url acessa sem aprovação, ex: \"docs.rs,api.exemplo.com\"")
+	fs.BoolVar(&opts.verbose, "v", false, "Mostra o progresso do agente na saída de erro")
+	return fs, opts
+}
+
+// runCommand executa uma única tarefa até o fim, sem menus, e retorna o código de saída.
+// O prompt vem de -p ou, se omitido, da entrada padrão redirecionada.
+func runCommand(args []string) int {
+	fs, opts := runFlags()
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if opts.output != "text" && opts.output != "json" {
+		fmt.Fprintf(os.Stderr, "formato de saída inválido '%s' (use text ou json)\n", opts.output)
+		return exitUsage
+	}
+	mode, err := parseAgentMode(opts.agentType)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsage
+	}
+	opts.agentType = mode
+
+	task := strings.TrimSpace(opts.prompt)
+	if task == "" && stdinIsPiped() {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "erro ao ler a entrada padrão: %v\n", err)
+			return exitFailure
+		}
+		task = strings.TrimSpace(string(data))
+	}
+	if task == "" {
+		fmt.Fprintln(os.Stderr, "nenhuma tarefa informada: use -p \"...\" ou envie o prompt pela entrada padrão")
+		return exitUsage
+	}
+
+	result := runResult{Provider: opts.provider, Model: opts.model}
+	answer, err := runTask(task, opts, &result)
+	result.Answer = answer
+	if err != nil {
+		result.Error = err.Error()
+	}
+
+	if opts.output == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(result)
+	} else if err == nil {
+		fmt.Println(answer)
+		if opts.dryRun {
+			fmt.Printf("\n%s\n", agent.FormatPlan(result.Plan))
+		}
+	}
+
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, context.Canceled):
+		if opts.output == "text" {
+			fmt.Fprintln(os.Stderr, "interrompido")
+		}
+		return exitInterrupted
+	case errors.Is(err, agent.ErrMaxIterations):
+		if opts.output == "text" {
+			fmt.Fprintf(os.Stderr, "erro: %v\n", err)
+		}
+		return exitLoopGuard
+	default:
+		if opts.output == "text" {
+			fmt.Fprintf(os.Stderr, "erro: %v\n", err)
+		}
+		return exitFailure
+	}
+}
+
+// runTask cria o cliente e o agente e executa a tarefa. O provedor e o modelo efetivamente
+// usados são registrados em result.
+func runTask(task string, opts *runOptions, result *runResult) (string, error) {
+	keys := loadAPIKeys()
+	provider := opts.provider
+	if provider == "" {
+		detected, err := keys.detectProvider()
+		if err != nil {
+			return "", err
+		}
+		provider = detected
+	}
+	result.Provider = provider
+
+	// Sem Approver: no modo não interativo "ask" vira "deny".
+	policy, err := newApprovalPolicy(opts.approval, opts.approve, nil)
+	if err != nil {
+		return "", err
+	}
+	if _, err := setupWorkspace(opts.workspace, opts.allowRead); err != nil {
+		return "", err
+	}
+	setupCommands(opts.allowCmd, opts.denyCmd)
+	setupFetch(opts.allowDomain)
+
+	client, model, err := newLLMClient(keys, provider, opts.model)
+	if err != nil {
+		return "", err
+	}
+	result.Model = model
+
+	// Sem ask_human_for_clarification: no modo não interativo ninguém responderia.
+	a := agent.NewAgent(client, newTools(false))
+	a.SetMaxIterations(opts.maxIterations)
+	a.SetApprovalPolicy(policy)
+	a.SetDryRun(opts.dryRun)
+	builtin.SetTodoList(todo.New(nil))
+	a.SetContextProvider(builtin.TodoContext)
+	defer func() {
+		result.Plan, result.TaskPlan, result.Trajectory = a.PlannedChanges(), a.TaskPlan(), a.Trajectory()
+	}()
+	if opts.verbose {
+		a.SetOutput(os.Stderr)
+	} else {
+		a.SetOutput(io.Discard)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return runAgentMode(ctx, a, opts.agentType, task)
+}
+
+// stdinIsPiped indica se a entrada padrão vem de um pipe ou arquivo, e não de um terminal.
+func stdinIsPiped() bool {
+	info, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice == 0
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/matheusbuniotto/goagent/internal/checkpoint"
+	"github.com/matheusbuniotto/goagent/internal/session"
+	"github.com/matheusbuniotto/goagent/internal/todo"
+)
+
+// checkpointStore retorna o store de checkpoints de uma sessão, em <base>/checkpoints/<id>.
+func checkpointStore(sessionID string) (*checkpoint.Store, error) {
+	base, err := session.DefaultDir()
+	if err != nil {
+		return nil, err
+	}
+	return checkpoint.NewStore(filepath.Join(base, "checkpoints", sessionID)), nil
+}
+
+// describeCheckpoint resume um checkpoint em uma linha, com caminhos relativos a root
+// quando estiverem dentro dele.
+func describeCheckpoint(cp *checkpoint.Checkpoint, root string) string {
+	var paths []string
+	for _, p := range cp.Paths() {
+		if rel, err := filepath.Rel(root, p); err == nil && !strings.HasPrefix(rel, "..") {
+			p = rel
+		}
+		paths = append(paths, p)
+	}
+	return fmt.Sprintf("#%d  %s  %s  %s", cp.ID, cp.CreatedAt.Format("15:04:05"), cp.Tool, strings.Join(paths, ", "))
+}
+
+// sessionsCommand implementa `goagent sessions list|show|rm|revert`.
+func sessionsCommand(args []string) int {
+	action, rest, code, ok := parseSubcommand("sessions", args)
+	if !ok {
+		return code
+	}
+
+	store, err := session.DefaultStore()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "erro: %v\n", err)
+		return exitFailure
+	}
+
+	switch action {
+	case "list":
+		sessions, err := store.List()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "erro: %v\n", err)
+			return exitFailure
+		}
+		if len(sessions) == 0 {
+			fmt.Println("Nenhuma sessão salva.")
+			return exitOK
+		}
+		tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "ID\tATUALIZADA\tPROVEDOR\tMENSAGENS\tTÍTULO")
+		for _, s := range sessions {
+			provider := s.Provider
+			if s.Model != "" {
+				provider += "/" + s.Model
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\n", s.ID, s.UpdatedAt.Format("2006-01-02 15:04"), provider, len(s.History), s.Title())
+		}
+		tw.Flush()
+		return exitOK
+
+	case "show":
+		if len(rest) != 1 {
+			fmt.Fprintln(os.Stderr, "uso: goagent sessions show <id>")
+			return exitUsage
+		}
+		s, err := store.Load(rest[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "erro: %v\n", err)
+			return exitFailure
+		}
+		fmt.Printf("Sessão %s (%s", s.ID, s.Provider)
+		if s.Model != "" {
+			fmt.Printf(", %s", s.Model)
+		}
+		fmt.Printf(")\nCriada em %s, atualizada em %s\n\n", s.CreatedAt.Format("2006-01-02 15:04:05"), s.UpdatedAt.Format("2006-01-02 15:04:05"))
+		for _, msg := range s.History {
+			fmt.Printf("[%s] %s\n\n", msg.Role, msg.Content)
+		}
+		if s.TaskPlan != nil {
+			fmt.Printf("%s\n\n", s.TaskPlan)
+		}
+		if s.Trajectory != nil {
+			fmt.Printf("Trajetória ReAct:\n%s\n\n", s.Trajectory)
+		}
+		if len(s.Todos) > 0 {
+			fmt.Println(todo.New(s.Todos))
+		}
+		return exitOK
+
+	case "rm":
+		if len(rest) == 0 {
+			fmt.Fprintln(os.Stderr, "uso: goagent sessions rm <id>...")
+			return exitUsage
+		}
+		code := exitOK
+		for _, id := range rest {
+			s, err := store.Load(id)
+			if err == nil {
+				err = store.Delete(s.ID)
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "erro: %v\n", err)
+				code = exitFailure
+				continue
+			}
+			if cps, err := checkpointStore(s.ID); err == nil {
+				os.RemoveAll(cps.Dir())
+			}
+			fmt.Printf("Sessão '%s' removida.\n", s.ID)
+		}
+		return code
+
+	case "revert":
+		if len(rest) < 1 || len(rest) > 2 {
+			fmt.Fprintln(os.Stderr, "uso: goagent sessions revert <id> [checkpoint]")
+			return exitUsage
+		}
+		s, err := store.Load(rest[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "erro: %v\n", err)
+			return exitFailure
+		}
+		cps, err := checkpointStore(s.ID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "erro: %v\n", err)
+			return exitFailure
+		}
+		root, _ := os.Getwd()
+
+		if len(rest) == 1 {
+			list, err := cps.List()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "erro: %v\n", err)
+				return exitFailure
+			}
+			if len(list) == 0 {
+				fmt.Println("A sessão não tem checkpoints.")
+				return exitOK
+			}
+			fmt.Printf("Checkpoints da sessão %s (reverter para N desfaz N e os seguintes):\n", s.ID)
+			for _, cp := range list {
+				fmt.Printf("  %s\n", describeCheckpoint(cp, root))
+			}
+			return exitOK
+		}
+
+		id, err := strconv.Atoi(strings.TrimPrefix(rest[1], "#"))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "checkpoint inválido '%s'\n", rest[1])
+			return exitUsage
+		}
+		reverted, err := cps.Revert(id)
+		for _, cp := range reverted {
+			fmt.Printf("Desfeito %s\n", describeCheckpoint(cp, root))
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "erro: %v\n", err)
+			return exitFailure
+		}
+		return exitOK
+	}
+	return exitUsage
+}
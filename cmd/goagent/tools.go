@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/matheusbuniotto/goagent/internal/builtin"
+	"github.com/matheusbuniotto/goagent/pkg/agent"
+	"github.com/matheusbuniotto/goagent/pkg/toolkit"
+)
+
+// builtinDefinitions lista todas as ferramentas embutidas que o agente pode usar.
+func builtinDefinitions() []toolkit.ToolDefinition {
+	return []toolkit.ToolDefinition{
+		builtin.ListFilesDef,
+		builtin.WriteFileDef,
+		builtin.ReadFileDef,
+		builtin.SearchFilesDef,
+		builtin.CreateDirectoryDef,
+		builtin.EditFileDef,
+		builtin.ApplyPatchDef,
+		builtin.QueryDataDef,
+		builtin.UpdateDataDef,
+		builtin.CSVQueryDef,
+		builtin.CalculateDef,
+		builtin.StatPathDef,
+		builtin.CopyPathDef,
+		builtin.MovePathDef,
+		builtin.DeletePathDef,
+		builtin.RunCommandDef,
+		builtin.GoToolDef,
+		builtin.GoSymbolsDef,
+		builtin.GitDef,
+		builtin.FetchURLDef,
+		builtin.TodoDef,
+		builtin.AskHumanDef,
+		builtin.AnalyzeReasoningDef,
+		builtin.ReviewDecisionDef,
+	}
+}
+
+// newTools adapta as definições para a interface agent.Tool. Ferramentas interativas
+// são omitidas quando interactive é false, pois ninguém poderia respondê-las.
+func newTools(interactive bool) []agent.Tool {
+	var tools []agent.Tool
+	for _, def := range builtinDefinitions() {
+		if !interactive && def.Name == builtin.AskHumanDef.Name {
+			continue
+		}
+		tools = append(tools, &toolkit.ToolAdapter{Definition: def})
+	}
+	return tools
+}
+
+// setupWorkspace define a raiz das ferramentas de arquivo e os diretórios extras
+// liberados para leitura (lista separada por vírgulas).
+func setupWorkspace(root, allowRead string) (*builtin.Workspace, error) {
+	w, err := builtin.NewWorkspace(root, splitList(allowRead)...)
+	if err != nil {
+		return nil, err
+	}
+	builtin.SetWorkspace(w)
+	return w, nil
+}
+
+// setupCommands define os comandos que o run_command executa sem aprovação e os que
+// recusa sempre, além das listas padrão (listas separadas por vírgulas).
+func setupCommands(allow, deny string) {
+	builtin.SetCommandPolicy(builtin.NewCommandPolicy(splitList(allow), splitList(deny)))
+}
+
+// setupFetch define os domínios extras que o fetch_url acessa sem aprovação
+// (lista separada por vírgulas).
+func setupFetch(allow string) {
+	builtin.SetFetchPolicy(builtin.NewFetchPolicy(splitList(allow)))
+}
+
+// splitList separa uma lista de flags separada por vírgulas, ignorando itens vazios.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// toolsCommand implementa `goagent tools list|describe`.
+func toolsCommand(args []string) int {
+	action, rest, code, ok := parseSubcommand("tools", args)
+	if !ok {
+		return code
+	}
+
+	switch action {
+	case "list":
+		tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		for _, def := range builtinDefinitions() {
+			fmt.Fprintf(tw, "%s\t%s\n", def.Name, firstSentence(def.Description))
+		}
+		tw.Flush()
+		return exitOK
+
+	case "describe":
+		if len(rest) != 1 {
+			fmt.Fprintln(os.Stderr, "uso: goagent tools describe <nome>")
+			return exitUsage
+		}
+		for _, def := range builtinDefinitions() {
+			if def.Name == rest[0] {
+				fmt.Printf("%s\n\n%s\n", def.Name, def.Description)
+				if def.Mutating || def.MutatingFunc != nil {
+					fmt.Println("\nAltera o sistema: sim (passa pela política de aprovação)")
+				}
+				return exitOK
+			}
+		}
+		fmt.Fprintf(os.Stderr, "ferramenta desconhecida '%s'\n", rest[0])
+		return exitFailure
+	}
+	return exitUsage
+}
+
+// firstSentence corta a descrição na primeira frase para caber em uma linha da listagem.
+func firstSentence(s string) string {
+	if i := strings.Index(s, ". "); i >= 0 {
+		return s[:i+1]
+	}
+	return s
+}
@@ -0,0 +1,1416 @@
+package builtin
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	_ "time/tzdata" // Fusos horários funcionam mesmo sem o zoneinfo do sistema
+
+	"github.com/matheusbuniotto/goagent/pkg/toolkit"
+)
+
+const (
+	// maxCalcExpression é o tamanho máximo de uma expressão.
+	maxCalcExpression = 2000
+	// maxCalcDepth limita o aninhamento de parênteses e operadores unários.
+	maxCalcDepth = 200
+	// defaultCalcPrecision é o número de casas decimais de resultados não exatos.
+	defaultCalcPrecision = 20
+	// maxCalcPrecision é o maior número de casas decimais aceito.
+	maxCalcPrecision = 1000
+	// maxCalcDigits limita os dígitos mostrados de um resultado inteiro.
+	maxCalcDigits = 2000
+	// maxCalcBits limita o tamanho dos números exatos (bits do numerador mais os do
+	// denominador), seja um literal ou o resultado de uma operação.
+	maxCalcBits = 1 << 20
+	// maxCalcFactorial é o maior n aceito em n!.
+	maxCalcFactorial = 10000
+)
+
+// calcNow permite fixar o relógio nos testes.
+var calcNow = time.Now
+
+// calcUnits mapeia os nomes aceitos de unidades de tempo para o nome canônico.
+var calcUnits = map[string]string{
+	"ns": "ns", "nanosecond": "ns", "nanoseconds": "ns",
+	"us": "µs", "µs": "µs", "microsecond": "µs", "microseconds": "µs",
+	"ms": "ms", "millisecond": "ms", "milliseconds": "ms",
+	"s": "s", "sec": "s", "secs": "s", "second": "s", "seconds": "s", "seg": "s", "segundo": "s", "segundos": "s",
+	"m": "min", "min": "min", "mins": "min", "minute": "min", "minutes": "min", "minuto": "min", "minutos": "min",
+	"h": "h", "hr": "h", "hrs": "h", "hour": "h", "hours": "h", "hora": "h", "horas": "h",
+	"d": "d", "day": "d", "days": "d", "dia": "d", "dias": "d",
+	"w": "w", "wk": "w", "week": "w", "weeks": "w", "semana": "w", "semanas": "w",
+	"mo": "mo", "month": "mo", "months": "mo", "mes": "mo", "mês": "mo", "meses": "mo",
+	"y": "y", "yr": "y", "yrs": "y", "year": "y", "years": "y", "ano": "y", "anos": "y",
+}
+
+// calcUnitNanos é a duração fixa das unidades menores que um dia.
+var calcUnitNanos = map[string]int64{
+	"ns": 1, "µs": int64(time.Microsecond), "ms": int64(time.Millisecond),
+	"s": int64(time.Second), "min": int64(time.Minute), "h": int64(time.Hour),
+}
+
+// calcSpanPart reconhece cada "número unidade" de uma duração como "1h30m".
+var calcSpanPart = regexp.MustCompile(`(\d+(?:\.\d+)?)\s*([a-zA-Zµê]+)`)
+
+// calcWeekdays são os dias da semana em português, a partir de domingo.
+var calcWeekdays = []string{"domingo", "segunda-feira", "terça-feira", "quarta-feira", "quinta-feira", "sexta-feira", "sábado"}
+
+// ::: Léxico :::
+
+type calcToken struct {
+	kind byte // 'n' número, 'u' número com unidade, 'i' nome, 's' texto, 'p' pontuação, 0 fim
+	text string
+	pos  int // posição em bytes na expressão
+}
+
+// lexCalc divide a expressão em tokens. Números seguidos de letras sem espaço, como
+// "1h30m" ou "3days", viram um token de duração.
+func lexCalc(src string) ([]calcToken, error) {
+	var tokens []calcToken
+	i := 0
+	for i < len(src) {
+		r, size := utf8.DecodeRuneInString(src[i:])
+		start := i
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+		case r >= '0' && r <= '9' || r == '.' && i+1 < len(src) && src[i+1] >= '0' && src[i+1] <= '9':
+			i = scanCalcNumber(src, i)
+			kind := byte('n')
+			for i < len(src) {
+				r, size := utf8.DecodeRuneInString(src[i:])
+				if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '.' {
+					break
+				}
+				kind = 'u'
+				i += size
+			}
+			tokens = append(tokens, calcToken{kind: kind, text: src[start:i], pos: start})
+		case unicode.IsLetter(r) || r == '_':
+			for i < len(src) {
+				r, size := utf8.DecodeRuneInString(src[i:])
+				if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' {
+					break
+				}
+				i += size
+			}
+			tokens = append(tokens, calcToken{kind: 'i', text: src[start:i], pos: start})
+		case r == '"' || r == '\'':
+			end := strings.IndexRune(src[i+1:], r)
+			if end < 0 {
+				return nil, fmt.Errorf("texto sem fechamento na posição %d", start+1)
+			}
+			i += end + 2
+			tokens = append(tokens, calcToken{kind: 's', text: src[start+1 : i-1], pos: start})
+		case strings.HasPrefix(src[i:], "**"):
+			i += 2
+			tokens = append(tokens, calcToken{kind: 'p', text: "^", pos: start})
+		case strings.ContainsRune("+-*/%^(),!", r):
+			i += size
+			tokens = append(tokens, calcToken{kind: 'p', text: string(r), pos: start})
+		case r == '×' || r == '·':
+			i += size
+			tokens = append(tokens, calcToken{kind: 'p', text: "*", pos: start})
+		case r == '÷':
+			i += size
+			tokens = append(tokens, calcToken{kind: 'p', text: "/", pos: start})
+		case r == '−':
+			i += size
+			tokens = append(tokens, calcToken{kind: 'p', text: "-", pos: start})
+		default:
+			return nil, fmt.Errorf("caractere inesperado '%c' na posição %d", r, start+1)
+		}
+	}
+	return append(tokens, calcToken{pos: len(src)}), nil
+}
+
+// scanCalcNumber avança sobre dígitos, "_", a parte decimal e o expoente.
+func scanCalcNumber(src string, i int) int {
+	digits := func() {
+		for i < len(src) && (src[i] >= '0' && src[i] <= '9' || src[i] == '_') {
+			i++
+		}
+	}
+	digits()
+	if i < len(src) && src[i] == '.' {
+		i++
+		digits()
+	}
+	if i+1 < len(src) && (src[i] == 'e' || src[i] == 'E') {
+		j := i + 1
+		if src[j] == '+' || src[j] == '-' {
+			j++
+		}
+		if j < len(src) && src[j] >= '0' && src[j] <= '9' {
+			i = j
+			digits()
+		}
+	}
+	return i
+}
+
+// ::: Árvore da expressão :::
+
+// calcNode é um nó da expressão já interpretada.
+type calcNode struct {
+	kind  byte // 'n' número, 's' texto, 'd' duração, 'c' nome, 'u' unário, 'b' binário, '!' fatorial, 'f' função
+	op    string
+	text  string
+	num   *big.Rat
+	parts []calcSpanLiteral
+	args  []*calcNode
+}
+
+// calcSpanLiteral é uma parte de uma duração literal, como "30 min".
+type calcSpanLiteral struct {
+	amount *big.Rat
+	text   string
+	unit   string // nome canônico
+}
+
+// prec é a precedência usada para decidir onde a forma normalizada precisa de parênteses.
+func (n *calcNode) prec() int {
+	switch n.kind {
+	case 'b':
+		switch n.op {
+		case "+", "-":
+			return 1
+		case "^":
+			return 4
+		}
+		return 2
+	case 'u':
+		return 3
+	case '!':
+		return 5
+	}
+	return 6
+}
+
+// String devolve a forma normalizada da expressão.
+func (n *calcNode) String() string {
+	switch n.kind {
+	case 'n', 'c':
+		return n.text
+	case 's':
+		return strconv.Quote(n.text)
+	case 'd':
+		var sb strings.Builder
+		for _, p := range n.parts {
+			sb.WriteString(p.text + p.unit)
+		}
+		return sb.String()
+	case 'u':
+		return n.op + n.args[0].wrap(n.args[0].prec() < 3)
+	case '!':
+		return n.args[0].wrap(n.args[0].prec() < 5) + "!"
+	case 'f':
+		args := make([]string, len(n.args))
+		for i, a := range n.args {
+			args[i] = a.String()
+		}
+		return n.text + "(" + strings.Join(args, ", ") + ")"
+	}
+	l, r, p := n.args[0], n.args[1], n.prec()
+	if n.op == "^" {
+		return l.wrap(l.prec() <= p) + "^" + r.wrap(r.prec() < p)
+	}
+	return l.wrap(l.prec() < p) + " " + n.op + " " + r.wrap(r.prec() <= p)
+}
+
+func (n *calcNode) wrap(paren bool) string {
+	if paren {
+		return "(" + n.String() + ")"
+	}
+	return n.String()
+}
+
+// ::: Parser :::
+
+type calcParser struct {
+	tokens []calcToken
+	pos    int
+	depth  int
+}
+
+func (p *calcParser) peek() calcToken { return p.tokens[p.pos] }
+
+func (p *calcParser) next() calcToken {
+	t := p.tokens[p.pos]
+	if t.kind != 0 {
+		p.pos++
+	}
+	return t
+}
+
+func (p *calcParser) isPunct(text string) bool {
+	t := p.peek()
+	return t.kind == 'p' && t.text == text
+}
+
+func (p *calcParser) expect(text string) error {
+	if !p.isPunct(text) {
+		return p.unexpected()
+	}
+	p.pos++
+	return nil
+}
+
+func (p *calcParser) unexpected() error {
+	t := p.peek()
+	if t.kind == 0 {
+		return fmt.Errorf("fim inesperado da expressão")
+	}
+	return fmt.Errorf("token inesperado '%s' na posição %d", t.text, t.pos+1)
+}
+
+// parseCalc interpreta a expressão inteira.
+func parseCalc(src string) (*calcNode, error) {
+	tokens, err := lexCalc(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &calcParser{tokens: tokens}
+	n, err := p.additive()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != 0 {
+		return nil, p.unexpected()
+	}
+	return n, nil
+}
+
+func (p *calcParser) additive() (*calcNode, error) {
+	left, err := p.multiplicative()
+	if err != nil {
+		return nil, err
+	}
+	for p.isPunct("+") || p.isPunct("-") {
+		op := p.next().text
+		right, err := p.multiplicative()
+		if err != nil {
+			return nil, err
+		}
+		left = &calcNode{kind: 'b', op: op, args: []*calcNode{left, right}}
+	}
+	return left, nil
+}
+
+func (p *calcParser) multiplicative() (*calcNode, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isPunct("*") || p.isPunct("/") || p.isPunct("%") {
+		op := p.next().text
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		left = &calcNode{kind: 'b', op: op, args: []*calcNode{left, right}}
+	}
+	return left, nil
+}
+
+func (p *calcParser) unary() (*calcNode, error) {
+	p.depth++
+	defer func() { p.depth-- }()
+	if p.depth > maxCalcDepth {
+		return nil, fmt.Errorf("expressão aninhada demais")
+	}
+	if p.isPunct("-") || p.isPunct("+") {
+		op := p.next().text
+		x, err := p.unary()
+		if err != nil || op == "+" {
+			return x, err
+		}
+		return &calcNode{kind: 'u', op: op, args: []*calcNode{x}}, nil
+	}
+	return p.power()
+}
+
+// power é associativo à direita e liga mais forte que o menos unário: -2^2 é -4.
+func (p *calcParser) power() (*calcNode, error) {
+	base, err := p.postfix()
+	if err != nil {
+		return nil, err
+	}
+	if !p.isPunct("^") {
+		return base, nil
+	}
+	p.pos++
+	exp, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	return &calcNode{kind: 'b', op: "^", args: []*calcNode{base, exp}}, nil
+}
+
+func (p *calcParser) postfix() (*calcNode, error) {
+	x, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isPunct("!") {
+		p.pos++
+		x = &calcNode{kind: '!', args: []*calcNode{x}}
+	}
+	return x, nil
+}
+
+func (p *calcParser) primary() (*calcNode, error) {
+	t := p.next()
+	switch t.kind {
+	case 'n':
+		num, err := parseCalcNumber(t)
+		if err != nil {
+			return nil, err
+		}
+		if unit, ok := p.unit(); ok {
+			return p.span(calcSpanLiteral{amount: num, text: strings.ReplaceAll(t.text, "_", ""), unit: unit})
+		}
+		return &calcNode{kind: 'n', text: strings.ReplaceAll(t.text, "_", ""), num: num}, nil
+	case 'u':
+		parts, err := parseSpanText(t.text)
+		if err != nil {
+			return nil, fmt.Errorf("%w na posição %d", err, t.pos+1)
+		}
+		return p.span(parts...)
+	case 's':
+		return &calcNode{kind: 's', text: t.text}, nil
+	case 'i':
+		name := strings.ToLower(t.text)
+		if !p.isPunct("(") {
+			if _, ok := calcConstants[name]; !ok {
+				return nil, fmt.Errorf("nome desconhecido '%s' na posição %d", t.text, t.pos+1)
+			}
+			return &calcNode{kind: 'c', text: name}, nil
+		}
+		if _, ok := calcFunctions[name]; !ok {
+			return nil, fmt.Errorf("função desconhecida '%s' na posição %d", t.text, t.pos+1)
+		}
+		p.pos++
+		n := &calcNode{kind: 'f', text: name}
+		for !p.isPunct(")") {
+			if len(n.args) > 0 {
+				if err := p.expect(","); err != nil {
+					return nil, err
+				}
+			}
+			arg, err := p.additive()
+			if err != nil {
+				return nil, err
+			}
+			n.args = append(n.args, arg)
+		}
+		p.pos++
+		return n, nil
+	case 'p':
+		if t.text == "(" {
+			p.depth++
+			defer func() { p.depth-- }()
+			if p.depth > maxCalcDepth {
+				return nil, fmt.Errorf("expressão aninhada demais")
+			}
+			x, err := p.additive()
+			if err != nil {
+				return nil, err
+			}
+			return x, p.expect(")")
+		}
+	}
+	if t.kind != 0 {
+		p.pos--
+	}
+	return nil, p.unexpected()
+}
+
+// unit consome o nome de unidade que segue um número, como em "3 days". Um nome seguido
+// de "(" é uma chamada de função, não uma unidade.
+func (p *calcParser) unit() (string, bool) {
+	t := p.peek()
+	if t.kind != 'i' || p.tokens[p.pos+1].kind == 'p' && p.tokens[p.pos+1].text == "(" {
+		return "", false
+	}
+	unit, ok := calcUnits[strings.ToLower(t.text)]
+	if ok {
+		p.pos++
+	}
+	return unit, ok
+}
+
+// span junta as partes seguidas de uma duração, como "2 h 30 min" ou "1h 15m".
+func (p *calcParser) span(parts ...calcSpanLiteral) (*calcNode, error) {
+	for {
+		t := p.peek()
+		switch {
+		case t.kind == 'u':
+			more, err := parseSpanText(t.text)
+			if err != nil {
+				return nil, fmt.Errorf("%w na posição %d", err, t.pos+1)
+			}
+			p.pos++
+			parts = append(parts, more...)
+			continue
+		case t.kind == 'n' && p.tokens[p.pos+1].kind == 'i':
+			if _, ok := calcUnits[strings.ToLower(p.tokens[p.pos+1].text)]; ok {
+				p.pos++
+				num, err := parseCalcNumber(t)
+				if err != nil {
+					return nil, err
+				}
+				unit, _ := p.unit()
+				parts = append(parts, calcSpanLiteral{amount: num, text: strings.ReplaceAll(t.text, "_", ""), unit: unit})
+				continue
+			}
+		}
+		return &calcNode{kind: 'd', parts: parts}, nil
+	}
+}
+
+func parseCalcNumber(t calcToken) (*big.Rat, error) {
+	text := strings.ReplaceAll(t.text, "_", "")
+	// Confere o expoente antes de SetString, que calcularia 10^e inteiro: cada potência
+	// de dez ocupa mais de 3 bits.
+	if i := strings.IndexAny(text, "eE"); i >= 0 {
+		if exp, err := strconv.Atoi(strings.TrimPrefix(text[i+1:], "+")); err != nil || exp > maxCalcBits/3 || exp < -maxCalcBits/3 {
+			return nil, fmt.Errorf("número '%s' na posição %d passa do limite de %d bits", t.text, t.pos+1, maxCalcBits)
+		}
+	}
+	num, ok := new(big.Rat).SetString(text)
+	if !ok {
+		return nil, fmt.Errorf("número inválido '%s' na posição %d", t.text, t.pos+1)
+	}
+	if ratBits(num) > maxCalcBits {
+		return nil, fmt.Errorf("número '%s' na posição %d passa do limite de %d bits", t.text, t.pos+1, maxCalcBits)
+	}
+	return num, nil
+}
+
+// parseSpanText interpreta durações escritas juntas, como "1h30m", "2d12h" ou "90min".
+func parseSpanText(s string) ([]calcSpanLiteral, error) {
+	s = strings.TrimSpace(s)
+	matches := calcSpanPart.FindAllStringSubmatchIndex(s, -1)
+	var parts []calcSpanLiteral
+	end := 0
+	for _, m := range matches {
+		if strings.TrimSpace(s[end:m[0]]) != "" {
+			break
+		}
+		amount, _ := new(big.Rat).SetString(s[m[2]:m[3]])
+		unit, ok := calcUnits[strings.ToLower(s[m[4]:m[5]])]
+		if !ok {
+			return nil, fmt.Errorf("unidade desconhecida '%s' em '%s'", s[m[4]:m[5]], s)
+		}
+		parts = append(parts, calcSpanLiteral{amount: amount, text: s[m[2]:m[3]], unit: unit})
+		end = m[1]
+	}
+	if len(parts) == 0 || end != len(s) {
+		return nil, fmt.Errorf("duração inválida '%s'", s)
+	}
+	return parts, nil
+}
+
+// ::: Valores :::
+
+type calcKind int
+
+const (
+	calcNum calcKind = iota
+	calcTime
+	calcDur
+	calcStr
+)
+
+func (k calcKind) String() string {
+	return [...]string{"número", "data", "duração", "texto"}[k]
+}
+
+// calcValue é o resultado de um nó. Números são racionais exatos; exact fica falso quando
+// o valor veio de uma função de ponto flutuante.
+type calcValue struct {
+	kind  calcKind
+	num   *big.Rat
+	exact bool
+	t     time.Time
+	span  calcSpan
+	str   string
+}
+
+// calcSpan é uma duração. Meses e dias são de calendário: somar 1 mês a 31/01 cai no
+// fim de fevereiro e somar 1 dia atravessa mudanças de horário de verão.
+type calcSpan struct {
+	months, days, nanos int64
+}
+
+// ratBits é o tamanho de r em bits, somando numerador e denominador.
+func ratBits(r *big.Rat) int {
+	return r.Num().BitLen() + r.Denom().BitLen()
+}
+
+func numberValue(r *big.Rat, exact bool) calcValue {
+	return calcValue{kind: calcNum, num: r, exact: exact}
+}
+
+func floatValue(f float64) (calcValue, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return calcValue{}, fmt.Errorf("resultado não é um número finito")
+	}
+	return numberValue(new(big.Rat).SetFloat64(f), false), nil
+}
+
+func (v calcValue) float() (float64, error) {
+	f, _ := v.num.Float64()
+	if math.IsInf(f, 0) {
+		return 0, fmt.Errorf("número grande demais para ponto flutuante")
+	}
+	return f, nil
+}
+
+// addInt64 soma com verificação de estouro.
+func addInt64(a, b int64) (int64, error) {
+	s := a + b
+	if (s > a) != (b > 0) {
+		return 0, fmt.Errorf("duração grande demais")
+	}
+	return s, nil
+}
+
+// ratInt64 converte um racional para int64, arredondando para o inteiro mais próximo.
+func ratInt64(r *big.Rat) (int64, error) {
+	i := roundRat(r, 0)
+	if !i.Num().IsInt64() {
+		return 0, fmt.Errorf("duração grande demais")
+	}
+	return i.Num().Int64(), nil
+}
+
+// spanFromParts converte as partes de uma duração literal.
+func spanFromParts(parts []calcSpanLiteral) (calcSpan, error) {
+	var total calcSpan
+	for _, p := range parts {
+		s, err := scaleSpan(unitSpan(p.unit), p.amount)
+		if err != nil {
+			return calcSpan{}, err
+		}
+		if total, err = total.add(s); err != nil {
+			return calcSpan{}, err
+		}
+	}
+	return total, nil
+}
+
+// unitSpan é uma unidade de tempo.
+func unitSpan(unit string) calcSpan {
+	switch unit {
+	case "d":
+		return calcSpan{days: 1}
+	case "w":
+		return calcSpan{days: 7}
+	case "mo":
+		return calcSpan{months: 1}
+	case "y":
+		return calcSpan{months: 12}
+	}
+	return calcSpan{nanos: calcUnitNanos[unit]}
+}
+
+func (s calcSpan) add(o calcSpan) (calcSpan, error) {
+	var err1, err2, err3 error
+	s.months, err1 = addInt64(s.months, o.months)
+	s.days, err2 = addInt64(s.days, o.days)
+	s.nanos, err3 = addInt64(s.nanos, o.nanos)
+	if err1 != nil || err2 != nil || err3 != nil {
+		return calcSpan{}, fmt.Errorf("duração grande demais")
+	}
+	return s, nil
+}
+
+// scaleSpan multiplica a duração por k. Frações de dia viram horas; meses precisam
+// continuar inteiros.
+func scaleSpan(s calcSpan, k *big.Rat) (calcSpan, error) {
+	months := new(big.Rat).Mul(new(big.Rat).SetInt64(s.months), k)
+	if !months.IsInt() {
+		return calcSpan{}, fmt.Errorf("a duração precisa ter um número inteiro de meses, pois meses e anos não têm tamanho fixo")
+	}
+	days := new(big.Rat).Mul(new(big.Rat).SetInt64(s.days), k)
+	whole := new(big.Int).Quo(days.Num(), days.Denom())
+	frac := new(big.Rat).Sub(days, new(big.Rat).SetInt(whole))
+	nanos := new(big.Rat).Mul(new(big.Rat).SetInt64(s.nanos), k)
+	nanos.Add(nanos, frac.Mul(frac, new(big.Rat).SetInt64(int64(24*time.Hour))))
+
+	var out calcSpan
+	var err error
+	if !months.Num().IsInt64() || !whole.IsInt64() {
+		return calcSpan{}, fmt.Errorf("duração grande demais")
+	}
+	out.months, out.days = months.Num().Int64(), whole.Int64()
+	if out.nanos, err = ratInt64(nanos); err != nil {
+		return calcSpan{}, err
+	}
+	return out, nil
+}
+
+// fixedNanos é o total em nanossegundos, contando um dia como 24 horas. Meses não têm
+// tamanho fixo.
+func (s calcSpan) fixedNanos() (*big.Rat, error) {
+	if s.months != 0 {
+		return nil, fmt.Errorf("durações com meses ou anos não têm tamanho fixo; subtraia datas para obter um intervalo exato")
+	}
+	total := new(big.Int).Mul(big.NewInt(s.days), big.NewInt(int64(24*time.Hour)))
+	total.Add(total, big.NewInt(s.nanos))
+	return new(big.Rat).SetInt(total), nil
+}
+
+func (s calcSpan) String() string {
+	if s.months <= 0 && s.days <= 0 && s.nanos <= 0 && s != (calcSpan{}) {
+		return "-" + calcSpan{-s.months, -s.days, -s.nanos}.String()
+	}
+	var parts []string
+	if y := s.months / 12; y != 0 {
+		parts = append(parts, fmt.Sprintf("%dy", y))
+	}
+	if mo := s.months % 12; mo != 0 {
+		parts = append(parts, fmt.Sprintf("%dmo", mo))
+	}
+	days := s.days + s.nanos/int64(24*time.Hour)
+	rest := time.Duration(s.nanos % int64(24*time.Hour))
+	if days != 0 {
+		parts = append(parts, fmt.Sprintf("%dd", days))
+	}
+	if h := rest / time.Hour; h != 0 {
+		parts = append(parts, fmt.Sprintf("%dh", h))
+	}
+	if m := rest % time.Hour / time.Minute; m != 0 {
+		parts = append(parts, fmt.Sprintf("%dmin", m))
+	}
+	if sec := rest % time.Minute; sec != 0 {
+		parts = append(parts, formatRat(new(big.Rat).SetFrac64(int64(sec), int64(time.Second)), 9)+"s")
+	}
+	if len(parts) == 0 {
+		return "0s"
+	}
+	return strings.Join(parts, " ")
+}
+
+// ::: Avaliação :::
+
+type calcEval struct {
+	loc *time.Location
+}
+
+// calcConstants são os nomes aceitos sem parênteses.
+var calcConstants = map[string]float64{
+	"pi":  math.Pi,
+	"tau": 2 * math.Pi,
+	"e":   math.E,
+	"phi": math.Phi,
+}
+
+// eval calcula o nó e recusa números exatos maiores que maxCalcBits, para que as
+// operações seguintes (e a formatação do resultado) continuem rápidas.
+func (e *calcEval) eval(n *calcNode) (calcValue, error) {
+	v, err := e.evalNode(n)
+	if err == nil && v.kind == calcNum && ratBits(v.num) > maxCalcBits {
+		return calcValue{}, fmt.Errorf("resultado passa do limite de %d bits", maxCalcBits)
+	}
+	return v, err
+}
+
+func (e *calcEval) evalNode(n *calcNode) (calcValue, error) {
+	switch n.kind {
+	case 'n':
+		return numberValue(n.num, true), nil
+	case 's':
+		return calcValue{kind: calcStr, str: n.text}, nil
+	case 'c':
+		return numberValue(new(big.Rat).SetFloat64(calcConstants[n.text]), false), nil
+	case 'd':
+		s, err := spanFromParts(n.parts)
+		return calcValue{kind: calcDur, span: s}, err
+	case 'f':
+		args := make([]calcValue, len(n.args))
+		for i, a := range n.args {
+			v, err := e.eval(a)
+			if err != nil {
+				return calcValue{}, err
+			}
+			args[i] = v
+		}
+		return e.call(n.text, args)
+	}
+
+	x, err := e.eval(n.args[0])
+	if err != nil {
+		return calcValue{}, err
+	}
+	switch n.kind {
+	case 'u':
+		switch x.kind {
+		case calcNum:
+			return numberValue(new(big.Rat).Neg(x.num), x.exact), nil
+		case calcDur:
+			s, err := scaleSpan(x.span, big.NewRat(-1, 1))
+			return calcValue{kind: calcDur, span: s}, err
+		}
+		return calcValue{}, fmt.Errorf("operação inválida: -%s", x.kind)
+	case '!':
+		return factorial(x)
+	}
+	y, err := e.eval(n.args[1])
+	if err != nil {
+		return calcValue{}, err
+	}
+	return e.binary(n.op, x, y)
+}
+
+func (e *calcEval) binary(op string, a, b calcValue) (calcValue, error) {
+	switch {
+	case a.kind == calcNum && b.kind == calcNum:
+		return numericBinary(op, a, b)
+	case a.kind == calcTime && b.kind == calcDur && (op == "+" || op == "-"):
+		return addToDate(a.t, b.span, op == "-")
+	case a.kind == calcDur && b.kind == calcTime && op == "+":
+		return addToDate(b.t, a.span, false)
+	case a.kind == calcTime && b.kind == calcTime && op == "-":
+		return dateDiff(a.t, b.t)
+	case a.kind == calcDur && b.kind == calcDur && (op == "+" || op == "-"):
+		if op == "-" {
+			neg, err := scaleSpan(b.span, big.NewRat(-1, 1))
+			if err != nil {
+				return calcValue{}, err
+			}
+			b.span = neg
+		}
+		s, err := a.span.add(b.span)
+		return calcValue{kind: calcDur, span: s}, err
+	case a.kind == calcDur && b.kind == calcNum && (op == "*" || op == "/"):
+		k := b.num
+		if op == "/" {
+			if k.Sign() == 0 {
+				return calcValue{}, fmt.Errorf("divisão por zero")
+			}
+			k = new(big.Rat).Inv(k)
+		}
+		s, err := scaleSpan(a.span, k)
+		return calcValue{kind: calcDur, span: s}, err
+	case a.kind == calcNum && b.kind == calcDur && op == "*":
+		s, err := scaleSpan(b.span, a.num)
+		return calcValue{kind: calcDur, span: s}, err
+	case a.kind == calcDur && b.kind == calcDur && op == "/":
+		x, err := a.span.fixedNanos()
+		if err != nil {
+			return calcValue{}, err
+		}
+		y, err := b.span.fixedNanos()
+		if err != nil {
+			return calcValue{}, err
+		}
+		if y.Sign() == 0 {
+			return calcValue{}, fmt.Errorf("divisão por zero")
+		}
+		return numberValue(x.Quo(x, y), true), nil
+	}
+	return calcValue{}, fmt.Errorf("operação inválida: %s %s %s", a.kind, op, b.kind)
+}
+
+func numericBinary(op string, a, b calcValue) (calcValue, error) {
+	exact := a.exact && b.exact
+	r := new(big.Rat)
+	switch op {
+	case "+":
+		r.Add(a.num, b.num)
+	case "-":
+		r.Sub(a.num, b.num)
+	case "*":
+		r.Mul(a.num, b.num)
+	case "/":
+		if b.num.Sign() == 0 {
+			return calcValue{}, fmt.Errorf("divisão por zero")
+		}
+		r.Quo(a.num, b.num)
+	case "%":
+		if b.num.Sign() == 0 {
+			return calcValue{}, fmt.Errorf("divisão por zero")
+		}
+		// Resto com o sinal do divisor: a - b*floor(a/b).
+		q := floorRat(new(big.Rat).Quo(a.num, b.num))
+		r.Sub(a.num, q.Mul(q, b.num))
+	case "^":
+		return power(a, b)
+	}
+	return numberValue(r, exact), nil
+}
+
+// power calcula a^b exatamente quando b é inteiro e o resultado cabe em maxCalcBits;
+// nos outros casos usa ponto flutuante.
+func power(a, b calcValue) (calcValue, error) {
+	if b.num.IsInt() && b.num.Num().IsInt64() {
+		n := b.num.Num().Int64()
+		size := int64(a.num.Num().BitLen() + a.num.Denom().BitLen())
+		if n == 0 {
+			return numberValue(big.NewRat(1, 1), a.exact && b.exact), nil
+		}
+		if a.num.Sign() == 0 && n < 0 {
+			return calcValue{}, fmt.Errorf("divisão por zero")
+		}
+		abs := n
+		if abs < 0 {
+			abs = -abs
+		}
+		if abs <= maxCalcBits && size*abs <= maxCalcBits {
+			e := big.NewInt(abs)
+			num := new(big.Int).Exp(a.num.Num(), e, nil)
+			den := new(big.Int).Exp(a.num.Denom(), e, nil)
+			if n < 0 {
+				num, den = den, num
+			}
+			return numberValue(new(big.Rat).SetFrac(num, den), a.exact && b.exact), nil
+		}
+	}
+	x, err := a.float()
+	if err != nil {
+		return calcValue{}, err
+	}
+	y, err := b.float()
+	if err != nil {
+		return calcValue{}, err
+	}
+	if x < 0 && y != math.Trunc(y) {
+		return calcValue{}, fmt.Errorf("potência fracionária de número negativo")
+	}
+	v, err := floatValue(math.Pow(x, y))
+	if err != nil {
+		return calcValue{}, fmt.Errorf("resultado grande demais para %s^%s", formatRat(a.num, 6), formatRat(b.num, 6))
+	}
+	return v, nil
+}
+
+func factorial(x calcValue) (calcValue, error) {
+	if x.kind != calcNum || !x.num.IsInt() || x.num.Sign() < 0 || x.num.Cmp(big.NewRat(maxCalcFactorial, 1)) > 0 {
+		return calcValue{}, fmt.Errorf("fatorial só é definido para inteiros de 0 a %d", maxCalcFactorial)
+	}
+	n := x.num.Num().Int64()
+	f := big.NewInt(1)
+	if n > 1 {
+		f.MulRange(2, n)
+	}
+	return numberValue(new(big.Rat).SetInt(f), x.exact), nil
+}
+
+func addToDate(t time.Time, s calcSpan, subtract bool) (calcValue, error) {
+	if subtract {
+		neg, err := scaleSpan(s, big.NewRat(-1, 1))
+		if err != nil {
+			return calcValue{}, err
+		}
+		s = neg
+	}
+	if s.months > math.MaxInt32 || s.months < math.MinInt32 || s.days > math.MaxInt32 || s.days < math.MinInt32 {
+		return calcValue{}, fmt.Errorf("duração grande demais")
+	}
+	if s.months != 0 {
+		// Sem o ajuste, AddDate levaria 31/01 + 1 mês para 02/03 ou 03/03.
+		y, m, d := t.Date()
+		first := time.Date(y, m+time.Month(s.months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+		last := first.AddDate(0, 1, -1).Day()
+		t = first.AddDate(0, 0, min(d, last)-1)
+	}
+	return calcValue{kind: calcTime, t: t.AddDate(0, 0, int(s.days)).Add(time.Duration(s.nanos))}, nil
+}
+
+// dateDiff devolve o intervalo exato entre duas datas.
+func dateDiff(a, b time.Time) (calcValue, error) {
+	d := new(big.Int).Mul(big.NewInt(a.Unix()-b.Unix()), big.NewInt(int64(time.Second)))
+	d.Add(d, big.NewInt(int64(a.Nanosecond()-b.Nanosecond())))
+	if !d.IsInt64() {
+		return calcValue{}, fmt.Errorf("intervalo grande demais")
+	}
+	return calcValue{kind: calcDur, span: calcSpan{nanos: d.Int64()}}, nil
+}
+
+// floorRat arredonda para baixo.
+func floorRat(r *big.Rat) *big.Rat {
+	// Com divisor positivo (o denominador sempre é), Div é a divisão euclidiana, que
+	// arredonda para baixo.
+	return new(big.Rat).SetInt(new(big.Int).Div(r.Num(), r.Denom()))
+}
+
+// roundRat arredonda para digits casas decimais, com metades para longe do zero.
+func roundRat(r *big.Rat, digits int) *big.Rat {
+	scale := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(digits)), nil))
+	x := new(big.Rat).Mul(new(big.Rat).Abs(r), scale)
+	x = floorRat(x.Add(x, big.NewRat(1, 2)))
+	if r.Sign() < 0 {
+		x.Neg(x)
+	}
+	return x.Quo(x, scale)
+}
+
+// ::: Funções :::
+
+type calcFunc struct {
+	minArgs, maxArgs int // maxArgs < 0 aceita qualquer quantidade
+	fn               func(e *calcEval, args []calcValue) (calcValue, error)
+}
+
+var calcFunctions map[string]calcFunc
+
+func init() {
+	floatFn := func(f func(float64) float64) calcFunc {
+		return calcFunc{1, 1, func(_ *calcEval, args []calcValue) (calcValue, error) {
+			x, err := args[0].float()
+			if err != nil {
+				return calcValue{}, err
+			}
+			return floatValue(f(x))
+		}}
+	}
+	float2 := func(f func(float64, float64) float64) calcFunc {
+		return calcFunc{2, 2, func(_ *calcEval, args []calcValue) (calcValue, error) {
+			x, err := args[0].float()
+			if err != nil {
+				return calcValue{}, err
+			}
+			y, err := args[1].float()
+			if err != nil {
+				return calcValue{}, err
+			}
+			return floatValue(f(x, y))
+		}}
+	}
+	exactFn := func(f func(r *big.Rat) *big.Rat) calcFunc {
+		return calcFunc{1, 1, func(_ *calcEval, args []calcValue) (calcValue, error) {
+			return numberValue(f(args[0].num), args[0].exact), nil
+		}}
+	}
+	spanFn := func(unit time.Duration) calcFunc {
+		return calcFunc{1, 1, func(_ *calcEval, args []calcValue) (calcValue, error) {
+			n, err := args[0].span.fixedNanos()
+			if err != nil {
+				return calcValue{}, err
+			}
+			return numberValue(n.Quo(n, new(big.Rat).SetInt64(int64(unit))), true), nil
+		}}
+	}
+	datePart := func(f func(t time.Time) int) calcFunc {
+		return calcFunc{1, 1, func(_ *calcEval, args []calcValue) (calcValue, error) {
+			return numberValue(big.NewRat(int64(f(args[0].t)), 1), true), nil
+		}}
+	}
+
+	calcFunctions = map[string]calcFunc{
+		"sqrt":  {1, 1, sqrtFn},
+		"cbrt":  floatFn(math.Cbrt),
+		"exp":   floatFn(math.Exp),
+		"ln":    floatFn(math.Log),
+		"log10": floatFn(math.Log10),
+		"log2":  floatFn(math.Log2),
+		"log": {1, 2, func(_ *calcEval, args []calcValue) (calcValue, error) {
+			x, err := args[0].float()
+			if err != nil {
+				return calcValue{}, err
+			}
+			if len(args) == 1 {
+				return floatValue(math.Log(x))
+			}
+			base, err := args[1].float()
+			if err != nil {
+				return calcValue{}, err
+			}
+			return floatValue(math.Log(x) / math.Log(base))
+		}},
+		"sin":     floatFn(math.Sin),
+		"cos":     floatFn(math.Cos),
+		"tan":     floatFn(math.Tan),
+		"asin":    floatFn(math.Asin),
+		"acos":    floatFn(math.Acos),
+		"atan":    floatFn(math.Atan),
+		"sinh":    floatFn(math.Sinh),
+		"cosh":    floatFn(math.Cosh),
+		"tanh":    floatFn(math.Tanh),
+		"radians": floatFn(func(x float64) float64 { return x * math.Pi / 180 }),
+		"degrees": floatFn(func(x float64) float64 { return x * 180 / math.Pi }),
+		"atan2":   float2(math.Atan2),
+		"hypot":   float2(math.Hypot),
+		"pow": {2, 2, func(_ *calcEval, args []calcValue) (calcValue, error) {
+			return power(args[0], args[1])
+		}},
+		"abs":   exactFn(func(r *big.Rat) *big.Rat { return new(big.Rat).Abs(r) }),
+		"floor": exactFn(floorRat),
+		"ceil": exactFn(func(r *big.Rat) *big.Rat {
+			c := floorRat(new(big.Rat).Neg(r))
+			return c.Neg(c)
+		}),
+		"trunc": exactFn(func(r *big.Rat) *big.Rat { return new(big.Rat).SetInt(new(big.Int).Quo(r.Num(), r.Denom())) }),
+		"round": {1, 2, func(_ *calcEval, args []calcValue) (calcValue, error) {
+			digits := 0
+			if len(args) == 2 {
+				if !args[1].num.IsInt() || args[1].num.Sign() < 0 || args[1].num.Cmp(big.NewRat(maxCalcPrecision, 1)) > 0 {
+					return calcValue{}, fmt.Errorf("round: as casas decimais devem ser um inteiro de 0 a %d", maxCalcPrecision)
+				}
+				digits = int(args[1].num.Num().Int64())
+			}
+			return numberValue(roundRat(args[0].num, digits), args[0].exact), nil
+		}},
+		"mod": {2, 2, func(_ *calcEval, args []calcValue) (calcValue, error) {
+			return numericBinary("%", args[0], args[1])
+		}},
+		"min":       {1, -1, func(_ *calcEval, args []calcValue) (calcValue, error) { return calcExtreme(args, -1) }},
+		"max":       {1, -1, func(_ *calcEval, args []calcValue) (calcValue, error) { return calcExtreme(args, 1) }},
+		"gcd":       {2, 2, func(_ *calcEval, args []calcValue) (calcValue, error) { return gcdLcm(args, false) }},
+		"lcm":       {2, 2, func(_ *calcEval, args []calcValue) (calcValue, error) { return gcdLcm(args, true) }},
+		"factorial": {1, 1, func(_ *calcEval, args []calcValue) (calcValue, error) { return factorial(args[0]) }},
+
+		"now": {0, 1, func(e *calcEval, args []calcValue) (calcValue, error) {
+			loc, err := e.zoneArg(args, 0)
+			if err != nil {
+				return calcValue{}, err
+			}
+			return calcValue{kind: calcTime, t: calcNow().In(loc)}, nil
+		}},
+		"today": {0, 1, func(e *calcEval, args []calcValue) (calcValue, error) {
+			loc, err := e.zoneArg(args, 0)
+			if err != nil {
+				return calcValue{}, err
+			}
+			y, m, d := calcNow().In(loc).Date()
+			return calcValue{kind: calcTime, t: time.Date(y, m, d, 0, 0, 0, 0, loc)}, nil
+		}},
+		"date": {1, 2, func(e *calcEval, args []calcValue) (calcValue, error) {
+			loc, err := e.zoneArg(args, 1)
+			if err != nil {
+				return calcValue{}, err
+			}
+			t, err := parseCalcDate(args[0].str, loc)
+			return calcValue{kind: calcTime, t: t}, err
+		}},
+		"tz": {2, 2, func(e *calcEval, args []calcValue) (calcValue, error) {
+			loc, err := e.zoneArg(args, 1)
+			if err != nil {
+				return calcValue{}, err
+			}
+			return calcValue{kind: calcTime, t: args[0].t.In(loc)}, nil
+		}},
+		"fromunix": {1, 2, func(e *calcEval, args []calcValue) (calcValue, error) {
+			loc, err := e.zoneArg(args, 1)
+			if err != nil {
+				return calcValue{}, err
+			}
+			nanos := new(big.Rat).Mul(args[0].num, big.NewRat(int64(time.Second), 1))
+			n, err := ratInt64(nanos)
+			if err != nil {
+				return calcValue{}, fmt.Errorf("fromunix: data fora do intervalo")
+			}
+			return calcValue{kind: calcTime, t: time.Unix(0, n).In(loc)}, nil
+		}},
+		"unix": {1, 1, func(_ *calcEval, args []calcValue) (calcValue, error) {
+			t := args[0].t
+			r := new(big.Rat).SetFrac(big.NewInt(int64(t.Nanosecond())), big.NewInt(int64(time.Second)))
+			return numberValue(r.Add(r, new(big.Rat).SetInt64(t.Unix())), true), nil
+		}},
+		"duration": {1, 1, func(_ *calcEval, args []calcValue) (calcValue, error) {
+			parts, err := parseSpanText(args[0].str)
+			if err != nil {
+				return calcValue{}, err
+			}
+			s, err := spanFromParts(parts)
+			return calcValue{kind: calcDur, span: s}, err
+		}},
+		"year":      datePart(func(t time.Time) int { return t.Year() }),
+		"month":     datePart(func(t time.Time) int { return int(t.Month()) }),
+		"day":       datePart(func(t time.Time) int { return t.Day() }),
+		"hour":      datePart(func(t time.Time) int { return t.Hour() }),
+		"minute":    datePart(func(t time.Time) int { return t.Minute() }),
+		"second":    datePart(func(t time.Time) int { return t.Second() }),
+		"weekday":   datePart(func(t time.Time) int { return int(t.Weekday()) }),
+		"dayofyear": datePart(func(t time.Time) int { return t.YearDay() }),
+		"days":      spanFn(24 * time.Hour),
+		"hours":     spanFn(time.Hour),
+		"minutes":   spanFn(time.Minute),
+		"seconds":   spanFn(time.Second),
+	}
+}
+
+// calcArgKinds diz o tipo esperado dos argumentos das funções que não recebem números.
+var calcArgKinds = map[string][]calcKind{
+	"now": {calcStr}, "today": {calcStr}, "date": {calcStr, calcStr}, "tz": {calcTime, calcStr},
+	"fromunix": {calcNum, calcStr}, "unix": {calcTime}, "duration": {calcStr},
+	"year": {calcTime}, "month": {calcTime}, "day": {calcTime}, "hour": {calcTime}, "minute": {calcTime},
+	"second": {calcTime}, "weekday": {calcTime}, "dayofyear": {calcTime},
+	"days": {calcDur}, "hours": {calcDur}, "minutes": {calcDur}, "seconds": {calcDur},
+}
+
+func (e *calcEval) call(name string, args []calcValue) (calcValue, error) {
+	f := calcFunctions[name]
+	if len(args) < f.minArgs || f.maxArgs >= 0 && len(args) > f.maxArgs {
+		switch {
+		case f.maxArgs < 0:
+			return calcValue{}, fmt.Errorf("%s: espera ao menos %d argumento(s)", name, f.minArgs)
+		case f.minArgs == f.maxArgs:
+			return calcValue{}, fmt.Errorf("%s: espera %d argumento(s), recebeu %d", name, f.minArgs, len(args))
+		default:
+			return calcValue{}, fmt.Errorf("%s: espera de %d a %d argumentos, recebeu %d", name, f.minArgs, f.maxArgs, len(args))
+		}
+	}
+	kinds := calcArgKinds[name]
+	for i, a := range args {
+		want := calcNum
+		if i < len(kinds) {
+			want = kinds[i]
+		}
+		if name == "min" || name == "max" {
+			want = args[0].kind
+		}
+		if a.kind != want {
+			return calcValue{}, fmt.Errorf("%s: o argumento %d deve ser %s, não %s", name, i+1, want, a.kind)
+		}
+	}
+	return f.fn(e, args)
+}
+
+// zoneArg devolve o fuso do argumento i, ou o fuso padrão quando ele não foi passado.
+func (e *calcEval) zoneArg(args []calcValue, i int) (*time.Location, error) {
+	if i >= len(args) {
+		return e.loc, nil
+	}
+	return loadZone(args[i].str)
+}
+
+func loadZone(name string) (*time.Location, error) {
+	if strings.EqualFold(name, "local") {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("fuso horário desconhecido '%s'; use nomes IANA como \"America/Sao_Paulo\" ou \"UTC\"", name)
+	}
+	return loc, nil
+}
+
+// calcDateLayouts são os formatos aceitos por date(); os sem fuso usam o fuso pedido.
+var calcDateLayouts = []string{
+	time.RFC3339Nano, "2006-01-02T15:04Z07:00", "2006-01-02 15:04:05Z07:00",
+	"2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02T15:04", "2006-01-02 15:04", "2006-01-02",
+}
+
+func parseCalcDate(s string, loc *time.Location) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range calcDateLayouts {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("data inválida '%s'; use o formato ISO, como \"2024-03-10\", \"2024-03-10 14:30\" ou \"2024-03-10T14:30:00-03:00\"", s)
+}
+
+func sqrtFn(_ *calcEval, args []calcValue) (calcValue, error) {
+	r := args[0].num
+	if r.Sign() < 0 {
+		return calcValue{}, fmt.Errorf("raiz quadrada de número negativo")
+	}
+	num, den := new(big.Int).Sqrt(r.Num()), new(big.Int).Sqrt(r.Denom())
+	if new(big.Int).Mul(num, num).Cmp(r.Num()) == 0 && new(big.Int).Mul(den, den).Cmp(r.Denom()) == 0 {
+		return numberValue(new(big.Rat).SetFrac(num, den), args[0].exact), nil
+	}
+	x, err := args[0].float()
+	if err != nil {
+		return calcValue{}, err
+	}
+	return floatValue(math.Sqrt(x))
+}
+
+// calcExtreme devolve o menor (dir -1) ou o maior (dir 1) entre números ou datas.
+func calcExtreme(args []calcValue, dir int) (calcValue, error) {
+	best := args[0]
+	for _, a := range args[1:] {
+		var c int
+		switch a.kind {
+		case calcNum:
+			c = a.num.Cmp(best.num)
+		case calcTime:
+			c = a.t.Compare(best.t)
+		default:
+			return calcValue{}, fmt.Errorf("min e max aceitam números ou datas")
+		}
+		if c == dir {
+			best = a
+		}
+	}
+	return best, nil
+}
+
+func gcdLcm(args []calcValue, lcm bool) (calcValue, error) {
+	a, b := args[0].num, args[1].num
+	if !a.IsInt() || !b.IsInt() {
+		return calcValue{}, fmt.Errorf("gcd e lcm só aceitam inteiros")
+	}
+	x, y := new(big.Int).Abs(a.Num()), new(big.Int).Abs(b.Num())
+	g := new(big.Int).GCD(nil, nil, x, y)
+	if !lcm {
+		return numberValue(new(big.Rat).SetInt(g), true), nil
+	}
+	if g.Sign() == 0 {
+		return numberValue(new(big.Rat), true), nil
+	}
+	return numberValue(new(big.Rat).SetInt(x.Mul(x.Quo(x, g), y)), true), nil
+}
+
+// ::: Formatação :::
+
+// formatRat escreve r com no máximo digits casas decimais, sem zeros à direita.
+func formatRat(r *big.Rat, digits int) string {
+	s := r.FloatString(digits)
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	if s == "-0" {
+		s = "0"
+	}
+	return s
+}
+
+// decimalDigits devolve as casas decimais da expansão finita de r, ou -1 quando a
+// expansão é uma dízima ou tem mais de maxCalcPrecision casas.
+func decimalDigits(r *big.Rat) int {
+	twos := int(r.Denom().TrailingZeroBits())
+	if twos > maxCalcPrecision {
+		return -1
+	}
+	den := new(big.Int).Rsh(r.Denom(), uint(twos))
+	fives := 0
+	five, mod := big.NewInt(5), new(big.Int)
+	for {
+		q, m := new(big.Int).QuoRem(den, five, mod)
+		if m.Sign() != 0 {
+			break
+		}
+		if den, fives = q, fives+1; fives > maxCalcPrecision {
+			return -1
+		}
+	}
+	if den.Cmp(big.NewInt(1)) != 0 {
+		return -1
+	}
+	return max(twos, fives)
+}
+
+// formatCalcValue escreve o resultado e, quando o valor não é exato, uma observação.
+func formatCalcValue(v calcValue, precision int) (value, note string) {
+	switch v.kind {
+	case calcTime:
+		layout := "2006-01-02T15:04:05Z07:00"
+		if v.t.Nanosecond() != 0 {
+			layout = time.RFC3339Nano
+		}
+		zone := v.t.Location().String()
+		if zone == "Local" {
+			zone, _ = v.t.Zone()
+		}
+		return fmt.Sprintf("%s (%s, %s)", v.t.Format(layout), calcWeekdays[v.t.Weekday()], zone), ""
+	case calcDur:
+		value = v.span.String()
+		if n, err := v.span.fixedNanos(); err == nil && n.Sign() != 0 {
+			sec := new(big.Rat).Quo(n, big.NewRat(int64(time.Second), 1))
+			hours := new(big.Rat).Quo(n, big.NewRat(int64(time.Hour), 1))
+			days := new(big.Rat).Quo(n, big.NewRat(int64(24*time.Hour), 1))
+			value += fmt.Sprintf(" (%s segundos; %s horas; %s dias)", formatRat(sec, 9), formatRat(hours, 6), formatRat(days, 6))
+		}
+		return value, ""
+	case calcStr:
+		return strconv.Quote(v.str), ""
+	}
+
+	r := v.num
+	if !v.exact {
+		f := new(big.Float).SetPrec(64).SetRat(r)
+		return f.Text('g', 15), "valor aproximado (ponto flutuante, cerca de 15 dígitos significativos)"
+	}
+	if r.IsInt() {
+		s := r.Num().String()
+		if digits := len(strings.TrimPrefix(s, "-")); digits > maxCalcDigits {
+			f := new(big.Float).SetPrec(128).SetRat(r)
+			return f.Text('g', 30), fmt.Sprintf("inteiro exato com %d dígitos, mostrado em notação científica", digits)
+		}
+		return s, ""
+	}
+	if d := decimalDigits(r); d >= 0 && d <= precision {
+		return r.FloatString(d), ""
+	}
+	note = fmt.Sprintf("arredondado para %d casas decimais", precision)
+	if frac := r.String(); len(frac) <= 60 {
+		note += "; fração exata: " + frac
+	}
+	return formatRat(r, precision), note
+}
+
+// ::: Ferramenta: Calculate :::
+
+type CalculateInput struct {
+	Expression string `json:"expression"`
+	Precision  int    `json:"precision,omitempty"` // casas decimais de resultados não exatos
+	Timezone   string `json:"timezone,omitempty"`  // fuso padrão de now(), today() e date()
+}
+
+func calculate(input json.RawMessage) (string, error) {
+	var typedInput CalculateInput
+	if err := json.Unmarshal(input, &typedInput); err != nil {
+		return "", fmt.Errorf("JSON inválido para argumentos: %w", err)
+	}
+	if strings.TrimSpace(typedInput.Expression) == "" {
+		return "", fmt.Errorf("argumento inválido. 'expression' é obrigatório")
+	}
+	if len(typedInput.Expression) > maxCalcExpression {
+		return "", fmt.Errorf("argumento inválido. 'expression' tem mais de %d caracteres", maxCalcExpression)
+	}
+	if typedInput.Precision < 0 || typedInput.Precision > maxCalcPrecision {
+		return "", fmt.Errorf("argumento inválido. 'precision' deve estar entre 0 e %d", maxCalcPrecision)
+	}
+	precision := typedInput.Precision
+	if precision == 0 {
+		precision = defaultCalcPrecision
+	}
+	e := &calcEval{loc: time.Local}
+	if typedInput.Timezone != "" {
+		loc, err := loadZone(typedInput.Timezone)
+		if err != nil {
+			return "", err
+		}
+		e.loc = loc
+	}
+
+	n, err := parseCalc(typedInput.Expression)
+	if err != nil {
+		return "", fmt.Errorf("expressão inválida: %w", err)
+	}
+	v, err := e.eval(n)
+	if err != nil {
+		return "", fmt.Errorf("erro ao calcular %s: %w", n, err)
+	}
+	if v.kind == calcStr {
+		return "", fmt.Errorf("o resultado de %s é um texto; textos só servem como argumento de funções como date()", n)
+	}
+
+	value, note := formatCalcValue(v, precision)
+	out := fmt.Sprintf("Expressão: %s\nResultado: %s", n, value)
+	if note != "" {
+		out += "\nObservação: " + note
+	}
+	return out, nil
+}
+
+var CalculateDef = toolkit.ToolDefinition{
+	Name:        "calculate",
+	Description: `Calcula expressões com precisão, sem executar código: use em vez de fazer contas de cabeça. Números são racionais exatos (0.1 + 0.2 = 0.3, 2^200 e 50! com todos os dígitos); funções transcendentais usam ponto flutuante e o resultado avisa quando é aproximado. Operadores: + - * / % ^ (ou **) e ! com precedência usual e parênteses. Funções: sqrt, cbrt, exp, ln, log(x[, base]), log10, log2, sin, cos, tan, asin, acos, atan, atan2, hypot, radians, degrees, abs, floor, ceil, trunc, round(x[, casas]), mod, min, max, gcd, lcm, factorial; constantes pi, tau, e, phi. Datas e durações: now(["fuso"]), today(["fuso"]), date("2024-03-10 14:30"[, "fuso"]), tz(data, "America/Sao_Paulo"), fromunix(n), unix(data), year/month/day/hour/minute/second/weekday(0=domingo)/dayofyear(data), durações como 90min, 1h30m, "3 days", 2 weeks, 1 month, e days/hours/minutes/seconds(duração). Data ± duração dá data (meses e dias seguem o calendário), data - data dá a duração exata. Retorna a expressão normalizada e o resultado. Exemplos: {"expression": "(1.07^10 - 1) * 100"}, {"expression": "days(date(\"2025-12-25\") - today())", "timezone": "America/Sao_Paulo"}`,
+	Function:    calculate,
+}
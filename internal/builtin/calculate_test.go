@@ -0,0 +1,117 @@
+package builtin
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestCalculate testa a expressão normalizada e o resultado de números, datas e durações.
+func TestCalculate(t *testing.T) {
+	previous := calcNow
+	calcNow = func() time.Time { return time.Date(2024, 3, 10, 15, 30, 0, 0, time.UTC) }
+	t.Cleanup(func() { calcNow = previous })
+
+	testCases := []struct {
+		expression    string
+		timezone      string
+		precision     int
+		expected      string
+		expectedError string
+	}{
+		{expression: "0.1 + 0.2", expected: "Expressão: 0.1 + 0.2\nResultado: 0.3"},
+		{expression: "2+3*4", expected: "Expressão: 2 + 3 * 4\nResultado: 14"},
+		{expression: "((2 + 3)) × 4", expected: "Expressão: (2 + 3) * 4\nResultado: 20"},
+		{expression: "10 - (4 - 1)", expected: "Expressão: 10 - (4 - 1)\nResultado: 7"},
+		{expression: "-2^2", expected: "Expressão: -2^2\nResultado: -4"},
+		{expression: "2**3^2", expected: "Expressão: 2^3^2\nResultado: 512"},
+		{expression: "2^-2", expected: "Expressão: 2^(-2)\nResultado: 0.25"},
+		{expression: "-7 % 3", expected: "Expressão: -7 % 3\nResultado: 2"},
+		{expression: "1_000 * 1e3", expected: "Expressão: 1000 * 1e3\nResultado: 1000000"},
+		{expression: "2^100", expected: "Expressão: 2^100\nResultado: 1267650600228229401496703205376"},
+		{expression: "25!", expected: "Expressão: 25!\nResultado: 15511210043330985984000000"},
+		{
+			expression: "1/3",
+			expected:   "Expressão: 1 / 3\nResultado: 0.33333333333333333333\nObservação: arredondado para 20 casas decimais; fração exata: 1/3",
+		},
+		{
+			expression: "2/3",
+			precision:  4,
+			expected:   "Resultado: 0.6667\nObservação: arredondado para 4 casas decimais; fração exata: 2/3",
+		},
+		{expression: "1e-100000", expected: "Resultado: 0\nObservação: arredondado para 20 casas decimais"},
+		{expression: "1e-1000 * 2", precision: 1000, expected: "Resultado: 0." + strings.Repeat("0", 999) + "2"},
+		{expression: "sqrt(16/9) * 3", expected: "Resultado: 4"},
+		{
+			expression: "sqrt(2)",
+			expected:   "Resultado: 1.4142135623731\nObservação: valor aproximado (ponto flutuante, cerca de 15 dígitos significativos)",
+		},
+		{expression: "round(-2.345, 2) + ceil(1.2) + floor(-1.2) + abs(-3)", expected: "Resultado: 0.65"},
+		{expression: "max(gcd(12, 18), lcm(4, 6)) + min(3, 1, 2)", expected: "Resultado: 13"},
+		{expression: "round(log(8, 2), 6)", expected: "Resultado: 3"},
+		{expression: "1h30m + 2 h 15 min", expected: "Expressão: 1h30min + 2h15min\nResultado: 3h 45min (13500 segundos; 3.75 horas; 0.15625 dias)"},
+		{expression: "1.5 days / 2", expected: "Expressão: 1.5d / 2\nResultado: 18h (64800 segundos; 18 horas; 0.75 dias)"},
+		{expression: "2 weeks / 1h", expected: "Resultado: 336"},
+		{expression: "3 / 1h", expectedError: "operação inválida: número / duração"},
+		{expression: "1 year + 2 months", expected: "Resultado: 1y 2mo"},
+		{expression: "date(\"2024-01-31\") + 1 month", timezone: "UTC", expected: "Resultado: 2024-02-29T00:00:00Z (quinta-feira, UTC)"},
+		{
+			expression: "date(\"2025-12-25\") - date(\"2025-01-01 12:00\")",
+			timezone:   "UTC",
+			expected:   "Resultado: 357d 12h (30888000 segundos; 8580 horas; 357.5 dias)",
+		},
+		{
+			expression: "date(\"2024-03-09 12:00\", \"America/New_York\") + 1 day",
+			expected:   "Resultado: 2024-03-10T12:00:00-04:00 (domingo, America/New_York)",
+		},
+		{
+			expression: "date(\"2024-03-09 12:00\", \"America/New_York\") + 24h",
+			expected:   "Resultado: 2024-03-10T13:00:00-04:00 (domingo, America/New_York)",
+		},
+		{expression: "now()", timezone: "America/Sao_Paulo", expected: "Resultado: 2024-03-10T12:30:00-03:00 (domingo, America/Sao_Paulo)"},
+		{expression: "days(date(\"2024-12-25\", \"UTC\") - today(\"UTC\"))", expected: "Resultado: 290"},
+		{expression: "tz(date(\"2024-03-10T12:00:00Z\"), \"Asia/Tokyo\")", expected: "Resultado: 2024-03-10T21:00:00+09:00 (domingo, Asia/Tokyo)"},
+		{expression: "weekday(today()) + unix(date(\"1970-01-02\", \"UTC\"))", timezone: "UTC", expected: "Resultado: 86400"},
+		{expression: "1/0", expectedError: "erro ao calcular 1 / 0: divisão por zero"},
+		{expression: "2 +", expectedError: "expressão inválida: fim inesperado da expressão"},
+		{expression: "2 # 3", expectedError: "caractere inesperado '#' na posição 3"},
+		{expression: "foo(1)", expectedError: "função desconhecida 'foo' na posição 1"},
+		{expression: "sqrt(1, 2)", expectedError: "sqrt: espera 1 argumento(s), recebeu 2"},
+		{expression: "year(3)", expectedError: "year: o argumento 1 deve ser data, não número"},
+		{expression: "1 + date(\"2024-01-01\")", expectedError: "operação inválida: número + data"},
+		{expression: "date(\"10/03/2024\")", expectedError: "data inválida '10/03/2024'"},
+		{expression: "now(\"Marte/Olimpo\")", expectedError: "fuso horário desconhecido 'Marte/Olimpo'"},
+		{expression: "1.5 months", expectedError: "a duração precisa ter um número inteiro de meses"},
+		{expression: "days(1 month)", expectedError: "durações com meses ou anos não têm tamanho fixo"},
+		{expression: "(-8)^(1/3)", expectedError: "potência fracionária de número negativo"},
+		{expression: "exp(1000)", expectedError: "resultado não é um número finito"},
+		{expression: "20000!", expectedError: "fatorial só é definido para inteiros de 0 a 10000"},
+		{expression: "1e-1000000", expectedError: "número '1e-1000000' na posição 1 passa do limite de 1048576 bits"},
+		{expression: "1e1000000000", expectedError: "passa do limite de 1048576 bits"},
+		{expression: "1e300000 * 1e300000", expectedError: "erro ao calcular 1e300000 * 1e300000: resultado passa do limite de 1048576 bits"},
+		{expression: "1e-300000 / 1e300000", expectedError: "resultado passa do limite de 1048576 bits"},
+		{expression: "1e300000 + 1e-300000", expectedError: "resultado passa do limite de 1048576 bits"},
+		{expression: strings.Repeat("(", 300) + "1" + strings.Repeat(")", 300), expectedError: "expressão aninhada demais"},
+		{expression: "", expectedError: "'expression' é obrigatório"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.expression, func(t *testing.T) {
+			rawInput, _ := json.Marshal(CalculateInput{Expression: tc.expression, Timezone: tc.timezone, Precision: tc.precision})
+			result, err := calculate(rawInput)
+			if tc.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedError) {
+					t.Fatalf("erro = %v, esperado conter %q", err, tc.expectedError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+			if !strings.Contains(result, tc.expected) {
+				t.Errorf("resultado =\n%s\nesperado conter\n%s", result, tc.expected)
+			}
+		})
+	}
+}
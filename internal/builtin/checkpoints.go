@@ -0,0 +1,71 @@
+package builtin
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/matheusbuniotto/goagent/internal/checkpoint"
+)
+
+var (
+	checkpointMu sync.RWMutex
+	checkpoints  *checkpoint.Store
+)
+
+// SetCheckpointStore define onde as ferramentas que alteram arquivos registram o estado
+// anterior. Com nil, nenhum checkpoint é criado.
+func SetCheckpointStore(s *checkpoint.Store) {
+	checkpointMu.Lock()
+	defer checkpointMu.Unlock()
+	checkpoints = s
+}
+
+// CheckpointStore retorna o store de checkpoints em uso, ou nil.
+func CheckpointStore() *checkpoint.Store {
+	checkpointMu.RLock()
+	defer checkpointMu.RUnlock()
+	return checkpoints
+}
+
+// recordCheckpoint registra o estado dos caminhos antes que a ferramenta os altere.
+func recordCheckpoint(tool string, paths ...string) error {
+	s := CheckpointStore()
+	if s == nil || len(paths) == 0 {
+		return nil
+	}
+	if _, err := s.Create(tool, paths...); err != nil {
+		return fmt.Errorf("alteração cancelada, não foi possível criar o checkpoint: %w", err)
+	}
+	return nil
+}
+
+// recordCheckpointNoFollow é como recordCheckpoint, para ferramentas que removem ou
+// renomeiam os caminhos: links simbólicos são registrados como links.
+func recordCheckpointNoFollow(tool string, paths ...string) error {
+	s := CheckpointStore()
+	if s == nil || len(paths) == 0 {
+		return nil
+	}
+	if _, err := s.CreateNoFollow(tool, paths...); err != nil {
+		return fmt.Errorf("alteração cancelada, não foi possível criar o checkpoint: %w", err)
+	}
+	return nil
+}
+
+// firstMissing retorna o ancestral mais raso de path que ainda não existe (ou o próprio
+// path), para que desfazer um MkdirAll remova todos os diretórios criados. Retorna ""
+// se path já existe.
+func firstMissing(path string) string {
+	missing := ""
+	for p := path; ; p = filepath.Dir(p) {
+		if _, err := os.Lstat(p); err == nil {
+			return missing
+		}
+		missing = p
+		if filepath.Dir(p) == p {
+			return missing
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package builtin
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestFileToolsCheckpoints testa que as ferramentas de escrita registram o estado anterior.
+func TestFileToolsCheckpoints(t *testing.T) {
+	root, store := newTestWorkspaceWithCheckpoints(t, map[string]string{"a.txt": "antes"})
+
+	writeJSON, _ := json.Marshal(WriteFileInput{Path: "a.txt", Content: "depois"})
+	if _, err := writeFile(writeJSON); err != nil {
+		t.Fatalf("writeFile() erro inesperado: %v", err)
+	}
+	createJSON, _ := json.Marshal(CreateDirectoryInput{Path: "x/y/z"})
+	if _, err := createDirectory(createJSON); err != nil {
+		t.Fatalf("createDirectory() erro inesperado: %v", err)
+	}
+	// Diretório já existente: nada a registrar.
+	if _, err := createDirectory(createJSON); err != nil {
+		t.Fatalf("createDirectory() erro inesperado: %v", err)
+	}
+
+	list, _ := store.List()
+	if len(list) != 2 {
+		t.Fatalf("checkpoints = %d, esperado 2", len(list))
+	}
+
+	if _, err := store.Revert(1); err != nil {
+		t.Fatalf("Revert() erro inesperado: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "x")); !os.IsNotExist(err) {
+		t.Error("desfazer create_directory deveria remover todos os diretórios criados")
+	}
+	if data, _ := os.ReadFile(filepath.Join(root, "a.txt")); string(data) != "antes" {
+		t.Errorf("conteúdo restaurado = %q, esperado %q", data, "antes")
+	}
+}
@@ -0,0 +1,821 @@
+package builtin
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/matheusbuniotto/goagent/pkg/toolkit"
+)
+
+// maxCSVBytes é o maior arquivo CSV aceito por csv_query.
+const maxCSVBytes = 50 << 20
+
+// defaultCSVLimit é o número de linhas do resultado quando limit não é informado.
+const defaultCSVLimit = 20
+
+// maxCSVLimit é o maior limit aceito, para manter as tabelas pequenas.
+const maxCSVLimit = 500
+
+// maxCSVCell é o número de caracteres mostrados de cada célula.
+const maxCSVCell = 60
+
+// csvDelimiters são os delimitadores tentados na detecção, em ordem de preferência.
+var csvDelimiters = []rune{',', ';', '\t', '|'}
+
+var (
+	// csvFilterRegex separa "coluna operador valor" em um filtro de where.
+	csvFilterRegex = regexp.MustCompile(`^\s*(.+?)\s*(==|!=|>=|<=|!~|=|>|<|~)\s*(.*?)\s*$`)
+	// csvAggregateRegex reconhece "count", "avg(coluna)", "p95(coluna)" etc.
+	csvAggregateRegex = regexp.MustCompile(`^\s*([a-zA-Z_]+|p\d+(?:\.\d+)?)\s*(?:\((.*)\))?\s*$`)
+)
+
+// csvTable é um CSV carregado, com as linhas já completadas até o número de colunas.
+type csvTable struct {
+	columns   []string
+	rows      [][]string
+	delimiter rune
+	header    bool
+	ragged    int // linhas com número de campos diferente do cabeçalho
+}
+
+// column devolve o índice da coluna pelo nome, sem diferenciar maiúsculas se não houver
+// uma igual.
+func (t *csvTable) column(name string) (int, error) {
+	name = strings.TrimSpace(name)
+	for i, c := range t.columns {
+		if c == name {
+			return i, nil
+		}
+	}
+	for i, c := range t.columns {
+		if strings.EqualFold(c, name) {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("coluna '%s' não existe (colunas: %s)", name, strings.Join(t.columns, ", "))
+}
+
+// detectDelimiter escolhe o delimitador que aparece o mesmo número de vezes (fora de
+// aspas) nas primeiras linhas; sem nenhum consistente, o mais frequente.
+func detectDelimiter(data string) rune {
+	var lines []string
+	for _, line := range strings.Split(data, "\n") {
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+		if len(lines) == 20 {
+			break
+		}
+	}
+	best, bestScore := ',', 0
+	for _, d := range csvDelimiters {
+		first, consistent := -1, true
+		total := 0
+		for _, line := range lines {
+			n := countOutsideQuotes(line, d)
+			total += n
+			if first == -1 {
+				first = n
+			} else if n != first {
+				consistent = false
+			}
+		}
+		score := total
+		if consistent && first > 0 {
+			score = 1_000_000 + first
+		}
+		if score > bestScore {
+			best, bestScore = d, score
+		}
+	}
+	return best
+}
+
+// countOutsideQuotes conta as ocorrências de r que não estão entre aspas duplas.
+func countOutsideQuotes(line string, r rune) int {
+	n, quoted := 0, false
+	for _, c := range line {
+		switch {
+		case c == '"':
+			quoted = !quoted
+		case c == r && !quoted:
+			n++
+		}
+	}
+	return n
+}
+
+// detectHeader considera a primeira linha um cabeçalho quando todos os campos são
+// textos não vazios, sem repetição e que não parecem números.
+func detectHeader(records [][]string) bool {
+	if len(records) == 0 {
+		return false
+	}
+	seen := map[string]bool{}
+	for _, cell := range records[0] {
+		cell = strings.TrimSpace(cell)
+		if cell == "" || seen[cell] {
+			return false
+		}
+		if _, ok := csvNumber(cell); ok {
+			return false
+		}
+		seen[cell] = true
+	}
+	return true
+}
+
+// parseDelimiter interpreta o argumento delimiter.
+func parseDelimiter(s string) (rune, error) {
+	switch s {
+	case ",", ";", "|", ":":
+		return rune(s[0]), nil
+	case "\t", "\\t", "tab":
+		return '\t', nil
+	}
+	return 0, fmt.Errorf("delimitador inválido '%s'. Use \",\", \";\", \"|\", \":\" ou \"tab\"", s)
+}
+
+// delimiterName descreve o delimitador na saída.
+func delimiterName(d rune) string {
+	if d == '\t' {
+		return "tab"
+	}
+	return "'" + string(d) + "'"
+}
+
+// loadCSV lê um CSV, detectando delimitador e cabeçalho quando não informados.
+func loadCSV(abs, display, delimiter string, header *bool) (*csvTable, error) {
+	info, err := os.Stat(abs)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao acessar o arquivo '%s': %w", display, err)
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("'%s' é um diretório", display)
+	}
+	if info.Size() > maxCSVBytes {
+		return nil, fmt.Errorf("'%s' tem %d bytes; o limite de csv_query é %d", display, info.Size(), maxCSVBytes)
+	}
+	raw, err := os.ReadFile(abs)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler o arquivo '%s': %w", display, err)
+	}
+	data := strings.TrimPrefix(string(raw), "\ufeff")
+
+	t := &csvTable{}
+	if delimiter != "" {
+		if t.delimiter, err = parseDelimiter(delimiter); err != nil {
+			return nil, err
+		}
+	} else {
+		t.delimiter = detectDelimiter(data)
+	}
+
+	r := csv.NewReader(strings.NewReader(data))
+	r.Comma = t.delimiter
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("'%s' não é um CSV válido: %w", display, err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("'%s' está vazio", display)
+	}
+
+	if header != nil {
+		t.header = *header
+	} else {
+		t.header = detectHeader(records)
+	}
+	width := len(records[0])
+	if t.header {
+		for _, c := range records[0] {
+			t.columns = append(t.columns, strings.TrimSpace(c))
+		}
+		records = records[1:]
+	} else {
+		for _, rec := range records {
+			width = max(width, len(rec))
+		}
+		for i := range width {
+			t.columns = append(t.columns, fmt.Sprintf("col%d", i+1))
+		}
+	}
+
+	for _, rec := range records {
+		if len(rec) == 1 && strings.TrimSpace(rec[0]) == "" && width > 1 {
+			continue // Linha em branco
+		}
+		if len(rec) != width {
+			t.ragged++
+		}
+		row := make([]string, width)
+		for i := range min(len(rec), width) {
+			row[i] = strings.TrimSpace(rec[i])
+		}
+		t.rows = append(t.rows, row)
+	}
+	return t, nil
+}
+
+// csvNumber interpreta uma célula como número.
+func csvNumber(s string) (float64, bool) {
+	if s == "" {
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, false
+	}
+	return f, true
+}
+
+// formatCSVNumber escreve números sem expoente e com no máximo 6 casas decimais.
+func formatCSVNumber(f float64) string {
+	if f == math.Trunc(f) && math.Abs(f) < 1e15 {
+		return strconv.FormatFloat(f, 'f', 0, 64)
+	}
+	s := strconv.FormatFloat(f, 'f', 6, 64)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+// compareCells compara numericamente quando os dois valores são números e como texto
+// nos outros casos.
+func compareCells(a, b string) int {
+	fa, okA := csvNumber(a)
+	fb, okB := csvNumber(b)
+	if okA && okB {
+		switch {
+		case fa < fb:
+			return -1
+		case fa > fb:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(a, b)
+}
+
+// csvDateLayouts são os formatos reconhecidos como data no esquema.
+var csvDateLayouts = []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02T15:04:05", "2006-01-02"}
+
+// cellType classifica uma célula não vazia.
+func cellType(s string) string {
+	if _, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return "inteiro"
+	}
+	if _, ok := csvNumber(s); ok {
+		return "decimal"
+	}
+	switch strings.ToLower(s) {
+	case "true", "false":
+		return "booleano"
+	}
+	for _, layout := range csvDateLayouts {
+		if _, err := time.Parse(layout, s); err == nil {
+			return "data"
+		}
+	}
+	return "texto"
+}
+
+// csvSchema descreve cada coluna: tipo, células vazias, valores distintos e intervalo ou
+// exemplos.
+func csvSchema(t *csvTable) [][]string {
+	rows := [][]string{{"COLUNA", "TIPO", "VAZIOS", "DISTINTOS", "VALORES"}}
+	for i, name := range t.columns {
+		kind, empty := "", 0
+		distinct := map[string]bool{}
+		var examples []string
+		low, high := "", ""
+		for _, row := range t.rows {
+			v := row[i]
+			if v == "" {
+				empty++
+				continue
+			}
+			switch k := cellType(v); {
+			case kind == "":
+				kind = k
+			case kind == k:
+			case kind == "inteiro" && k == "decimal", kind == "decimal" && k == "inteiro":
+				kind = "decimal"
+			default:
+				kind = "texto"
+			}
+			if !distinct[v] {
+				distinct[v] = true
+				if len(examples) < 3 {
+					examples = append(examples, v)
+				}
+			}
+			if low == "" || compareCells(v, low) < 0 {
+				low = v
+			}
+			if high == "" || compareCells(v, high) > 0 {
+				high = v
+			}
+		}
+
+		values := strings.Join(examples, ", ")
+		switch kind {
+		case "":
+			kind = "vazio"
+		case "inteiro", "decimal", "data":
+			values = low + " .. " + high
+		}
+		rows = append(rows, []string{name, kind, strconv.Itoa(empty), strconv.Itoa(len(distinct)), values})
+	}
+	return rows
+}
+
+// csvFilter é uma condição de where já interpretada.
+type csvFilter struct {
+	column int
+	op     string
+	value  string
+	re     *regexp.Regexp
+}
+
+// parseCSVFilter interpreta "coluna operador valor". O valor pode vir entre aspas.
+func parseCSVFilter(t *csvTable, src string) (csvFilter, error) {
+	m := csvFilterRegex.FindStringSubmatch(src)
+	if m == nil {
+		return csvFilter{}, fmt.Errorf("filtro inválido '%s'. Use \"coluna operador valor\", com ==, !=, >, >=, <, <=, ~ ou !~", src)
+	}
+	col, err := t.column(m[1])
+	if err != nil {
+		return csvFilter{}, fmt.Errorf("filtro '%s': %w", src, err)
+	}
+	f := csvFilter{column: col, op: m[2], value: m[3]}
+	if f.op == "=" {
+		f.op = "=="
+	}
+	if len(f.value) >= 2 && (f.value[0] == '"' || f.value[0] == '\'') && f.value[len(f.value)-1] == f.value[0] {
+		f.value = f.value[1 : len(f.value)-1]
+	}
+	if f.op == "~" || f.op == "!~" {
+		if f.re, err = regexp.Compile(f.value); err != nil {
+			return csvFilter{}, fmt.Errorf("filtro '%s': regex inválida: %w", src, err)
+		}
+	}
+	return f, nil
+}
+
+// match diz se a linha satisfaz o filtro. Comparações de ordem entre textos usam a
+// ordem lexicográfica, que serve para datas ISO.
+func (f csvFilter) match(row []string) bool {
+	v := row[f.column]
+	switch f.op {
+	case "~":
+		return f.re.MatchString(v)
+	case "!~":
+		return !f.re.MatchString(v)
+	case "==":
+		return compareCells(v, f.value) == 0
+	case "!=":
+		return compareCells(v, f.value) != 0
+	}
+	if v == "" {
+		return false
+	}
+	c := compareCells(v, f.value)
+	switch f.op {
+	case ">":
+		return c > 0
+	case ">=":
+		return c >= 0
+	case "<":
+		return c < 0
+	default:
+		return c <= 0
+	}
+}
+
+// csvAggregate é uma agregação pedida, como avg(latency).
+type csvAggregate struct {
+	fn         string
+	column     int // -1 para count sem coluna
+	percentile float64
+	label      string
+}
+
+// parseCSVAggregate interpreta count, count(c), distinct(c), sum, avg, min, max, median e
+// pN (percentil N, ex: p95).
+func parseCSVAggregate(t *csvTable, src string) (csvAggregate, error) {
+	m := csvAggregateRegex.FindStringSubmatch(src)
+	if m == nil {
+		return csvAggregate{}, fmt.Errorf("agregação inválida '%s'", src)
+	}
+	a := csvAggregate{fn: strings.ToLower(m[1]), column: -1}
+	if a.fn == "mean" {
+		a.fn = "avg"
+	}
+	switch {
+	case a.fn == "count" || a.fn == "distinct":
+	case a.fn == "sum" || a.fn == "avg" || a.fn == "min" || a.fn == "max" || a.fn == "median":
+	case strings.HasPrefix(a.fn, "p"):
+		p, err := strconv.ParseFloat(a.fn[1:], 64)
+		if err != nil || p < 0 || p > 100 {
+			return csvAggregate{}, fmt.Errorf("percentil inválido '%s'; use de p0 a p100", src)
+		}
+		a.percentile = p
+	default:
+		return csvAggregate{}, fmt.Errorf("agregação desconhecida '%s'. Use count, distinct, sum, avg, min, max, median ou pN (ex: p95)", m[1])
+	}
+
+	arg := strings.TrimSpace(m[2])
+	if arg == "" || arg == "*" {
+		if a.fn != "count" {
+			return csvAggregate{}, fmt.Errorf("a agregação '%s' precisa de uma coluna, ex: %s(valor)", a.fn, a.fn)
+		}
+		a.label = "count"
+		return a, nil
+	}
+	col, err := t.column(arg)
+	if err != nil {
+		return csvAggregate{}, fmt.Errorf("agregação '%s': %w", src, err)
+	}
+	a.column = col
+	a.label = fmt.Sprintf("%s(%s)", a.fn, t.columns[col])
+	return a, nil
+}
+
+// compute calcula a agregação sobre as linhas de um grupo. Células vazias são ignoradas,
+// exceto em count sem coluna.
+func (a csvAggregate) compute(t *csvTable, rows [][]string) (string, error) {
+	if a.column < 0 {
+		return strconv.Itoa(len(rows)), nil
+	}
+	var values []string
+	for _, row := range rows {
+		if v := row[a.column]; v != "" {
+			values = append(values, v)
+		}
+	}
+	switch a.fn {
+	case "count":
+		return strconv.Itoa(len(values)), nil
+	case "distinct":
+		seen := map[string]bool{}
+		for _, v := range values {
+			seen[v] = true
+		}
+		return strconv.Itoa(len(seen)), nil
+	case "min", "max":
+		// min e max também funcionam com texto e datas ISO.
+		best := ""
+		for _, v := range values {
+			c := compareCells(v, best)
+			if best == "" || (a.fn == "min" && c < 0) || (a.fn == "max" && c > 0) {
+				best = v
+			}
+		}
+		return best, nil
+	}
+
+	numbers := make([]float64, 0, len(values))
+	for _, v := range values {
+		f, ok := csvNumber(v)
+		if !ok {
+			return "", fmt.Errorf("%s: a coluna '%s' tem o valor não numérico '%s'", a.label, t.columns[a.column], truncateCell(v))
+		}
+		numbers = append(numbers, f)
+	}
+	if len(numbers) == 0 {
+		if a.fn == "sum" {
+			return "0", nil
+		}
+		return "", nil
+	}
+	switch a.fn {
+	case "sum", "avg":
+		sum := 0.0
+		for _, f := range numbers {
+			sum += f
+		}
+		if a.fn == "avg" {
+			sum /= float64(len(numbers))
+		}
+		return formatCSVNumber(sum), nil
+	case "median":
+		return formatCSVNumber(percentile(numbers, 50)), nil
+	default:
+		return formatCSVNumber(percentile(numbers, a.percentile)), nil
+	}
+}
+
+// percentile calcula o percentil p com interpolação linear entre os vizinhos, como o
+// padrão do numpy.
+func percentile(values []float64, p float64) float64 {
+	sort.Float64s(values)
+	rank := p / 100 * float64(len(values)-1)
+	lo := int(math.Floor(rank))
+	hi := int(math.Ceil(rank))
+	return values[lo] + (values[hi]-values[lo])*(rank-float64(lo))
+}
+
+// truncateCell encurta células longas e troca quebras de linha e tabs por espaços, para
+// não desalinhar a tabela.
+func truncateCell(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if r := []rune(s); len(r) > maxCSVCell {
+		return string(r[:maxCSVCell-1]) + "…"
+	}
+	return s
+}
+
+// renderTable alinha as linhas em colunas; a primeira linha é o cabeçalho.
+func renderTable(rows [][]string) string {
+	var buf bytes.Buffer
+	tw := tabwriter.NewWriter(&buf, 0, 4, 2, ' ', 0)
+	for _, row := range rows {
+		cells := make([]string, len(row))
+		for i, c := range row {
+			cells[i] = truncateCell(c)
+			if cells[i] == "" {
+				cells[i] = "-"
+			}
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	tw.Flush()
+	return strings.TrimRight(buf.String(), "\n")
+}
+
+// sortResult ordena as linhas do resultado pela coluna pedida; "-coluna" ordena em ordem
+// decrescente. Valores vazios ficam sempre no fim.
+func sortResult(header []string, rows [][]string, spec string) error {
+	desc := strings.HasPrefix(spec, "-")
+	name := strings.TrimSpace(strings.TrimPrefix(spec, "-"))
+	col := -1
+	for i, h := range header {
+		if h == name {
+			col = i
+			break
+		}
+	}
+	if col < 0 {
+		for i, h := range header {
+			if strings.EqualFold(h, name) {
+				col = i
+				break
+			}
+		}
+	}
+	if col < 0 {
+		return fmt.Errorf("não é possível ordenar por '%s': a coluna não está no resultado (disponíveis: %s)", name, strings.Join(header, ", "))
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := rows[i][col], rows[j][col]
+		if a == "" || b == "" {
+			return a != "" && b == ""
+		}
+		if desc {
+			return compareCells(a, b) > 0
+		}
+		return compareCells(a, b) < 0
+	})
+	return nil
+}
+
+// ::: Ferramenta: CSVQuery :::
+
+type CSVQueryInput struct {
+	Path      string   `json:"path"`
+	Delimiter string   `json:"delimiter,omitempty"` // padrão: detectado
+	Header    *bool    `json:"header,omitempty"`    // padrão: detectado
+	Where     []string `json:"where,omitempty"`     // filtros combinados com E, ex: "status >= 500"
+	GroupBy   []string `json:"group_by,omitempty"`
+	Aggregate []string `json:"aggregate,omitempty"` // ex: count, avg(latency), p95(latency)
+	Columns   []string `json:"columns,omitempty"`   // colunas mostradas quando não há agregação
+	Sort      string   `json:"sort,omitempty"`      // coluna do resultado; "-" para decrescente
+	Limit     int      `json:"limit,omitempty"`
+}
+
+func csvQuery(input json.RawMessage) (string, error) {
+	var typedInput CSVQueryInput
+	if err := json.Unmarshal(input, &typedInput); err != nil {
+		return "", fmt.Errorf("JSON inválido para argumentos: %w", err)
+	}
+	if typedInput.Path == "" {
+		return "", fmt.Errorf("argumento inválido. 'path' é obrigatório")
+	}
+	if typedInput.Limit < 0 || typedInput.Limit > maxCSVLimit {
+		return "", fmt.Errorf("argumento inválido. 'limit' deve estar entre 1 e %d", maxCSVLimit)
+	}
+	limit := typedInput.Limit
+	if limit == 0 {
+		limit = defaultCSVLimit
+	}
+	if len(typedInput.Columns) > 0 && (len(typedInput.Aggregate) > 0 || len(typedInput.GroupBy) > 0) {
+		return "", fmt.Errorf("argumentos inválidos. 'columns' não pode ser usado com 'aggregate' ou 'group_by'")
+	}
+
+	w, err := CurrentWorkspace()
+	if err != nil {
+		return "", err
+	}
+	abs, err := w.ResolveRead(typedInput.Path)
+	if err != nil {
+		return "", err
+	}
+	display := w.Display(typedInput.Path, abs)
+	t, err := loadCSV(abs, display, typedInput.Delimiter, typedInput.Header)
+	if err != nil {
+		return "", err
+	}
+
+	var out strings.Builder
+	headerNote := "sem cabeçalho"
+	if t.header {
+		headerNote = "com cabeçalho"
+	}
+	fmt.Fprintf(&out, "%s: %d linha(s), %d coluna(s) (delimitador %s, %s)", display, len(t.rows), len(t.columns), delimiterName(t.delimiter), headerNote)
+	if t.ragged > 0 {
+		fmt.Fprintf(&out, "; %d linha(s) com número de campos diferente", t.ragged)
+	}
+	out.WriteString("\n")
+
+	// Sem nenhuma operação, mostra o esquema e as primeiras linhas.
+	if len(typedInput.Where) == 0 && len(typedInput.GroupBy) == 0 && len(typedInput.Aggregate) == 0 &&
+		len(typedInput.Columns) == 0 && typedInput.Sort == "" {
+		out.WriteString("\n" + renderTable(csvSchema(t)) + "\n")
+		if len(t.rows) > 0 {
+			sample := append([][]string{t.columns}, t.rows[:min(5, len(t.rows))]...)
+			fmt.Fprintf(&out, "\nPrimeiras %d linha(s):\n%s\n", len(sample)-1, renderTable(sample))
+		}
+		return strings.TrimRight(out.String(), "\n"), nil
+	}
+
+	rows := t.rows
+	if len(typedInput.Where) > 0 {
+		var filters []csvFilter
+		for _, src := range typedInput.Where {
+			f, err := parseCSVFilter(t, src)
+			if err != nil {
+				return "", err
+			}
+			filters = append(filters, f)
+		}
+		rows = nil
+	rowLoop:
+		for _, row := range t.rows {
+			for _, f := range filters {
+				if !f.match(row) {
+					continue rowLoop
+				}
+			}
+			rows = append(rows, row)
+		}
+		fmt.Fprintf(&out, "%d de %d linha(s) passam pelos filtros\n", len(rows), len(t.rows))
+	}
+
+	header, result, err := csvResult(t, rows, typedInput)
+	if err != nil {
+		return "", err
+	}
+	if typedInput.Sort != "" {
+		if err := sortResult(header, result, typedInput.Sort); err != nil {
+			return "", err
+		}
+	}
+	omitted := 0
+	if len(result) > limit {
+		omitted = len(result) - limit
+		result = result[:limit]
+	}
+	if len(result) == 0 {
+		out.WriteString("\nNenhuma linha no resultado.")
+		return out.String(), nil
+	}
+	out.WriteString("\n" + renderTable(append([][]string{header}, result...)))
+	if omitted > 0 {
+		fmt.Fprintf(&out, "\n... (%d linha(s) omitida(s); aumente 'limit' ou refine a consulta)", omitted)
+	}
+	return out.String(), nil
+}
+
+// csvResult monta a tabela do resultado: grupos com agregações, uma linha de agregações
+// sobre todas as linhas, ou as próprias linhas com as colunas pedidas.
+func csvResult(t *csvTable, rows [][]string, in CSVQueryInput) ([]string, [][]string, error) {
+	if len(in.Aggregate) == 0 && len(in.GroupBy) == 0 {
+		cols := make([]int, len(t.columns))
+		for i := range cols {
+			cols[i] = i
+		}
+		if len(in.Columns) > 0 {
+			cols = cols[:0]
+			for _, name := range in.Columns {
+				i, err := t.column(name)
+				if err != nil {
+					return nil, nil, err
+				}
+				cols = append(cols, i)
+			}
+		}
+		header := make([]string, len(cols))
+		for i, c := range cols {
+			header[i] = t.columns[c]
+		}
+		result := make([][]string, len(rows))
+		for r, row := range rows {
+			result[r] = make([]string, len(cols))
+			for i, c := range cols {
+				result[r][i] = row[c]
+			}
+		}
+		return header, result, nil
+	}
+
+	aggregates := in.Aggregate
+	if len(aggregates) == 0 {
+		aggregates = []string{"count"}
+	}
+	var aggs []csvAggregate
+	for _, src := range aggregates {
+		a, err := parseCSVAggregate(t, src)
+		if err != nil {
+			return nil, nil, err
+		}
+		aggs = append(aggs, a)
+	}
+	var keys []int
+	for _, name := range in.GroupBy {
+		i, err := t.column(name)
+		if err != nil {
+			return nil, nil, err
+		}
+		keys = append(keys, i)
+	}
+
+	// Agrupa mantendo a ordem de aparição; sem sort, os grupos saem ordenados pelas chaves.
+	var order []string
+	groups := map[string][][]string{}
+	for _, row := range rows {
+		parts := make([]string, len(keys))
+		for i, k := range keys {
+			parts[i] = row[k]
+		}
+		id := strings.Join(parts, "\x00")
+		if _, ok := groups[id]; !ok {
+			order = append(order, id)
+		}
+		groups[id] = append(groups[id], row)
+	}
+	if len(keys) == 0 && len(order) == 0 {
+		order = []string{""} // Agregação sobre zero linhas ainda produz uma linha
+	}
+
+	var header []string
+	for _, k := range keys {
+		header = append(header, t.columns[k])
+	}
+	for _, a := range aggs {
+		header = append(header, a.label)
+	}
+	var result [][]string
+	for _, id := range order {
+		var line []string
+		if len(keys) > 0 {
+			line = strings.Split(id, "\x00")
+		}
+		for _, a := range aggs {
+			v, err := a.compute(t, groups[id])
+			if err != nil {
+				return nil, nil, err
+			}
+			line = append(line, v)
+		}
+		result = append(result, line)
+	}
+	if in.Sort == "" && len(keys) > 0 {
+		sort.SliceStable(result, func(i, j int) bool {
+			for k := range keys {
+				if c := compareCells(result[i][k], result[j][k]); c != 0 {
+					return c < 0
+				}
+			}
+			return false
+		})
+	}
+	return header, result, nil
+}
+
+var CSVQueryDef = toolkit.ToolDefinition{
+	Name:        "csv_query",
+	Description: `Analisa um arquivo CSV e devolve tabelas pequenas com números exatos, para não fazer contas de cabeça. Detecta o delimitador (",", ";", tab, "|") e o cabeçalho (ou use "delimiter" e "header"). Só com "path", mostra o número de linhas, o esquema (tipo, vazios, distintos, intervalo de cada coluna) e as primeiras linhas. "where" filtra com "coluna operador valor" (==, !=, >, >=, <, <=, ~ regex, !~), combinados com E; "group_by" agrupa e "aggregate" calcula count, count(c), distinct(c), sum(c), avg(c), min(c), max(c), median(c) e percentis como p95(c); sem agregação, "columns" escolhe as colunas das linhas. "sort" ordena pelo nome de uma coluna do resultado ("-" na frente para decrescente) e "limit" define o top-N (padrão 20). Exemplo: {"path": "metrics.csv", "where": ["status >= 500"], "group_by": ["endpoint"], "aggregate": ["count", "p95(latency_ms)"], "sort": "-count", "limit": 5}`,
+	Function:    csvQuery,
+}
@@ -0,0 +1,185 @@
+package builtin
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// setupCSVTools cria um workspace próprio com alguns arquivos CSV.
+func setupCSVTools(t *testing.T) {
+	t.Helper()
+	newTestWorkspace(t, map[string]string{
+		"metrics.csv": "endpoint,status,latency_ms,day\n" +
+			"/api/users,200,12,2024-01-01\n" +
+			"/api/users,500,340,2024-01-01\n" +
+			"/api/orders,200,45.5,2024-01-02\n" +
+			"/api/orders,200,30,2024-01-02\n" +
+			"/api/orders,503,900,2024-01-03\n" +
+			"/health,200,,2024-01-03\n",
+		"vendas.csv":    "\ufeffregião;\"produto\";valor\nSul;\"Café; moído\";10,5\nNorte;Chá;3\n",
+		"semcab.tsv":    "a\t1\nb\t2\n",
+		"irregular.csv": "x,y\n1,2\n3\n4,5,6\n",
+	})
+}
+
+// TestCSVQuery testa esquema, filtros, agrupamentos e top-N sobre arquivos de exemplo.
+func TestCSVQuery(t *testing.T) {
+	setupCSVTools(t)
+	withHeader := true
+	testCases := []struct {
+		name          string
+		input         CSVQueryInput
+		expected      []string
+		expectedError string
+	}{
+		{
+			name:  "Esquema",
+			input: CSVQueryInput{Path: "metrics.csv"},
+			expected: []string{
+				"metrics.csv: 6 linha(s), 4 coluna(s) (delimitador ',', com cabeçalho)",
+				"latency_ms  decimal  1       5          12 .. 900",
+				"day         data     0       3          2024-01-01 .. 2024-01-03",
+				"Primeiras 5 linha(s):",
+			},
+		},
+		{
+			name:  "Delimitador ponto e vírgula e BOM",
+			input: CSVQueryInput{Path: "vendas.csv", Columns: []string{"produto", "região"}},
+			expected: []string{
+				"(delimitador ';', com cabeçalho)",
+				"produto      região\nCafé; moído  Sul\nChá          Norte",
+			},
+		},
+		{
+			name:     "Sem cabeçalho",
+			input:    CSVQueryInput{Path: "semcab.tsv", Aggregate: []string{"sum(col2)"}},
+			expected: []string{"(delimitador tab, sem cabeçalho)", "sum(col2)\n3"},
+		},
+		{
+			name:     "Cabeçalho forçado",
+			input:    CSVQueryInput{Path: "semcab.tsv", Header: &withHeader, Delimiter: "tab"},
+			expected: []string{"1 linha(s), 2 coluna(s) (delimitador tab, com cabeçalho)"},
+		},
+		{
+			name:     "Linhas irregulares",
+			input:    CSVQueryInput{Path: "irregular.csv", Sort: "-x"},
+			expected: []string{"2 linha(s) com número de campos diferente", "x  y\n4  5\n3  -\n1  2"},
+		},
+		{
+			name:  "Filtros",
+			input: CSVQueryInput{Path: "metrics.csv", Where: []string{"status >= 500", "endpoint ~ orders"}, Columns: []string{"day", "latency_ms"}},
+			expected: []string{
+				"1 de 6 linha(s) passam pelos filtros",
+				"day         latency_ms\n2024-01-03  900",
+			},
+		},
+		{
+			name:     "Filtro com texto entre aspas",
+			input:    CSVQueryInput{Path: "metrics.csv", Where: []string{`endpoint = "/health"`}, Aggregate: []string{"count", "count(latency_ms)"}},
+			expected: []string{"count  count(latency_ms)\n1      0"},
+		},
+		{
+			name: "Agrupamento com percentis e top-N",
+			input: CSVQueryInput{
+				Path:      "metrics.csv",
+				GroupBy:   []string{"endpoint"},
+				Aggregate: []string{"count", "avg(latency_ms)", "p95(latency_ms)", "max(day)"},
+				Sort:      "-count",
+				Limit:     2,
+			},
+			expected: []string{
+				"endpoint     count  avg(latency_ms)  p95(latency_ms)  max(day)\n" +
+					"/api/orders  3      325.166667       814.55           2024-01-03\n" +
+					"/api/users   2      176              323.6            2024-01-01\n" +
+					"... (1 linha(s) omitida(s); aumente 'limit' ou refine a consulta)",
+			},
+		},
+		{
+			name:     "Agrupamento ordenado pelas chaves",
+			input:    CSVQueryInput{Path: "metrics.csv", GroupBy: []string{"status"}},
+			expected: []string{"status  count\n200     4\n500     1\n503     1"},
+		},
+		{
+			name:     "Agregação sem grupos",
+			input:    CSVQueryInput{Path: "metrics.csv", Aggregate: []string{"sum(latency_ms)", "median(latency_ms)", "min(latency_ms)", "distinct(endpoint)"}},
+			expected: []string{"sum(latency_ms)  median(latency_ms)  min(latency_ms)  distinct(endpoint)\n1327.5           45.5                12               3"},
+		},
+		{
+			name:     "Nenhuma linha",
+			input:    CSVQueryInput{Path: "metrics.csv", Where: []string{"status == 404"}},
+			expected: []string{"0 de 6 linha(s) passam pelos filtros\n\nNenhuma linha no resultado."},
+		},
+		{
+			name:          "Agregação não numérica",
+			input:         CSVQueryInput{Path: "metrics.csv", Aggregate: []string{"avg(endpoint)"}},
+			expectedError: "avg(endpoint): a coluna 'endpoint' tem o valor não numérico '/api/users'",
+		},
+		{
+			name:          "Coluna inexistente",
+			input:         CSVQueryInput{Path: "metrics.csv", Where: []string{"latencia > 10"}},
+			expectedError: "filtro 'latencia > 10': coluna 'latencia' não existe (colunas: endpoint, status, latency_ms, day)",
+		},
+		{
+			name:          "Agregação desconhecida",
+			input:         CSVQueryInput{Path: "metrics.csv", Aggregate: []string{"stddev(latency_ms)"}},
+			expectedError: "agregação desconhecida 'stddev'",
+		},
+		{
+			name:          "Ordenação por coluna ausente",
+			input:         CSVQueryInput{Path: "metrics.csv", GroupBy: []string{"endpoint"}, Sort: "status"},
+			expectedError: "não é possível ordenar por 'status': a coluna não está no resultado (disponíveis: endpoint, count)",
+		},
+		{
+			name:          "Columns com agregação",
+			input:         CSVQueryInput{Path: "metrics.csv", Columns: []string{"day"}, Aggregate: []string{"count"}},
+			expectedError: "'columns' não pode ser usado com 'aggregate' ou 'group_by'",
+		},
+		{
+			name:          "Sem path",
+			input:         CSVQueryInput{},
+			expectedError: "'path' é obrigatório",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rawInput, _ := json.Marshal(tc.input)
+			result, err := csvQuery(rawInput)
+			if tc.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedError) {
+					t.Fatalf("erro = %v, esperado conter %q", err, tc.expectedError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+			for _, expected := range tc.expected {
+				if !strings.Contains(result, expected) {
+					t.Errorf("resultado =\n%s\nesperado conter\n%s", result, expected)
+				}
+			}
+		})
+	}
+}
+
+// TestPercentile testa a interpolação linear entre os valores ordenados.
+func TestPercentile(t *testing.T) {
+	testCases := []struct {
+		values   []float64
+		p        float64
+		expected float64
+	}{
+		{values: []float64{5}, p: 99, expected: 5},
+		{values: []float64{4, 1, 3, 2}, p: 50, expected: 2.5},
+		{values: []float64{1, 2, 3, 4, 5}, p: 0, expected: 1},
+		{values: []float64{1, 2, 3, 4, 5}, p: 100, expected: 5},
+		{values: []float64{10, 20}, p: 90, expected: 19},
+	}
+	for _, tc := range testCases {
+		if got := percentile(tc.values, tc.p); got != tc.expected {
+			t.Errorf("percentile(%v, %v) = %v, esperado %v", tc.values, tc.p, got, tc.expected)
+		}
+	}
+}
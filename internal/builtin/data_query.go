@@ -0,0 +1,1679 @@
+package builtin
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/matheusbuniotto/goagent/internal/dataformat"
+)
+
+// query é uma expressão compilada: recebe um valor e produz zero ou mais valores, como
+// os filtros do jq. Os valores são os de dataformat.Node.Value: nil, bool, int64,
+// json.Number, float64, string, []any e *dataformat.Map.
+type query func(any) ([]any, error)
+
+// ::: Léxico :::
+
+const (
+	tokEOF = iota
+	tokPunct
+	tokIdent
+	tokField  // .nome
+	tokString // "texto"
+	tokNumber
+)
+
+type queryToken struct {
+	kind  int
+	text  string
+	value any // string ou número já interpretado
+	pos   int
+}
+
+func lexQuery(src string) ([]queryToken, error) {
+	var toks []queryToken
+	isIdent := func(c byte, first bool) bool {
+		return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (!first && c >= '0' && c <= '9')
+	}
+	for i := 0; i < len(src); {
+		c := src[i]
+		start := i
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+			continue
+		case c == '.' && i+1 < len(src) && src[i+1] == '.':
+			i += 2
+			toks = append(toks, queryToken{kind: tokPunct, text: "..", pos: start})
+		case c == '.' && i+1 < len(src) && isIdent(src[i+1], true):
+			for i++; i < len(src) && isIdent(src[i], false); i++ {
+			}
+			toks = append(toks, queryToken{kind: tokField, text: src[start+1 : i], pos: start})
+		case isIdent(c, true):
+			for ; i < len(src) && isIdent(src[i], false); i++ {
+			}
+			toks = append(toks, queryToken{kind: tokIdent, text: src[start:i], pos: start})
+		case c >= '0' && c <= '9':
+			for ; i < len(src) && (src[i] >= '0' && src[i] <= '9' || src[i] == '.'); i++ {
+			}
+			if i < len(src) && (src[i] == 'e' || src[i] == 'E') {
+				for i++; i < len(src) && (src[i] >= '0' && src[i] <= '9' || src[i] == '+' || src[i] == '-'); i++ {
+				}
+			}
+			f, err := strconv.ParseFloat(src[start:i], 64)
+			if err != nil {
+				return nil, fmt.Errorf("número inválido '%s' na posição %d", src[start:i], start+1)
+			}
+			toks = append(toks, queryToken{kind: tokNumber, text: src[start:i], value: normalizeNumber(f), pos: start})
+		case c == '"':
+			for i++; i < len(src) && src[i] != '"'; i++ {
+				if src[i] == '\\' {
+					i++
+				}
+			}
+			if i >= len(src) {
+				return nil, fmt.Errorf("string sem fechamento na posição %d", start+1)
+			}
+			i++
+			var s string
+			if err := json.Unmarshal([]byte(src[start:i]), &s); err != nil {
+				return nil, fmt.Errorf("string inválida na posição %d", start+1)
+			}
+			toks = append(toks, queryToken{kind: tokString, text: src[start:i], value: s, pos: start})
+		default:
+			op := ""
+			for _, candidate := range []string{"==", "!=", "<=", ">=", "//", "|", ",", "(", ")", "[", "]", "{", "}", ":", ";", "?", "+", "-", "*", "/", "%", "<", ">", "."} {
+				if strings.HasPrefix(src[i:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				r, _ := utf8.DecodeRuneInString(src[i:])
+				return nil, fmt.Errorf("caractere inesperado '%c' na posição %d", r, start+1)
+			}
+			i += len(op)
+			toks = append(toks, queryToken{kind: tokPunct, text: op, pos: start})
+		}
+	}
+	return append(toks, queryToken{kind: tokEOF, pos: len(src)}), nil
+}
+
+// ::: Sintaxe :::
+
+// queryParser compila a expressão. Precedência, da menor para a maior: "|", ",", "//",
+// "or", "and", comparações, "+ -", "* / %", e os sufixos (.campo, [n], [a:b], [], ?).
+type queryParser struct {
+	toks []queryToken
+	i    int
+}
+
+// compileQuery compila uma expressão no estilo do jq.
+func compileQuery(src string) (query, error) {
+	if strings.TrimSpace(src) == "" {
+		return nil, fmt.Errorf("expressão vazia; use '.' para o documento inteiro")
+	}
+	toks, err := lexQuery(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &queryParser{toks: toks}
+	q, err := p.pipe()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, p.unexpected(t)
+	}
+	return q, nil
+}
+
+func (p *queryParser) peek() queryToken {
+	return p.toks[p.i]
+}
+
+func (p *queryParser) next() queryToken {
+	t := p.toks[p.i]
+	if t.kind != tokEOF {
+		p.i++
+	}
+	return t
+}
+
+// accept consome o próximo token se for a pontuação ou palavra-chave indicada.
+func (p *queryParser) accept(text string) bool {
+	if t := p.peek(); (t.kind == tokPunct || t.kind == tokIdent) && t.text == text {
+		p.i++
+		return true
+	}
+	return false
+}
+
+func (p *queryParser) expect(text string) error {
+	if !p.accept(text) {
+		t := p.peek()
+		if t.kind == tokEOF {
+			return fmt.Errorf("esperava '%s' no fim da expressão", text)
+		}
+		return fmt.Errorf("esperava '%s' na posição %d, encontrou '%s'", text, t.pos+1, tokenText(t))
+	}
+	return nil
+}
+
+func (p *queryParser) unexpected(t queryToken) error {
+	if t.kind == tokEOF {
+		return fmt.Errorf("fim inesperado da expressão")
+	}
+	return fmt.Errorf("'%s' inesperado na posição %d", tokenText(t), t.pos+1)
+}
+
+func tokenText(t queryToken) string {
+	if t.kind == tokField {
+		return "." + t.text
+	}
+	return t.text
+}
+
+func (p *queryParser) pipe() (query, error) {
+	left, err := p.comma()
+	if err != nil || !p.accept("|") {
+		return left, err
+	}
+	right, err := p.pipe()
+	if err != nil {
+		return nil, err
+	}
+	return pipeQuery(left, right), nil
+}
+
+func pipeQuery(left, right query) query {
+	return func(v any) ([]any, error) {
+		ins, err := left(v)
+		if err != nil {
+			return nil, err
+		}
+		var out []any
+		for _, in := range ins {
+			res, err := right(in)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, res...)
+		}
+		return out, nil
+	}
+}
+
+func (p *queryParser) comma() (query, error) {
+	left, err := p.alternative()
+	for err == nil && p.accept(",") {
+		var right query
+		if right, err = p.alternative(); err == nil {
+			l := left
+			left = func(v any) ([]any, error) {
+				a, err := l(v)
+				if err != nil {
+					return nil, err
+				}
+				b, err := right(v)
+				return append(a, b...), err
+			}
+		}
+	}
+	return left, err
+}
+
+// alternative implementa "a // b": os valores de a que não são null nem false, ou os de b.
+func (p *queryParser) alternative() (query, error) {
+	left, err := p.or()
+	if err != nil || !p.accept("//") {
+		return left, err
+	}
+	right, err := p.alternative()
+	if err != nil {
+		return nil, err
+	}
+	return func(v any) ([]any, error) {
+		a, err := left(v)
+		var out []any
+		if err == nil {
+			for _, x := range a {
+				if truthy(x) {
+					out = append(out, x)
+				}
+			}
+		}
+		if len(out) > 0 {
+			return out, nil
+		}
+		return right(v)
+	}, nil
+}
+
+func (p *queryParser) or() (query, error) {
+	return p.logical("or", (*queryParser).and, true)
+}
+
+func (p *queryParser) and() (query, error) {
+	return p.logical("and", (*queryParser).comparison, false)
+}
+
+// logical implementa "or" e "and" com curto-circuito.
+func (p *queryParser) logical(op string, operand func(*queryParser) (query, error), shortOn bool) (query, error) {
+	left, err := operand(p)
+	for err == nil && p.accept(op) {
+		var right query
+		if right, err = operand(p); err == nil {
+			l := left
+			left = func(v any) ([]any, error) {
+				as, err := l(v)
+				if err != nil {
+					return nil, err
+				}
+				var out []any
+				for _, a := range as {
+					if truthy(a) == shortOn {
+						out = append(out, shortOn)
+						continue
+					}
+					bs, err := right(v)
+					if err != nil {
+						return nil, err
+					}
+					for _, b := range bs {
+						out = append(out, truthy(b))
+					}
+				}
+				return out, nil
+			}
+		}
+	}
+	return left, err
+}
+
+func (p *queryParser) comparison() (query, error) {
+	left, err := p.additive()
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if p.accept(op) {
+			right, err := p.additive()
+			if err != nil {
+				return nil, err
+			}
+			return binaryQuery(left, right, func(a, b any) (any, error) {
+				c := compareValues(a, b)
+				switch op {
+				case "==":
+					return c == 0, nil
+				case "!=":
+					return c != 0, nil
+				case "<=":
+					return c <= 0, nil
+				case ">=":
+					return c >= 0, nil
+				case "<":
+					return c < 0, nil
+				}
+				return c > 0, nil
+			}), nil
+		}
+	}
+	return left, nil
+}
+
+func (p *queryParser) additive() (query, error) {
+	return p.arithmetic([]string{"+", "-"}, (*queryParser).multiplicative)
+}
+
+func (p *queryParser) multiplicative() (query, error) {
+	return p.arithmetic([]string{"*", "/", "%"}, (*queryParser).unary)
+}
+
+func (p *queryParser) arithmetic(ops []string, operand func(*queryParser) (query, error)) (query, error) {
+	left, err := operand(p)
+	for err == nil {
+		op := ""
+		for _, candidate := range ops {
+			if p.accept(candidate) {
+				op = candidate
+				break
+			}
+		}
+		if op == "" {
+			break
+		}
+		var right query
+		if right, err = operand(p); err == nil {
+			left = binaryQuery(left, right, func(a, b any) (any, error) { return arithmetic(op, a, b) })
+		}
+	}
+	return left, err
+}
+
+// binaryQuery combina cada valor da esquerda com cada valor da direita.
+func binaryQuery(left, right query, op func(a, b any) (any, error)) query {
+	return func(v any) ([]any, error) {
+		as, err := left(v)
+		if err != nil {
+			return nil, err
+		}
+		bs, err := right(v)
+		if err != nil {
+			return nil, err
+		}
+		var out []any
+		for _, a := range as {
+			for _, b := range bs {
+				r, err := op(a, b)
+				if err != nil {
+					return nil, err
+				}
+				out = append(out, r)
+			}
+		}
+		return out, nil
+	}
+}
+
+func (p *queryParser) unary() (query, error) {
+	if p.accept("-") {
+		operand, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return binaryQuery(constQuery(int64(0)), operand, func(a, b any) (any, error) { return arithmetic("-", a, b) }), nil
+	}
+	return p.postfix()
+}
+
+func (p *queryParser) postfix() (query, error) {
+	q, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		switch {
+		case t.kind == tokField:
+			p.next()
+			q = indexQuery(q, constQuery(t.text))
+		case t.kind == tokPunct && t.text == "." && p.toks[p.i+1].kind == tokString:
+			p.next()
+			q = indexQuery(q, constQuery(p.next().value))
+		case t.kind == tokPunct && t.text == "." && p.toks[p.i+1].text == "[":
+			p.next()
+		case t.kind == tokPunct && t.text == "[":
+			p.next()
+			if q, err = p.bracket(q); err != nil {
+				return nil, err
+			}
+		case t.kind == tokPunct && t.text == "?":
+			p.next()
+			q = tryQuery(q)
+		default:
+			return q, nil
+		}
+	}
+}
+
+// bracket lê o que vem depois de "[": "]", "n]", "a:b]".
+func (p *queryParser) bracket(target query) (query, error) {
+	if p.accept("]") {
+		return iterateQuery(target), nil
+	}
+	var from, to query
+	var err error
+	if !p.accept(":") {
+		if from, err = p.pipe(); err != nil {
+			return nil, err
+		}
+		if !p.accept(":") {
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+			return indexQuery(target, from), nil
+		}
+	}
+	if !p.accept("]") {
+		if to, err = p.pipe(); err != nil {
+			return nil, err
+		}
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+	}
+	return sliceQuery(target, from, to), nil
+}
+
+func (p *queryParser) primary() (query, error) {
+	t := p.next()
+	switch t.kind {
+	case tokNumber, tokString:
+		return constQuery(t.value), nil
+	case tokField:
+		return indexQuery(identity, constQuery(t.text)), nil
+	case tokIdent:
+		return p.identifier(t)
+	case tokPunct:
+		switch t.text {
+		case ".":
+			if p.peek().kind == tokString {
+				return indexQuery(identity, constQuery(p.next().value)), nil
+			}
+			return identity, nil
+		case "..":
+			return recurseQuery, nil
+		case "(":
+			q, err := p.pipe()
+			if err != nil {
+				return nil, err
+			}
+			return q, p.expect(")")
+		case "[":
+			if p.accept("]") {
+				return constQuery([]any{}), nil
+			}
+			q, err := p.pipe()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+			return func(v any) ([]any, error) {
+				items, err := q(v)
+				if items == nil {
+					items = []any{}
+				}
+				return []any{items}, err
+			}, nil
+		case "{":
+			return p.object()
+		}
+	}
+	return nil, p.unexpected(t)
+}
+
+func (p *queryParser) identifier(t queryToken) (query, error) {
+	switch t.text {
+	case "true", "false":
+		return constQuery(t.text == "true"), nil
+	case "null":
+		return constQuery(nil), nil
+	case "if":
+		return p.conditional()
+	}
+	var args []query
+	if p.accept("(") {
+		for {
+			arg, err := p.pipe()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if !p.accept(";") {
+				break
+			}
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+	}
+	fn, ok := queryFunctions[fmt.Sprintf("%s/%d", t.text, len(args))]
+	if !ok {
+		return nil, fmt.Errorf("função desconhecida '%s/%d' na posição %d", t.text, len(args), t.pos+1)
+	}
+	return func(v any) ([]any, error) { return fn(v, args) }, nil
+}
+
+// conditional lê "if c then a (elif c then a)* (else b)? end".
+func (p *queryParser) conditional() (query, error) {
+	cond, err := p.pipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect("then"); err != nil {
+		return nil, err
+	}
+	then, err := p.pipe()
+	if err != nil {
+		return nil, err
+	}
+	otherwise := identity
+	switch {
+	case p.accept("elif"):
+		if otherwise, err = p.conditional(); err != nil {
+			return nil, err
+		}
+	case p.accept("else"):
+		if otherwise, err = p.pipe(); err != nil {
+			return nil, err
+		}
+		fallthrough
+	default:
+		if err := p.expect("end"); err != nil {
+			return nil, err
+		}
+	}
+	return func(v any) ([]any, error) {
+		conds, err := cond(v)
+		if err != nil {
+			return nil, err
+		}
+		var out []any
+		for _, c := range conds {
+			branch := otherwise
+			if truthy(c) {
+				branch = then
+			}
+			res, err := branch(v)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, res...)
+		}
+		return out, nil
+	}, nil
+}
+
+// object lê a construção de objeto: {a: .x, "b c": 1, (.k): .v, d}.
+func (p *queryParser) object() (query, error) {
+	type entry struct{ key, value query }
+	var entries []entry
+	for !p.accept("}") {
+		if len(entries) > 0 {
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+		}
+		var key query
+		var name string
+		switch t := p.next(); {
+		case t.kind == tokIdent:
+			name = t.text
+			key = constQuery(name)
+		case t.kind == tokString:
+			name = t.value.(string)
+			key = constQuery(name)
+		case t.kind == tokPunct && t.text == "(":
+			k, err := p.pipe()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			key = k
+		default:
+			return nil, p.unexpected(t)
+		}
+		value := indexQuery(identity, constQuery(name)) // {a} é o mesmo que {a: .a}
+		if p.accept(":") {
+			v, err := p.alternative()
+			for err == nil && p.accept("|") {
+				var next query
+				if next, err = p.alternative(); err == nil {
+					v = pipeQuery(v, next)
+				}
+			}
+			if err != nil {
+				return nil, err
+			}
+			value = v
+		} else if name == "" {
+			return nil, fmt.Errorf("chave calculada sem valor no objeto")
+		}
+		entries = append(entries, entry{key, value})
+	}
+	return func(v any) ([]any, error) {
+		results := []*dataformat.Map{dataformat.NewMap()}
+		for _, e := range entries {
+			keys, err := e.key(v)
+			if err != nil {
+				return nil, err
+			}
+			values, err := e.value(v)
+			if err != nil {
+				return nil, err
+			}
+			var next []*dataformat.Map
+			for _, m := range results {
+				for _, k := range keys {
+					ks, ok := k.(string)
+					if !ok {
+						return nil, fmt.Errorf("chave de objeto deve ser string, não %s", typeName(k))
+					}
+					for _, val := range values {
+						c := cloneMap(m)
+						c.Set(ks, val)
+						next = append(next, c)
+					}
+				}
+			}
+			results = next
+		}
+		out := make([]any, len(results))
+		for i, m := range results {
+			out[i] = m
+		}
+		return out, nil
+	}, nil
+}
+
+// ::: Avaliação :::
+
+func identity(v any) ([]any, error) {
+	return []any{v}, nil
+}
+
+func constQuery(c any) query {
+	return func(any) ([]any, error) { return []any{c}, nil }
+}
+
+// tryQuery implementa "?": erros viram nenhum resultado.
+func tryQuery(q query) query {
+	return func(v any) ([]any, error) {
+		out, err := q(v)
+		if err != nil {
+			return nil, nil
+		}
+		return out, nil
+	}
+}
+
+func indexQuery(target, key query) query {
+	return func(v any) ([]any, error) {
+		targets, err := target(v)
+		if err != nil {
+			return nil, err
+		}
+		keys, err := key(v)
+		if err != nil {
+			return nil, err
+		}
+		var out []any
+		for _, t := range targets {
+			for _, k := range keys {
+				r, err := indexValue(t, k)
+				if err != nil {
+					return nil, err
+				}
+				out = append(out, r)
+			}
+		}
+		return out, nil
+	}
+}
+
+func indexValue(t, k any) (any, error) {
+	switch t := t.(type) {
+	case nil:
+		switch k.(type) {
+		case string, int64, json.Number, float64, nil:
+			return nil, nil
+		}
+	case *dataformat.Map:
+		if ks, ok := k.(string); ok {
+			v, _ := t.Get(ks)
+			return v, nil
+		}
+	case []any:
+		if f, ok := toFloat(k); ok {
+			i := int(math.Floor(f))
+			if i < 0 {
+				i += len(t)
+			}
+			if i < 0 || i >= len(t) {
+				return nil, nil
+			}
+			return t[i], nil
+		}
+	}
+	return nil, fmt.Errorf("não é possível indexar %s com %s", typeName(t), describeKey(k))
+}
+
+func describeKey(k any) string {
+	if s, ok := k.(string); ok {
+		return strconv.Quote(s)
+	}
+	return typeName(k)
+}
+
+func sliceQuery(target, from, to query) query {
+	bound := func(q query, v any, def int, length int) (int, error) {
+		if q == nil {
+			return def, nil
+		}
+		res, err := q(v)
+		if err != nil || len(res) == 0 {
+			return def, err
+		}
+		if res[0] == nil {
+			return def, nil
+		}
+		f, ok := toFloat(res[0])
+		if !ok {
+			return 0, fmt.Errorf("os limites do intervalo devem ser números")
+		}
+		i := int(math.Floor(f))
+		if i < 0 {
+			i += length
+		}
+		return max(0, min(i, length)), nil
+	}
+	return func(v any) ([]any, error) {
+		targets, err := target(v)
+		if err != nil {
+			return nil, err
+		}
+		var out []any
+		for _, t := range targets {
+			var length int
+			switch t := t.(type) {
+			case nil:
+				out = append(out, nil)
+				continue
+			case []any:
+				length = len(t)
+			case string:
+				length = utf8.RuneCountInString(t)
+			default:
+				return nil, fmt.Errorf("não é possível fatiar %s", typeName(t))
+			}
+			a, err := bound(from, v, 0, length)
+			if err != nil {
+				return nil, err
+			}
+			b, err := bound(to, v, length, length)
+			if err != nil {
+				return nil, err
+			}
+			b = max(a, b)
+			if s, ok := t.(string); ok {
+				out = append(out, string([]rune(s)[a:b]))
+			} else {
+				out = append(out, append([]any{}, t.([]any)[a:b]...))
+			}
+		}
+		return out, nil
+	}
+}
+
+func iterateQuery(target query) query {
+	return func(v any) ([]any, error) {
+		targets, err := target(v)
+		if err != nil {
+			return nil, err
+		}
+		var out []any
+		for _, t := range targets {
+			items, err := iterate(t)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, items...)
+		}
+		return out, nil
+	}
+}
+
+func iterate(v any) ([]any, error) {
+	switch v := v.(type) {
+	case []any:
+		return v, nil
+	case *dataformat.Map:
+		items := make([]any, 0, v.Len())
+		for _, k := range v.Keys() {
+			item, _ := v.Get(k)
+			items = append(items, item)
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("não é possível iterar sobre %s", typeName(v))
+}
+
+// recurseQuery implementa "..": o valor e todos os valores dentro dele.
+func recurseQuery(v any) ([]any, error) {
+	out := []any{v}
+	if items, err := iterate(v); err == nil {
+		for _, item := range items {
+			sub, _ := recurseQuery(item)
+			out = append(out, sub...)
+		}
+	}
+	return out, nil
+}
+
+func truthy(v any) bool {
+	return v != nil && v != false
+}
+
+func typeName(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case int64, json.Number, float64:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	}
+	return "object"
+}
+
+func toFloat(v any) (float64, bool) {
+	switch v := v.(type) {
+	case int64:
+		return float64(v), true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+// toBigInt converte inteiros (int64 ou json.Number) para comparações exatas.
+func toBigInt(v any) (*big.Int, bool) {
+	switch v := v.(type) {
+	case int64:
+		return big.NewInt(v), true
+	case json.Number:
+		return new(big.Int).SetString(string(v), 10)
+	}
+	return nil, false
+}
+
+// normalizeNumber usa int64 quando o número é inteiro e representável com exatidão.
+func normalizeNumber(f float64) any {
+	if f == math.Trunc(f) && math.Abs(f) < 1<<53 {
+		return int64(f)
+	}
+	return f
+}
+
+func cloneMap(m *dataformat.Map) *dataformat.Map {
+	c := dataformat.NewMap()
+	for _, k := range m.Keys() {
+		v, _ := m.Get(k)
+		c.Set(k, v)
+	}
+	return c
+}
+
+// arithmetic aplica + - * / % com as regras do jq para cada tipo.
+func arithmetic(op string, a, b any) (any, error) {
+	fa, aNum := toFloat(a)
+	fb, bNum := toFloat(b)
+	if aNum && bNum {
+		switch op {
+		case "+":
+			return normalizeNumber(fa + fb), nil
+		case "-":
+			return normalizeNumber(fa - fb), nil
+		case "*":
+			return normalizeNumber(fa * fb), nil
+		case "/":
+			if fb == 0 {
+				return nil, fmt.Errorf("divisão por zero")
+			}
+			return normalizeNumber(fa / fb), nil
+		case "%":
+			if int64(fb) == 0 {
+				return nil, fmt.Errorf("divisão por zero")
+			}
+			return int64(fa) % int64(fb), nil
+		}
+	}
+	switch {
+	case op == "+" && a == nil:
+		return b, nil
+	case op == "+" && b == nil:
+		return a, nil
+	}
+	switch a := a.(type) {
+	case string:
+		if s, ok := b.(string); ok {
+			switch op {
+			case "+":
+				return a + s, nil
+			case "/":
+				return toAnySlice(strings.Split(a, s)), nil
+			}
+		}
+	case []any:
+		if items, ok := b.([]any); ok {
+			switch op {
+			case "+":
+				return append(append([]any{}, a...), items...), nil
+			case "-":
+				var out []any
+				for _, x := range a {
+					if !containsEqual(items, x) {
+						out = append(out, x)
+					}
+				}
+				if out == nil {
+					out = []any{}
+				}
+				return out, nil
+			}
+		}
+	case *dataformat.Map:
+		if m, ok := b.(*dataformat.Map); ok && op == "+" {
+			c := cloneMap(a)
+			for _, k := range m.Keys() {
+				v, _ := m.Get(k)
+				c.Set(k, v)
+			}
+			return c, nil
+		}
+	}
+	return nil, fmt.Errorf("não é possível aplicar '%s' entre %s e %s", op, typeName(a), typeName(b))
+}
+
+func toAnySlice(items []string) []any {
+	out := make([]any, len(items))
+	for i, s := range items {
+		out[i] = s
+	}
+	return out
+}
+
+func containsEqual(items []any, v any) bool {
+	for _, item := range items {
+		if compareValues(item, v) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// compareValues ordena como o jq: null < false < true < números < strings < arrays < objetos.
+func compareValues(a, b any) int {
+	rank := func(v any) int {
+		switch v := v.(type) {
+		case nil:
+			return 0
+		case bool:
+			if v {
+				return 2
+			}
+			return 1
+		case int64, json.Number, float64:
+			return 3
+		case string:
+			return 4
+		case []any:
+			return 5
+		}
+		return 6
+	}
+	if ra, rb := rank(a), rank(b); ra != rb {
+		return ra - rb
+	}
+	switch a := a.(type) {
+	case int64, json.Number, float64:
+		if ia, ok := toBigInt(a); ok {
+			if ib, ok := toBigInt(b); ok {
+				return ia.Cmp(ib)
+			}
+		}
+		fa, _ := toFloat(a)
+		fb, _ := toFloat(b)
+		switch {
+		case fa < fb:
+			return -1
+		case fa > fb:
+			return 1
+		}
+		return 0
+	case string:
+		return strings.Compare(a, b.(string))
+	case []any:
+		bs := b.([]any)
+		for i := 0; i < len(a) && i < len(bs); i++ {
+			if c := compareValues(a[i], bs[i]); c != 0 {
+				return c
+			}
+		}
+		return len(a) - len(bs)
+	case *dataformat.Map:
+		bm := b.(*dataformat.Map)
+		ka, kb := sortedKeys(a), sortedKeys(bm)
+		if c := compareValues(toAnySlice(ka), toAnySlice(kb)); c != 0 {
+			return c
+		}
+		for _, k := range ka {
+			va, _ := a.Get(k)
+			vb, _ := bm.Get(k)
+			if c := compareValues(va, vb); c != 0 {
+				return c
+			}
+		}
+	}
+	return 0
+}
+
+func sortedKeys(m *dataformat.Map) []string {
+	keys := append([]string{}, m.Keys()...)
+	sort.Strings(keys)
+	return keys
+}
+
+// ::: Funções :::
+
+type queryFunction func(v any, args []query) ([]any, error)
+
+// queryFunctions indexa as funções por nome/aridade, como o jq.
+var queryFunctions map[string]queryFunction
+
+func init() {
+	queryFunctions = map[string]queryFunction{
+		"empty/0":  func(any, []query) ([]any, error) { return nil, nil },
+		"not/0":    simple(func(v any) (any, error) { return !truthy(v), nil }),
+		"type/0":   simple(func(v any) (any, error) { return typeName(v), nil }),
+		"length/0": simple(length),
+		"keys/0":   simple(keys),
+		"add/0": simple(func(v any) (any, error) {
+			items, err := iterate(v)
+			if err != nil {
+				return nil, err
+			}
+			var sum any
+			for _, item := range items {
+				if sum, err = arithmetic("+", sum, item); err != nil {
+					return nil, err
+				}
+			}
+			return sum, nil
+		}),
+		"to_entries/0":   simple(toEntries),
+		"from_entries/0": simple(fromEntries),
+		"sort/0": simple(func(v any) (any, error) {
+			return sortBy(v, nil)
+		}),
+		"unique/0": simple(func(v any) (any, error) {
+			return uniqueBy(v, nil)
+		}),
+		"min/0": simple(func(v any) (any, error) { return extreme(v, nil, -1) }),
+		"max/0": simple(func(v any) (any, error) { return extreme(v, nil, 1) }),
+		"first/0": simple(func(v any) (any, error) {
+			return indexValue(v, int64(0))
+		}),
+		"last/0": simple(func(v any) (any, error) {
+			return indexValue(v, int64(-1))
+		}),
+		"reverse/0": simple(func(v any) (any, error) {
+			switch v := v.(type) {
+			case nil:
+				return []any{}, nil
+			case string:
+				r := []rune(v)
+				for i, j := 0, len(r)-1; i < j; i, j = i+1, j-1 {
+					r[i], r[j] = r[j], r[i]
+				}
+				return string(r), nil
+			case []any:
+				out := make([]any, len(v))
+				for i, item := range v {
+					out[len(v)-1-i] = item
+				}
+				return out, nil
+			}
+			return nil, fmt.Errorf("não é possível inverter %s", typeName(v))
+		}),
+		"flatten/0": simple(func(v any) (any, error) {
+			items, ok := v.([]any)
+			if !ok {
+				return nil, fmt.Errorf("flatten espera um array, não %s", typeName(v))
+			}
+			return flatten(items), nil
+		}),
+		"any/0": simple(func(v any) (any, error) { return quantify(v, nil, true) }),
+		"all/0": simple(func(v any) (any, error) { return quantify(v, nil, false) }),
+		"tostring/0": simple(func(v any) (any, error) {
+			if s, ok := v.(string); ok {
+				return s, nil
+			}
+			return dataformat.EncodeJSON(v), nil
+		}),
+		"tojson/0": simple(func(v any) (any, error) { return dataformat.EncodeJSON(v), nil }),
+		"tonumber/0": simple(func(v any) (any, error) {
+			switch v := v.(type) {
+			case int64, json.Number, float64:
+				return v, nil
+			case string:
+				f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+				if err != nil {
+					return nil, fmt.Errorf("não é possível converter %q em número", v)
+				}
+				return normalizeNumber(f), nil
+			}
+			return nil, fmt.Errorf("não é possível converter %s em número", typeName(v))
+		}),
+		"ascii_downcase/0": stringFunc(strings.ToLower),
+		"ascii_upcase/0":   stringFunc(strings.ToUpper),
+
+		"select/1": func(v any, args []query) ([]any, error) {
+			conds, err := args[0](v)
+			if err != nil {
+				return nil, err
+			}
+			var out []any
+			for _, c := range conds {
+				if truthy(c) {
+					out = append(out, v)
+				}
+			}
+			return out, nil
+		},
+		"map/1": func(v any, args []query) ([]any, error) {
+			items, err := iterate(v)
+			if err != nil {
+				return nil, err
+			}
+			out := []any{}
+			for _, item := range items {
+				res, err := args[0](item)
+				if err != nil {
+					return nil, err
+				}
+				out = append(out, res...)
+			}
+			return []any{out}, nil
+		},
+		"first/1": func(v any, args []query) ([]any, error) {
+			res, err := args[0](v)
+			if err != nil || len(res) == 0 {
+				return nil, err
+			}
+			return res[:1], nil
+		},
+		"last/1": func(v any, args []query) ([]any, error) {
+			res, err := args[0](v)
+			if err != nil || len(res) == 0 {
+				return nil, err
+			}
+			return res[len(res)-1:], nil
+		},
+		"limit/2": func(v any, args []query) ([]any, error) {
+			ns, err := args[0](v)
+			if err != nil || len(ns) == 0 {
+				return nil, err
+			}
+			n, ok := toFloat(ns[0])
+			if !ok {
+				return nil, fmt.Errorf("limit espera um número")
+			}
+			res, err := args[1](v)
+			if err != nil {
+				return nil, err
+			}
+			return res[:max(0, min(int(n), len(res)))], nil
+		},
+		"with_entries/1": func(v any, args []query) ([]any, error) {
+			entries, err := toEntries(v)
+			if err != nil {
+				return nil, err
+			}
+			var mapped []any
+			for _, e := range entries.([]any) {
+				res, err := args[0](e)
+				if err != nil {
+					return nil, err
+				}
+				mapped = append(mapped, res...)
+			}
+			m, err := fromEntries(mapped)
+			return []any{m}, err
+		},
+		"sort_by/1":   filterArg(sortBy),
+		"unique_by/1": filterArg(uniqueBy),
+		"group_by/1":  filterArg(groupBy),
+		"min_by/1":    filterArg(func(v any, f query) (any, error) { return extreme(v, f, -1) }),
+		"max_by/1":    filterArg(func(v any, f query) (any, error) { return extreme(v, f, 1) }),
+		"any/1":       filterArg(func(v any, f query) (any, error) { return quantify(v, f, true) }),
+		"all/1":       filterArg(func(v any, f query) (any, error) { return quantify(v, f, false) }),
+
+		"has/1": valueArg(func(v, k any) (any, error) {
+			switch v := v.(type) {
+			case *dataformat.Map:
+				if ks, ok := k.(string); ok {
+					_, found := v.Get(ks)
+					return found, nil
+				}
+			case []any:
+				if f, ok := toFloat(k); ok {
+					return f >= 0 && int(f) < len(v), nil
+				}
+			}
+			return nil, fmt.Errorf("não é possível verificar se %s tem a chave %s", typeName(v), describeKey(k))
+		}),
+		"contains/1": valueArg(func(v, b any) (any, error) {
+			if typeName(v) != typeName(b) {
+				return nil, fmt.Errorf("%s e %s não podem ser comparados com contains", typeName(v), typeName(b))
+			}
+			return containsValue(v, b), nil
+		}),
+		"test/1": valueArg(func(v, pattern any) (any, error) {
+			s, ok1 := v.(string)
+			p, ok2 := pattern.(string)
+			if !ok1 || !ok2 {
+				return nil, fmt.Errorf("test espera uma string e um padrão, não %s e %s", typeName(v), typeName(pattern))
+			}
+			re, err := regexp.Compile(p)
+			if err != nil {
+				return nil, fmt.Errorf("expressão regular inválida %q: %w", p, err)
+			}
+			return re.MatchString(s), nil
+		}),
+		"startswith/1": stringsArg(func(s, t string) any { return strings.HasPrefix(s, t) }),
+		"endswith/1":   stringsArg(func(s, t string) any { return strings.HasSuffix(s, t) }),
+		"ltrimstr/1":   stringsArg(func(s, t string) any { return strings.TrimPrefix(s, t) }),
+		"rtrimstr/1":   stringsArg(func(s, t string) any { return strings.TrimSuffix(s, t) }),
+		"split/1":      stringsArg(func(s, t string) any { return toAnySlice(strings.Split(s, t)) }),
+		"join/1": valueArg(func(v, sep any) (any, error) {
+			items, ok := v.([]any)
+			s, ok2 := sep.(string)
+			if !ok || !ok2 {
+				return nil, fmt.Errorf("join espera um array e uma string separadora")
+			}
+			parts := make([]string, len(items))
+			for i, item := range items {
+				switch item := item.(type) {
+				case nil:
+				case string:
+					parts[i] = item
+				case bool, int64, json.Number, float64:
+					parts[i] = dataformat.EncodeJSON(item)
+				default:
+					return nil, fmt.Errorf("join não aceita %s dentro do array", typeName(item))
+				}
+			}
+			return strings.Join(parts, s), nil
+		}),
+	}
+}
+
+// simple adapta uma função de um valor sem argumentos.
+func simple(fn func(any) (any, error)) queryFunction {
+	return func(v any, _ []query) ([]any, error) {
+		r, err := fn(v)
+		if err != nil {
+			return nil, err
+		}
+		return []any{r}, nil
+	}
+}
+
+// filterArg adapta uma função que recebe o argumento como filtro (ex: sort_by(.x)).
+func filterArg(fn func(any, query) (any, error)) queryFunction {
+	return func(v any, args []query) ([]any, error) {
+		r, err := fn(v, args[0])
+		if err != nil {
+			return nil, err
+		}
+		return []any{r}, nil
+	}
+}
+
+// valueArg adapta uma função que recebe o valor do argumento, avaliado sobre a entrada.
+func valueArg(fn func(v, arg any) (any, error)) queryFunction {
+	return func(v any, args []query) ([]any, error) {
+		argValues, err := args[0](v)
+		if err != nil {
+			return nil, err
+		}
+		var out []any
+		for _, a := range argValues {
+			r, err := fn(v, a)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, r)
+		}
+		return out, nil
+	}
+}
+
+func stringsArg(fn func(s, arg string) any) queryFunction {
+	return valueArg(func(v, arg any) (any, error) {
+		s, ok1 := v.(string)
+		t, ok2 := arg.(string)
+		if !ok1 || !ok2 {
+			return nil, fmt.Errorf("esperava strings, não %s e %s", typeName(v), typeName(arg))
+		}
+		return fn(s, t), nil
+	})
+}
+
+func stringFunc(fn func(string) string) queryFunction {
+	return simple(func(v any) (any, error) {
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("esperava uma string, não %s", typeName(v))
+		}
+		return fn(s), nil
+	})
+}
+
+func length(v any) (any, error) {
+	switch v := v.(type) {
+	case nil:
+		return int64(0), nil
+	case int64:
+		return max(v, -v), nil
+	case json.Number:
+		return json.Number(strings.TrimPrefix(string(v), "-")), nil
+	case float64:
+		return math.Abs(v), nil
+	case string:
+		return int64(utf8.RuneCountInString(v)), nil
+	case []any:
+		return int64(len(v)), nil
+	case *dataformat.Map:
+		return int64(v.Len()), nil
+	}
+	return nil, fmt.Errorf("%s não tem tamanho", typeName(v))
+}
+
+func keys(v any) (any, error) {
+	switch v := v.(type) {
+	case *dataformat.Map:
+		return toAnySlice(sortedKeys(v)), nil
+	case []any:
+		out := make([]any, len(v))
+		for i := range v {
+			out[i] = int64(i)
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("%s não tem chaves", typeName(v))
+}
+
+func toEntries(v any) (any, error) {
+	m, ok := v.(*dataformat.Map)
+	if !ok {
+		return nil, fmt.Errorf("to_entries espera um objeto, não %s", typeName(v))
+	}
+	out := make([]any, 0, m.Len())
+	for _, k := range m.Keys() {
+		value, _ := m.Get(k)
+		e := dataformat.NewMap()
+		e.Set("key", k)
+		e.Set("value", value)
+		out = append(out, e)
+	}
+	return out, nil
+}
+
+func fromEntries(v any) (any, error) {
+	items, ok := v.([]any)
+	if !ok {
+		return nil, fmt.Errorf("from_entries espera um array, não %s", typeName(v))
+	}
+	out := dataformat.NewMap()
+	for _, item := range items {
+		e, ok := item.(*dataformat.Map)
+		if !ok {
+			return nil, fmt.Errorf("from_entries espera objetos {key, value}, não %s", typeName(item))
+		}
+		var key any
+		for _, name := range []string{"key", "k", "name", "Name", "Key", "K"} {
+			if k, found := e.Get(name); found && k != nil {
+				key = k
+				break
+			}
+		}
+		var value any
+		for _, name := range []string{"value", "v", "Value", "V"} {
+			if val, found := e.Get(name); found {
+				value = val
+				break
+			}
+		}
+		switch k := key.(type) {
+		case string:
+			out.Set(k, value)
+		case int64, json.Number, float64, bool:
+			out.Set(dataformat.EncodeJSON(k), value)
+		default:
+			return nil, fmt.Errorf("from_entries: chave inválida %s", typeName(key))
+		}
+	}
+	return out, nil
+}
+
+// sortKeys avalia f em cada item, para ordenar ou agrupar por ele. Sem f, usa o item.
+func sortKeys(v any, f query) ([]any, []any, error) {
+	items, ok := v.([]any)
+	if !ok {
+		return nil, nil, fmt.Errorf("esperava um array, não %s", typeName(v))
+	}
+	ks := make([]any, len(items))
+	for i, item := range items {
+		if f == nil {
+			ks[i] = item
+			continue
+		}
+		res, err := f(item)
+		if err != nil {
+			return nil, nil, err
+		}
+		ks[i] = res
+	}
+	return items, ks, nil
+}
+
+func sortBy(v any, f query) (any, error) {
+	items, ks, err := sortKeys(v, f)
+	if err != nil {
+		return nil, err
+	}
+	idx := make([]int, len(items))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(a, b int) bool { return compareValues(ks[idx[a]], ks[idx[b]]) < 0 })
+	out := make([]any, len(items))
+	for i, j := range idx {
+		out[i] = items[j]
+	}
+	return out, nil
+}
+
+func groupBy(v any, f query) (any, error) {
+	items, ks, err := sortKeys(v, f)
+	if err != nil {
+		return nil, err
+	}
+	idx := make([]int, len(items))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(a, b int) bool { return compareValues(ks[idx[a]], ks[idx[b]]) < 0 })
+	out := []any{}
+	for n, j := range idx {
+		if n == 0 || compareValues(ks[j], ks[idx[n-1]]) != 0 {
+			out = append(out, []any{})
+		}
+		out[len(out)-1] = append(out[len(out)-1].([]any), items[j])
+	}
+	return out, nil
+}
+
+func uniqueBy(v any, f query) (any, error) {
+	groups, err := groupBy(v, f)
+	if err != nil {
+		return nil, err
+	}
+	out := []any{}
+	for _, g := range groups.([]any) {
+		out = append(out, g.([]any)[0])
+	}
+	return out, nil
+}
+
+// extreme retorna o menor (dir -1) ou maior (dir 1) item; null para arrays vazios.
+func extreme(v any, f query, dir int) (any, error) {
+	items, ks, err := sortKeys(v, f)
+	if err != nil || len(items) == 0 {
+		return nil, err
+	}
+	best := 0
+	for i := range items {
+		if c := compareValues(ks[i], ks[best]); c*dir > 0 || (c == 0 && dir > 0) {
+			best = i
+		}
+	}
+	return items[best], nil
+}
+
+// quantify implementa any (some=true) e all (some=false).
+func quantify(v any, f query, some bool) (any, error) {
+	items, ks, err := sortKeys(v, f)
+	if err != nil {
+		return nil, err
+	}
+	for i := range items {
+		values := []any{ks[i]}
+		if f != nil {
+			values = ks[i].([]any)
+		}
+		for _, x := range values {
+			if truthy(x) == some {
+				return some, nil
+			}
+		}
+	}
+	return !some, nil
+}
+
+func flatten(items []any) []any {
+	out := []any{}
+	for _, item := range items {
+		if inner, ok := item.([]any); ok {
+			out = append(out, flatten(inner)...)
+		} else {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// containsValue segue o jq: substrings, e arrays/objetos contidos recursivamente.
+func containsValue(a, b any) bool {
+	switch a := a.(type) {
+	case string:
+		return strings.Contains(a, b.(string))
+	case []any:
+		for _, y := range b.([]any) {
+			found := false
+			for _, x := range a {
+				if typeName(x) == typeName(y) && containsValue(x, y) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+		return true
+	case *dataformat.Map:
+		bm := b.(*dataformat.Map)
+		for _, k := range bm.Keys() {
+			x, ok := a.Get(k)
+			y, _ := bm.Get(k)
+			if !ok || typeName(x) != typeName(y) || !containsValue(x, y) {
+				return false
+			}
+		}
+		return true
+	}
+	return compareValues(a, b) == 0
+}
+
+// ::: Caminhos :::
+
+// parseDataPath lê um caminho simples, como .spec.containers[0].image ou ."chave x"[2],
+// usado para indicar onde gravar um valor.
+func parseDataPath(src string) ([]dataformat.PathElem, error) {
+	invalid := fmt.Errorf("caminho inválido '%s'; use um caminho simples como .spec.containers[0].image", src)
+	toks, err := lexQuery(src)
+	if err != nil {
+		return nil, invalid
+	}
+	var path []dataformat.PathElem
+	i := 0
+	at := func(kind int, text string) bool {
+		return toks[i].kind == kind && (text == "" || toks[i].text == text)
+	}
+	if at(tokPunct, ".") {
+		i++
+		if at(tokString, "") {
+			path = append(path, dataformat.PathElem{Key: toks[i].value.(string)})
+			i++
+		}
+	} else if !at(tokField, "") {
+		return nil, invalid
+	}
+	for !at(tokEOF, "") {
+		switch {
+		case at(tokField, ""):
+			path = append(path, dataformat.PathElem{Key: toks[i].text})
+			i++
+		case at(tokPunct, ".") && toks[i+1].kind == tokString:
+			path = append(path, dataformat.PathElem{Key: toks[i+1].value.(string)})
+			i += 2
+		case at(tokPunct, ".") && toks[i+1].text == "[", at(tokPunct, "["):
+			if at(tokPunct, ".") {
+				i++
+			}
+			i++
+			negative := at(tokPunct, "-")
+			if negative {
+				i++
+			}
+			switch {
+			case at(tokString, "") && !negative:
+				path = append(path, dataformat.PathElem{Key: toks[i].value.(string)})
+			case at(tokNumber, ""):
+				// Os números da expressão vêm do léxico (normalizeNumber), nunca json.Number:
+				// índices fracionários ou grandes demais para um float exato são recusados.
+				n, ok := toks[i].value.(int64)
+				if !ok {
+					return nil, invalid
+				}
+				if negative {
+					n = -n
+				}
+				path = append(path, dataformat.PathElem{Index: int(n), IsIndex: true})
+			default:
+				return nil, invalid
+			}
+			i++
+			if !at(tokPunct, "]") {
+				return nil, invalid
+			}
+			i++
+		default:
+			return nil, invalid
+		}
+	}
+	return path, nil
+}
@@ -0,0 +1,138 @@
+package builtin
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/matheusbuniotto/goagent/internal/dataformat"
+)
+
+// TestCompileQuery testa a linguagem de consulta sobre um documento fixo. O resultado
+// é a lista de saídas em JSON, separadas por espaço.
+func TestCompileQuery(t *testing.T) {
+	doc, _ := dataformat.ParseJSON(`{
+		"name": "app",
+		"version": 2,
+		"tags": ["web", "api", "web"],
+		"services": [
+			{"name": "api", "port": 8080, "replicas": 3, "env": {"DEBUG": "1"}},
+			{"name": "worker", "port": null, "replicas": 1},
+			{"name": "db", "port": 5432, "replicas": 1}
+		],
+		"empty key": true,
+		"big": 12345678901234567890
+	}`)
+	input := doc.Value()
+
+	testCases := []struct {
+		expression    string
+		expected      string
+		expectedError string
+	}{
+		{expression: ".", expected: dataformat.EncodeJSON(input)},
+		{expression: ".name", expected: `"app"`},
+		{expression: ".big", expected: `12345678901234567890`},
+		{expression: "[.big, .version] | sort", expected: `[2,12345678901234567890]`},
+		{expression: ".big | tostring", expected: `"12345678901234567890"`},
+		{expression: `."empty key"`, expected: `true`},
+		{expression: `.["name"]`, expected: `"app"`},
+		{expression: ".missing.deep", expected: `null`},
+		{expression: ".tags[1]", expected: `"api"`},
+		{expression: ".tags[-1]", expected: `"web"`},
+		{expression: ".tags[1:]", expected: `["api","web"]`},
+		{expression: ".tags[]", expected: `"web" "api" "web"`},
+		{expression: ".services[].name", expected: `"api" "worker" "db"`},
+		{expression: ".services[] | select(.replicas > 1) | .name", expected: `"api"`},
+		{expression: `.services[] | select(.port == null).name`, expected: `"worker"`},
+		{expression: ".services | map(.port // 0)", expected: `[8080,0,5432]`},
+		{expression: ".services | length", expected: `3`},
+		{expression: "[.services[].replicas] | add", expected: `5`},
+		{expression: ".services | map(.replicas) | add / length", expected: `1.6666666666666667`},
+		{expression: ".tags | unique", expected: `["api","web"]`},
+		{expression: ".services | sort_by(.name) | map(.name)", expected: `["api","db","worker"]`},
+		{expression: ".services | group_by(.replicas) | map(length)", expected: `[2,1]`},
+		{expression: ".services | max_by(.port).name", expected: `"api"`},
+		{expression: ".services | min_by(.replicas).name", expected: `"worker"`},
+		{expression: "keys", expected: `["big","empty key","name","services","tags","version"]`},
+		{expression: ".services[0].env | to_entries", expected: `[{"key":"DEBUG","value":"1"}]`},
+		{expression: `{name, total: (.services | length)}`, expected: `{"name":"app","total":3}`},
+		{expression: `.services[] | {(.name): .replicas}`, expected: `{"api":3} {"worker":1} {"db":1}`},
+		{expression: `.name, .version`, expected: `"app" 2`},
+		{expression: `.version * 10 + 1`, expected: `21`},
+		{expression: `.name + "-" + (.version | tostring)`, expected: `"app-2"`},
+		{expression: `.tags | join(",")`, expected: `"web,api,web"`},
+		{expression: `.name | test("^a")`, expected: `true`},
+		{expression: `.services | any(.port == null)`, expected: `true`},
+		{expression: `.services | all(.replicas >= 1)`, expected: `true`},
+		{expression: `has("name") and (.tags | contains(["api"]))`, expected: `true`},
+		{expression: `.services[] | if .replicas > 1 then "ha" elif .port then "single" else "none" end`, expected: `"ha" "none" "single"`},
+		{expression: `[.. | select(type == "number")] | length`, expected: `7`},
+		{expression: `.services[] | .name | ascii_upcase`, expected: `"API" "WORKER" "DB"`},
+		{expression: `.tags[] | empty`, expected: ``},
+		{expression: `.name[0]`, expectedError: `não é possível indexar string com number`},
+		{expression: `.name[0]?`, expected: ``},
+		{expression: `.version[]`, expectedError: `não é possível iterar sobre number`},
+		{expression: `.services[`, expectedError: `fim inesperado da expressão`},
+		{expression: `.name |`, expectedError: `fim inesperado da expressão`},
+		{expression: `.a & .b`, expectedError: `caractere inesperado '&' na posição 4`},
+		{expression: `foo(1)`, expectedError: `função desconhecida 'foo/1'`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.expression, func(t *testing.T) {
+			q, err := compileQuery(tc.expression)
+			var results []any
+			if err == nil {
+				results, err = q(input)
+			}
+			if tc.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedError) {
+					t.Fatalf("erro = %v, esperado conter %q", err, tc.expectedError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+			var parts []string
+			for _, r := range results {
+				parts = append(parts, dataformat.EncodeJSON(r))
+			}
+			if got := strings.Join(parts, " "); got != tc.expected {
+				t.Errorf("resultado = %s, esperado %s", got, tc.expected)
+			}
+		})
+	}
+}
+
+// TestParseDataPath testa os caminhos aceitos por update_data.
+func TestParseDataPath(t *testing.T) {
+	testCases := []struct {
+		path     string
+		expected string
+		invalid  bool
+	}{
+		{path: ".", expected: "."},
+		{path: ".spec.replicas", expected: ".spec.replicas"},
+		{path: "spec", invalid: true},
+		{path: `.items[0]."chave x"`, expected: `.items[0]."chave x"`},
+		{path: `.["a"].b[-1]`, expected: ".a.b[-1]"},
+		{path: ".a.[2]", expected: ".a[2]"},
+		{path: ".a[1.5]", invalid: true},
+		{path: ".a[12345678901234567890]", invalid: true},
+		{path: ".a | .b", invalid: true},
+		{path: ".a[]", invalid: true},
+	}
+	for _, tc := range testCases {
+		path, err := parseDataPath(tc.path)
+		if tc.invalid {
+			if err == nil {
+				t.Errorf("parseDataPath(%q) deveria falhar", tc.path)
+			}
+			continue
+		}
+		if err != nil || dataformat.FormatPath(path) != tc.expected {
+			t.Errorf("parseDataPath(%q) = %s (%v), esperado %s", tc.path, dataformat.FormatPath(path), err, tc.expected)
+		}
+	}
+}
@@ -0,0 +1,291 @@
+package builtin
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/matheusbuniotto/goagent/internal/dataformat"
+	"github.com/matheusbuniotto/goagent/pkg/toolkit"
+)
+
+// defaultDataLimit é o número de resultados devolvidos quando limit não é informado.
+const defaultDataLimit = 100
+
+// maxDataFiles é o máximo de arquivos lidos em uma consulta com glob.
+const maxDataFiles = 200
+
+// maxDataOutput é o limite de bytes da saída de query_data.
+const maxDataOutput = 100_000
+
+// dataDocument é um documento de um arquivo de dados, com o rótulo usado na saída.
+type dataDocument struct {
+	label string
+	value any
+}
+
+// loadDataFile lê e interpreta um arquivo JSON, YAML ou TOML.
+func loadDataFile(abs, display, format string) (dataformat.Format, []*dataformat.Node, error) {
+	f, err := dataFormat(abs, format)
+	if err != nil {
+		return "", nil, err
+	}
+	data, err := os.ReadFile(abs)
+	if err != nil {
+		return "", nil, fmt.Errorf("erro ao ler o arquivo '%s': %w", display, err)
+	}
+	docs, err := dataformat.Parse(f, string(data))
+	if err != nil {
+		return "", nil, fmt.Errorf("'%s' não é %s válido: %w", display, strings.ToUpper(string(f)), err)
+	}
+	return f, docs, nil
+}
+
+// dataFormat usa o formato informado ou o deduz pela extensão.
+func dataFormat(path, format string) (dataformat.Format, error) {
+	if format != "" {
+		return dataformat.ParseFormat(format)
+	}
+	return dataformat.DetectFormat(path)
+}
+
+// ::: Ferramenta: QueryData :::
+
+type QueryDataInput struct {
+	Path       string `json:"path"`             // arquivo ou glob (ex: "k8s/**/*.yaml")
+	Expression string `json:"expression"`       // expressão no estilo do jq
+	Format     string `json:"format,omitempty"` // json, yaml ou toml; padrão pela extensão
+	Raw        bool   `json:"raw,omitempty"`    // strings sem aspas, como jq -r
+	Limit      int    `json:"limit,omitempty"`  // máximo de resultados
+}
+
+// dataFiles resolve o caminho de query_data: um arquivo, ou os arquivos que casam com o
+// glob, procurados a partir do diretório antes do primeiro curinga.
+func dataFiles(path string) (files [][2]string, err error) {
+	w, err := CurrentWorkspace()
+	if err != nil {
+		return nil, err
+	}
+	if !strings.ContainsAny(path, "*?[") {
+		abs, err := w.ResolveRead(path)
+		if err != nil {
+			return nil, err
+		}
+		return [][2]string{{abs, w.Display(path, abs)}}, nil
+	}
+
+	base, pattern := ".", filepath.ToSlash(path)
+	if i := strings.IndexAny(pattern, "*?["); i > 0 {
+		if slash := strings.LastIndexByte(pattern[:i], '/'); slash >= 0 {
+			base, pattern = pattern[:slash], pattern[slash+1:]
+		}
+	}
+	if !strings.Contains(pattern, "/") {
+		pattern = "/" + pattern // Sem "/" o glob casaria em qualquer nível
+	}
+	re, err := globRegexp(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("padrão inválido '%s': %w", path, err)
+	}
+	root, err := w.ResolveRead(base)
+	if err != nil {
+		return nil, err
+	}
+	err = treeWalker{}.walk(root, func(abs, rel string, e os.DirEntry) error {
+		if !e.Type().IsRegular() || !re.MatchString(rel) {
+			return nil
+		}
+		if len(files) == maxDataFiles {
+			return fmt.Errorf("o padrão '%s' casa com mais de %d arquivos; use um padrão mais específico", path, maxDataFiles)
+		}
+		files = append(files, [2]string{abs, w.Display(filepath.Join(base, rel), abs)})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("nenhum arquivo corresponde a '%s'", path)
+	}
+	return files, nil
+}
+
+func queryData(input json.RawMessage) (string, error) {
+	var typedInput QueryDataInput
+	if err := json.Unmarshal(input, &typedInput); err != nil {
+		return "", fmt.Errorf("JSON inválido para argumentos: %w", err)
+	}
+
+	if typedInput.Path == "" || strings.TrimSpace(typedInput.Expression) == "" {
+		return "", fmt.Errorf("argumentos inválidos. 'path' e 'expression' são obrigatórios")
+	}
+	if typedInput.Limit < 0 {
+		return "", fmt.Errorf("argumento inválido. 'limit' não pode ser negativo")
+	}
+	limit := typedInput.Limit
+	if limit == 0 {
+		limit = defaultDataLimit
+	}
+
+	q, err := compileQuery(typedInput.Expression)
+	if err != nil {
+		return "", fmt.Errorf("expressão inválida: %w", err)
+	}
+	files, err := dataFiles(typedInput.Path)
+	if err != nil {
+		return "", err
+	}
+
+	// Com vários arquivos, um arquivo inválido vira uma linha de erro em vez de
+	// interromper a consulta.
+	var docs []dataDocument
+	var lines []string
+	for _, f := range files {
+		_, nodes, err := loadDataFile(f[0], f[1], typedInput.Format)
+		if err != nil {
+			if len(files) == 1 {
+				return "", err
+			}
+			lines = append(lines, fmt.Sprintf("%s: erro: %v", f[1], err))
+			continue
+		}
+		for i, n := range nodes {
+			label := f[1]
+			if len(nodes) > 1 {
+				label = fmt.Sprintf("%s#%d", f[1], i+1)
+			}
+			docs = append(docs, dataDocument{label: label, value: n.Value()})
+		}
+	}
+	labeled := len(files) > 1 || len(docs) > 1
+
+	total := 0
+	for _, d := range docs {
+		results, err := q(d.value)
+		if err != nil {
+			if !labeled {
+				return "", fmt.Errorf("erro ao avaliar a expressão: %w", err)
+			}
+			lines = append(lines, fmt.Sprintf("%s: erro: %v", d.label, err))
+			continue
+		}
+		for _, r := range results {
+			total++
+			if total > limit {
+				continue
+			}
+			text := dataformat.EncodeJSON(r)
+			if s, ok := r.(string); ok && typedInput.Raw {
+				text = s
+			}
+			if labeled {
+				text = d.label + ": " + text
+			}
+			lines = append(lines, text)
+		}
+	}
+
+	if total == 0 && len(lines) == 0 {
+		return fmt.Sprintf("Nenhum resultado para '%s'.", typedInput.Expression), nil
+	}
+	if total > limit {
+		lines = append(lines, fmt.Sprintf("... (%d resultado(s) omitido(s); aumente 'limit' ou refine a expressão)", total-limit))
+	}
+	out := strings.Join(lines, "\n")
+	if len(out) > maxDataOutput {
+		out = truncateUTF8(out, maxDataOutput) + "\n... (saída truncada; refine a expressão)"
+	}
+	return out, nil
+}
+
+var QueryDataDef = toolkit.ToolDefinition{
+	Name:        "query_data",
+	Description: `Consulta arquivos JSON, YAML ou TOML com uma expressão no estilo do jq e retorna só os valores encontrados, um JSON por linha. "path" pode ser um arquivo ou um glob (ex: "deploy/**/*.yaml"); com vários arquivos ou documentos YAML, cada linha começa com "arquivo: " (ou "arquivo#N: "). Suporta .campo, ."chave com espaço", [n], [a:b], [], .., |, ",", select, map, keys, length, has, contains, test, sort_by, group_by, unique, min/max, add, to_entries, if/then/else, comparações, and/or, // e construção de objetos e arrays. Use "raw": true para strings sem aspas. Exemplo: {"path": "package.json", "expression": ".dependencies | keys"} ou {"path": "k8s/*.yaml", "expression": ".spec.template.spec.containers[] | select(.name == \"api\") | .image"}`,
+	Function:    queryData,
+}
+
+// ::: Ferramenta: UpdateData :::
+
+type UpdateDataInput struct {
+	Path     string          `json:"path"`
+	Selector string          `json:"selector"`           // caminho simples, ex: .spec.replicas
+	Value    json.RawMessage `json:"value"`              // novo valor em JSON
+	Document int             `json:"document,omitempty"` // documento YAML, a partir de 1
+	Format   string          `json:"format,omitempty"`
+}
+
+// planUpdateData calcula o novo conteúdo do arquivo sem gravá-lo.
+func planUpdateData(input json.RawMessage) (fileChange, string, error) {
+	var typedInput UpdateDataInput
+	if err := json.Unmarshal(input, &typedInput); err != nil {
+		return fileChange{}, "", fmt.Errorf("JSON inválido para argumentos: %w", err)
+	}
+
+	if typedInput.Path == "" || typedInput.Selector == "" || len(typedInput.Value) == 0 {
+		return fileChange{}, "", fmt.Errorf("argumentos inválidos. 'path', 'selector' e 'value' são obrigatórios")
+	}
+	path, err := parseDataPath(typedInput.Selector)
+	if err != nil {
+		return fileChange{}, "", err
+	}
+	value, err := dataformat.DecodeJSON(typedInput.Value)
+	if err != nil {
+		return fileChange{}, "", fmt.Errorf("'value' não é um JSON válido: %w", err)
+	}
+
+	change, err := readForEdit(typedInput.Path)
+	if err != nil {
+		return change, "", err
+	}
+	format, docs, err := loadDataFile(change.path, change.display, typedInput.Format)
+	if err != nil {
+		return change, "", err
+	}
+	doc := typedInput.Document
+	switch {
+	case doc == 0 && len(docs) > 1:
+		return change, "", fmt.Errorf("'%s' tem %d documentos; informe 'document' (1 a %d)", change.display, len(docs), len(docs))
+	case doc == 0:
+		doc = 1
+	case doc < 0 || doc > len(docs):
+		return change, "", fmt.Errorf("'document' deve estar entre 1 e %d", len(docs))
+	}
+
+	target := dataformat.FormatPath(path)
+	if change.new, err = dataformat.Set(format, change.old, doc-1, path, value); err != nil {
+		return change, "", fmt.Errorf("erro ao alterar %s em '%s': %w", target, change.display, err)
+	}
+	return change, target, nil
+}
+
+func updateData(input json.RawMessage) (string, error) {
+	change, target, err := planUpdateData(input)
+	if err != nil {
+		return "", err
+	}
+	if change.new == change.old {
+		return fmt.Sprintf("Nenhuma alteração: %s em '%s' já tem o valor pedido.", target, change.display), nil
+	}
+	if err := writeChanges("update_data", []fileChange{change}); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s alterado em '%s'.\n%s", target, change.display, change.diff()), nil
+}
+
+func previewUpdateData(input json.RawMessage) (string, error) {
+	change, target, err := planUpdateData(input)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Alteraria %s em '%s':\n%s", target, change.display, change.diff()), nil
+}
+
+var UpdateDataDef = toolkit.ToolDefinition{
+	Name:        "update_data",
+	Description: `Altera um valor em um arquivo JSON, YAML ou TOML, reescrevendo só o trecho do valor e mantendo comentários, ordem das chaves e formatação do resto do arquivo. "selector" é um caminho simples (ex: .spec.replicas, .servers[0].host, ."chave com espaço"); chaves inexistentes são criadas e [n] igual ao tamanho do array acrescenta um item. "value" é o novo valor em JSON. Em YAML com vários documentos, informe "document" (a partir de 1). Retorna o diff. Exemplo: {"path": "config.yaml", "selector": ".server.port", "value": 8080}`,
+	Function:    updateData,
+	Mutating:    true,
+	Preview:     previewUpdateData,
+}
@@ -0,0 +1,232 @@
+package builtin
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/matheusbuniotto/goagent/internal/checkpoint"
+)
+
+// setupDataTools cria um workspace próprio com arquivos JSON, YAML e TOML.
+func setupDataTools(t *testing.T) (root string, store *checkpoint.Store) {
+	t.Helper()
+	return newTestWorkspaceWithCheckpoints(t, map[string]string{
+		"package.json":       "{\n  \"name\": \"app\",\n  \"dependencies\": {\"b\": \"^2.0\", \"a\": \"^1.0\"}\n}\n",
+		"config.yaml":        "# servidor\nserver:\n  port: 80 # porta\n  hosts:\n    - a.local\n    - b.local\n",
+		"Cargo.toml":         "[package]\nname = \"app\"\nversion = \"0.1.0\"\n",
+		"k8s/api.yaml":       "kind: Deployment\nspec:\n  replicas: 2\n---\nkind: Service\nspec:\n  port: 80\n",
+		"k8s/worker.yaml":    "kind: Deployment\nspec:\n  replicas: 1\n",
+		"k8s/nested/db.yaml": "kind: StatefulSet\n",
+		"k8s/quebrado.yaml":  "a: [1\n",
+		"notas.txt":          "texto",
+	})
+}
+
+// TestQueryData testa consultas em um arquivo, em vários documentos e com glob.
+func TestQueryData(t *testing.T) {
+	setupDataTools(t)
+	testCases := []struct {
+		name          string
+		input         QueryDataInput
+		expected      string
+		expectedError string
+	}{
+		{
+			name:     "JSON",
+			input:    QueryDataInput{Path: "package.json", Expression: ".dependencies | keys"},
+			expected: `["a","b"]`,
+		},
+		{
+			name:     "YAML com raw",
+			input:    QueryDataInput{Path: "config.yaml", Expression: ".server.hosts[]", Raw: true},
+			expected: "a.local\nb.local",
+		},
+		{
+			name:     "TOML",
+			input:    QueryDataInput{Path: "Cargo.toml", Expression: ".package.version", Raw: true},
+			expected: "0.1.0",
+		},
+		{
+			name:     "TOML com objeto",
+			input:    QueryDataInput{Path: "Cargo.toml", Expression: ".package | {name, version}"},
+			expected: `{"name":"app","version":"0.1.0"}`,
+		},
+		{
+			name:     "Vários documentos",
+			input:    QueryDataInput{Path: "k8s/api.yaml", Expression: ".kind"},
+			expected: "k8s/api.yaml#1: \"Deployment\"\nk8s/api.yaml#2: \"Service\"",
+		},
+		{
+			name:  "Glob com arquivo inválido",
+			input: QueryDataInput{Path: "k8s/*.yaml", Expression: "select(.kind == \"Deployment\") | .spec.replicas"},
+			expected: "k8s/quebrado.yaml: erro: 'k8s/quebrado.yaml' não é YAML válido: linha 1, coluna 4: coleção sem fechamento ']'\n" +
+				"k8s/api.yaml#1: 2\nk8s/worker.yaml: 1",
+		},
+		{
+			name:     "Glob recursivo",
+			input:    QueryDataInput{Path: "k8s/**/db.yaml", Expression: ".kind", Raw: true},
+			expected: "StatefulSet",
+		},
+		{
+			name:     "Limite de resultados",
+			input:    QueryDataInput{Path: "config.yaml", Expression: ".server.hosts[]", Limit: 1},
+			expected: "\"a.local\"\n... (1 resultado(s) omitido(s); aumente 'limit' ou refine a expressão)",
+		},
+		{
+			name:     "Sem resultados",
+			input:    QueryDataInput{Path: "config.yaml", Expression: ".server.hosts[] | select(. == \"x\")"},
+			expected: "Nenhum resultado para '.server.hosts[] | select(. == \"x\")'.",
+		},
+		{
+			name:          "Formato desconhecido",
+			input:         QueryDataInput{Path: "notas.txt", Expression: "."},
+			expectedError: "formato de 'notas.txt' não reconhecido pela extensão",
+		},
+		{
+			name:          "Erro de avaliação",
+			input:         QueryDataInput{Path: "package.json", Expression: ".name[]"},
+			expectedError: "erro ao avaliar a expressão: não é possível iterar sobre string",
+		},
+		{
+			name:          "Glob sem arquivos",
+			input:         QueryDataInput{Path: "*.xml", Expression: "."},
+			expectedError: "nenhum arquivo corresponde a '*.xml'",
+		},
+		{
+			name:          "Sem expressão",
+			input:         QueryDataInput{Path: "package.json"},
+			expectedError: "'path' e 'expression' são obrigatórios",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rawInput, _ := json.Marshal(tc.input)
+			result, err := queryData(rawInput)
+			if tc.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedError) {
+					t.Fatalf("erro = %v, esperado conter %q", err, tc.expectedError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+			if result != tc.expected {
+				t.Errorf("resultado =\n%s\nesperado\n%s", result, tc.expected)
+			}
+		})
+	}
+}
+
+// TestUpdateData testa a alteração de valores preservando o resto do arquivo.
+func TestUpdateData(t *testing.T) {
+	testCases := []struct {
+		name          string
+		input         string
+		expected      string
+		expectedError string
+		check         func(t *testing.T, root string)
+	}{
+		{
+			name:     "YAML mantém comentários",
+			input:    `{"path": "config.yaml", "selector": ".server.port", "value": 8080}`,
+			expected: "-  port: 80 # porta\n+  port: 8080 # porta",
+			check:    assertContent("config.yaml", "# servidor\nserver:\n  port: 8080 # porta\n  hosts:\n    - a.local\n    - b.local\n"),
+		},
+		{
+			name:     "YAML acrescenta item",
+			input:    `{"path": "config.yaml", "selector": ".server.hosts[2]", "value": "c.local"}`,
+			expected: "+    - c.local",
+		},
+		{
+			name:     "JSON cria chave",
+			input:    `{"path": "package.json", "selector": ".scripts.test", "value": "go test"}`,
+			expected: ".scripts.test alterado em 'package.json'.",
+			check:    assertContent("package.json", "{\n  \"name\": \"app\",\n  \"dependencies\": {\"b\": \"^2.0\", \"a\": \"^1.0\"},\n  \"scripts\": {\n    \"test\": \"go test\"\n  }\n}\n"),
+		},
+		{
+			name:     "TOML",
+			input:    `{"path": "Cargo.toml", "selector": ".package.version", "value": "0.2.0"}`,
+			expected: "+version = \"0.2.0\"",
+		},
+		{
+			name:     "Segundo documento",
+			input:    `{"path": "k8s/api.yaml", "selector": ".spec.port", "value": 443, "document": 2}`,
+			expected: "+  port: 443",
+		},
+		{
+			name:     "Valor igual",
+			input:    `{"path": "Cargo.toml", "selector": ".package.name", "value": "app"}`,
+			expected: "Nenhuma alteração: .package.name em 'Cargo.toml' já tem o valor pedido.",
+		},
+		{
+			name:          "Vários documentos sem document",
+			input:         `{"path": "k8s/api.yaml", "selector": ".kind", "value": "X"}`,
+			expectedError: "'k8s/api.yaml' tem 2 documentos; informe 'document' (1 a 2)",
+		},
+		{
+			name:          "Seletor com filtro",
+			input:         `{"path": "config.yaml", "selector": ".server.hosts[] | select(. == \"a\")", "value": 1}`,
+			expectedError: "caminho inválido",
+		},
+		{
+			name:          "Valor inválido",
+			input:         `{"path": "config.yaml", "selector": ".server.port", "value": "x", "format": "xml"}`,
+			expectedError: "formato inválido 'xml'",
+		},
+		{
+			name:          "Caminho através de lista",
+			input:         `{"path": "config.yaml", "selector": ".server.hosts.x", "value": 1}`,
+			expectedError: "erro ao alterar .server.hosts.x em 'config.yaml': não é possível acessar '.x': array não é um objeto",
+		},
+		{
+			name:          "Sem valor",
+			input:         `{"path": "config.yaml", "selector": ".server.port"}`,
+			expectedError: "'path', 'selector' e 'value' são obrigatórios",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			root, _ := setupDataTools(t)
+			result, err := updateData(json.RawMessage(tc.input))
+			if tc.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedError) {
+					t.Fatalf("erro = %v, esperado conter %q", err, tc.expectedError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+			if !strings.Contains(result, tc.expected) {
+				t.Errorf("resultado =\n%s\nesperado conter\n%s", result, tc.expected)
+			}
+			if tc.check != nil {
+				tc.check(t, root)
+			}
+		})
+	}
+}
+
+// TestUpdateDataUndoAndPreview testa o dry-run e a reversão pelo checkpoint.
+func TestUpdateDataUndoAndPreview(t *testing.T) {
+	root, store := setupDataTools(t)
+	input := json.RawMessage(`{"path": "config.yaml", "selector": ".server.port", "value": 9000}`)
+
+	preview, err := previewUpdateData(input)
+	if err != nil || !strings.Contains(preview, "Alteraria .server.port em 'config.yaml':") || !strings.Contains(preview, "+  port: 9000 # porta") {
+		t.Fatalf("preview = %q (%v)", preview, err)
+	}
+	assertContent("config.yaml", "# servidor\nserver:\n  port: 80 # porta\n  hosts:\n    - a.local\n    - b.local\n")(t, root)
+
+	if _, err := updateData(input); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if _, err := store.Revert(1); err != nil {
+		t.Fatalf("Revert() erro inesperado: %v", err)
+	}
+	assertContent("config.yaml", "# servidor\nserver:\n  port: 80 # porta\n  hosts:\n    - a.local\n    - b.local\n")(t, root)
+}
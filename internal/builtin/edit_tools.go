@@ -0,0 +1,324 @@
+package builtin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/matheusbuniotto/goagent/internal/diff"
+	"github.com/matheusbuniotto/goagent/pkg/toolkit"
+)
+
+// fileChange é o resultado calculado de uma edição, antes de ser gravado.
+type fileChange struct {
+	path    string      // caminho absoluto
+	display string      // caminho como mostrado ao modelo
+	old     string      // conteúdo atual
+	new     string      // conteúdo após a edição
+	mode    fs.FileMode // permissões a manter
+	create  bool        // o arquivo ainda não existe
+	remove  bool        // o arquivo deve ser removido
+	notes   []string    // observações da aplicação (ex: fuzz)
+}
+
+// diff retorna o diff unificado da alteração.
+func (c fileChange) diff() string {
+	oldName, newName := "a/"+c.display, "b/"+c.display
+	if c.create {
+		oldName = diff.DevNull
+	}
+	if c.remove {
+		newName = diff.DevNull
+	}
+	return diff.Unified(oldName, newName, c.old, c.new)
+}
+
+// readForEdit resolve o caminho no workspace e lê o conteúdo atual do arquivo.
+func readForEdit(path string) (fileChange, error) {
+	w, err := CurrentWorkspace()
+	if err != nil {
+		return fileChange{}, err
+	}
+	abs, err := w.Resolve(path)
+	if err != nil {
+		return fileChange{}, err
+	}
+	change := fileChange{path: abs, display: w.Display(path, abs), mode: 0644}
+	info, err := os.Stat(abs)
+	if err != nil {
+		return change, fmt.Errorf("erro ao ler o arquivo '%s': %w", path, err)
+	}
+	if info.IsDir() {
+		return change, fmt.Errorf("'%s' é um diretório", path)
+	}
+	data, err := os.ReadFile(abs)
+	if err != nil {
+		return change, fmt.Errorf("erro ao ler o arquivo '%s': %w", path, err)
+	}
+	change.old, change.new, change.mode = string(data), string(data), info.Mode().Perm()
+	return change, nil
+}
+
+// writeChanges registra um checkpoint e grava as alterações.
+func writeChanges(tool string, changes []fileChange) error {
+	var paths []string
+	for _, c := range changes {
+		if c.create {
+			if missing := firstMissing(filepath.Dir(c.path)); missing != "" {
+				paths = append(paths, missing)
+			}
+		}
+		paths = append(paths, c.path)
+	}
+	if err := recordCheckpoint(tool, paths...); err != nil {
+		return err
+	}
+
+	for _, c := range changes {
+		var err error
+		switch {
+		case c.remove:
+			err = os.Remove(c.path)
+		case c.create:
+			if err = os.MkdirAll(filepath.Dir(c.path), 0755); err == nil {
+				err = os.WriteFile(c.path, []byte(c.new), c.mode)
+			}
+		default:
+			err = os.WriteFile(c.path, []byte(c.new), c.mode)
+		}
+		if err != nil {
+			return fmt.Errorf("erro ao gravar '%s': %w", c.display, err)
+		}
+	}
+	return nil
+}
+
+// ::: Ferramenta: EditFile :::
+
+// EditBlock é uma substituição exata de um trecho do arquivo.
+type EditBlock struct {
+	Search     string `json:"search"`
+	Replace    string `json:"replace"`
+	ReplaceAll bool   `json:"replace_all,omitempty"`
+}
+
+// EditFileInput aceita um único bloco (search/replace) ou vários em "edits".
+type EditFileInput struct {
+	Path       string      `json:"path"`
+	Search     string      `json:"search,omitempty"`
+	Replace    string      `json:"replace,omitempty"`
+	ReplaceAll bool        `json:"replace_all,omitempty"`
+	Edits      []EditBlock `json:"edits,omitempty"`
+}
+
+// planEdit aplica os blocos em memória, na ordem. Se algum falhar, nada é gravado.
+func planEdit(input json.RawMessage) (fileChange, error) {
+	var typedInput EditFileInput
+	if err := json.Unmarshal(input, &typedInput); err != nil {
+		return fileChange{}, fmt.Errorf("JSON inválido para argumentos: %w", err)
+	}
+
+	edits := typedInput.Edits
+	if typedInput.Search != "" {
+		edits = append([]EditBlock{{typedInput.Search, typedInput.Replace, typedInput.ReplaceAll}}, edits...)
+	}
+	if typedInput.Path == "" || len(edits) == 0 {
+		return fileChange{}, fmt.Errorf("argumentos inválidos. 'path' e 'search'/'replace' (ou 'edits') são obrigatórios")
+	}
+
+	change, err := readForEdit(typedInput.Path)
+	if err != nil {
+		return change, err
+	}
+	for i, e := range edits {
+		if e.Search == "" {
+			return change, fmt.Errorf("bloco %d: 'search' não pode ser vazio", i+1)
+		}
+		switch n := strings.Count(change.new, e.Search); {
+		case n == 0:
+			return change, fmt.Errorf("bloco %d: trecho não encontrado em '%s'%s", i+1, typedInput.Path, searchHint(change.new, e.Search))
+		case n > 1 && !e.ReplaceAll:
+			return change, fmt.Errorf("bloco %d: trecho aparece %d vezes em '%s'; inclua mais linhas de contexto para torná-lo único ou use \"replace_all\": true", i+1, n, typedInput.Path)
+		}
+		if e.ReplaceAll {
+			change.new = strings.ReplaceAll(change.new, e.Search, e.Replace)
+		} else {
+			change.new = strings.Replace(change.new, e.Search, e.Replace, 1)
+		}
+	}
+	return change, nil
+}
+
+// searchHint sugere o motivo de um trecho não ter sido encontrado.
+func searchHint(content, search string) string {
+	normalize := func(s string) string {
+		lines := strings.Split(s, "\n")
+		for i, l := range lines {
+			lines[i] = strings.TrimSpace(l)
+		}
+		return strings.Join(lines, "\n")
+	}
+	if strings.Contains(normalize(content), strings.TrimSpace(normalize(search))) {
+		return " (o trecho existe com indentação ou espaços diferentes; copie-o exatamente como aparece no read_file)"
+	}
+	return " (confira o conteúdo atual com read_file)"
+}
+
+func editFile(input json.RawMessage) (string, error) {
+	change, err := planEdit(input)
+	if err != nil {
+		return "", err
+	}
+	if change.new == change.old {
+		return fmt.Sprintf("Nenhuma alteração: o conteúdo de '%s' já está como pedido.", change.display), nil
+	}
+	if err := writeChanges("edit_file", []fileChange{change}); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Arquivo '%s' editado com sucesso.\n%s", change.display, change.diff()), nil
+}
+
+func previewEditFile(input json.RawMessage) (string, error) {
+	change, err := planEdit(input)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Editaria o arquivo '%s':\n%s", change.display, change.diff()), nil
+}
+
+var EditFileDef = toolkit.ToolDefinition{
+	Name:        "edit_file",
+	Description: `Edita um arquivo trocando trechos exatos, sem reenviar o arquivo inteiro. Cada "search" deve aparecer exatamente uma vez (inclua linhas vizinhas para desambiguar) ou use "replace_all": true. Vários blocos podem ir em "edits" e são aplicados em ordem; se um falhar, nada é alterado. Retorna o diff resultante. Exemplo: {"path": "main.go", "search": "fmt.Println(\"oi\")", "replace": "fmt.Println(\"olá\")"} ou {"path": "main.go", "edits": [{"search": "a", "replace": "b"}]}`,
+	Function:    editFile,
+	Mutating:    true,
+	Preview:     previewEditFile,
+}
+
+// ::: Ferramenta: ApplyPatch :::
+
+// ApplyPatchInput define os parâmetros para a função applyPatch.
+type ApplyPatchInput struct {
+	Patch string `json:"patch"`
+	Path  string `json:"path,omitempty"` // substitui o nome do arquivo em patches de um só arquivo
+}
+
+// planPatch calcula o resultado do patch em todos os arquivos antes de gravar qualquer um.
+func planPatch(input json.RawMessage) ([]fileChange, error) {
+	var typedInput ApplyPatchInput
+	if err := json.Unmarshal(input, &typedInput); err != nil {
+		return nil, fmt.Errorf("JSON inválido para argumentos: %w", err)
+	}
+
+	if strings.TrimSpace(typedInput.Patch) == "" {
+		return nil, fmt.Errorf("argumento inválido. 'patch' é obrigatório")
+	}
+
+	files, err := diff.Parse(typedInput.Patch)
+	if err != nil {
+		return nil, fmt.Errorf("patch inválido: %w", err)
+	}
+	if typedInput.Path != "" && len(files) > 1 {
+		return nil, fmt.Errorf("'path' só pode ser usado com patches de um único arquivo")
+	}
+
+	var changes []fileChange
+	for _, f := range files {
+		path := f.Path()
+		if typedInput.Path != "" {
+			path = typedInput.Path
+		}
+
+		var change fileChange
+		if f.IsNew() {
+			w, err := CurrentWorkspace()
+			if err != nil {
+				return nil, err
+			}
+			abs, err := w.Resolve(path)
+			if err != nil {
+				return nil, err
+			}
+			if _, err := os.Stat(abs); err == nil {
+				return nil, fmt.Errorf("'%s': o patch cria o arquivo, mas ele já existe", path)
+			} else if !errors.Is(err, os.ErrNotExist) {
+				return nil, err
+			}
+			change = fileChange{path: abs, display: w.Display(path, abs), mode: 0644, create: true}
+		} else if change, err = readForEdit(path); err != nil {
+			return nil, err
+		}
+
+		result, reports, err := diff.Apply(change.old, f.Hunks)
+		if err != nil {
+			return nil, fmt.Errorf("'%s': %w (confira o conteúdo atual com read_file)", path, err)
+		}
+		change.new = result
+		for _, r := range reports {
+			if r.Offset != 0 || r.Fuzz != 0 {
+				change.notes = append(change.notes, r.String())
+			}
+		}
+		if f.IsDelete() {
+			if result != "" {
+				return nil, fmt.Errorf("'%s': o patch remove o arquivo, mas sobrariam linhas não removidas", path)
+			}
+			change.remove = true
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+// describeChanges resume as alterações de um patch, com avisos de fuzz e o diff.
+func describeChanges(verb string, changes []fileChange) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s em %d arquivo(s):\n", verb, len(changes))
+	for _, c := range changes {
+		status := "editado"
+		switch {
+		case c.create:
+			status = "criado"
+		case c.remove:
+			status = "removido"
+		}
+		fmt.Fprintf(&b, "- %s (%s)\n", c.display, status)
+		for _, note := range c.notes {
+			fmt.Fprintf(&b, "  aviso: %s\n", note)
+		}
+	}
+	for _, c := range changes {
+		b.WriteString(c.diff())
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+func applyPatch(input json.RawMessage) (string, error) {
+	changes, err := planPatch(input)
+	if err != nil {
+		return "", err
+	}
+	if err := writeChanges("apply_patch", changes); err != nil {
+		return "", err
+	}
+	return describeChanges("Patch aplicado", changes), nil
+}
+
+func previewApplyPatch(input json.RawMessage) (string, error) {
+	changes, err := planPatch(input)
+	if err != nil {
+		return "", err
+	}
+	return describeChanges("Aplicaria o patch", changes), nil
+}
+
+var ApplyPatchDef = toolkit.ToolDefinition{
+	Name:        "apply_patch",
+	Description: `Aplica um diff unificado (formato de "diff -u" / "git diff") a um ou mais arquivos, inclusive criando (--- /dev/null) ou removendo (+++ /dev/null) arquivos. Hunks deslocados ou com pequenas diferenças de contexto são aplicados com fuzz e reportados. Se algum hunk não corresponder, nada é alterado. Retorna o diff resultante. Exemplo: {"patch": "--- a/main.go\n+++ b/main.go\n@@ -1,3 +1,3 @@\n package main\n-// velho\n+// novo\n"}`,
+	Function:    applyPatch,
+	Mutating:    true,
+	Preview:     previewApplyPatch,
+}
@@ -0,0 +1,135 @@
+package builtin
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestEditFile testa as substituições exatas do edit_file.
+func TestEditFile(t *testing.T) {
+	original := "package main\n\nfunc a() {}\n\nfunc b() {}\n\n\tvalor := 1\n"
+	testCases := []struct {
+		name          string
+		input         EditFileInput
+		expected      string
+		expectedError string
+	}{
+		{
+			name:     "Sucesso - Um bloco",
+			input:    EditFileInput{Search: "func a() {}", Replace: "func a() { return }"},
+			expected: "package main\n\nfunc a() { return }\n\nfunc b() {}\n\n\tvalor := 1\n",
+		},
+		{
+			name: "Sucesso - Vários blocos em ordem",
+			input: EditFileInput{Edits: []EditBlock{
+				{Search: "func a() {}", Replace: "func x() {}"},
+				{Search: "func x() {}\n\nfunc b() {}", Replace: "func x() {}"},
+			}},
+			expected: "package main\n\nfunc x() {}\n\n\tvalor := 1\n",
+		},
+		{
+			name:     "Sucesso - replace_all",
+			input:    EditFileInput{Search: "func", Replace: "fn", ReplaceAll: true},
+			expected: "package main\n\nfn a() {}\n\nfn b() {}\n\n\tvalor := 1\n",
+		},
+		{
+			name:          "Erro - Trecho ambíguo",
+			input:         EditFileInput{Search: "func", Replace: "fn"},
+			expectedError: "aparece 2 vezes",
+		},
+		{
+			name:          "Erro - Trecho inexistente",
+			input:         EditFileInput{Search: "func c() {}", Replace: ""},
+			expectedError: "não encontrado",
+		},
+		{
+			name:          "Erro - Indentação diferente",
+			input:         EditFileInput{Search: "    valor := 1", Replace: "valor := 2"},
+			expectedError: "espaços diferentes",
+		},
+		{
+			name: "Erro - Segundo bloco falha e nada é gravado",
+			input: EditFileInput{Edits: []EditBlock{
+				{Search: "func a() {}", Replace: "func x() {}"},
+				{Search: "inexistente", Replace: "y"},
+			}},
+			expectedError: "bloco 2",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(newTestWorkspace(t, nil), "main.go")
+			_ = os.WriteFile(path, []byte(original), 0600)
+
+			tc.input.Path = "main.go"
+			rawInput, _ := json.Marshal(tc.input)
+			result, err := editFile(rawInput)
+
+			if (err != nil) != (tc.expectedError != "") {
+				t.Fatalf("editFile() erro = %v, esperado erro %q", err, tc.expectedError)
+			}
+			data, _ := os.ReadFile(path)
+			if err != nil {
+				if !strings.Contains(err.Error(), tc.expectedError) {
+					t.Errorf("editFile() erro = %v, esperado conter %q", err, tc.expectedError)
+				}
+				if string(data) != original {
+					t.Error("editFile() alterou o arquivo apesar do erro")
+				}
+				return
+			}
+
+			if string(data) != tc.expected {
+				t.Errorf("conteúdo = %q, esperado %q", data, tc.expected)
+			}
+			if !strings.Contains(result, "@@") {
+				t.Errorf("editFile() deveria retornar o diff: %s", result)
+			}
+			if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+				t.Errorf("editFile() não manteve as permissões: %v", info.Mode().Perm())
+			}
+		})
+	}
+}
+
+// TestApplyPatch testa a aplicação de diffs com vários arquivos.
+func TestApplyPatch(t *testing.T) {
+	dir := newTestWorkspace(t, map[string]string{"a.txt": "um\ndois\ntrês\n", "velho.txt": "tchau\n"})
+
+	patch := "--- a/a.txt\n+++ b/a.txt\n@@ -1,2 +1,2 @@\n dois\n-três\n+3\n" +
+		"--- /dev/null\n+++ b/sub/novo.txt\n@@ -0,0 +1 @@\n+oi\n" +
+		"--- a/velho.txt\n+++ /dev/null\n@@ -1 +0,0 @@\n-tchau\n"
+
+	rawInput, _ := json.Marshal(ApplyPatchInput{Patch: patch})
+	result, err := applyPatch(rawInput)
+	if err != nil {
+		t.Fatalf("applyPatch() erro inesperado: %v", err)
+	}
+	if !strings.Contains(result, "3 arquivo(s)") || !strings.Contains(result, "deslocamento") {
+		t.Errorf("applyPatch() resultado inesperado:\n%s", result)
+	}
+
+	if data, _ := os.ReadFile(filepath.Join(dir, "a.txt")); string(data) != "um\ndois\n3\n" {
+		t.Errorf("a.txt = %q", data)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "sub", "novo.txt")); string(data) != "oi\n" {
+		t.Errorf("sub/novo.txt = %q", data)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "velho.txt")); !os.IsNotExist(err) {
+		t.Error("velho.txt deveria ter sido removido")
+	}
+
+	// Um hunk que não corresponde não altera nenhum arquivo.
+	bad := "--- a/a.txt\n+++ b/a.txt\n@@ -1 +1 @@\n-um\n+1\n--- a/a.txt\n+++ b/a.txt\n@@ -1 +1 @@\n-inexistente\n+x\n"
+	rawInput, _ = json.Marshal(ApplyPatchInput{Patch: bad})
+	if _, err := applyPatch(rawInput); err == nil {
+		t.Fatal("applyPatch() deveria falhar com hunk que não corresponde")
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "a.txt")); string(data) != "um\ndois\n3\n" {
+		t.Errorf("a.txt foi alterado apesar do erro: %q", data)
+	}
+}
@@ -0,0 +1,303 @@
+package builtin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/matheusbuniotto/goagent/pkg/toolkit"
+)
+
+const (
+	defaultFetchTimeout  = 30 * time.Second
+	maxFetchTimeout      = 120 * time.Second
+	defaultFetchMaxBytes = 50_000   // texto devolvido ao modelo
+	maxFetchDownload     = 10 << 20 // corpo lido da resposta
+	maxFetchRedirects    = 5
+	fetchUserAgent       = "goagent/1.0 (+fetch_url)"
+)
+
+// DefaultAllowedDomains são os domínios consultados sem pedir aprovação. Um domínio
+// libera também os subdomínios: "go.dev" libera "pkg.go.dev".
+var DefaultAllowedDomains = []string{
+	"go.dev", "golang.org", "github.com", "githubusercontent.com",
+	"developer.mozilla.org", "wikipedia.org", "stackoverflow.com",
+}
+
+// FetchPolicy define os domínios que o fetch_url acessa sem aprovação. Requisições
+// para outros domínios, ou com métodos que alteram dados, passam pela aprovação.
+type FetchPolicy struct {
+	Allow []string
+}
+
+// NewFetchPolicy cria uma política com os domínios padrão acrescidos dos extras.
+func NewFetchPolicy(allow []string) *FetchPolicy {
+	return &FetchPolicy{Allow: append(append([]string{}, DefaultAllowedDomains...), allow...)}
+}
+
+// Allowed informa se o host (sem porta) está na lista de domínios permitidos.
+func (p *FetchPolicy) Allowed(host string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	for _, domain := range p.Allow {
+		domain = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(domain)), "*.")
+		if domain == "*" || host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	fetchPolicyMu sync.RWMutex
+	fetchPolicy   *FetchPolicy
+)
+
+// SetFetchPolicy define a política usada pelo fetch_url.
+func SetFetchPolicy(p *FetchPolicy) {
+	fetchPolicyMu.Lock()
+	defer fetchPolicyMu.Unlock()
+	fetchPolicy = p
+}
+
+// CurrentFetchPolicy retorna a política em uso. Se nenhuma foi definida, usa os domínios padrão.
+func CurrentFetchPolicy() *FetchPolicy {
+	fetchPolicyMu.RLock()
+	defer fetchPolicyMu.RUnlock()
+	if fetchPolicy == nil {
+		return NewFetchPolicy(nil)
+	}
+	return fetchPolicy
+}
+
+// FetchURLInput são os argumentos da ferramenta fetch_url.
+type FetchURLInput struct {
+	URL      string            `json:"url"`
+	Method   string            `json:"method,omitempty"`
+	Headers  map[string]string `json:"headers,omitempty"`
+	Body     string            `json:"body,omitempty"`
+	Format   string            `json:"format,omitempty"` // markdown (padrão), text ou raw
+	MaxBytes int               `json:"max_bytes,omitempty"`
+	Timeout  int               `json:"timeout,omitempty"` // Segundos
+}
+
+// parseFetchInput valida os argumentos e retorna a URL já interpretada.
+func parseFetchInput(input json.RawMessage) (FetchURLInput, *url.URL, error) {
+	var typedInput FetchURLInput
+	if err := json.Unmarshal(input, &typedInput); err != nil {
+		return typedInput, nil, fmt.Errorf("JSON inválido para argumentos: %w", err)
+	}
+	if strings.TrimSpace(typedInput.URL) == "" {
+		return typedInput, nil, errors.New("argumento inválido. 'url' é obrigatório")
+	}
+	u, err := url.Parse(strings.TrimSpace(typedInput.URL))
+	if err != nil {
+		return typedInput, nil, fmt.Errorf("URL inválida: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return typedInput, nil, fmt.Errorf("URL inválida '%s': só http e https são suportados", typedInput.URL)
+	}
+	if u.Hostname() == "" {
+		return typedInput, nil, fmt.Errorf("URL inválida '%s': host ausente", typedInput.URL)
+	}
+
+	typedInput.Method = strings.ToUpper(strings.TrimSpace(typedInput.Method))
+	if typedInput.Method == "" {
+		typedInput.Method = http.MethodGet
+	}
+	switch typedInput.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+	default:
+		return typedInput, nil, fmt.Errorf("método inválido '%s'", typedInput.Method)
+	}
+	switch typedInput.Format {
+	case "":
+		typedInput.Format = "markdown"
+	case "markdown", "text", "raw":
+	default:
+		return typedInput, nil, fmt.Errorf("formato inválido '%s'. Use markdown, text ou raw", typedInput.Format)
+	}
+	return typedInput, u, nil
+}
+
+// safeMethod informa se o método só consulta dados.
+func safeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+// fetchURLMutating decide se a chamada passa pela política de aprovação: só consultas
+// a domínios permitidos rodam direto.
+func fetchURLMutating(input json.RawMessage) bool {
+	typedInput, u, err := parseFetchInput(input)
+	if err != nil {
+		return false // A chamada falha de qualquer forma; não há o que aprovar.
+	}
+	return !safeMethod(typedInput.Method) || !CurrentFetchPolicy().Allowed(u.Hostname())
+}
+
+func fetchURL(ctx context.Context, input json.RawMessage) (string, error) {
+	typedInput, u, err := parseFetchInput(input)
+	if err != nil {
+		return "", err
+	}
+
+	timeout := defaultFetchTimeout
+	if typedInput.Timeout > 0 {
+		timeout = min(time.Duration(typedInput.Timeout)*time.Second, maxFetchTimeout)
+	}
+	reqCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var body io.Reader
+	if typedInput.Body != "" {
+		body = strings.NewReader(typedInput.Body)
+	}
+	req, err := http.NewRequestWithContext(reqCtx, typedInput.Method, u.String(), body)
+	if err != nil {
+		return "", fmt.Errorf("erro ao montar a requisição: %w", err)
+	}
+	req.Header.Set("User-Agent", fetchUserAgent)
+	req.Header.Set("Accept", "text/html,text/markdown,text/plain,application/json;q=0.9,*/*;q=0.8")
+	for name, value := range typedInput.Headers {
+		req.Header.Set(name, value)
+	}
+
+	client := &http.Client{
+		// Um redirecionamento não pode levar a um domínio que não foi aprovado.
+		CheckRedirect: func(next *http.Request, via []*http.Request) error {
+			if len(via) >= maxFetchRedirects {
+				return fmt.Errorf("mais de %d redirecionamentos", maxFetchRedirects)
+			}
+			host := next.URL.Hostname()
+			if host != u.Hostname() && !CurrentFetchPolicy().Allowed(host) {
+				return fmt.Errorf("redirecionamento para domínio fora da lista de permitidos: %s", host)
+			}
+			return nil
+		},
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		switch {
+		case ctx.Err() != nil:
+			return "", ctx.Err()
+		case reqCtx.Err() == context.DeadlineExceeded:
+			return "", fmt.Errorf("tempo limite de %s excedido ao acessar %s", timeout, u)
+		}
+		return "", fmt.Errorf("erro ao acessar %s: %w", u, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxFetchDownload+1))
+	if err != nil {
+		switch {
+		case ctx.Err() != nil:
+			return "", ctx.Err()
+		case reqCtx.Err() == context.DeadlineExceeded:
+			return "", fmt.Errorf("tempo limite de %s excedido ao ler a resposta de %s", timeout, u)
+		}
+		return "", fmt.Errorf("erro ao ler a resposta de %s: %w", u, err)
+	}
+	downloadTruncated := len(data) > maxFetchDownload
+	if downloadTruncated {
+		data = data[:maxFetchDownload]
+	}
+	elapsed := time.Since(start).Round(10 * time.Millisecond)
+
+	contentType := resp.Header.Get("Content-Type")
+	mediaType, params, _ := mime.ParseMediaType(contentType)
+	if mediaType == "" {
+		mediaType = http.DetectContentType(data)
+		mediaType, params, _ = mime.ParseMediaType(mediaType)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s\n[status %s em %s]\n", typedInput.Method, resp.Request.URL, resp.Status, elapsed)
+	if contentType != "" {
+		fmt.Fprintf(&b, "Content-Type: %s\n", contentType)
+	}
+
+	text, title, isText := extractText(data, mediaType, params["charset"], resp.Request.URL, typedInput.Format)
+	if title != "" {
+		fmt.Fprintf(&b, "Título: %s\n", title)
+	}
+	switch {
+	case !isText:
+		fmt.Fprintf(&b, "\n(conteúdo binário de %d bytes não exibido)", len(data))
+		return b.String(), nil
+	case strings.TrimSpace(text) == "":
+		b.WriteString("\n(sem conteúdo)")
+		return b.String(), nil
+	}
+
+	maxBytes := typedInput.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = defaultFetchMaxBytes
+	}
+	total := len(text)
+	if total > maxBytes {
+		text = truncateUTF8(text, maxBytes)
+		fmt.Fprintf(&b, "[texto truncado: exibindo %d de %d bytes; aumente 'max_bytes' para ver mais]\n", len(text), total)
+	} else if downloadTruncated {
+		fmt.Fprintf(&b, "[resposta truncada em %d bytes]\n", maxFetchDownload)
+	}
+	b.WriteString("\n")
+	b.WriteString(text)
+	return b.String(), nil
+}
+
+// extractText converte o corpo conforme o tipo de conteúdo. HTML vira markdown ou texto
+// (exceto no formato raw); outros tipos textuais são devolvidos como estão.
+func extractText(data []byte, mediaType, charset string, base *url.URL, format string) (text, title string, isText bool) {
+	isHTML := mediaType == "text/html" || mediaType == "application/xhtml+xml"
+	textual := strings.HasPrefix(mediaType, "text/") || strings.HasSuffix(mediaType, "json") ||
+		strings.HasSuffix(mediaType, "xml") || mediaType == "application/javascript" || mediaType == "application/x-yaml"
+	if !textual && (mediaType != "" || !utf8.Valid(data)) {
+		return "", "", false
+	}
+
+	switch {
+	case strings.EqualFold(charset, "iso-8859-1") || strings.EqualFold(charset, "latin1") || strings.EqualFold(charset, "windows-1252"):
+		decoded, _ := io.ReadAll(&latin1Reader{r: bytes.NewReader(data)})
+		text = string(decoded)
+	default:
+		text = strings.ToValidUTF8(string(data), "�")
+	}
+
+	if isHTML && format != "raw" {
+		page := htmlToText(text, base, format == "markdown")
+		return page.text, page.title, true
+	}
+	return text, "", true
+}
+
+func previewFetchURL(input json.RawMessage) (string, error) {
+	typedInput, u, err := parseFetchInput(input)
+	if err != nil {
+		return "", err
+	}
+	preview := fmt.Sprintf("Faria %s para %s", typedInput.Method, u)
+	if typedInput.Body != "" {
+		preview += fmt.Sprintf(" com corpo de %d bytes", len(typedInput.Body))
+	}
+	return preview, nil
+}
+
+// FetchURLDef é a definição da ferramenta fetch_url.
+var FetchURLDef = toolkit.ToolDefinition{
+	Name:            "fetch_url",
+	Description:     `Faz uma requisição HTTP e retorna o status, o Content-Type e o conteúdo. Páginas HTML viram markdown legível (sem scripts, estilos, menus e rodapés; se houver <main> ou <article>, só ele). Consultas (GET, HEAD) a domínios da lista de permitidos (ex: go.dev, github.com) rodam direto; outros domínios e métodos como POST pedem aprovação. Opcionais: "method", "headers" (objeto), "body", "format" (markdown, text ou raw), "max_bytes" (texto retornado, padrão 50000), "timeout" (segundos, padrão 30, máximo 120). Exemplo: {"url": "https://go.dev/doc/effective_go"}`,
+	ContextFunction: fetchURL,
+	MutatingFunc:    fetchURLMutating,
+	Preview:         previewFetchURL,
+}
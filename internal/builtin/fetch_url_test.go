@@ -0,0 +1,225 @@
+package builtin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// allowTestServer libera o host dos servidores de teste (127.0.0.1) durante o teste.
+func allowTestServer(t *testing.T) {
+	t.Helper()
+	SetFetchPolicy(NewFetchPolicy([]string{"127.0.0.1"}))
+	t.Cleanup(func() { SetFetchPolicy(nil) })
+}
+
+func newFetchServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/docs", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, `<!DOCTYPE html><html><head><title>Docs</title><script>var x = 1;</script></head>
+<body><nav><a href="/">Início</a></nav><main><h1>Instalação</h1><p>Rode <code>go install</code>, veja <a href="/ref">a referência</a>.</p></main><footer>rodapé</footer></body></html>`)
+	})
+	mux.HandleFunc("/eco", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "text/plain")
+		fmt.Fprintf(w, "%s %s %s", r.Method, r.Header.Get("X-Token"), body)
+	})
+	mux.HandleFunc("/json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"versao": "1.2.3"}`)
+	})
+	mux.HandleFunc("/grande", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		fmt.Fprint(w, strings.Repeat("á", 100))
+	})
+	mux.HandleFunc("/latin1", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=ISO-8859-1")
+		w.Write([]byte("ol\xe1"))
+	})
+	mux.HandleFunc("/imagem", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte("\x89PNG\r\n\x1a\n\x00\x00"))
+	})
+	mux.HandleFunc("/ausente", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "página não encontrada", http.StatusNotFound)
+	})
+	mux.HandleFunc("/lento", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	})
+	mux.HandleFunc("/mover", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/docs", http.StatusFound)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// TestFetchURL testa requisições, conversão de conteúdo e limites.
+func TestFetchURL(t *testing.T) {
+	allowTestServer(t)
+	server := newFetchServer(t)
+
+	testCases := []struct {
+		name          string
+		input         FetchURLInput
+		contains      []string
+		notContains   []string
+		expectedError string
+	}{
+		{
+			name:        "HTML vira markdown",
+			input:       FetchURLInput{URL: server.URL + "/docs"},
+			contains:    []string{"GET " + server.URL + "/docs\n[status 200 OK em ", "Content-Type: text/html; charset=utf-8", "Título: Docs", "# Instalação\n\nRode `go install`, veja [a referência](" + server.URL + "/ref)."},
+			notContains: []string{"var x", "Início", "rodapé"},
+		},
+		{
+			name:     "HTML como texto",
+			input:    FetchURLInput{URL: server.URL + "/docs", Format: "text"},
+			contains: []string{"Instalação\n\nRode go install, veja a referência."},
+		},
+		{
+			name:     "HTML bruto",
+			input:    FetchURLInput{URL: server.URL + "/docs", Format: "raw"},
+			contains: []string{"<script>var x = 1;</script>"},
+		},
+		{
+			name:     "POST com cabeçalhos e corpo",
+			input:    FetchURLInput{URL: server.URL + "/eco", Method: "post", Headers: map[string]string{"X-Token": "abc"}, Body: `{"a":1}`},
+			contains: []string{"POST " + server.URL + "/eco", `POST abc {"a":1}`},
+		},
+		{
+			name:     "JSON é devolvido como está",
+			input:    FetchURLInput{URL: server.URL + "/json"},
+			contains: []string{"Content-Type: application/json", `{"versao": "1.2.3"}`},
+		},
+		{
+			name:     "Texto truncado sem quebrar caracteres",
+			input:    FetchURLInput{URL: server.URL + "/grande", MaxBytes: 11},
+			contains: []string{"[texto truncado: exibindo 10 de 200 bytes", "\n\náááá"},
+		},
+		{
+			name:     "Converte ISO-8859-1",
+			input:    FetchURLInput{URL: server.URL + "/latin1"},
+			contains: []string{"\n\nolá"},
+		},
+		{
+			name:     "Conteúdo binário",
+			input:    FetchURLInput{URL: server.URL + "/imagem"},
+			contains: []string{"(conteúdo binário de 10 bytes não exibido)"},
+		},
+		{
+			name:     "Status de erro ainda retorna o conteúdo",
+			input:    FetchURLInput{URL: server.URL + "/ausente"},
+			contains: []string{"[status 404 Not Found", "página não encontrada"},
+		},
+		{
+			name:     "Segue redirecionamento no mesmo host",
+			input:    FetchURLInput{URL: server.URL + "/mover"},
+			contains: []string{"GET " + server.URL + "/docs\n", "# Instalação"},
+		},
+		{
+			name:          "Tempo limite",
+			input:         FetchURLInput{URL: server.URL + "/lento", Timeout: 1},
+			expectedError: "tempo limite de 1s excedido",
+		},
+		{
+			name:          "Sem URL",
+			input:         FetchURLInput{},
+			expectedError: "'url' é obrigatório",
+		},
+		{
+			name:          "Esquema não suportado",
+			input:         FetchURLInput{URL: "file:///etc/passwd"},
+			expectedError: "só http e https",
+		},
+		{
+			name:          "Método inválido",
+			input:         FetchURLInput{URL: server.URL, Method: "TRACE"},
+			expectedError: "método inválido 'TRACE'",
+		},
+		{
+			name:          "Formato inválido",
+			input:         FetchURLInput{URL: server.URL, Format: "pdf"},
+			expectedError: "formato inválido 'pdf'",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rawInput, _ := json.Marshal(tc.input)
+			result, err := fetchURL(context.Background(), rawInput)
+			if tc.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedError) {
+					t.Fatalf("fetchURL() erro = %v, esperado conter %q", err, tc.expectedError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("fetchURL() erro inesperado: %v", err)
+			}
+			for _, s := range tc.contains {
+				if !strings.Contains(result, s) {
+					t.Errorf("fetchURL() =\n%s\nesperado conter %q", result, s)
+				}
+			}
+			for _, s := range tc.notContains {
+				if strings.Contains(result, s) {
+					t.Errorf("fetchURL() =\n%s\nnão deveria conter %q", result, s)
+				}
+			}
+		})
+	}
+}
+
+// TestFetchURLRedirectOutsideAllowlist testa que um redirecionamento não escapa da lista de domínios.
+func TestFetchURLRedirectOutsideAllowlist(t *testing.T) {
+	allowTestServer(t)
+	target := newFetchServer(t)
+	// "localhost" aponta para o mesmo servidor, mas não está na lista de permitidos.
+	outside := strings.Replace(target.URL, "127.0.0.1", "localhost", 1) + "/docs"
+	redirect := httptest.NewServer(http.RedirectHandler(outside, http.StatusFound))
+	defer redirect.Close()
+
+	rawInput, _ := json.Marshal(FetchURLInput{URL: redirect.URL})
+	_, err := fetchURL(context.Background(), rawInput)
+	if err == nil || !strings.Contains(err.Error(), "fora da lista de permitidos: localhost") {
+		t.Errorf("fetchURL() erro = %v, esperado bloqueio do redirecionamento", err)
+	}
+}
+
+// TestFetchURLMutating testa quais chamadas passam pela política de aprovação.
+func TestFetchURLMutating(t *testing.T) {
+	SetFetchPolicy(NewFetchPolicy([]string{"api.interna.com"}))
+	defer SetFetchPolicy(nil)
+
+	testCases := []struct {
+		input    FetchURLInput
+		expected bool
+	}{
+		{FetchURLInput{URL: "https://go.dev/doc"}, false},
+		{FetchURLInput{URL: "https://pkg.go.dev/fmt"}, false},
+		{FetchURLInput{URL: "https://api.interna.com:8443/v1", Method: "HEAD"}, false},
+		{FetchURLInput{URL: "https://notgo.dev/"}, true},
+		{FetchURLInput{URL: "https://exemplo.com/"}, true},
+		{FetchURLInput{URL: "https://go.dev/doc", Method: "POST"}, true},
+		{FetchURLInput{URL: "https://github.com/x", Method: "DELETE"}, true},
+		{FetchURLInput{URL: "ftp://go.dev/"}, false},
+	}
+	for _, tc := range testCases {
+		rawInput, _ := json.Marshal(tc.input)
+		if got := fetchURLMutating(rawInput); got != tc.expected {
+			t.Errorf("fetchURLMutating(%+v) = %v, esperado %v", tc.input, got, tc.expected)
+		}
+	}
+}
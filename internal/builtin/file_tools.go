@@ -4,60 +4,21 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-	"path/filepath"
 
 	"github.com/matheusbuniotto/goagent/pkg/toolkit"
 )
 
-// ::: Ferramenta: ListFiles :::
-
-// ListFilesInput define os parâmetros para a função ListFiles.
-type ListFilesInput struct {
-	Path string `json:"path,omitempty"`
-}
-
-// listFiles é a função lógica que varre um diretório.
-func listFiles(input json.RawMessage) (string, error) {
-	var typedInput ListFilesInput
-	if len(input) > 0 && string(input) != "null" {
-		if err := json.Unmarshal(input, &typedInput); err != nil {
-			return "", fmt.Errorf("JSON inválido para argumentos: %w", err)
-		}
-	}
-
-	dir := "." // Valor padrão: diretório atual
-	if typedInput.Path != "" {
-		dir = typedInput.Path
-	}
-
-	var files []string
-	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-		if path != dir {
-			files = append(files, path)
-		}
-		return nil
-	})
-
+// resolvePath valida o caminho no workspace e retorna sua forma absoluta. Caminhos
+// para escrita não podem estar nos diretórios liberados só para leitura.
+func resolvePath(path string, write bool) (string, error) {
+	w, err := CurrentWorkspace()
 	if err != nil {
 		return "", err
 	}
-
-	result, err := json.Marshal(files)
-	if err != nil {
-		return "", err
+	if write {
+		return w.Resolve(path)
 	}
-
-	return string(result), nil
-}
-
-// ListFilesDef é a definição pública da nossa ferramenta.
-var ListFilesDef = toolkit.ToolDefinition{
-	Name:        "list_files",
-	Description: "Lista arquivos e diretórios em um caminho específico. Se nenhum caminho for fornecido, lista o conteúdo do diretório atual.",
-	Function:    listFiles,
+	return w.ResolveRead(path)
 }
 
 // ::: Ferramenta: WriteFile :::
@@ -77,27 +38,64 @@ func writeFile(input json.RawMessage) (string, error) {
 		return "", fmt.Errorf("argumentos inválidos. 'path' e 'content' são obrigatórios")
 	}
 
-	err := os.WriteFile(typedInput.Path, []byte(typedInput.Content), 0644)
+	path, err := resolvePath(typedInput.Path, true)
+	if err != nil {
+		return "", err
+	}
+
+	if err := recordCheckpoint("write_file", path); err != nil {
+		return "", err
+	}
+
+	err = os.WriteFile(path, []byte(typedInput.Content), 0644)
 	if err != nil {
 		return "", fmt.Errorf("erro ao escrever no arquivo '%s': %w", typedInput.Path, err)
 	}
 	return fmt.Sprintf("Arquivo '%s' escrito com sucesso.", typedInput.Path), nil
 }
 
+// previewWriteFile descreve o que writeFile faria, sem escrever nada.
+func previewWriteFile(input json.RawMessage) (string, error) {
+	var typedInput WriteFileInput
+	if err := json.Unmarshal(input, &typedInput); err != nil {
+		return "", fmt.Errorf("JSON inválido para argumentos: %w", err)
+	}
+
+	if typedInput.Path == "" {
+		return "", fmt.Errorf("argumentos inválidos. 'path' e 'content' são obrigatórios")
+	}
+
+	path, err := resolvePath(typedInput.Path, true)
+	if err != nil {
+		return "", err
+	}
+
+	info, err := os.Stat(path)
+	switch {
+	case err == nil && info.IsDir():
+		return "", fmt.Errorf("erro ao escrever no arquivo '%s': é um diretório", typedInput.Path)
+	case err == nil:
+		return fmt.Sprintf("Sobrescreveria o arquivo '%s' (%d → %d bytes)", typedInput.Path, info.Size(), len(typedInput.Content)), nil
+	default:
+		return fmt.Sprintf("Criaria o arquivo '%s' (%d bytes)", typedInput.Path, len(typedInput.Content)), nil
+	}
+}
+
 var WriteFileDef = toolkit.ToolDefinition{
 	Name:        "write_file",
 	Description: `Escreve o conteúdo fornecido em um arquivo. Requer um objeto JSON com as chaves "path" e "content". Exemplo: {"path": "caminho/arquivo.txt", "content": "Olá, mundo!"}`,
 	Function:    writeFile,
+	Mutating:    true,
+	Preview:     previewWriteFile,
 }
 
-// :::: Ferramenta: ReadFile :::
-
-type ReadFileInput struct {
+// ::: Ferramenta: CreateDirectory :::
+type CreateDirectoryInput struct {
 	Path string `json:"path"`
 }
 
-func readFile(input json.RawMessage) (string, error) {
-	var typedInput ReadFileInput
+func createDirectory(input json.RawMessage) (string, error) {
+	var typedInput CreateDirectoryInput
 	if err := json.Unmarshal(input, &typedInput); err != nil {
 		return "", fmt.Errorf("JSON inválido para argumentos: %w", err)
 	}
@@ -106,25 +104,28 @@ func readFile(input json.RawMessage) (string, error) {
 		return "", fmt.Errorf("argumento inválido. 'path' é obrigatório")
 	}
 
-	content, err := os.ReadFile(typedInput.Path)
+	path, err := resolvePath(typedInput.Path, true)
 	if err != nil {
-		return "", fmt.Errorf("erro ao ler o arquivo '%s': %w", typedInput.Path, err)
+		return "", err
 	}
-	return string(content), nil
-}
 
-var ReadFileDef = toolkit.ToolDefinition{
-	Name:        "read_file",
-	Description: `Lê o conteúdo de um arquivo. Requer um objeto JSON com a chave "path". Exemplo: {"path": "caminho/arquivo.txt"}`,
-	Function:    readFile,
-}
+	if missing := firstMissing(path); missing != "" {
+		if err := recordCheckpoint("create_directory", missing); err != nil {
+			return "", err
+		}
+	}
 
-// ::: Ferramenta: CreateDirectory :::
-type CreateDirectoryInput struct {
-	Path string `json:"path"`
+	// 0755 são as permissões = leitura/execução para todos, escrita para o dono
+	err = os.MkdirAll(path, 0755)
+	if err != nil {
+		return "", fmt.Errorf("erro ao criar o diretório '%s': %w", typedInput.Path, err)
+	}
+
+	return fmt.Sprintf("Diretório '%s' criado com sucesso.", typedInput.Path), nil
 }
 
-func createDirectory(input json.RawMessage) (string, error) {
+// previewCreateDirectory descreve o que createDirectory faria, sem criar nada.
+func previewCreateDirectory(input json.RawMessage) (string, error) {
 	var typedInput CreateDirectoryInput
 	if err := json.Unmarshal(input, &typedInput); err != nil {
 		return "", fmt.Errorf("JSON inválido para argumentos: %w", err)
@@ -134,17 +135,24 @@ func createDirectory(input json.RawMessage) (string, error) {
 		return "", fmt.Errorf("argumento inválido. 'path' é obrigatório")
 	}
 
-	// 0755 são as permissões = leitura/execução para todos, escrita para o dono
-	err := os.MkdirAll(typedInput.Path, 0755)
+	path, err := resolvePath(typedInput.Path, true)
 	if err != nil {
-		return "", fmt.Errorf("erro ao criar o diretório '%s': %w", typedInput.Path, err)
+		return "", err
 	}
 
-	return fmt.Sprintf("Diretório '%s' criado com sucesso.", typedInput.Path), nil
+	if info, err := os.Stat(path); err == nil {
+		if !info.IsDir() {
+			return "", fmt.Errorf("erro ao criar o diretório '%s': já existe um arquivo com esse nome", typedInput.Path)
+		}
+		return fmt.Sprintf("Nada a fazer: o diretório '%s' já existe", typedInput.Path), nil
+	}
+	return fmt.Sprintf("Criaria o diretório '%s'", typedInput.Path), nil
 }
 
 var CreateDirectoryDef = toolkit.ToolDefinition{
 	Name:        "create_directory",
 	Description: `Cria um novo diretório no caminho especificado, necessita de um nome. Exemplo: {"path": "meu/novo/nome_diretorio"}`,
 	Function:    createDirectory,
+	Mutating:    true,
+	Preview:     previewCreateDirectory,
 }
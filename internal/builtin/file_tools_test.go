@@ -15,22 +15,19 @@ import (
 func TestCreateDirectory(t *testing.T) {
 	// Define casos de teste em uma tabela
 	testCases := []struct {
-		name         string // Nome do caso de teste.
-		inputPath    string // O caminho
-		expectErr    bool   // Indica se esperamos um erro
-		setupTempDir bool   // Indica se op teste precisa de um temp dir
+		name      string // Nome do caso de teste.
+		inputPath string // O caminho, relativo ao workspace
+		expectErr bool   // Indica se esperamos um erro
 	}{
 		{
-			name:         "Sucesso - Cria um novo diretório",
-			inputPath:    "meu-diretorio-de-teste",
-			expectErr:    false,
-			setupTempDir: true,
+			name:      "Sucesso - Cria um novo diretório",
+			inputPath: "meu-diretorio-de-teste",
+			expectErr: false,
 		},
 		{
-			name:         "Erro - Caminho vazio",
-			inputPath:    "",
-			expectErr:    true,
-			setupTempDir: false,
+			name:      "Erro - Caminho vazio",
+			inputPath: "",
+			expectErr: true,
 		},
 	}
 
@@ -38,15 +35,11 @@ func TestCreateDirectory(t *testing.T) {
 	for _, tc := range testCases {
 		// t.Run nos permite agrupar a lógica de cada teste e dar um nome a ele.
 		t.Run(tc.name, func(t *testing.T) {
-			// Prepara o caminho completo do diretório, se necessário.
-			fullPath := tc.inputPath
-			if tc.setupTempDir {
-				tempDir := t.TempDir()
-				fullPath = filepath.Join(tempDir, tc.inputPath)
-			}
+			// Cada caso usa um workspace próprio.
+			root := newTestWorkspace(t, nil)
 
 			// Monta o input JSON para a nossa função.
-			inputData := CreateDirectoryInput{Path: fullPath}
+			inputData := CreateDirectoryInput{Path: tc.inputPath}
 			rawInput, _ := json.Marshal(inputData)
 
 			// Executa a função que estamos testando.
@@ -60,8 +53,8 @@ func TestCreateDirectory(t *testing.T) {
 			// Se não esperávamos um erro, fazemos uma verificação extra:
 			// o diretório realmente existe no sistema de arquivos?
 			if !tc.expectErr {
-				if _, err := os.Stat(fullPath); os.IsNotExist(err) {
-					t.Errorf("createDirectory() não criou o diretório esperado em %s", fullPath)
+				if _, err := os.Stat(filepath.Join(root, tc.inputPath)); os.IsNotExist(err) {
+					t.Errorf("createDirectory() não criou o diretório esperado em %s", tc.inputPath)
 				}
 			}
 		})
@@ -70,27 +63,22 @@ func TestCreateDirectory(t *testing.T) {
 
 // TestListFiles
 func TestListFiles(t *testing.T) {
-	// Setup: Criar uma estrutura de diretórios e arquivos conhecida.
-	tempDir := t.TempDir()
-
-	// Cria uma sub pasta
-	subDir := filepath.Join(tempDir, "subdir")
-	_ = os.Mkdir(subDir, 0755)
+	// Setup: Criar um workspace com uma estrutura de diretórios e arquivos conhecida.
+	newTestWorkspace(t, map[string]string{
+		"testando_som.txt":           "a",
+		"subdir/testando_som_12.txt": "b",
+	})
 
-	// Cria alguns arquivos.
-	_ = os.WriteFile(filepath.Join(tempDir, "testando_som.txt"), []byte("a"), 0644)
-	_ = os.WriteFile(filepath.Join(subDir, "testando_som_12.txt"), []byte("b"), 0644)
-
-	// Os caminhos que esperamos que sejam listados.
+	// Os caminhos que esperamos que sejam listados, relativos ao workspace.
 	// filepath.Join para garantir que funcione em qualquer so
 	expectedFiles := []string{
-		filepath.Join(tempDir, "testando_som.txt"),
-		filepath.Join(tempDir, "subdir"),
-		filepath.Join(tempDir, "subdir", "testando_som_12.txt"),
+		"testando_som.txt",
+		"subdir",
+		filepath.Join("subdir", "testando_som_12.txt"),
 	}
 
 	// Execução: Chamar a função a ser testada.
-	inputData := ListFilesInput{Path: tempDir}
+	inputData := ListFilesInput{Path: "."}
 	rawInput, _ := json.Marshal(inputData)
 
 	resultJSON, err := listFiles(rawInput)
@@ -152,15 +140,12 @@ func TestReadFile(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			tempDir := t.TempDir()
-			var fullPath string
+			root := newTestWorkspace(t, nil)
 
 			if tc.fileName != "" {
-				fullPath = filepath.Join(tempDir, tc.fileName)
-				
 				// Cria o arquivo se não for teste de arquivo inexistente
 				if !tc.expectErr || tc.expectedError != "no such file or directory" {
-					err := os.WriteFile(fullPath, []byte(tc.fileContent), 0644)
+					err := os.WriteFile(filepath.Join(root, tc.fileName), []byte(tc.fileContent), 0644)
 					if err != nil {
 						t.Fatalf("Falha ao criar arquivo de teste: %v", err)
 					}
@@ -168,7 +153,7 @@ func TestReadFile(t *testing.T) {
 			}
 
 			// Prepara input JSON
-			inputData := ReadFileInput{Path: fullPath}
+			inputData := ReadFileInput{Path: tc.fileName}
 			rawInput, _ := json.Marshal(inputData)
 
 			// Executa a função
@@ -233,12 +218,10 @@ func TestWriteFile(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			tempDir := t.TempDir()
-			var fullPath string
+			root := newTestWorkspace(t, nil)
+			fullPath := filepath.Join(root, tc.fileName)
 
 			if tc.fileName != "" {
-				fullPath = filepath.Join(tempDir, tc.fileName)
-				
 				// Se for teste de sobrescrita, cria arquivo existente
 				if tc.name == "Sucesso - Sobrescreve arquivo existente" {
 					err := os.WriteFile(fullPath, []byte("conteúdo antigo"), 0644)
@@ -249,7 +232,7 @@ func TestWriteFile(t *testing.T) {
 			}
 
 			// Prepara input JSON
-			inputData := WriteFileInput{Path: fullPath, Content: tc.content}
+			inputData := WriteFileInput{Path: tc.fileName, Content: tc.content}
 			rawInput, _ := json.Marshal(inputData)
 
 			// Executa a função
@@ -270,7 +253,7 @@ func TestWriteFile(t *testing.T) {
 			// Verifica se foi criado com sucesso
 			if !tc.expectErr {
 				// Verifica se o resultado da função está correto
-				expectedResult := fmt.Sprintf("Arquivo '%s' escrito com sucesso.", fullPath)
+				expectedResult := fmt.Sprintf("Arquivo '%s' escrito com sucesso.", tc.fileName)
 				if result != expectedResult {
 					t.Errorf("writeFile() resultado = %q, esperado %q", result, expectedResult)
 				}
@@ -291,6 +274,7 @@ func TestWriteFile(t *testing.T) {
 
 // TestListFilesEdgeCases - Testa casos extremos do listFiles
 func TestListFilesEdgeCases(t *testing.T) {
+	newTestWorkspace(t, nil)
 	testCases := []struct {
 		name          string
 		inputPath     string
@@ -300,14 +284,20 @@ func TestListFilesEdgeCases(t *testing.T) {
 		{
 			name:      "Sucesso - Diretório vazio",
 			inputPath: "",
-			expectErr: false, // Deve usar diretório atual
+			expectErr: false, // Deve usar a raiz do workspace
 		},
 		{
 			name:          "Erro - Diretório inexistente",
-			inputPath:     "/diretorio/que/nao/existe",
+			inputPath:     "diretorio/que/nao/existe",
 			expectErr:     true,
 			expectedError: "no such file or directory",
 		},
+		{
+			name:          "Erro - Diretório fora do workspace",
+			inputPath:     "/diretorio/que/nao/existe",
+			expectErr:     true,
+			expectedError: "fora do workspace",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -316,7 +306,7 @@ func TestListFilesEdgeCases(t *testing.T) {
 			var rawInput []byte
 
 			if tc.inputPath == "" {
-				// Teste com input vazio - deve usar diretório atual
+				// Teste com input vazio - deve usar a raiz do workspace
 				rawInput = []byte("{}")
 			} else {
 				inputData = ListFilesInput{Path: tc.inputPath}
@@ -383,3 +373,40 @@ func containsMiddle(s, substr string) bool {
 	}
 	return false
 }
+
+// TestPreviews - Testa que as prévias do dry-run descrevem o efeito sem alterar nada
+func TestPreviews(t *testing.T) {
+	root := newTestWorkspace(t, map[string]string{"existente.txt": "abc"})
+
+	testCases := []struct {
+		name     string
+		function func(json.RawMessage) (string, error)
+		input    interface{}
+		expected string
+	}{
+		{"Arquivo novo", previewWriteFile, WriteFileInput{Path: "novo.txt", Content: "12345"}, "Criaria o arquivo"},
+		{"Arquivo existente", previewWriteFile, WriteFileInput{Path: "existente.txt", Content: "x"}, "(3 → 1 bytes)"},
+		{"Diretório novo", previewCreateDirectory, CreateDirectoryInput{Path: "dir"}, "Criaria o diretório"},
+		{"Diretório existente", previewCreateDirectory, CreateDirectoryInput{Path: "."}, "já existe"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rawInput, _ := json.Marshal(tc.input)
+			result, err := tc.function(rawInput)
+			if err != nil {
+				t.Fatalf("prévia retornou erro inesperado: %v", err)
+			}
+			if !contains(result, tc.expected) {
+				t.Errorf("prévia = %q, esperado conter %q", result, tc.expected)
+			}
+		})
+	}
+
+	if _, err := os.Stat(filepath.Join(root, "novo.txt")); !os.IsNotExist(err) {
+		t.Error("previewWriteFile() não deveria criar o arquivo")
+	}
+	if _, err := os.Stat(filepath.Join(root, "dir")); !os.IsNotExist(err) {
+		t.Error("previewCreateDirectory() não deveria criar o diretório")
+	}
+}
@@ -0,0 +1,622 @@
+package builtin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/matheusbuniotto/goagent/pkg/toolkit"
+)
+
+const (
+	gitTimeout           = 60 * time.Second
+	defaultGitLogLimit   = 20
+	maxGitLogLimit       = 200
+	defaultBlameLines    = 100
+	maxBlameLines        = 400
+	defaultPatchMaxBytes = 30_000
+)
+
+// GitInput define os argumentos da ferramenta git.
+type GitInput struct {
+	Action   string   `json:"action"`              // status, diff, log, show, blame, add, commit, branch ou stash
+	Dir      string   `json:"dir,omitempty"`       // Repositório, relativo ao workspace
+	Paths    []string `json:"paths,omitempty"`     // Restringe a estes caminhos
+	Ref      string   `json:"ref,omitempty"`       // Commit, branch ou intervalo (a..b)
+	Staged   bool     `json:"staged,omitempty"`    // diff: só o que está no índice
+	Author   string   `json:"author,omitempty"`    // log
+	Since    string   `json:"since,omitempty"`     // log, ex: "2 weeks ago"
+	Until    string   `json:"until,omitempty"`     // log
+	Grep     string   `json:"grep,omitempty"`      // log: filtra pela mensagem
+	Limit    int      `json:"limit,omitempty"`     // log
+	Start    int      `json:"start,omitempty"`     // blame: primeira linha
+	End      int      `json:"end,omitempty"`       // blame: última linha
+	Message  string   `json:"message,omitempty"`   // commit e stash push
+	All      bool     `json:"all,omitempty"`       // add: todas as alterações
+	Name     string   `json:"name,omitempty"`      // branch a criar ou trocar
+	Switch   bool     `json:"switch,omitempty"`    // branch: muda para ela (criando se preciso)
+	Stash    string   `json:"stash,omitempty"`     // list, push, pop, apply ou drop
+	MaxBytes int      `json:"max_bytes,omitempty"` // Limite do patch em diff e show
+}
+
+// GitFileStatus é um arquivo alterado no status. Staged e Unstaged usam as letras do
+// git (M, A, D, R...), vazias quando não há alteração naquele lado.
+type GitFileStatus struct {
+	Path     string `json:"path"`
+	OrigPath string `json:"orig_path,omitempty"`
+	Staged   string `json:"staged,omitempty"`
+	Unstaged string `json:"unstaged,omitempty"`
+}
+
+// GitStatus é o resultado da ação status.
+type GitStatus struct {
+	Branch    string          `json:"branch"`
+	Upstream  string          `json:"upstream,omitempty"`
+	Ahead     int             `json:"ahead,omitempty"`
+	Behind    int             `json:"behind,omitempty"`
+	Clean     bool            `json:"clean"`
+	Changes   []GitFileStatus `json:"changes,omitempty"`
+	Untracked []string        `json:"untracked,omitempty"`
+	Conflicts []string        `json:"conflicts,omitempty"`
+}
+
+// GitFileStat conta as linhas alteradas em um arquivo.
+type GitFileStat struct {
+	Path    string `json:"path"`
+	Added   int    `json:"added"`
+	Deleted int    `json:"deleted"`
+	Binary  bool   `json:"binary,omitempty"`
+}
+
+// GitPatch é o resultado de diff e show.
+type GitPatch struct {
+	Commit    *GitCommit    `json:"commit,omitempty"`
+	Files     []GitFileStat `json:"files"`
+	Patch     string        `json:"patch,omitempty"`
+	Truncated bool          `json:"truncated,omitempty"`
+}
+
+// GitCommit resume um commit.
+type GitCommit struct {
+	Hash    string `json:"hash"`
+	Author  string `json:"author"`
+	Date    string `json:"date"`
+	Subject string `json:"subject"`
+	Body    string `json:"body,omitempty"`
+}
+
+// GitBlameLine é uma linha do blame com o commit que a introduziu.
+type GitBlameLine struct {
+	Line    int    `json:"line"`
+	Hash    string `json:"hash"`
+	Author  string `json:"author"`
+	Date    string `json:"date"`
+	Summary string `json:"summary"`
+	Text    string `json:"text"`
+}
+
+// gitRunner executa o git no diretório do repositório.
+type gitRunner struct {
+	ctx context.Context
+	dir string
+}
+
+// run executa o git e retorna o stdout. Um código de saída diferente de zero vira erro
+// com a mensagem do git.
+func (g gitRunner) run(args ...string) (string, error) {
+	argv := append([]string{"git", "--no-pager", "-c", "color.ui=never", "-c", "core.quotepath=off"}, args...)
+	var stdout, stderr bytes.Buffer
+	res, err := execInWorkspace(g.ctx, g.dir, argv, gitTimeout, &stdout, &stderr)
+	if err != nil {
+		return "", err
+	}
+	if res.timedOut {
+		return "", fmt.Errorf("git %s excedeu o tempo limite de %s", args[0], gitTimeout)
+	}
+	if res.exitCode != 0 {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = strings.TrimSpace(stdout.String())
+		}
+		return "", fmt.Errorf("git %s falhou (código %d): %s", args[0], res.exitCode, msg)
+	}
+	return stdout.String(), nil
+}
+
+// gitMutating indica as ações que alteram o repositório e passam pela aprovação.
+func gitMutating(input json.RawMessage) bool {
+	var typedInput GitInput
+	if err := json.Unmarshal(input, &typedInput); err != nil {
+		return false
+	}
+	switch typedInput.Action {
+	case "add", "commit":
+		return true
+	case "branch":
+		return typedInput.Name != ""
+	case "stash":
+		return typedInput.Stash != "" && typedInput.Stash != "list"
+	}
+	return false
+}
+
+// validateGitInput recusa refs e caminhos que o git interpretaria como opções, e
+// caminhos fora do workspace. Retorna os caminhos relativos ao repositório.
+func validateGitInput(w *Workspace, repo string, in GitInput, write bool) ([]string, error) {
+	for _, v := range []string{in.Ref, in.Name} {
+		if strings.HasPrefix(v, "-") {
+			return nil, fmt.Errorf("ref inválida '%s'", v)
+		}
+	}
+	var paths []string
+	for _, p := range in.Paths {
+		resolve := w.ResolveRead
+		if write {
+			resolve = w.Resolve
+		}
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(repo, p)
+		}
+		abs, err := resolve(p)
+		if err != nil {
+			return nil, err
+		}
+		rel, err := filepath.Rel(repo, abs)
+		if err != nil || !within(abs, repo) {
+			return nil, fmt.Errorf("caminho '%s' fora do repositório", p)
+		}
+		paths = append(paths, filepath.ToSlash(rel))
+	}
+	return paths, nil
+}
+
+func gitTool(ctx context.Context, input json.RawMessage) (string, error) {
+	var typedInput GitInput
+	if err := json.Unmarshal(input, &typedInput); err != nil {
+		return "", fmt.Errorf("JSON inválido para argumentos: %w", err)
+	}
+	if typedInput.Action == "" {
+		return "", errors.New("argumento inválido. 'action' é obrigatório")
+	}
+
+	w, err := CurrentWorkspace()
+	if err != nil {
+		return "", err
+	}
+	// Consultas valem também nos diretórios liberados só para leitura.
+	write := gitMutating(input)
+	resolve := w.ResolveRead
+	if write {
+		resolve = w.Resolve
+	}
+	repo, err := resolve(typedInput.Dir)
+	if err != nil {
+		return "", err
+	}
+	paths, err := validateGitInput(w, repo, typedInput, write)
+	if err != nil {
+		return "", err
+	}
+	g := gitRunner{ctx: ctx, dir: repo}
+
+	var result any
+	switch typedInput.Action {
+	case "status":
+		result, err = g.status()
+	case "diff":
+		result, err = g.diff(typedInput, paths)
+	case "log":
+		result, err = g.log(typedInput, paths)
+	case "show":
+		result, err = g.show(typedInput, paths)
+	case "blame":
+		result, err = g.blame(typedInput, paths)
+	case "add":
+		result, err = g.add(typedInput, paths)
+	case "commit":
+		result, err = g.commit(typedInput)
+	case "branch":
+		result, err = g.branch(typedInput)
+	case "stash":
+		result, err = g.stash(typedInput, paths)
+	default:
+		return "", fmt.Errorf("ação inválida '%s' (use status, diff, log, show, blame, add, commit, branch ou stash)", typedInput.Action)
+	}
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return "", fmt.Errorf("erro ao serializar o resultado: %w", err)
+	}
+	return string(data), nil
+}
+
+func (g gitRunner) status() (*GitStatus, error) {
+	out, err := g.run("status", "--porcelain=v2", "--branch", "-z")
+	if err != nil {
+		return nil, err
+	}
+	st := &GitStatus{}
+	records := strings.Split(strings.TrimSuffix(out, "\x00"), "\x00")
+	for i := 0; i < len(records); i++ {
+		rec := records[i]
+		switch {
+		case strings.HasPrefix(rec, "# branch.head "):
+			st.Branch = strings.TrimPrefix(rec, "# branch.head ")
+		case strings.HasPrefix(rec, "# branch.upstream "):
+			st.Upstream = strings.TrimPrefix(rec, "# branch.upstream ")
+		case strings.HasPrefix(rec, "# branch.ab "):
+			_, _ = fmt.Sscanf(strings.TrimPrefix(rec, "# branch.ab "), "+%d -%d", &st.Ahead, &st.Behind)
+		case strings.HasPrefix(rec, "1 "), strings.HasPrefix(rec, "2 "):
+			// "1 XY sub mH mI mW hH hI caminho"; o tipo 2 (renomeação) tem mais um campo
+			// e o caminho original vem no registro seguinte.
+			nfields := 9
+			if rec[0] == '2' {
+				nfields = 10
+			}
+			fields := strings.SplitN(rec, " ", nfields)
+			if len(fields) < nfields {
+				continue
+			}
+			f := GitFileStatus{Path: fields[nfields-1], Staged: gitStatusLetter(fields[1][0]), Unstaged: gitStatusLetter(fields[1][1])}
+			if rec[0] == '2' && i+1 < len(records) {
+				i++
+				f.OrigPath = records[i]
+			}
+			st.Changes = append(st.Changes, f)
+		case strings.HasPrefix(rec, "u "):
+			fields := strings.SplitN(rec, " ", 11)
+			st.Conflicts = append(st.Conflicts, fields[len(fields)-1])
+		case strings.HasPrefix(rec, "? "):
+			st.Untracked = append(st.Untracked, strings.TrimPrefix(rec, "? "))
+		}
+	}
+	st.Clean = len(st.Changes) == 0 && len(st.Untracked) == 0 && len(st.Conflicts) == 0
+	return st, nil
+}
+
+func gitStatusLetter(c byte) string {
+	if c == '.' {
+		return ""
+	}
+	return string(c)
+}
+
+// parseNumstat converte a saída de --numstat em contagens por arquivo.
+func parseNumstat(out string) []GitFileStat {
+	files := []GitFileStat{}
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		f := GitFileStat{Path: fields[2]}
+		if fields[0] == "-" {
+			f.Binary = true
+		} else {
+			f.Added, _ = strconv.Atoi(fields[0])
+			f.Deleted, _ = strconv.Atoi(fields[1])
+		}
+		files = append(files, f)
+	}
+	return files
+}
+
+// patch executa o comando de diff e corta o resultado em maxBytes.
+func (g gitRunner) patch(in GitInput, args []string, paths []string) (*GitPatch, error) {
+	stats, err := g.run(append(append(append([]string{}, args...), "--numstat", "--"), paths...)...)
+	if err != nil {
+		return nil, err
+	}
+	out, err := g.run(append(append(append([]string{}, args...), "--"), paths...)...)
+	if err != nil {
+		return nil, err
+	}
+	maxBytes := in.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = defaultPatchMaxBytes
+	}
+	p := &GitPatch{Files: parseNumstat(stats), Patch: out}
+	if len(out) > maxBytes {
+		p.Patch, p.Truncated = truncateUTF8(out, maxBytes), true
+	}
+	return p, nil
+}
+
+func (g gitRunner) diff(in GitInput, paths []string) (*GitPatch, error) {
+	args := []string{"diff"}
+	if in.Staged {
+		args = append(args, "--cached")
+	}
+	if in.Ref != "" {
+		args = append(args, in.Ref)
+	}
+	return g.patch(in, args, paths)
+}
+
+// commitFormat separa os campos de cada commit por \x1f e os commits por \x1e.
+const commitFormat = "--format=%h%x1f%an <%ae>%x1f%aI%x1f%s%x1f%b%x1e"
+
+func parseCommits(out string, withBody bool) []GitCommit {
+	commits := []GitCommit{}
+	for _, rec := range strings.Split(out, "\x1e") {
+		fields := strings.Split(strings.TrimLeft(rec, "\n"), "\x1f")
+		if len(fields) < 5 {
+			continue
+		}
+		c := GitCommit{Hash: fields[0], Author: fields[1], Date: fields[2], Subject: fields[3]}
+		if withBody {
+			c.Body = strings.TrimSpace(fields[4])
+		}
+		commits = append(commits, c)
+	}
+	return commits
+}
+
+func (g gitRunner) log(in GitInput, paths []string) ([]GitCommit, error) {
+	limit := in.Limit
+	if limit <= 0 {
+		limit = defaultGitLogLimit
+	}
+	limit = min(limit, maxGitLogLimit)
+	args := []string{"log", commitFormat, "-n", strconv.Itoa(limit)}
+	for _, filter := range [][2]string{{"--author=", in.Author}, {"--since=", in.Since}, {"--until=", in.Until}, {"--grep=", in.Grep}} {
+		if filter[1] != "" {
+			args = append(args, filter[0]+filter[1])
+		}
+	}
+	if in.Ref != "" {
+		args = append(args, in.Ref)
+	}
+	out, err := g.run(append(append(args, "--"), paths...)...)
+	if err != nil {
+		return nil, err
+	}
+	return parseCommits(out, false), nil
+}
+
+func (g gitRunner) show(in GitInput, paths []string) (*GitPatch, error) {
+	ref := in.Ref
+	if ref == "" {
+		ref = "HEAD"
+	}
+	out, err := g.run("show", "-s", commitFormat, ref, "--")
+	if err != nil {
+		return nil, err
+	}
+	commits := parseCommits(out, true)
+	if len(commits) == 0 {
+		return nil, fmt.Errorf("commit '%s' não encontrado", ref)
+	}
+	p, err := g.patch(in, []string{"show", "--format=", ref}, paths)
+	if err != nil {
+		return nil, err
+	}
+	p.Commit = &commits[0]
+	return p, nil
+}
+
+func (g gitRunner) blame(in GitInput, paths []string) ([]GitBlameLine, error) {
+	if len(paths) != 1 {
+		return nil, errors.New("argumento inválido. blame exige exatamente um caminho em 'paths'")
+	}
+	start := max(in.Start, 1)
+	end := in.End
+	if end < start {
+		end = start + defaultBlameLines - 1
+	}
+	end = min(end, start+maxBlameLines-1)
+
+	args := []string{"blame", "--porcelain", "-L", fmt.Sprintf("%d,%d", start, end)}
+	if in.Ref != "" {
+		args = append(args, in.Ref)
+	}
+	out, err := g.run(append(args, "--", paths[0])...)
+	if err != nil {
+		return nil, err
+	}
+
+	// No formato porcelain, os dados de cada commit só aparecem na primeira linha dele.
+	type commitInfo struct{ author, date, summary string }
+	infos := make(map[string]*commitInfo)
+	var lines []GitBlameLine
+	var cur *GitBlameLine
+	for _, line := range strings.Split(out, "\n") {
+		switch {
+		case strings.HasPrefix(line, "\t"):
+			if cur != nil {
+				info := infos[cur.Hash]
+				cur.Text, cur.Author, cur.Date, cur.Summary = line[1:], info.author, info.date, info.summary
+				cur.Hash = cur.Hash[:min(len(cur.Hash), 12)]
+				lines = append(lines, *cur)
+				cur = nil
+			}
+		case cur == nil:
+			fields := strings.Fields(line)
+			if len(fields) < 3 {
+				continue
+			}
+			n, _ := strconv.Atoi(fields[2])
+			cur = &GitBlameLine{Hash: fields[0], Line: n}
+			if infos[fields[0]] == nil {
+				infos[fields[0]] = &commitInfo{}
+			}
+		default:
+			key, value, _ := strings.Cut(line, " ")
+			info := infos[cur.Hash]
+			switch key {
+			case "author":
+				info.author = value
+			case "author-time":
+				if sec, err := strconv.ParseInt(value, 10, 64); err == nil {
+					info.date = time.Unix(sec, 0).UTC().Format("2006-01-02")
+				}
+			case "summary":
+				info.summary = value
+			}
+		}
+	}
+	return lines, nil
+}
+
+func (g gitRunner) add(in GitInput, paths []string) (*GitStatus, error) {
+	switch {
+	case in.All:
+		if _, err := g.run("add", "-A"); err != nil {
+			return nil, err
+		}
+	case len(paths) > 0:
+		if _, err := g.run(append([]string{"add", "--"}, paths...)...); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.New("argumento inválido. informe 'paths' ou \"all\": true")
+	}
+	return g.status()
+}
+
+func (g gitRunner) commit(in GitInput) (*GitPatch, error) {
+	if strings.TrimSpace(in.Message) == "" {
+		return nil, errors.New("argumento inválido. 'message' é obrigatório")
+	}
+	if _, err := g.run("commit", "-m", in.Message); err != nil {
+		return nil, err
+	}
+	// Só o resumo: o patch do commit recém-criado o modelo já conhece.
+	p, err := g.show(GitInput{Ref: "HEAD"}, nil)
+	if err != nil {
+		return nil, err
+	}
+	p.Patch, p.Truncated = "", false
+	return p, nil
+}
+
+// gitBranches é o resultado da ação branch.
+type gitBranches struct {
+	Current  string   `json:"current"`
+	Branches []string `json:"branches,omitempty"`
+	Output   string   `json:"output,omitempty"`
+}
+
+func (g gitRunner) branch(in GitInput) (*gitBranches, error) {
+	if in.Name != "" {
+		args := []string{"branch", in.Name}
+		if in.Switch {
+			args = []string{"switch", "-c", in.Name}
+			if g.hasBranch(in.Name) {
+				args = []string{"switch", in.Name}
+				in.Ref = "" // A branch já existe; a ref só vale na criação.
+			}
+		}
+		if in.Ref != "" {
+			args = append(args, in.Ref)
+		}
+		if _, err := g.run(args...); err != nil {
+			return nil, err
+		}
+	}
+
+	out, err := g.run("branch", "--list", "--format=%(HEAD)%(refname:short)")
+	if err != nil {
+		return nil, err
+	}
+	b := &gitBranches{}
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		if name, ok := strings.CutPrefix(line, "*"); ok {
+			b.Current = name
+			line = name
+		}
+		if line = strings.TrimSpace(line); line != "" {
+			b.Branches = append(b.Branches, line)
+		}
+	}
+	return b, nil
+}
+
+func (g gitRunner) hasBranch(name string) bool {
+	_, err := g.run("rev-parse", "--verify", "--quiet", "refs/heads/"+name)
+	return err == nil
+}
+
+// gitStashes é o resultado da ação stash.
+type gitStashes struct {
+	Stashes []string `json:"stashes"`
+	Output  string   `json:"output,omitempty"`
+}
+
+func (g gitRunner) stash(in GitInput, paths []string) (*gitStashes, error) {
+	var out string
+	var err error
+	switch in.Stash {
+	case "", "list":
+	case "push":
+		args := []string{"stash", "push"}
+		if in.Message != "" {
+			args = append(args, "-m", in.Message)
+		}
+		out, err = g.run(append(append(args, "--"), paths...)...)
+	case "pop", "apply", "drop":
+		args := []string{"stash", in.Stash}
+		if in.Ref != "" {
+			args = append(args, in.Ref)
+		}
+		out, err = g.run(args...)
+	default:
+		return nil, fmt.Errorf("operação de stash inválida '%s' (use list, push, pop, apply ou drop)", in.Stash)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := g.run("stash", "list", "--format=%gd: %s")
+	if err != nil {
+		return nil, err
+	}
+	s := &gitStashes{Stashes: []string{}, Output: strings.TrimSpace(out)}
+	for _, line := range strings.Split(strings.TrimSpace(list), "\n") {
+		if line != "" {
+			s.Stashes = append(s.Stashes, line)
+		}
+	}
+	return s, nil
+}
+
+func previewGit(input json.RawMessage) (string, error) {
+	var typedInput GitInput
+	if err := json.Unmarshal(input, &typedInput); err != nil {
+		return "", fmt.Errorf("JSON inválido para argumentos: %w", err)
+	}
+	switch typedInput.Action {
+	case "add":
+		if typedInput.All {
+			return "Adicionaria todas as alterações ao índice do git", nil
+		}
+		return fmt.Sprintf("Adicionaria ao índice do git: %s", strings.Join(typedInput.Paths, ", ")), nil
+	case "commit":
+		return fmt.Sprintf("Criaria um commit com a mensagem %q", typedInput.Message), nil
+	case "branch":
+		if typedInput.Switch {
+			return fmt.Sprintf("Mudaria para a branch '%s'", typedInput.Name), nil
+		}
+		return fmt.Sprintf("Criaria a branch '%s'", typedInput.Name), nil
+	case "stash":
+		return fmt.Sprintf("Executaria git stash %s", typedInput.Stash), nil
+	}
+	return fmt.Sprintf("Executaria git %s", typedInput.Action), nil
+}
+
+// GitDef é a definição da ferramenta git.
+var GitDef = toolkit.ToolDefinition{
+	Name:            "git",
+	Description:     `Consulta e altera o repositório git do workspace, com saída em JSON. Leitura: "status"; "diff" ("staged": true para o índice, "ref" para comparar com um commit ou intervalo a..b); "log" ("author", "since", "until", "grep", "limit"); "show" ("ref", padrão HEAD); "blame" (um caminho em "paths", "start" e "end"). Com aprovação: "add" ("paths" ou "all": true), "commit" ("message"), "branch" (lista; com "name" cria, e com "switch": true muda para ela), "stash" ("stash": list, push, pop, apply ou drop). Opcionais: "paths", "dir" (repositório), "max_bytes" (limite do patch). Exemplo: {"action": "diff", "paths": ["main.go"]}`,
+	ContextFunction: gitTool,
+	MutatingFunc:    gitMutating,
+	Preview:         previewGit,
+}
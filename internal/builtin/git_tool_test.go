@@ -0,0 +1,192 @@
+package builtin
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setupGitRepo cria um workspace com um repositório vazio no diretório "repo", isolado da
+// configuração do usuário, e retorna o caminho relativo à raiz e o absoluto.
+func setupGitRepo(t *testing.T) (rel, abs string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git não encontrado no PATH")
+	}
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("GIT_AUTHOR_NAME", "Ana")
+	t.Setenv("GIT_AUTHOR_EMAIL", "ana@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Ana")
+	t.Setenv("GIT_COMMITTER_EMAIL", "ana@example.com")
+
+	rel = "repo"
+	abs = filepath.Join(newTestWorkspace(t, nil), rel)
+	if err := os.Mkdir(abs, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := exec.Command("git", "-C", abs, "init", "-q", "-b", "main").CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v\n%s", err, out)
+	}
+	return rel, abs
+}
+
+// TestGitTool percorre um fluxo completo: status, add, commit, diff, log, blame, branch e stash.
+func TestGitTool(t *testing.T) {
+	repo, abs := setupGitRepo(t)
+	_ = os.WriteFile(filepath.Join(abs, "a.txt"), []byte("um\n"), 0644)
+
+	call := func(t *testing.T, in GitInput, out any) {
+		t.Helper()
+		in.Dir = repo
+		rawInput, _ := json.Marshal(in)
+		result, err := gitTool(context.Background(), rawInput)
+		if err != nil {
+			t.Fatalf("gitTool(%s) erro inesperado: %v", in.Action, err)
+		}
+		if err := json.Unmarshal([]byte(result), out); err != nil {
+			t.Fatalf("saída não é JSON: %v\n%s", err, result)
+		}
+	}
+
+	var status GitStatus
+	call(t, GitInput{Action: "status"}, &status)
+	if status.Branch != "main" || status.Clean || len(status.Untracked) != 1 || status.Untracked[0] != "a.txt" {
+		t.Errorf("status inicial inesperado: %+v", status)
+	}
+
+	call(t, GitInput{Action: "add", Paths: []string{"a.txt"}}, &status)
+	if len(status.Changes) != 1 || status.Changes[0] != (GitFileStatus{Path: "a.txt", Staged: "A"}) {
+		t.Errorf("status após add inesperado: %+v", status)
+	}
+
+	var commit GitPatch
+	call(t, GitInput{Action: "commit", Message: "Primeiro commit\n\nCom corpo."}, &commit)
+	if commit.Commit == nil || commit.Commit.Subject != "Primeiro commit" || commit.Commit.Body != "Com corpo." || commit.Commit.Author != "Ana <ana@example.com>" {
+		t.Errorf("commit inesperado: %+v", commit.Commit)
+	}
+	if len(commit.Files) != 1 || commit.Files[0] != (GitFileStat{Path: "a.txt", Added: 1}) {
+		t.Errorf("arquivos do commit inesperados: %+v", commit.Files)
+	}
+
+	_ = os.WriteFile(filepath.Join(abs, "a.txt"), []byte("dois\n"), 0644)
+	var diff GitPatch
+	call(t, GitInput{Action: "diff"}, &diff)
+	if len(diff.Files) != 1 || diff.Files[0] != (GitFileStat{Path: "a.txt", Added: 1, Deleted: 1}) || !strings.Contains(diff.Patch, "-um\n+dois") {
+		t.Errorf("diff inesperado: %+v", diff)
+	}
+	call(t, GitInput{Action: "diff", MaxBytes: 10}, &diff)
+	if !diff.Truncated || len(diff.Patch) != 10 {
+		t.Errorf("diff deveria ser truncado em 10 bytes: %+v", diff)
+	}
+	diff = GitPatch{}
+	call(t, GitInput{Action: "diff", Staged: true}, &diff)
+	if len(diff.Files) != 0 || diff.Patch != "" {
+		t.Errorf("diff do índice deveria estar vazio: %+v", diff)
+	}
+
+	var commits []GitCommit
+	call(t, GitInput{Action: "log", Paths: []string{"a.txt"}}, &commits)
+	if len(commits) != 1 || commits[0].Subject != "Primeiro commit" || commits[0].Body != "" {
+		t.Errorf("log inesperado: %+v", commits)
+	}
+	call(t, GitInput{Action: "log", Grep: "inexistente"}, &commits)
+	if len(commits) != 0 {
+		t.Errorf("log filtrado deveria estar vazio: %+v", commits)
+	}
+
+	var blame []GitBlameLine
+	call(t, GitInput{Action: "blame", Paths: []string{"a.txt"}, Ref: "HEAD"}, &blame)
+	if len(blame) != 1 || blame[0].Line != 1 || blame[0].Text != "um" || blame[0].Author != "Ana" || blame[0].Summary != "Primeiro commit" || blame[0].Hash != headHash(t, abs) {
+		t.Errorf("blame inesperado: %+v", blame)
+	}
+
+	var branches gitBranches
+	call(t, GitInput{Action: "branch", Name: "feature", Switch: true}, &branches)
+	if branches.Current != "feature" || strings.Join(branches.Branches, ",") != "feature,main" {
+		t.Errorf("branch inesperada: %+v", branches)
+	}
+
+	var stashes gitStashes
+	call(t, GitInput{Action: "stash", Stash: "push", Message: "wip"}, &stashes)
+	if len(stashes.Stashes) != 1 || !strings.HasSuffix(stashes.Stashes[0], "wip") {
+		t.Errorf("stash inesperado: %+v", stashes)
+	}
+	call(t, GitInput{Action: "status"}, &status)
+	if !status.Clean {
+		t.Errorf("status deveria estar limpo após o stash: %+v", status)
+	}
+	call(t, GitInput{Action: "stash", Stash: "pop"}, &stashes)
+	if data, _ := os.ReadFile(filepath.Join(abs, "a.txt")); string(data) != "dois\n" || len(stashes.Stashes) != 0 {
+		t.Errorf("stash pop não restaurou a alteração: %q %+v", data, stashes)
+	}
+}
+
+// headHash retorna o hash curto do HEAD, no mesmo tamanho usado pelo blame.
+func headHash(t *testing.T, dir string) string {
+	t.Helper()
+	out, err := exec.Command("git", "-C", dir, "rev-parse", "HEAD").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.TrimSpace(string(out))[:12]
+}
+
+// TestGitToolErrors testa as validações da ferramenta git.
+func TestGitToolErrors(t *testing.T) {
+	repo, _ := setupGitRepo(t)
+
+	testCases := []struct {
+		name          string
+		input         GitInput
+		expectedError string
+	}{
+		{name: "Sem ação", input: GitInput{}, expectedError: "'action' é obrigatório"},
+		{name: "Ação inválida", input: GitInput{Action: "push"}, expectedError: "ação inválida 'push'"},
+		{name: "Ref como opção", input: GitInput{Action: "diff", Ref: "--output=/tmp/x"}, expectedError: "ref inválida"},
+		{name: "Caminho fora do workspace", input: GitInput{Action: "log", Paths: []string{"/etc/passwd"}}, expectedError: "fora do workspace"},
+		{name: "Blame sem caminho", input: GitInput{Action: "blame"}, expectedError: "exatamente um caminho"},
+		{name: "Commit sem mensagem", input: GitInput{Action: "commit"}, expectedError: "'message' é obrigatório"},
+		{name: "Erro do git", input: GitInput{Action: "show", Ref: "naoexiste"}, expectedError: "git show falhou"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.input.Action != "" {
+				tc.input.Dir = repo
+			}
+			rawInput, _ := json.Marshal(tc.input)
+			_, err := gitTool(context.Background(), rawInput)
+			if err == nil || !strings.Contains(err.Error(), tc.expectedError) {
+				t.Errorf("gitTool() erro = %v, esperado conter %q", err, tc.expectedError)
+			}
+		})
+	}
+}
+
+// TestGitMutating testa quais ações passam pela política de aprovação.
+func TestGitMutating(t *testing.T) {
+	testCases := []struct {
+		input    GitInput
+		expected bool
+	}{
+		{GitInput{Action: "status"}, false},
+		{GitInput{Action: "diff"}, false},
+		{GitInput{Action: "add", All: true}, true},
+		{GitInput{Action: "commit", Message: "x"}, true},
+		{GitInput{Action: "branch"}, false},
+		{GitInput{Action: "branch", Name: "nova"}, true},
+		{GitInput{Action: "stash", Stash: "list"}, false},
+		{GitInput{Action: "stash", Stash: "pop"}, true},
+	}
+	for _, tc := range testCases {
+		rawInput, _ := json.Marshal(tc.input)
+		if got := gitMutating(rawInput); got != tc.expected {
+			t.Errorf("gitMutating(%+v) = %v, esperado %v", tc.input, got, tc.expected)
+		}
+	}
+}
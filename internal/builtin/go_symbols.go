@@ -0,0 +1,644 @@
+package builtin
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"go/types"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/matheusbuniotto/goagent/pkg/toolkit"
+)
+
+const (
+	maxSymbolDefinitions = 10
+	maxDefinitionLines   = 200
+	maxSymbolReferences  = 200
+	maxSymbolValueRunes  = 80
+	maxSymbolListEntries = 500
+)
+
+// GoSymbolsInput define os argumentos de go_symbols.
+type GoSymbolsInput struct {
+	Action       string `json:"action"`                  // list, definition ou references
+	Path         string `json:"path,omitempty"`          // Arquivo ou diretório de pacote; padrão: raiz do workspace
+	Symbol       string `json:"symbol,omitempty"`        // Nome, Tipo.Metodo ou pacote.Nome
+	Exported     bool   `json:"exported,omitempty"`      // Só declarações exportadas (list)
+	IncludeTests bool   `json:"include_tests,omitempty"` // Inclui arquivos _test.go
+}
+
+// goPackage é um diretório de pacote do módulo, com os arquivos já analisados.
+type goPackage struct {
+	dir        string
+	importPath string
+	name       string
+	files      []*ast.File // Código do pacote
+	tests      []*ast.File // _test.go do próprio pacote
+	xtests     []*ast.File // _test.go do pacote externo nome_test
+	types      *types.Package
+}
+
+// goModule reúne os pacotes de um módulo Go do workspace. Também é o types.Importer
+// usado na checagem de tipos: pacotes do módulo vêm do código-fonte, e os de fora
+// (biblioteca padrão e dependências) viram pacotes vazios, o que basta para resolver
+// os identificadores do próprio módulo sem executar o go.
+type goModule struct {
+	root     string
+	path     string
+	fset     *token.FileSet
+	packages map[string]*goPackage // Por import path
+	order    []string
+	external map[string]*types.Package
+	checking map[string]bool
+	infos    []*types.Info
+}
+
+// findModuleRoot sobe de dir até achar um go.mod, sem sair do workspace. Sem go.mod,
+// usa o próprio dir e o módulo fica sem import path.
+func findModuleRoot(w *Workspace, dir string) (root, modulePath string) {
+	for d := dir; ; d = filepath.Dir(d) {
+		if data, err := os.ReadFile(filepath.Join(d, "go.mod")); err == nil {
+			scanner := bufio.NewScanner(bytes.NewReader(data))
+			for scanner.Scan() {
+				if rest, ok := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "module "); ok {
+					return d, strings.Trim(strings.TrimSpace(rest), `"`)
+				}
+			}
+			return d, ""
+		}
+		if d == w.Root() || filepath.Dir(d) == d || !within(d, w.Root()) {
+			return dir, ""
+		}
+	}
+}
+
+// loadGoModule analisa todos os pacotes do módulo que contém dir, respeitando as regras
+// de ignore e as restrições de build da plataforma atual.
+func loadGoModule(w *Workspace, dir string) (*goModule, error) {
+	root, modulePath := findModuleRoot(w, dir)
+	m := &goModule{
+		root:     root,
+		path:     modulePath,
+		fset:     token.NewFileSet(),
+		packages: make(map[string]*goPackage),
+		external: make(map[string]*types.Package),
+		checking: make(map[string]bool),
+	}
+
+	byDir := map[string][]string{root: nil}
+	err := treeWalker{}.walk(root, func(abs, rel string, e os.DirEntry) error {
+		name := e.Name()
+		if e.IsDir() {
+			// Convenções do go: testdata, _x e .x ficam fora; go.mod aninhado é outro módulo.
+			if name == "testdata" || strings.HasPrefix(name, "_") || strings.HasPrefix(name, ".") {
+				return filepath.SkipDir
+			}
+			if _, err := os.Stat(filepath.Join(abs, "go.mod")); err == nil && m.path != "" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasSuffix(name, ".go") && !strings.HasPrefix(name, "_") && !strings.HasPrefix(name, ".") {
+			byDir[filepath.Dir(abs)] = append(byDir[filepath.Dir(abs)], abs)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for d, files := range byDir {
+		if len(files) == 0 {
+			continue
+		}
+		pkg := m.parseDir(d, files)
+		if pkg.name == "" && len(pkg.tests) == 0 && len(pkg.xtests) == 0 {
+			continue
+		}
+		m.packages[pkg.importPath] = pkg
+		m.order = append(m.order, pkg.importPath)
+	}
+	sort.Strings(m.order)
+	return m, nil
+}
+
+// parseDir analisa os arquivos de um diretório e os separa em código, testes internos e
+// testes externos.
+func (m *goModule) parseDir(dir string, files []string) *goPackage {
+	rel, _ := filepath.Rel(m.root, dir)
+	importPath := path.Join(m.path, filepath.ToSlash(rel))
+	if importPath == "" {
+		importPath = "."
+	}
+	pkg := &goPackage{dir: dir, importPath: importPath}
+	for _, file := range files {
+		if ok, err := build.Default.MatchFile(dir, filepath.Base(file)); err != nil || !ok {
+			continue
+		}
+		// Arquivos com erros de sintaxe entram com o que foi possível analisar.
+		f, _ := parser.ParseFile(m.fset, file, nil, parser.ParseComments)
+		if f == nil {
+			continue
+		}
+		switch {
+		case !strings.HasSuffix(file, "_test.go"):
+			if pkg.name == "" {
+				pkg.name = f.Name.Name
+			}
+			pkg.files = append(pkg.files, f)
+		case strings.HasSuffix(f.Name.Name, "_test"):
+			pkg.xtests = append(pkg.xtests, f)
+		default:
+			pkg.tests = append(pkg.tests, f)
+		}
+	}
+	if pkg.name == "" && len(pkg.tests) > 0 {
+		pkg.name = pkg.tests[0].Name.Name
+	}
+	return pkg
+}
+
+// Import implementa types.Importer.
+func (m *goModule) Import(importPath string) (*types.Package, error) {
+	if pkg := m.packages[importPath]; pkg != nil && len(pkg.files) > 0 {
+		if pkg.types == nil {
+			if m.checking[importPath] {
+				return nil, fmt.Errorf("ciclo de importação em %s", importPath)
+			}
+			m.checking[importPath] = true
+			pkg.types = m.check(importPath, pkg.files)
+		}
+		return pkg.types, nil
+	}
+	if p := m.external[importPath]; p != nil {
+		return p, nil
+	}
+	p := types.NewPackage(importPath, guessPackageName(importPath))
+	p.MarkComplete()
+	m.external[importPath] = p
+	return p, nil
+}
+
+// guessPackageName deduz o nome de um pacote de fora do módulo pelo import path,
+// ignorando sufixos de versão como "/v2" e ".v3".
+func guessPackageName(importPath string) string {
+	parts := strings.Split(importPath, "/")
+	name := parts[len(parts)-1]
+	if len(parts) > 1 && len(name) > 1 && name[0] == 'v' && strings.Trim(name[1:], "0123456789") == "" {
+		name = parts[len(parts)-2]
+	}
+	if i := strings.Index(name, ".v"); i > 0 {
+		name = name[:i]
+	}
+	name = strings.TrimPrefix(name, "go-")
+	return strings.ReplaceAll(name, "-", "")
+}
+
+// check faz a checagem de tipos dos arquivos, tolerando erros, e guarda as informações
+// de uso dos identificadores.
+func (m *goModule) check(importPath string, files []*ast.File) *types.Package {
+	info := &types.Info{Defs: make(map[*ast.Ident]types.Object), Uses: make(map[*ast.Ident]types.Object)}
+	conf := types.Config{Importer: m, Error: func(error) {}, FakeImportC: true}
+	p, _ := conf.Check(importPath, m.fset, files, info)
+	m.infos = append(m.infos, info)
+	return p
+}
+
+// checkAll checa todos os pacotes do módulo e, se pedido, as variantes com testes.
+func (m *goModule) checkAll(includeTests bool) {
+	for _, importPath := range m.order {
+		pkg := m.packages[importPath]
+		_, _ = m.Import(importPath)
+		if !includeTests {
+			continue
+		}
+		if len(pkg.tests) > 0 {
+			files := append(append([]*ast.File{}, pkg.files...), pkg.tests...)
+			m.check(importPath, files)
+		}
+		if len(pkg.xtests) > 0 {
+			m.check(importPath+"_test", pkg.xtests)
+		}
+	}
+}
+
+// goSymbol é uma declaração encontrada na AST.
+type goSymbol struct {
+	pkg   *goPackage
+	kind  string // func, method, type, const, var ou field
+	name  string
+	recv  string // Tipo do receptor (métodos) ou tipo dono (campos)
+	ident *ast.Ident
+	node  ast.Node // Nó cujo intervalo de linhas é mostrado
+	doc   *ast.CommentGroup
+	sig   string
+}
+
+// symbols lista as declarações de nível superior de um arquivo e, se withMembers, os
+// campos de structs e métodos de interfaces.
+func (m *goModule) symbols(pkg *goPackage, f *ast.File, withMembers bool) []goSymbol {
+	var syms []goSymbol
+	for _, decl := range f.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			s := goSymbol{pkg: pkg, kind: "func", name: d.Name.Name, ident: d.Name, node: d, doc: d.Doc}
+			if d.Recv != nil && len(d.Recv.List) > 0 {
+				s.kind, s.recv = "method", receiverName(d.Recv.List[0].Type)
+			}
+			fn := *d
+			fn.Body, fn.Doc = nil, nil
+			s.sig = m.render(&fn)
+			syms = append(syms, s)
+
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				// Em blocos "const (...)", a documentação do bloco não é a da especificação.
+				node, doc := ast.Node(spec), (*ast.CommentGroup)(nil)
+				if !d.Lparen.IsValid() {
+					node, doc = d, d.Doc
+				}
+				switch sp := spec.(type) {
+				case *ast.TypeSpec:
+					if sp.Doc != nil {
+						doc = sp.Doc
+					}
+					syms = append(syms, goSymbol{pkg: pkg, kind: "type", name: sp.Name.Name, ident: sp.Name, node: node, doc: doc, sig: "type " + m.renderTypeSpec(sp)})
+					if withMembers {
+						syms = append(syms, m.members(pkg, sp)...)
+					}
+				case *ast.ValueSpec:
+					if sp.Doc != nil {
+						doc = sp.Doc
+					}
+					for i, name := range sp.Names {
+						if name.Name == "_" {
+							continue
+						}
+						sig := d.Tok.String() + " " + name.Name
+						if sp.Type != nil {
+							sig += " " + m.render(sp.Type)
+						}
+						if i < len(sp.Values) {
+							sig += " = " + truncateRunes(m.render(sp.Values[i]), maxSymbolValueRunes)
+						}
+						syms = append(syms, goSymbol{pkg: pkg, kind: d.Tok.String(), name: name.Name, ident: name, node: node, doc: doc, sig: sig})
+					}
+				}
+			}
+		}
+	}
+	return syms
+}
+
+// members lista os campos de uma struct ou os métodos de uma interface.
+func (m *goModule) members(pkg *goPackage, sp *ast.TypeSpec) []goSymbol {
+	var fields *ast.FieldList
+	kind := "field"
+	switch t := sp.Type.(type) {
+	case *ast.StructType:
+		fields = t.Fields
+	case *ast.InterfaceType:
+		fields, kind = t.Methods, "method"
+	default:
+		return nil
+	}
+	var syms []goSymbol
+	for _, field := range fields.List {
+		for _, name := range field.Names {
+			syms = append(syms, goSymbol{pkg: pkg, kind: kind, name: name.Name, recv: sp.Name.Name, ident: name, node: field, doc: field.Doc,
+				sig: fmt.Sprintf("%s %s.%s %s", kind, sp.Name.Name, name.Name, m.render(field.Type))})
+		}
+	}
+	return syms
+}
+
+// receiverName extrai o nome do tipo do receptor, sem ponteiro nem parâmetros de tipo.
+func receiverName(expr ast.Expr) string {
+	for {
+		switch t := expr.(type) {
+		case *ast.StarExpr:
+			expr = t.X
+		case *ast.IndexExpr:
+			expr = t.X
+		case *ast.IndexListExpr:
+			expr = t.X
+		case *ast.Ident:
+			return t.Name
+		default:
+			return ""
+		}
+	}
+}
+
+func (m *goModule) render(node any) string {
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, m.fset, node); err != nil {
+		return ""
+	}
+	return buf.String()
+}
+
+// renderTypeSpec mostra o tipo completo, exceto structs e interfaces, que ficam só com o
+// tipo (os membros aparecem na definição).
+func (m *goModule) renderTypeSpec(sp *ast.TypeSpec) string {
+	spec := *sp
+	spec.Doc, spec.Comment = nil, nil
+	switch sp.Type.(type) {
+	case *ast.StructType:
+		spec.Type = ast.NewIdent("struct")
+	case *ast.InterfaceType:
+		spec.Type = ast.NewIdent("interface")
+	}
+	return m.render(&spec)
+}
+
+func truncateRunes(s string, n int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if r := []rune(s); len(r) > n {
+		return string(r[:n]) + "…"
+	}
+	return s
+}
+
+// matches informa se o símbolo corresponde à consulta: "Nome", "Tipo.Membro",
+// "pacote.Nome" ou "pacote.Tipo.Membro".
+func (s goSymbol) matches(query string) bool {
+	parts := strings.Split(query, ".")
+	switch len(parts) {
+	case 1:
+		return s.name == parts[0]
+	case 2:
+		return s.name == parts[1] && (s.recv == parts[0] || s.recv == "" && s.pkg.name == parts[0])
+	case 3:
+		return s.pkg.name == parts[0] && s.recv == parts[1] && s.name == parts[2]
+	}
+	return false
+}
+
+func (s goSymbol) qualifiedName() string {
+	if s.recv != "" {
+		return s.recv + "." + s.name
+	}
+	return s.name
+}
+
+func (m *goModule) lines(node ast.Node) (int, int) {
+	return m.fset.Position(node.Pos()).Line, m.fset.Position(node.End()).Line
+}
+
+// lineRange formata um intervalo de linhas como "12-20", ou "12" se for uma só.
+func lineRange(start, end int) string {
+	if start == end {
+		return fmt.Sprint(start)
+	}
+	return fmt.Sprintf("%d-%d", start, end)
+}
+
+func goSymbols(input json.RawMessage) (string, error) {
+	var typedInput GoSymbolsInput
+	if err := json.Unmarshal(input, &typedInput); err != nil {
+		return "", fmt.Errorf("JSON inválido para argumentos: %w", err)
+	}
+	switch typedInput.Action {
+	case "list":
+	case "definition", "references":
+		if strings.TrimSpace(typedInput.Symbol) == "" {
+			return "", errors.New("argumento inválido. 'symbol' é obrigatório")
+		}
+	case "":
+		return "", errors.New("argumento inválido. 'action' é obrigatório")
+	default:
+		return "", fmt.Errorf("ação inválida '%s' (use list, definition ou references)", typedInput.Action)
+	}
+
+	w, err := CurrentWorkspace()
+	if err != nil {
+		return "", err
+	}
+	target, err := w.ResolveRead(typedInput.Path)
+	if err != nil {
+		return "", err
+	}
+	info, err := os.Stat(target)
+	if err != nil {
+		return "", fmt.Errorf("erro ao acessar '%s': %w", typedInput.Path, err)
+	}
+	dir := target
+	if !info.IsDir() {
+		if !strings.HasSuffix(target, ".go") {
+			return "", fmt.Errorf("'%s' não é um arquivo Go", typedInput.Path)
+		}
+		dir = filepath.Dir(target)
+	}
+
+	m, err := loadGoModule(w, dir)
+	if err != nil {
+		return "", err
+	}
+	display := func(abs string) string {
+		rel, err := filepath.Rel(w.Root(), abs)
+		if err != nil || !within(abs, w.Root()) {
+			return abs
+		}
+		return filepath.ToSlash(rel)
+	}
+
+	switch typedInput.Action {
+	case "list":
+		return m.listSymbols(target, info.IsDir(), typedInput, display), nil
+	case "definition":
+		return m.findDefinitions(typedInput, display), nil
+	default:
+		return m.findReferences(typedInput, display), nil
+	}
+}
+
+// packageFiles retorna os arquivos do pacote, com os testes se pedido.
+func (pkg *goPackage) packageFiles(includeTests bool) []*ast.File {
+	files := pkg.files
+	if includeTests {
+		files = append(append(append([]*ast.File{}, files...), pkg.tests...), pkg.xtests...)
+	}
+	return files
+}
+
+// listSymbols lista as declarações de um arquivo ou do pacote de um diretório.
+func (m *goModule) listSymbols(target string, isDir bool, in GoSymbolsInput, display func(string) string) string {
+	dir := target
+	if !isDir {
+		dir = filepath.Dir(target)
+	}
+	var pkg *goPackage
+	for _, p := range m.packages {
+		if p.dir == dir {
+			pkg = p
+		}
+	}
+	if pkg == nil {
+		return fmt.Sprintf("Nenhum arquivo Go encontrado em '%s'.", display(target))
+	}
+
+	files := pkg.packageFiles(in.IncludeTests || !isDir && strings.HasSuffix(target, "_test.go"))
+	sort.Slice(files, func(i, j int) bool {
+		return m.fset.Position(files[i].Pos()).Filename < m.fset.Position(files[j].Pos()).Filename
+	})
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "package %s (%s)\n", pkg.name, pkg.importPath)
+	count := 0
+	for _, f := range files {
+		filename := m.fset.Position(f.Pos()).Filename
+		if !isDir && filename != target {
+			continue
+		}
+		var lines []string
+		for _, s := range m.symbols(pkg, f, false) {
+			if in.Exported && !ast.IsExported(s.name) {
+				continue
+			}
+			lines = append(lines, fmt.Sprintf("  %-9s %s", lineRange(m.lines(s.node)), s.sig))
+		}
+		if len(lines) == 0 {
+			continue
+		}
+		if count >= maxSymbolListEntries {
+			fmt.Fprintf(&b, "[listagem interrompida em %d declarações; use \"path\" para um arquivo]\n", maxSymbolListEntries)
+			break
+		}
+		count += len(lines)
+		fmt.Fprintf(&b, "\n%s\n%s\n", display(filename), strings.Join(lines, "\n"))
+	}
+	if count == 0 {
+		b.WriteString("\nNenhuma declaração encontrada.\n")
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// lookup procura as declarações que correspondem ao símbolo em todo o módulo.
+func (m *goModule) lookup(in GoSymbolsInput) []goSymbol {
+	var found []goSymbol
+	for _, importPath := range m.order {
+		pkg := m.packages[importPath]
+		for _, f := range pkg.packageFiles(in.IncludeTests) {
+			for _, s := range m.symbols(pkg, f, true) {
+				if s.matches(in.Symbol) {
+					found = append(found, s)
+				}
+			}
+		}
+	}
+	return found
+}
+
+// findDefinitions mostra o código das declarações do símbolo, com a documentação.
+func (m *goModule) findDefinitions(in GoSymbolsInput, display func(string) string) string {
+	found := m.lookup(in)
+	if len(found) == 0 {
+		return fmt.Sprintf("Nenhuma definição encontrada para '%s'.", in.Symbol)
+	}
+
+	var blocks []string
+	for i, s := range found {
+		if i == maxSymbolDefinitions {
+			blocks = append(blocks, fmt.Sprintf("[mais %d definição(ões); qualifique o símbolo, ex: Tipo.Metodo ou pacote.Nome]", len(found)-i))
+			break
+		}
+		pos := m.fset.Position(s.node.Pos())
+		_, end := m.lines(s.node)
+		startNode := s.node.Pos()
+		if s.doc != nil && s.doc.Pos() < startNode {
+			startNode = s.doc.Pos()
+		}
+		start := m.fset.Position(startNode)
+
+		src, err := os.ReadFile(pos.Filename)
+		if err != nil {
+			continue
+		}
+		// Começa no início da linha para manter a indentação de campos e métodos.
+		code := string(src[start.Offset-(start.Column-1) : m.fset.Position(s.node.End()).Offset])
+		lines := strings.Split(code, "\n")
+		if len(lines) > maxDefinitionLines {
+			lines = append(lines[:maxDefinitionLines], fmt.Sprintf("[declaração truncada em %d linhas; use read_file com \"offset\": %d]", maxDefinitionLines, start.Line+maxDefinitionLines))
+		}
+		header := fmt.Sprintf("%s:%s (%s %s, package %s)", display(pos.Filename), lineRange(pos.Line, end), s.kind, s.qualifiedName(), s.pkg.name)
+		blocks = append(blocks, header+"\n"+strings.Join(lines, "\n"))
+	}
+	return strings.Join(blocks, "\n\n")
+}
+
+// findReferences lista os usos do símbolo em todo o módulo, resolvidos pela checagem de
+// tipos: um método Close de outro tipo não conta como referência.
+func (m *goModule) findReferences(in GoSymbolsInput, display func(string) string) string {
+	found := m.lookup(in)
+	if len(found) == 0 {
+		return fmt.Sprintf("Nenhuma definição encontrada para '%s'.", in.Symbol)
+	}
+	targets := make(map[token.Pos]bool)
+	var defs []string
+	for _, s := range found {
+		targets[s.ident.Pos()] = true
+		pos := m.fset.Position(s.ident.Pos())
+		defs = append(defs, fmt.Sprintf("%s %s (%s:%d)", s.kind, s.qualifiedName(), display(pos.Filename), pos.Line))
+	}
+
+	m.checkAll(in.IncludeTests)
+	seen := make(map[token.Pos]bool)
+	var refs []token.Position
+	for _, info := range m.infos {
+		for id, obj := range info.Uses {
+			if obj == nil || !targets[obj.Pos()] || seen[id.Pos()] {
+				continue
+			}
+			seen[id.Pos()] = true
+			refs = append(refs, m.fset.Position(id.Pos()))
+		}
+	}
+	sort.Slice(refs, func(i, j int) bool {
+		if refs[i].Filename != refs[j].Filename {
+			return refs[i].Filename < refs[j].Filename
+		}
+		if refs[i].Line != refs[j].Line {
+			return refs[i].Line < refs[j].Line
+		}
+		return refs[i].Column < refs[j].Column
+	})
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d referência(s) a %s\n", len(refs), strings.Join(defs, ", "))
+	sources := make(map[string][]string)
+	for i, ref := range refs {
+		if i == maxSymbolReferences {
+			fmt.Fprintf(&b, "[mais %d referência(s) omitidas]\n", len(refs)-i)
+			break
+		}
+		if _, ok := sources[ref.Filename]; !ok {
+			data, _ := os.ReadFile(ref.Filename)
+			sources[ref.Filename] = strings.Split(string(data), "\n")
+		}
+		text := ""
+		if lines := sources[ref.Filename]; ref.Line-1 < len(lines) {
+			text = truncateRunes(lines[ref.Line-1], maxMatchLineBytes)
+		}
+		fmt.Fprintf(&b, "%s:%d:%d: %s\n", display(ref.Filename), ref.Line, ref.Column, text)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// GoSymbolsDef é a definição da ferramenta go_symbols.
+var GoSymbolsDef = toolkit.ToolDefinition{
+	Name:        "go_symbols",
+	Description: `Navega por código Go sem ler arquivos inteiros. Ações: "list" lista as declarações (funções, métodos, tipos, constantes, variáveis) de um arquivo ou pacote com assinatura e linhas; "definition" mostra o código e a documentação de um símbolo; "references" lista os usos de um símbolo no módulo, resolvidos por tipo. "symbol" aceita Nome, Tipo.Metodo, Tipo.Campo ou pacote.Nome. Opcionais: "path" (arquivo ou diretório; padrão: raiz do workspace), "exported": true, "include_tests": true. Exemplo: {"action": "references", "symbol": "Agent.Run"}`,
+	Function:    goSymbols,
+}
@@ -0,0 +1,191 @@
+package builtin
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// setupGoModule cria um workspace com um módulo Go pequeno no diretório "loja" e
+// retorna esse caminho, relativo à raiz.
+func setupGoModule(t *testing.T) string {
+	t.Helper()
+	files := map[string]string{
+		"go.mod": "module example.com/loja\n\ngo 1.21\n",
+		"loja.go": `package loja
+
+import "example.com/loja/estoque"
+
+// Pedido é um pedido de compra.
+type Pedido struct {
+	ID    int
+	Itens []string // Nomes dos produtos
+}
+
+// Total soma os itens do pedido.
+func (p *Pedido) Total() int {
+	return len(p.Itens)
+}
+
+// Limite de itens por pedido.
+const Limite = 10
+
+func novo() *Pedido {
+	e := estoque.Novo()
+	e.Close()
+	return &Pedido{ID: 1}
+}
+`,
+		"loja_test.go": `package loja
+
+import "testing"
+
+func TestTotal(t *testing.T) {
+	p := novo()
+	if p.Total() != 0 {
+		t.Fail()
+	}
+}
+`,
+		"estoque/estoque.go": `package estoque
+
+// Estoque guarda os produtos.
+type Estoque struct{}
+
+// Novo cria um estoque vazio.
+func Novo() *Estoque { return &Estoque{} }
+
+// Close libera o estoque.
+func (e *Estoque) Close() {}
+
+type Arquivo struct{}
+
+func (a Arquivo) Close() {}
+
+func usa() {
+	Arquivo{}.Close()
+	Novo().Close()
+}
+`,
+	}
+	module := make(map[string]string, len(files))
+	for name, content := range files {
+		module["loja/"+name] = content
+	}
+	newTestWorkspace(t, module)
+	return "loja"
+}
+
+// TestGoSymbols testa listagem, definição e referências.
+func TestGoSymbols(t *testing.T) {
+	mod := setupGoModule(t)
+
+	testCases := []struct {
+		name          string
+		input         GoSymbolsInput
+		expected      string
+		expectedError string
+	}{
+		{
+			name:  "Lista um arquivo",
+			input: GoSymbolsInput{Action: "list", Path: mod + "/loja.go"},
+			expected: "package loja (example.com/loja)\n\n" + mod + "/loja.go\n" +
+				"  6-9       type Pedido struct\n" +
+				"  12-14     func (p *Pedido) Total() int\n" +
+				"  17        const Limite = 10\n" +
+				"  19-23     func novo() *Pedido",
+		},
+		{
+			name:     "Lista só exportados do pacote",
+			input:    GoSymbolsInput{Action: "list", Path: mod + "/estoque", Exported: true},
+			expected: "package estoque (example.com/loja/estoque)\n\n" + mod + "/estoque/estoque.go\n  4         type Estoque struct\n  7         func Novo() *Estoque\n  10        func (e *Estoque) Close()\n  12        type Arquivo struct\n  14        func (a Arquivo) Close()",
+		},
+		{
+			name:     "Definição de método",
+			input:    GoSymbolsInput{Action: "definition", Path: mod, Symbol: "Pedido.Total"},
+			expected: mod + "/loja.go:12-14 (method Pedido.Total, package loja)\n// Total soma os itens do pedido.\nfunc (p *Pedido) Total() int {\n\treturn len(p.Itens)\n}",
+		},
+		{
+			name:     "Definição de campo",
+			input:    GoSymbolsInput{Action: "definition", Path: mod, Symbol: "Pedido.Itens"},
+			expected: mod + "/loja.go:8 (field Pedido.Itens, package loja)\n\tItens []string",
+		},
+		{
+			name:     "Definição qualificada pelo pacote",
+			input:    GoSymbolsInput{Action: "definition", Path: mod, Symbol: "estoque.Novo"},
+			expected: mod + "/estoque/estoque.go:7 (func Novo, package estoque)\n// Novo cria um estoque vazio.\nfunc Novo() *Estoque { return &Estoque{} }",
+		},
+		{
+			name:     "Símbolo inexistente",
+			input:    GoSymbolsInput{Action: "definition", Path: mod, Symbol: "Nada"},
+			expected: "Nenhuma definição encontrada para 'Nada'.",
+		},
+		{
+			name:  "Referências resolvidas por tipo",
+			input: GoSymbolsInput{Action: "references", Path: mod, Symbol: "Estoque.Close"},
+			expected: "2 referência(s) a method Estoque.Close (" + mod + "/estoque/estoque.go:10)\n" +
+				mod + "/estoque/estoque.go:18:9: Novo().Close()\n" +
+				mod + "/loja.go:21:4: e.Close()",
+		},
+		{
+			name:     "Referências sem testes",
+			input:    GoSymbolsInput{Action: "references", Path: mod, Symbol: "Pedido.Total"},
+			expected: "0 referência(s) a method Pedido.Total (" + mod + "/loja.go:12)",
+		},
+		{
+			name:     "Referências com testes",
+			input:    GoSymbolsInput{Action: "references", Path: mod, Symbol: "novo", IncludeTests: true},
+			expected: "1 referência(s) a func novo (" + mod + "/loja.go:19)\n" + mod + "/loja_test.go:6:7: p := novo()",
+		},
+		{
+			name:          "Sem símbolo",
+			input:         GoSymbolsInput{Action: "references", Path: mod},
+			expectedError: "'symbol' é obrigatório",
+		},
+		{
+			name:          "Ação inválida",
+			input:         GoSymbolsInput{Action: "rename", Path: mod},
+			expectedError: "ação inválida 'rename'",
+		},
+		{
+			name:          "Arquivo que não é Go",
+			input:         GoSymbolsInput{Action: "list", Path: mod + "/go.mod"},
+			expectedError: "não é um arquivo Go",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rawInput, _ := json.Marshal(tc.input)
+			result, err := goSymbols(rawInput)
+			if tc.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedError) {
+					t.Fatalf("goSymbols() erro = %v, esperado conter %q", err, tc.expectedError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("goSymbols() erro inesperado: %v", err)
+			}
+			if result != tc.expected {
+				t.Errorf("goSymbols() =\n%s\nesperado\n%s", result, tc.expected)
+			}
+		})
+	}
+}
+
+// TestGuessPackageName testa o nome deduzido de pacotes de fora do módulo.
+func TestGuessPackageName(t *testing.T) {
+	testCases := map[string]string{
+		"fmt":                        "fmt",
+		"math/rand/v2":               "rand",
+		"gopkg.in/yaml.v3":           "yaml",
+		"github.com/go-chi/chi/v5":   "chi",
+		"github.com/mattn/go-isatty": "isatty",
+	}
+	for path, expected := range testCases {
+		if got := guessPackageName(path); got != expected {
+			t.Errorf("guessPackageName(%q) = %q, esperado %q", path, got, expected)
+		}
+	}
+}
@@ -0,0 +1,378 @@
+package builtin
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/matheusbuniotto/goagent/pkg/toolkit"
+)
+
+// maxTestOutputBytes limita a saída guardada de cada teste que falhou.
+const maxTestOutputBytes = 4000
+
+var (
+	// diagnosticRegex reconhece "arquivo.go:linha:coluna: mensagem" do compilador e do vet.
+	diagnosticRegex = regexp.MustCompile(`^(?:vet: )?(\S+?\.go):(\d+)(?::(\d+))?: (.*)$`)
+	coverageRegex   = regexp.MustCompile(`coverage: ([\d.]+)% of statements`)
+)
+
+// GoToolInput define os argumentos de go_tool.
+type GoToolInput struct {
+	Action   string   `json:"action"`             // build, vet ou test
+	Packages []string `json:"packages,omitempty"` // Padrão: ./...
+	Run      string   `json:"run,omitempty"`      // Regex de -run para filtrar testes
+	Coverage bool     `json:"coverage,omitempty"` // Mede a cobertura dos testes
+	Dir      string   `json:"dir,omitempty"`      // Diretório do módulo, relativo ao workspace
+	Timeout  int      `json:"timeout,omitempty"`  // Tempo limite em segundos
+}
+
+// GoDiagnostic é um erro de compilação ou um aviso do vet.
+type GoDiagnostic struct {
+	Package string `json:"package,omitempty"`
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+// GoTestFailure é um teste que falhou, com a saída que ele produziu.
+type GoTestFailure struct {
+	Name   string `json:"name"`
+	Output string `json:"output,omitempty"`
+}
+
+// GoPackageResult é o resultado dos testes de um pacote.
+type GoPackageResult struct {
+	Package  string          `json:"package"`
+	Status   string          `json:"status"` // ok, fail, build-fail ou skip (sem testes)
+	Elapsed  float64         `json:"elapsed,omitempty"`
+	Coverage *float64        `json:"coverage,omitempty"`
+	Failed   []GoTestFailure `json:"failed_tests,omitempty"`
+	Output   string          `json:"output,omitempty"` // Saída do pacote quando ele falha fora de um teste
+}
+
+// GoToolResult é a resposta de go_tool, serializada como JSON compacto.
+type GoToolResult struct {
+	Action      string            `json:"action"`
+	OK          bool              `json:"ok"`
+	ExitCode    int               `json:"exit_code"`
+	TimedOut    bool              `json:"timed_out,omitempty"`
+	Summary     string            `json:"summary"`
+	Diagnostics []GoDiagnostic    `json:"diagnostics,omitempty"`
+	Packages    []GoPackageResult `json:"packages,omitempty"`
+	Messages    []string          `json:"messages,omitempty"` // Linhas que não são diagnósticos, ex: erros do próprio go
+}
+
+// diagnosticParser acumula diagnósticos a partir da saída em texto do go build e do go vet.
+type diagnosticParser struct {
+	pkg      string
+	diags    []GoDiagnostic
+	messages []string
+	seen     map[string]bool
+}
+
+func (p *diagnosticParser) parse(text string) {
+	for _, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		p.line(line)
+	}
+}
+
+func (p *diagnosticParser) line(line string) {
+	if p.seen == nil {
+		p.seen = make(map[string]bool)
+	}
+	switch {
+	case strings.TrimSpace(line) == "" || strings.HasPrefix(line, "# ["):
+		return
+	case strings.HasPrefix(line, "# "):
+		// "# pacote" ou "# pacote [pacote.test]" abre os diagnósticos de um pacote.
+		p.pkg, _, _ = strings.Cut(strings.TrimPrefix(line, "# "), " ")
+		return
+	case strings.HasPrefix(line, "\t") && len(p.diags) > 0:
+		// Continuação da mensagem anterior, ex: "have (int)" / "want (string)".
+		last := &p.diags[len(p.diags)-1]
+		last.Message += "\n" + strings.TrimSpace(line)
+		return
+	}
+
+	if m := diagnosticRegex.FindStringSubmatch(line); m != nil {
+		d := GoDiagnostic{Package: p.pkg, File: strings.TrimPrefix(m[1], "./"), Message: m[4]}
+		d.Line, _ = strconv.Atoi(m[2])
+		d.Column, _ = strconv.Atoi(m[3])
+		key := fmt.Sprintf("%s:%d:%d:%s", d.File, d.Line, d.Column, d.Message)
+		if !p.seen[key] {
+			p.seen[key] = true
+			p.diags = append(p.diags, d)
+		}
+		return
+	}
+	if !p.seen[line] {
+		p.seen[line] = true
+		p.messages = append(p.messages, line)
+	}
+}
+
+// testEvent é uma linha da saída de `go test -json`.
+type testEvent struct {
+	Action      string
+	Package     string
+	ImportPath  string
+	Test        string
+	Output      string
+	Elapsed     float64
+	FailedBuild string
+}
+
+// testParser monta os resultados por pacote a partir dos eventos de `go test -json`.
+type testParser struct {
+	diag     *diagnosticParser
+	order    []string
+	packages map[string]*GoPackageResult
+	outputs  map[string]*cappedBuffer // Saída por pacote e teste
+}
+
+func (p *testParser) parse(stdout []byte) {
+	scanner := bufio.NewScanner(bytes.NewReader(stdout))
+	scanner.Buffer(make([]byte, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		var e testEvent
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil || e.Action == "" {
+			p.diag.line(scanner.Text())
+			continue
+		}
+		p.event(e)
+	}
+}
+
+func (p *testParser) event(e testEvent) {
+	switch e.Action {
+	case "build-output":
+		p.diag.line(strings.TrimRight(e.Output, "\n"))
+		return
+	case "build-fail":
+		return
+	}
+
+	pkg := p.packages[e.Package]
+	if pkg == nil {
+		pkg = &GoPackageResult{Package: e.Package}
+		p.packages[e.Package] = pkg
+		p.order = append(p.order, e.Package)
+	}
+	key := e.Package + "\x00" + e.Test
+	switch e.Action {
+	case "output":
+		if e.Test == "" {
+			if m := coverageRegex.FindStringSubmatch(e.Output); m != nil {
+				if c, err := strconv.ParseFloat(m[1], 64); err == nil {
+					pkg.Coverage = &c
+				}
+			}
+		}
+		if isTestFrame(e.Output) {
+			return
+		}
+		if p.outputs[key] == nil {
+			p.outputs[key] = &cappedBuffer{max: maxTestOutputBytes}
+		}
+		_, _ = p.outputs[key].Write([]byte(e.Output))
+	case "fail":
+		if e.Test != "" {
+			pkg.Failed = append(pkg.Failed, GoTestFailure{Name: e.Test, Output: p.output(key)})
+			return
+		}
+		pkg.Status, pkg.Elapsed = "fail", e.Elapsed
+		if e.FailedBuild != "" {
+			pkg.Status = "build-fail"
+		} else if len(pkg.Failed) == 0 {
+			pkg.Output = p.output(key)
+		}
+	case "pass", "skip":
+		if e.Test == "" {
+			pkg.Status, pkg.Elapsed = map[string]string{"pass": "ok", "skip": "skip"}[e.Action], e.Elapsed
+		}
+	}
+}
+
+// isTestFrame indica as linhas de moldura do go test, que não ajudam a entender a falha.
+func isTestFrame(line string) bool {
+	for _, prefix := range []string{"=== RUN", "=== PAUSE", "=== CONT", "=== NAME", "--- FAIL", "--- PASS", "--- SKIP", "FAIL\t", "ok  \t", "PASS\n", "FAIL\n", "coverage: "} {
+		if strings.HasPrefix(line, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *testParser) output(key string) string {
+	if b := p.outputs[key]; b != nil {
+		return strings.TrimRight(b.String(), "\n")
+	}
+	return ""
+}
+
+// results retorna os pacotes na ordem em que apareceram. Um teste que falhou só porque
+// um subteste falhou, sem saída própria, é omitido.
+func (p *testParser) results() []GoPackageResult {
+	var results []GoPackageResult
+	for _, name := range p.order {
+		pkg := *p.packages[name]
+		var failed []GoTestFailure
+		for _, f := range pkg.Failed {
+			if f.Output == "" && hasFailedSubtest(pkg.Failed, f.Name) {
+				continue
+			}
+			failed = append(failed, f)
+		}
+		pkg.Failed = failed
+		results = append(results, pkg)
+	}
+	return results
+}
+
+func hasFailedSubtest(failed []GoTestFailure, name string) bool {
+	for _, f := range failed {
+		if strings.HasPrefix(f.Name, name+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// goToolArgs monta a linha de comando do go para a ação pedida.
+func goToolArgs(in GoToolInput) ([]string, error) {
+	packages := in.Packages
+	if len(packages) == 0 {
+		packages = []string{"./..."}
+	}
+	for _, p := range packages {
+		if strings.HasPrefix(p, "-") {
+			return nil, fmt.Errorf("pacote inválido '%s': use só caminhos ou padrões de pacote", p)
+		}
+	}
+
+	switch in.Action {
+	case "build":
+		// A saída vai para o dispositivo nulo: só interessa se compila.
+		return append([]string{"go", "build", "-o", os.DevNull}, packages...), nil
+	case "vet":
+		return append([]string{"go", "vet"}, packages...), nil
+	case "test":
+		args := []string{"go", "test", "-json"}
+		if in.Run != "" {
+			args = append(args, "-run="+in.Run)
+		}
+		if in.Coverage {
+			args = append(args, "-cover")
+		}
+		return append(args, packages...), nil
+	case "":
+		return nil, errors.New("argumento inválido. 'action' é obrigatório")
+	default:
+		return nil, fmt.Errorf("ação inválida '%s' (use build, vet ou test)", in.Action)
+	}
+}
+
+func goTool(ctx context.Context, input json.RawMessage) (string, error) {
+	var typedInput GoToolInput
+	if err := json.Unmarshal(input, &typedInput); err != nil {
+		return "", fmt.Errorf("JSON inválido para argumentos: %w", err)
+	}
+	argv, err := goToolArgs(typedInput)
+	if err != nil {
+		return "", err
+	}
+	if rule := CurrentCommandPolicy().Denied(argv); rule != "" {
+		return "", fmt.Errorf("comando bloqueado pela regra '%s'", rule)
+	}
+
+	w, err := CurrentWorkspace()
+	if err != nil {
+		return "", err
+	}
+	dir, err := w.Resolve(typedInput.Dir)
+	if err != nil {
+		return "", err
+	}
+
+	var stdout, stderr bytes.Buffer
+	res, err := execInWorkspace(ctx, dir, argv, commandTimeout(typedInput.Timeout), &stdout, &stderr)
+	if err != nil {
+		return "", err
+	}
+
+	result := GoToolResult{Action: typedInput.Action, OK: res.exitCode == 0, ExitCode: res.exitCode, TimedOut: res.timedOut}
+	diag := &diagnosticParser{}
+	if typedInput.Action == "test" {
+		tests := &testParser{diag: diag, packages: make(map[string]*GoPackageResult), outputs: make(map[string]*cappedBuffer)}
+		tests.parse(stdout.Bytes())
+		diag.parse(stderr.String())
+		for _, pkg := range tests.results() {
+			// Pacotes aprovados só interessam pela cobertura.
+			if pkg.Status == "fail" || pkg.Status == "build-fail" || (typedInput.Coverage && pkg.Status == "ok") {
+				result.Packages = append(result.Packages, pkg)
+			}
+		}
+		result.Summary = summarizeTests(tests.results())
+	} else {
+		diag.parse(stdout.String())
+		diag.parse(stderr.String())
+	}
+	result.Diagnostics, result.Messages = diag.diags, diag.messages
+
+	if result.Summary == "" {
+		result.Summary = fmt.Sprintf("go %s: %d diagnóstico(s)", typedInput.Action, len(result.Diagnostics))
+		if result.OK {
+			result.Summary = fmt.Sprintf("go %s sem erros", typedInput.Action)
+		}
+	}
+	if res.timedOut {
+		result.Summary += fmt.Sprintf("; tempo limite excedido após %s", res.elapsed)
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return "", fmt.Errorf("erro ao serializar o resultado: %w", err)
+	}
+	return string(data), nil
+}
+
+// summarizeTests resume os pacotes por status e conta os testes que falharam.
+func summarizeTests(packages []GoPackageResult) string {
+	counts := make(map[string]int)
+	failed := 0
+	for _, pkg := range packages {
+		counts[pkg.Status]++
+		failed += len(pkg.Failed)
+	}
+	parts := []string{fmt.Sprintf("%d ok", counts["ok"])}
+	if n := counts["fail"]; n > 0 {
+		parts = append(parts, fmt.Sprintf("%d com falha", n))
+	}
+	if n := counts["build-fail"]; n > 0 {
+		parts = append(parts, fmt.Sprintf("%d sem compilar", n))
+	}
+	if n := counts["skip"]; n > 0 {
+		parts = append(parts, fmt.Sprintf("%d sem testes", n))
+	}
+	summary := fmt.Sprintf("%d pacote(s): %s", len(packages), strings.Join(parts, ", "))
+	if failed > 0 {
+		summary += fmt.Sprintf("; %d teste(s) falharam", failed)
+	}
+	return summary
+}
+
+// GoToolDef é a definição da ferramenta go_tool.
+var GoToolDef = toolkit.ToolDefinition{
+	Name:            "go_tool",
+	Description:     `Roda go build, go vet ou go test no workspace e retorna JSON compacto: erros de compilação e avisos do vet com arquivo, linha e coluna; testes que falharam com a saída de cada um; cobertura por pacote. Prefira esta ferramenta a run_command para Go. Opcionais: "packages" (padrão ["./..."]), "run" (regex de testes), "coverage": true, "dir" (diretório do módulo), "timeout" (segundos, padrão 120). Exemplo: {"action": "test", "packages": ["./internal/..."], "run": "TestParse"}`,
+	ContextFunction: goTool,
+}
@@ -0,0 +1,203 @@
+package builtin
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestDiagnosticParser testa a leitura dos erros do compilador e do vet.
+func TestDiagnosticParser(t *testing.T) {
+	output := "# example.com/app\n" +
+		"./main.go:5:2: declared and not used: x\n" +
+		"./main.go:9:9: cannot use s (variable of type string) as int value in return statement\n" +
+		"# example.com/app\n# [example.com/app]\n" +
+		"vet: ./main.go:5:2: declared and not used: x\n" +
+		"# example.com/app/util [example.com/app/util.test]\n" +
+		"util/util.go:3:1: missing return\n" +
+		"\thave ()\n" +
+		"go: warning: \"./nada/...\" matched no packages\n"
+
+	p := &diagnosticParser{}
+	p.parse(output)
+
+	expected := []GoDiagnostic{
+		{Package: "example.com/app", File: "main.go", Line: 5, Column: 2, Message: "declared and not used: x"},
+		{Package: "example.com/app", File: "main.go", Line: 9, Column: 9, Message: "cannot use s (variable of type string) as int value in return statement"},
+		{Package: "example.com/app/util", File: "util/util.go", Line: 3, Column: 1, Message: "missing return\nhave ()"},
+	}
+	if !reflect.DeepEqual(p.diags, expected) {
+		t.Errorf("diagnósticos = %+v, esperado %+v", p.diags, expected)
+	}
+	if want := []string{`go: warning: "./nada/..." matched no packages`}; !reflect.DeepEqual(p.messages, want) {
+		t.Errorf("mensagens = %q, esperado %q", p.messages, want)
+	}
+}
+
+// TestTestParser testa a leitura dos eventos de `go test -json`.
+func TestTestParser(t *testing.T) {
+	events := []string{
+		`{"Action":"start","Package":"ex/a"}`,
+		`{"Action":"run","Package":"ex/a","Test":"TestA"}`,
+		`{"Action":"output","Package":"ex/a","Test":"TestA","Output":"=== RUN   TestA\n"}`,
+		`{"Action":"output","Package":"ex/a","Test":"TestA/sub","Output":"=== RUN   TestA/sub\n"}`,
+		`{"Action":"output","Package":"ex/a","Test":"TestA/sub","Output":"    a_test.go:3: esperado 2, obtido 3\n"}`,
+		`{"Action":"output","Package":"ex/a","Test":"TestA/sub","Output":"--- FAIL: TestA/sub (0.00s)\n"}`,
+		`{"Action":"fail","Package":"ex/a","Test":"TestA/sub"}`,
+		`{"Action":"output","Package":"ex/a","Test":"TestA","Output":"--- FAIL: TestA (0.00s)\n"}`,
+		`{"Action":"fail","Package":"ex/a","Test":"TestA"}`,
+		`{"Action":"output","Package":"ex/a","Output":"FAIL\n"}`,
+		`{"Action":"output","Package":"ex/a","Output":"coverage: 42.5% of statements\n"}`,
+		`{"Action":"fail","Package":"ex/a","Elapsed":0.25}`,
+		`{"Action":"output","Package":"ex/b","Output":"ok  \tex/b\t0.01s\tcoverage: 100.0% of statements\n"}`,
+		`{"Action":"pass","Package":"ex/b","Elapsed":0.01}`,
+		`{"Action":"skip","Package":"ex/c"}`,
+		`{"ImportPath":"ex/d [ex/d.test]","Action":"build-output","Output":"# ex/d [ex/d.test]\n"}`,
+		`{"ImportPath":"ex/d [ex/d.test]","Action":"build-output","Output":"d/d.go:2:11: undefined: y\n"}`,
+		`{"ImportPath":"ex/d [ex/d.test]","Action":"build-fail"}`,
+		`{"Action":"fail","Package":"ex/d","FailedBuild":"ex/d [ex/d.test]"}`,
+	}
+
+	diag := &diagnosticParser{}
+	p := &testParser{diag: diag, packages: make(map[string]*GoPackageResult), outputs: make(map[string]*cappedBuffer)}
+	p.parse([]byte(strings.Join(events, "\n")))
+
+	a, b := 42.5, 100.0
+	expected := []GoPackageResult{
+		{Package: "ex/a", Status: "fail", Elapsed: 0.25, Coverage: &a, Failed: []GoTestFailure{{Name: "TestA/sub", Output: "    a_test.go:3: esperado 2, obtido 3"}}},
+		{Package: "ex/b", Status: "ok", Elapsed: 0.01, Coverage: &b},
+		{Package: "ex/c", Status: "skip"},
+		{Package: "ex/d", Status: "build-fail"},
+	}
+	if got := p.results(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("results() = %+v, esperado %+v", got, expected)
+	}
+	if len(diag.diags) != 1 || diag.diags[0].Package != "ex/d" || diag.diags[0].Message != "undefined: y" {
+		t.Errorf("diagnósticos = %+v", diag.diags)
+	}
+	if got, want := summarizeTests(p.results()), "4 pacote(s): 1 ok, 1 com falha, 1 sem compilar, 1 sem testes; 1 teste(s) falharam"; got != want {
+		t.Errorf("summarizeTests() = %q, esperado %q", got, want)
+	}
+}
+
+// TestGoToolArgs testa a montagem da linha de comando.
+func TestGoToolArgs(t *testing.T) {
+	testCases := []struct {
+		name          string
+		input         GoToolInput
+		expected      []string
+		expectedError string
+	}{
+		{name: "Build", input: GoToolInput{Action: "build"}, expected: []string{"go", "build", "-o", os.DevNull, "./..."}},
+		{name: "Vet", input: GoToolInput{Action: "vet", Packages: []string{"./cmd/..."}}, expected: []string{"go", "vet", "./cmd/..."}},
+		{name: "Test com filtros", input: GoToolInput{Action: "test", Run: "TestX$", Coverage: true, Packages: []string{"./a"}}, expected: []string{"go", "test", "-json", "-run=TestX$", "-cover", "./a"}},
+		{name: "Sem ação", input: GoToolInput{}, expectedError: "'action' é obrigatório"},
+		{name: "Ação inválida", input: GoToolInput{Action: "run"}, expectedError: "ação inválida 'run'"},
+		{name: "Flag como pacote", input: GoToolInput{Action: "test", Packages: []string{"-exec=rm"}}, expectedError: "pacote inválido"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			args, err := goToolArgs(tc.input)
+			if tc.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedError) {
+					t.Fatalf("goToolArgs() erro = %v, esperado conter %q", err, tc.expectedError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("goToolArgs() erro inesperado: %v", err)
+			}
+			if !reflect.DeepEqual(args, tc.expected) {
+				t.Errorf("goToolArgs() = %q, esperado %q", args, tc.expected)
+			}
+		})
+	}
+}
+
+// TestGoTool roda o go de verdade em um módulo temporário.
+func TestGoTool(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go não encontrado no PATH")
+	}
+	dir := newTestWorkspace(t, map[string]string{
+		"go.mod":        "module example.com/calc\n\ngo 1.21\n",
+		"calc.go":       "package calc\n\nfunc Soma(a, b int) int { return a - b }\n",
+		"calc_test.go":  "package calc\n\nimport \"testing\"\n\nfunc TestSoma(t *testing.T) {\n\tif got := Soma(2, 3); got != 5 {\n\t\tt.Errorf(\"Soma(2, 3) = %d\", got)\n\t}\n}\n\nfunc TestOutro(t *testing.T) {}\n",
+		"ruim/ruim.go":  "package ruim\n\nfunc F() int {\n\tx := 1\n\treturn \"a\"\n}\n",
+		"vet/vet.go":    "package vet\n\nimport \"fmt\"\n\nfunc F() string { return fmt.Sprintf(\"%d\", \"a\") }\n",
+		"ok/ok.go":      "package ok\n\nfunc F() int { return 1 }\n",
+		"ok/ok_test.go": "package ok\n\nimport \"testing\"\n\nfunc TestF(t *testing.T) { F() }\n",
+	})
+
+	run := func(in GoToolInput) GoToolResult {
+		t.Helper()
+		rawInput, _ := json.Marshal(in)
+		out, err := goTool(context.Background(), rawInput)
+		if err != nil {
+			t.Fatalf("goTool() erro inesperado: %v", err)
+		}
+		var result GoToolResult
+		if err := json.Unmarshal([]byte(out), &result); err != nil {
+			t.Fatalf("saída não é JSON: %v\n%s", err, out)
+		}
+		return result
+	}
+
+	t.Run("Build com erros", func(t *testing.T) {
+		result := run(GoToolInput{Action: "build", Packages: []string{"./ruim"}})
+		if result.OK || len(result.Diagnostics) != 2 {
+			t.Fatalf("resultado inesperado: %+v", result)
+		}
+		d := result.Diagnostics[0]
+		if d.File != "ruim/ruim.go" || d.Line != 4 || d.Column != 2 || !strings.Contains(d.Message, "declared and not used") {
+			t.Errorf("diagnóstico inesperado: %+v", d)
+		}
+	})
+
+	t.Run("Build sem erros", func(t *testing.T) {
+		result := run(GoToolInput{Action: "build", Packages: []string{".", "./ok"}})
+		if !result.OK || result.Summary != "go build sem erros" {
+			t.Errorf("resultado inesperado: %+v", result)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "calc")); err == nil {
+			t.Error("go build não deveria gerar binários no workspace")
+		}
+	})
+
+	t.Run("Vet", func(t *testing.T) {
+		result := run(GoToolInput{Action: "vet", Packages: []string{"./vet"}})
+		if result.OK || len(result.Diagnostics) != 1 || result.Diagnostics[0].File != "vet/vet.go" {
+			t.Errorf("resultado inesperado: %+v", result)
+		}
+	})
+
+	t.Run("Testes com falha e cobertura", func(t *testing.T) {
+		result := run(GoToolInput{Action: "test", Packages: []string{".", "./ok"}, Coverage: true})
+		if result.OK || len(result.Packages) != 2 {
+			t.Fatalf("resultado inesperado: %+v", result)
+		}
+		calc := result.Packages[0]
+		if calc.Status != "fail" || len(calc.Failed) != 1 || calc.Failed[0].Name != "TestSoma" || !strings.Contains(calc.Failed[0].Output, "Soma(2, 3) = -1") {
+			t.Errorf("pacote com falha inesperado: %+v", calc)
+		}
+		if ok := result.Packages[1]; ok.Status != "ok" || ok.Coverage == nil || *ok.Coverage != 100 {
+			t.Errorf("pacote aprovado inesperado: %+v", ok)
+		}
+		if !strings.HasSuffix(result.Summary, "1 teste(s) falharam") {
+			t.Errorf("resumo inesperado: %s", result.Summary)
+		}
+	})
+
+	t.Run("Filtro de testes", func(t *testing.T) {
+		result := run(GoToolInput{Action: "test", Packages: []string{"."}, Run: "TestOutro"})
+		if !result.OK || result.Summary != "1 pacote(s): 1 ok" {
+			t.Errorf("resultado inesperado: %+v", result)
+		}
+	})
+}
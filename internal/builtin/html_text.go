@@ -0,0 +1,472 @@
+package builtin
+
+import (
+	"html"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// htmlNode é um nó da árvore montada por parseHTML: um elemento (tag) ou um texto.
+type htmlNode struct {
+	tag      string // Vazio para nós de texto
+	attrs    map[string]string
+	text     string
+	children []*htmlNode
+	parent   *htmlNode
+}
+
+var (
+	// voidElements não têm conteúdo nem tag de fechamento.
+	voidElements = setOf("area", "base", "br", "col", "embed", "hr", "img", "input", "link", "meta", "param", "source", "track", "wbr")
+	// rawTextElements têm conteúdo que não é HTML, lido até a tag de fechamento.
+	rawTextElements = setOf("script", "style", "textarea", "title")
+	// blockElements fecham um <p> aberto, como faz o navegador.
+	blockElements = setOf("address", "article", "aside", "blockquote", "details", "div", "dl", "fieldset", "figure", "footer", "form", "h1", "h2", "h3", "h4", "h5", "h6", "header", "hr", "main", "nav", "ol", "p", "pre", "section", "table", "ul")
+	// implicitClose diz quais elementos abertos uma nova tag fecha implicitamente.
+	implicitClose = map[string]map[string]bool{
+		"li": setOf("li"), "dt": setOf("dt", "dd"), "dd": setOf("dt", "dd"),
+		"tr": setOf("tr", "td", "th"), "td": setOf("td", "th"), "th": setOf("td", "th"),
+		"option": setOf("option"),
+	}
+	// skippedElements não têm conteúdo legível: código, estilos e navegação.
+	skippedElements = setOf("script", "style", "noscript", "template", "svg", "canvas", "iframe", "head", "nav", "aside", "footer", "form", "button", "select", "textarea", "dialog")
+	// boilerplateNames são ids e classes típicos de menus, rodapés e avisos de cookies.
+	boilerplateNames = setOf("nav", "navbar", "navigation", "menu", "sidebar", "breadcrumb", "breadcrumbs", "footer", "cookie", "cookies", "cookie-banner", "skip-link", "toc")
+
+	tagNameRegex = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9-]*`)
+	attrRegex    = regexp.MustCompile(`([^\s"'>/=]+)(?:\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s>]+)))?`)
+	spaceRegex   = regexp.MustCompile(`[\s\x{00a0}]+`)
+	blankRegex   = regexp.MustCompile(`\n{3,}`)
+)
+
+func setOf(items ...string) map[string]bool {
+	set := make(map[string]bool, len(items))
+	for _, item := range items {
+		set[item] = true
+	}
+	return set
+}
+
+// parseHTML monta uma árvore tolerante a HTML malformado: tags sem fechamento são
+// fechadas pela tag pai, e fechamentos sem abertura são ignorados.
+func parseHTML(s string) *htmlNode {
+	root := &htmlNode{tag: "#document"}
+	cur := root
+	appendText := func(text string) {
+		if text != "" {
+			cur.children = append(cur.children, &htmlNode{text: html.UnescapeString(text), parent: cur})
+		}
+	}
+
+	for len(s) > 0 {
+		lt := strings.IndexByte(s, '<')
+		if lt < 0 {
+			appendText(s)
+			break
+		}
+		appendText(s[:lt])
+		s = s[lt:]
+
+		switch {
+		case strings.HasPrefix(s, "<!--"):
+			end := strings.Index(s, "-->")
+			if end < 0 {
+				return root
+			}
+			s = s[end+3:]
+
+		case strings.HasPrefix(s, "<!") || strings.HasPrefix(s, "<?"):
+			end := strings.IndexByte(s, '>')
+			if end < 0 {
+				return root
+			}
+			s = s[end+1:]
+
+		case strings.HasPrefix(s, "</"):
+			end := strings.IndexByte(s, '>')
+			if end < 0 {
+				return root
+			}
+			name := strings.ToLower(strings.TrimSpace(s[2:end]))
+			s = s[end+1:]
+			for n := cur; n != root; n = n.parent {
+				if n.tag == name {
+					cur = n.parent
+					break
+				}
+			}
+
+		default:
+			name := tagNameRegex.FindString(s[1:])
+			if name == "" {
+				appendText("<")
+				s = s[1:]
+				continue
+			}
+			end := tagEnd(s)
+			if end < 0 {
+				return root
+			}
+			attrText := s[1+len(name) : end]
+			selfClosing := strings.HasSuffix(strings.TrimSpace(attrText), "/")
+			s = s[end+1:]
+
+			name = strings.ToLower(name)
+			if blockElements[name] && cur.tag == "p" {
+				cur = cur.parent
+			}
+			for closes := implicitClose[name]; closes[cur.tag]; {
+				cur = cur.parent
+			}
+			node := &htmlNode{tag: name, attrs: parseAttrs(attrText), parent: cur}
+			cur.children = append(cur.children, node)
+
+			switch {
+			case rawTextElements[name]:
+				closeTag := "</" + name
+				end := strings.Index(strings.ToLower(s), closeTag)
+				if end < 0 {
+					end = len(s)
+				}
+				node.children = []*htmlNode{{text: s[:end], parent: node}}
+				if name == "title" || name == "textarea" {
+					node.children[0].text = html.UnescapeString(s[:end])
+				}
+				s = s[end:]
+				if gt := strings.IndexByte(s, '>'); gt >= 0 {
+					s = s[gt+1:]
+				}
+			case !voidElements[name] && !selfClosing:
+				cur = node
+			}
+		}
+	}
+	return root
+}
+
+// tagEnd acha o '>' que fecha a tag, ignorando os que estão dentro de aspas.
+func tagEnd(s string) int {
+	var quote byte
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '>':
+			return i
+		}
+	}
+	return -1
+}
+
+func parseAttrs(s string) map[string]string {
+	attrs := make(map[string]string)
+	for _, m := range attrRegex.FindAllStringSubmatch(s, -1) {
+		attrs[strings.ToLower(m[1])] = html.UnescapeString(m[2] + m[3] + m[4])
+	}
+	return attrs
+}
+
+// find retorna o primeiro elemento com a tag, em profundidade.
+func (n *htmlNode) find(tag string) *htmlNode {
+	if n.tag == tag {
+		return n
+	}
+	for _, c := range n.children {
+		if found := c.find(tag); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// textContent concatena todo o texto do nó, sem alterar espaços.
+func (n *htmlNode) textContent() string {
+	if n.tag == "" {
+		return n.text
+	}
+	var b strings.Builder
+	for _, c := range n.children {
+		b.WriteString(c.textContent())
+	}
+	return b.String()
+}
+
+// isBoilerplate identifica elementos de navegação pelo papel ARIA, id ou classe.
+func (n *htmlNode) isBoilerplate() bool {
+	if _, hidden := n.attrs["hidden"]; hidden || skippedElements[n.tag] || n.attrs["aria-hidden"] == "true" {
+		return true
+	}
+	switch n.attrs["role"] {
+	case "navigation", "banner", "contentinfo", "complementary", "search":
+		return true
+	}
+	for _, name := range append(strings.Fields(n.attrs["class"]), n.attrs["id"]) {
+		if boilerplateNames[strings.ToLower(name)] {
+			return true
+		}
+	}
+	return false
+}
+
+// htmlPage é o resultado da extração de uma página.
+type htmlPage struct {
+	title string
+	text  string
+}
+
+// htmlToText extrai o conteúdo legível de uma página. Com markdown, títulos, links,
+// ênfases, listas, tabelas e código viram a sintaxe correspondente; sem, só o texto.
+// Se a página tiver <main> ou <article>, só esse trecho é usado.
+func htmlToText(src string, base *url.URL, markdown bool) htmlPage {
+	doc := parseHTML(src)
+	page := htmlPage{}
+	if title := doc.find("title"); title != nil {
+		page.title = strings.TrimSpace(spaceRegex.ReplaceAllString(title.textContent(), " "))
+	}
+
+	content := doc
+	for _, tag := range []string{"main", "article", "body"} {
+		if n := doc.find(tag); n != nil {
+			content = n
+			break
+		}
+	}
+	r := htmlRenderer{base: base, markdown: markdown}
+	page.text = cleanRendered(r.children(content))
+	return page
+}
+
+// htmlRenderer converte a árvore em texto. Blocos são separados por linhas em branco;
+// indentação intencional (listas aninhadas) usa \x01 para sobreviver à limpeza final.
+type htmlRenderer struct {
+	base     *url.URL
+	markdown bool
+}
+
+func (r htmlRenderer) children(n *htmlNode) string {
+	var b strings.Builder
+	for _, c := range n.children {
+		b.WriteString(r.render(c))
+	}
+	return b.String()
+}
+
+func (r htmlRenderer) render(n *htmlNode) string {
+	if n.tag == "" {
+		return spaceRegex.ReplaceAllString(n.text, " ")
+	}
+	if n.tag == "title" || n.isBoilerplate() {
+		return ""
+	}
+
+	switch n.tag {
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		text := inline(r.children(n))
+		if text == "" {
+			return ""
+		}
+		if r.markdown {
+			text = strings.Repeat("#", int(n.tag[1]-'0')) + " " + text
+		}
+		return "\n\n" + text + "\n\n"
+	case "br":
+		return "\n"
+	case "hr":
+		return "\n\n---\n\n"
+	case "a":
+		text := inline(r.children(n))
+		href := r.resolve(n.attrs["href"])
+		if !r.markdown || text == "" || href == "" {
+			return r.children(n)
+		}
+		return "[" + text + "](" + href + ")"
+	case "img":
+		alt := inline(n.attrs["alt"])
+		if alt == "" {
+			return ""
+		}
+		if src := r.resolve(n.attrs["src"]); r.markdown && src != "" {
+			return "![" + alt + "](" + src + ")"
+		}
+		return alt
+	case "strong", "b":
+		return r.wrap(n, "**")
+	case "em", "i":
+		return r.wrap(n, "*")
+	case "code", "kbd", "samp":
+		return r.wrap(n, "`")
+	case "pre":
+		code := strings.Trim(n.textContent(), "\n")
+		lang := ""
+		if c := n.find("code"); c != nil && r.markdown {
+			for _, class := range strings.Fields(c.attrs["class"]) {
+				if strings.HasPrefix(class, "language-") {
+					lang = strings.TrimPrefix(class, "language-")
+				}
+			}
+		}
+		return "\n\n```" + lang + "\n" + code + "\n```\n\n"
+	case "ul", "ol":
+		return "\n\n" + r.list(n) + "\n\n"
+	case "li":
+		return "\n- " + strings.TrimSpace(r.children(n)) + "\n"
+	case "blockquote":
+		return "\n\n" + prefixLines(strings.TrimSpace(cleanRendered(r.children(n))), "> ") + "\n\n"
+	case "table":
+		return "\n\n" + r.table(n) + "\n\n"
+	case "dt":
+		return "\n\n" + r.wrap(n, "**") + "\n"
+	case "dd":
+		return "\n\x01" + strings.TrimSpace(r.children(n)) + "\n"
+	}
+	if blockElements[n.tag] || n.tag == "li" || n.tag == "tr" || n.tag == "figcaption" || n.tag == "summary" {
+		return "\n\n" + r.children(n) + "\n\n"
+	}
+	return r.children(n)
+}
+
+// wrap envolve o texto com o marcador do markdown, mantendo os espaços do lado de fora.
+func (r htmlRenderer) wrap(n *htmlNode, marker string) string {
+	text := r.children(n)
+	trimmed := strings.TrimSpace(text)
+	if !r.markdown || trimmed == "" || strings.Contains(trimmed, "\n") {
+		return text
+	}
+	lead := text[:len(text)-len(strings.TrimLeft(text, " "))]
+	trail := text[len(strings.TrimRight(text, " ")):]
+	return lead + marker + trimmed + marker + trail
+}
+
+// list renderiza os itens com "-" ou "1.", indentando o conteúdo de itens aninhados.
+func (r htmlRenderer) list(n *htmlNode) string {
+	var items []string
+	i := 1
+	for _, c := range n.children {
+		if c.tag != "li" {
+			continue
+		}
+		marker := "- "
+		if n.tag == "ol" {
+			marker = strconv.Itoa(i) + ". "
+			i++
+		}
+		content := strings.TrimSpace(cleanRendered(r.children(c)))
+		// Linhas em branco dentro do item separariam a lista; só os blocos de código as mantêm.
+		var lines []string
+		inCode := false
+		for j, line := range strings.Split(content, "\n") {
+			if strings.HasPrefix(line, "```") {
+				inCode = !inCode
+			}
+			switch {
+			case j == 0:
+				lines = append(lines, marker+line)
+			case line != "":
+				lines = append(lines, "\x01"+line)
+			case inCode:
+				lines = append(lines, line)
+			}
+		}
+		items = append(items, strings.Join(lines, "\n"))
+	}
+	return strings.Join(items, "\n")
+}
+
+// table renderiza a tabela no formato do markdown, com a primeira linha como cabeçalho.
+func (r htmlRenderer) table(n *htmlNode) string {
+	var rows [][]string
+	var collect func(*htmlNode)
+	collect = func(n *htmlNode) {
+		for _, c := range n.children {
+			switch c.tag {
+			case "tr":
+				var cells []string
+				for _, cell := range c.children {
+					if cell.tag == "td" || cell.tag == "th" {
+						cells = append(cells, strings.ReplaceAll(inline(r.children(cell)), "|", `\|`))
+					}
+				}
+				if len(cells) > 0 {
+					rows = append(rows, cells)
+				}
+			case "thead", "tbody", "tfoot":
+				collect(c)
+			}
+		}
+	}
+	collect(n)
+
+	var lines []string
+	for i, row := range rows {
+		if !r.markdown {
+			lines = append(lines, strings.Join(row, " | "))
+			continue
+		}
+		lines = append(lines, "| "+strings.Join(row, " | ")+" |")
+		if i == 0 {
+			lines = append(lines, "|"+strings.Repeat(" --- |", len(row)))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// resolve torna o link absoluto em relação à página, descartando âncoras e javascript:.
+func (r htmlRenderer) resolve(href string) string {
+	href = strings.TrimSpace(href)
+	if href == "" || strings.HasPrefix(href, "#") || strings.HasPrefix(strings.ToLower(href), "javascript:") {
+		return ""
+	}
+	u, err := url.Parse(href)
+	if err != nil {
+		return ""
+	}
+	if r.base != nil {
+		u = r.base.ResolveReference(u)
+	}
+	return u.String()
+}
+
+// inline junta o texto de um bloco em uma linha só.
+func inline(s string) string {
+	return strings.TrimSpace(spaceRegex.ReplaceAllString(strings.ReplaceAll(s, "\x01", ""), " "))
+}
+
+func prefixLines(s, prefix string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(prefix+line, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// cleanRendered remove os espaços nas bordas das linhas (fora dos blocos de código),
+// converte a indentação marcada e limita as linhas em branco seguidas a uma.
+func cleanRendered(s string) string {
+	lines := strings.Split(s, "\n")
+	inCode := false
+	for i, line := range lines {
+		if !inCode {
+			line = strings.TrimLeft(line, " ")
+		}
+		indent := len(line) - len(strings.TrimLeft(line, "\x01"))
+		line = line[indent:]
+		switch {
+		case strings.HasPrefix(strings.TrimSpace(line), "```"):
+			inCode = !inCode
+			line = strings.TrimSpace(line)
+		case inCode:
+		case indent > 0:
+			line = strings.TrimRight(line, " ")
+		default:
+			line = strings.TrimSpace(line)
+		}
+		lines[i] = strings.Repeat("  ", indent) + line
+	}
+	return strings.TrimSpace(blankRegex.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+}
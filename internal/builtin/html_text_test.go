@@ -0,0 +1,98 @@
+package builtin
+
+import (
+	"net/url"
+	"testing"
+)
+
+// TestHTMLToText testa a conversão de HTML para markdown e texto.
+func TestHTMLToText(t *testing.T) {
+	base, _ := url.Parse("https://exemplo.com/docs/guia.html")
+
+	testCases := []struct {
+		name     string
+		html     string
+		markdown bool
+		expected string
+	}{
+		{
+			name: "Títulos, parágrafos e ênfases",
+			html: `<html><head><title>Guia</title><style>p{color:red}</style></head>
+<body><h1>Guia  rápido</h1><p>Use <strong>sempre</strong> o <code>go vet</code>
+e <em>teste</em>.<p>Segundo&nbsp;parágrafo &amp; fim.</body></html>`,
+			markdown: true,
+			expected: "# Guia rápido\n\nUse **sempre** o `go vet` e *teste*.\n\nSegundo parágrafo & fim.",
+		},
+		{
+			name:     "Links relativos e imagens",
+			html:     `<p>Veja <a href="../api/">a API</a>, <a href="#topo">o topo</a> e <img src="/logo.png" alt="logo">.</p>`,
+			markdown: true,
+			expected: "Veja [a API](https://exemplo.com/api/), o topo e ![logo](https://exemplo.com/logo.png).",
+		},
+		{
+			name:     "Remove scripts, navegação e rodapé",
+			html:     `<body><nav><a href="/">Início</a></nav><div class="sidebar">Menu</div><div role="navigation">Mais</div><script>alert("x<p>")</script><p>Conteúdo</p><footer>© 2024</footer></body>`,
+			markdown: true,
+			expected: "Conteúdo",
+		},
+		{
+			name:     "Usa só o main",
+			html:     `<body><header><h1>Site</h1></header><main><h2>Artigo</h2><p>Texto</p></main><div>Propaganda</div></body>`,
+			markdown: true,
+			expected: "## Artigo\n\nTexto",
+		},
+		{
+			name:     "Listas aninhadas e numeradas",
+			html:     `<ul><li>Um<ul><li>Um.a<li>Um.b</ul><li>Dois</ul><ol><li>Primeiro</li><li>Segundo</li></ol>`,
+			markdown: true,
+			expected: "- Um\n  - Um.a\n  - Um.b\n- Dois\n\n1. Primeiro\n2. Segundo",
+		},
+		{
+			name:     "Código preformatado",
+			html:     "<p>Exemplo:</p><pre><code class=\"language-go\">func main() {\n\tfmt.Println(&quot;oi&quot;)\n}</code></pre>",
+			markdown: true,
+			expected: "Exemplo:\n\n```go\nfunc main() {\n\tfmt.Println(\"oi\")\n}\n```",
+		},
+		{
+			name:     "Tabela",
+			html:     `<table><thead><tr><th>Nome</th><th>Tipo</th></tr></thead><tbody><tr><td>id</td><td>int</td><tr><td>a|b</td><td>string</td></tbody></table>`,
+			markdown: true,
+			expected: "| Nome | Tipo |\n| --- | --- |\n| id | int |\n| a\\|b | string |",
+		},
+		{
+			name:     "Citação",
+			html:     `<blockquote><p>Linha um</p><p>Linha dois</p></blockquote>`,
+			markdown: true,
+			expected: "> Linha um\n>\n> Linha dois",
+		},
+		{
+			name:     "Texto puro",
+			html:     `<h2>Título</h2><p>Um <a href="/x">link</a> e <b>negrito</b>.</p><table><tr><td>a</td><td>b</td></tr></table>`,
+			markdown: false,
+			expected: "Título\n\nUm link e negrito.\n\na | b",
+		},
+		{
+			name:     "HTML malformado",
+			html:     `<div><p>Aberto <b>sem fechar</div></span><p>1 < 2`,
+			markdown: true,
+			expected: "Aberto **sem fechar**\n\n1 < 2",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			page := htmlToText(tc.html, base, tc.markdown)
+			if page.text != tc.expected {
+				t.Errorf("htmlToText() =\n%q\nesperado\n%q", page.text, tc.expected)
+			}
+		})
+	}
+}
+
+// TestHTMLTitle testa a extração do título da página.
+func TestHTMLTitle(t *testing.T) {
+	page := htmlToText("<title>\n  Documentação &mdash; Go\n</title><p>x</p>", nil, true)
+	if page.title != "Documentação — Go" {
+		t.Errorf("título = %q", page.title)
+	}
+}
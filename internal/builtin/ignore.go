@@ -0,0 +1,223 @@
+package builtin
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// defaultIgnoredDirs são diretórios pulados por padrão, mesmo sem .gitignore.
+var defaultIgnoredDirs = map[string]bool{
+	".git":         true,
+	".hg":          true,
+	".svn":         true,
+	"node_modules": true,
+	"vendor":       true,
+}
+
+// ignoreRule é uma linha de um .gitignore.
+type ignoreRule struct {
+	base    string // diretório do .gitignore, relativo à raiz da busca ("" = raiz)
+	re      *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// ignoreRules acumula as regras dos .gitignore encontrados no caminho até um diretório.
+type ignoreRules []ignoreRule
+
+// loadGitignore adiciona às regras as do .gitignore de dir, se existir. rel é o caminho
+// de dir relativo à raiz da busca, com barras.
+func (rules ignoreRules) loadGitignore(dir, rel string) ignoreRules {
+	data, err := os.ReadFile(filepath.Join(dir, ".gitignore"))
+	if err != nil {
+		return rules
+	}
+	// Cópia para que diretórios irmãos não vejam as regras uns dos outros.
+	rules = append(ignoreRules(nil), rules...)
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimRight(line, " \r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		rule := ignoreRule{base: rel}
+		if strings.HasPrefix(line, "!") {
+			rule.negate, line = true, line[1:]
+		} else if strings.HasPrefix(line, `\`) {
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly, line = true, strings.TrimRight(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+		re, err := globRegexp(line)
+		if err != nil {
+			continue
+		}
+		rule.re = re
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// ignored aplica as regras em ordem: a última que casar decide, como no git.
+func (rules ignoreRules) ignored(rel string, isDir bool) bool {
+	result := false
+	for _, r := range rules {
+		if r.dirOnly && !isDir {
+			continue
+		}
+		p := rel
+		if r.base != "" {
+			if !strings.HasPrefix(rel, r.base+"/") {
+				continue
+			}
+			p = rel[len(r.base)+1:]
+		}
+		if r.re.MatchString(p) {
+			result = !r.negate
+		}
+	}
+	return result
+}
+
+// globRegexp converte um padrão glob no estilo .gitignore em expressão regular sobre
+// caminhos relativos com barras. Padrões sem "/" casam com o nome em qualquer nível;
+// com "/" casam a partir da raiz. "**" atravessa diretórios.
+func globRegexp(pattern string) (*regexp.Regexp, error) {
+	anchored := strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+
+	var b strings.Builder
+	b.WriteString("^")
+	if !anchored || strings.HasPrefix(pattern, "**/") {
+		b.WriteString("(?:.*/)?")
+		pattern = strings.TrimPrefix(pattern, "**/")
+	}
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case strings.HasPrefix(pattern[i:], "/**/"):
+			b.WriteString("(?:/.*)?/")
+			i += 3
+		case strings.HasPrefix(pattern[i:], "/**") && i+3 == len(pattern):
+			b.WriteString("/.*")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i += end + 1
+		case c == '\\' && i+1 < len(pattern):
+			i++
+			b.WriteString(regexp.QuoteMeta(string(pattern[i])))
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
+
+// globSet é uma lista de padrões glob; um caminho casa se casar com qualquer um deles.
+type globSet []*regexp.Regexp
+
+// compileGlobs compila os padrões de include/exclude.
+func compileGlobs(patterns []string) (globSet, error) {
+	var set globSet
+	for _, p := range patterns {
+		if p = strings.TrimSpace(p); p == "" {
+			continue
+		}
+		re, err := globRegexp(p)
+		if err != nil {
+			return nil, err
+		}
+		set = append(set, re)
+	}
+	return set, nil
+}
+
+func (g globSet) match(rel string) bool {
+	for _, re := range g {
+		if re.MatchString(rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// treeWalker percorre um diretório em ordem alfabética, pulando o que as regras de
+// ignore e os globs de exclude excluem. Diretórios excluídos não são percorridos.
+type treeWalker struct {
+	noIgnore bool    // desliga o .gitignore e defaultIgnoredDirs
+	exclude  globSet // globs de arquivos e diretórios a pular
+	maxDepth int     // 1 = só o conteúdo direto; 0 = sem limite
+}
+
+// walk chama visit para cada entrada, com o caminho absoluto e o relativo a root (com
+// barras). Se visit retornar filepath.SkipDir para um diretório, ele não é percorrido;
+// qualquer outro erro interrompe a varredura.
+func (tw treeWalker) walk(root string, visit func(abs, rel string, e os.DirEntry) error) error {
+	var rules ignoreRules
+	if !tw.noIgnore {
+		rules = rules.loadGitignore(root, "")
+	}
+	return tw.walkDir(root, "", 1, rules, visit)
+}
+
+func (tw treeWalker) walkDir(dir, rel string, depth int, rules ignoreRules, visit func(abs, rel string, e os.DirEntry) error) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if rel == "" {
+			return err
+		}
+		return nil // subdiretório ilegível: segue com o resto
+	}
+	for _, e := range entries {
+		childRel := path.Join(rel, e.Name())
+		childAbs := filepath.Join(dir, e.Name())
+		isDir := e.IsDir()
+
+		if !tw.noIgnore && (isDir && defaultIgnoredDirs[e.Name()] || rules.ignored(childRel, isDir)) {
+			continue
+		}
+		if tw.exclude.match(childRel) {
+			continue
+		}
+		if err := visit(childAbs, childRel, e); err == filepath.SkipDir && isDir {
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		if isDir && (tw.maxDepth == 0 || depth < tw.maxDepth) {
+			childRules := rules
+			if !tw.noIgnore {
+				childRules = rules.loadGitignore(childAbs, childRel)
+			}
+			if err := tw.walkDir(childAbs, childRel, depth+1, childRules, visit); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -19,7 +19,24 @@ func TestToolDefinitionsIntegration(t *testing.T) {
 		{"ListFilesDef", ListFilesDef},
 		{"WriteFileDef", WriteFileDef},
 		{"ReadFileDef", ReadFileDef},
+		{"SearchFilesDef", SearchFilesDef},
 		{"CreateDirectoryDef", CreateDirectoryDef},
+		{"EditFileDef", EditFileDef},
+		{"ApplyPatchDef", ApplyPatchDef},
+		{"RunCommandDef", RunCommandDef},
+		{"GoToolDef", GoToolDef},
+		{"GoSymbolsDef", GoSymbolsDef},
+		{"GitDef", GitDef},
+		{"FetchURLDef", FetchURLDef},
+		{"StatPathDef", StatPathDef},
+		{"DeletePathDef", DeletePathDef},
+		{"MovePathDef", MovePathDef},
+		{"CopyPathDef", CopyPathDef},
+		{"QueryDataDef", QueryDataDef},
+		{"UpdateDataDef", UpdateDataDef},
+		{"CSVQueryDef", CSVQueryDef},
+		{"CalculateDef", CalculateDef},
+		{"TodoDef", TodoDef},
 		{"AskHumanDef", AskHumanDef},
 	}
 
@@ -34,8 +51,8 @@ func TestToolDefinitionsIntegration(t *testing.T) {
 				t.Errorf("%s.Description está vazio", td.name)
 			}
 
-			if td.def.Function == nil {
-				t.Errorf("%s.Function é nil", td.name)
+			if td.def.Function == nil && td.def.ContextFunction == nil {
+				t.Errorf("%s.Function e %s.ContextFunction são nil", td.name, td.name)
 			}
 
 			// Verifica se a descrição é útil (deve ter pelo menos 20 caracteres)
@@ -48,6 +65,8 @@ func TestToolDefinitionsIntegration(t *testing.T) {
 
 // TestToolAdapterIntegration testa a integração com o ToolAdapter
 func TestToolAdapterIntegration(t *testing.T) {
+	newTestWorkspace(t, nil)
+
 	// Testa algumas ferramentas através do ToolAdapter
 	testCases := []struct {
 		name    string
@@ -58,7 +77,7 @@ func TestToolAdapterIntegration(t *testing.T) {
 		{
 			name:    "CreateDirectory via ToolAdapter",
 			adapter: &toolkit.ToolAdapter{Definition: CreateDirectoryDef},
-			input:   CreateDirectoryInput{Path: filepath.Join(t.TempDir(), "test-dir")},
+			input:   CreateDirectoryInput{Path: "test-dir"},
 			wantErr: false,
 		},
 		{
@@ -70,7 +89,7 @@ func TestToolAdapterIntegration(t *testing.T) {
 		{
 			name:    "WriteFile via ToolAdapter",
 			adapter: &toolkit.ToolAdapter{Definition: WriteFileDef},
-			input:   WriteFileInput{Path: filepath.Join(t.TempDir(), "test.txt"), Content: "conteúdo teste"},
+			input:   WriteFileInput{Path: "test.txt", Content: "conteúdo teste"},
 			wantErr: false,
 		},
 	}
@@ -107,12 +126,12 @@ func TestToolAdapterIntegration(t *testing.T) {
 
 // TestFileToolsWorkflow testa um fluxo completo de operações de arquivo
 func TestFileToolsWorkflow(t *testing.T) {
-	tempDir := t.TempDir()
+	newTestWorkspace(t, nil)
 	
 	// Workflow: Criar diretório -> Escrever arquivo -> Ler arquivo -> Listar arquivos
 	
 	// 1. Criar diretório
-	subDir := filepath.Join(tempDir, "workflow-test")
+	subDir := "workflow-test"
 	createInput := CreateDirectoryInput{Path: subDir}
 	createJSON, _ := json.Marshal(createInput)
 	
@@ -222,7 +241,7 @@ func TestErrorHandlingConsistency(t *testing.T) {
 
 // TestConcurrentAccess testa acesso concorrente às ferramentas
 func TestConcurrentAccess(t *testing.T) {
-	tempDir := t.TempDir()
+	root := newTestWorkspace(t, nil)
 	
 	// Testa criação concorrente de diretórios
 	const numGoroutines = 10
@@ -230,7 +249,7 @@ func TestConcurrentAccess(t *testing.T) {
 
 	for i := 0; i < numGoroutines; i++ {
 		go func(id int) {
-			dirPath := filepath.Join(tempDir, "concurrent", "dir"+string(rune('0'+id)))
+			dirPath := filepath.Join("concurrent", "dir"+string(rune('0'+id)))
 			input := CreateDirectoryInput{Path: dirPath}
 			inputJSON, _ := json.Marshal(input)
 			
@@ -249,7 +268,7 @@ func TestConcurrentAccess(t *testing.T) {
 
 	// Verifica se todos os diretórios foram criados
 	for i := 0; i < numGoroutines; i++ {
-		dirPath := filepath.Join(tempDir, "concurrent", "dir"+string(rune('0'+i)))
+		dirPath := filepath.Join(root, "concurrent", "dir"+string(rune('0'+i)))
 		if _, err := os.Stat(dirPath); os.IsNotExist(err) {
 			t.Errorf("Diretório %d não foi criado: %s", i, dirPath)
 		}
@@ -258,11 +277,11 @@ func TestConcurrentAccess(t *testing.T) {
 
 // BenchmarkFileOperations benchmark das operações de arquivo
 func BenchmarkFileOperations(b *testing.B) {
-	tempDir := b.TempDir()
+	newTestWorkspace(b, nil)
 	
 	b.Run("CreateDirectory", func(b *testing.B) {
 		for i := 0; i < b.N; i++ {
-			dirPath := filepath.Join(tempDir, "bench", "dir", "subdir"+string(rune('0'+i%10)))
+			dirPath := filepath.Join("bench", "dir", "subdir"+string(rune('0'+i%10)))
 			input := CreateDirectoryInput{Path: dirPath}
 			inputJSON, _ := json.Marshal(input)
 			
@@ -276,7 +295,7 @@ func BenchmarkFileOperations(b *testing.B) {
 	b.Run("WriteFile", func(b *testing.B) {
 		content := "Conteúdo de teste para benchmark"
 		for i := 0; i < b.N; i++ {
-			filePath := filepath.Join(tempDir, "bench", "file"+string(rune('0'+i%10))+".txt")
+			filePath := filepath.Join("bench", "file"+string(rune('0'+i%10))+".txt")
 			input := WriteFileInput{Path: filePath, Content: content}
 			inputJSON, _ := json.Marshal(input)
 			
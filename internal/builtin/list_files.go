@@ -0,0 +1,258 @@
+package builtin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/matheusbuniotto/goagent/pkg/toolkit"
+)
+
+// ::: Ferramenta: ListFiles :::
+
+// defaultListLimit é o máximo de entradas devolvidas por chamada sem "limit".
+const defaultListLimit = 1000
+
+// ListFilesInput define os parâmetros para a função ListFiles.
+type ListFilesInput struct {
+	Path     string   `json:"path,omitempty"`
+	Depth    int      `json:"depth,omitempty"`     // 1 = só o conteúdo direto; 0 = sem limite
+	Include  []string `json:"include,omitempty"`   // globs de arquivos a listar (ex: "*.go")
+	Exclude  []string `json:"exclude,omitempty"`   // globs de arquivos e diretórios a pular
+	NoIgnore bool     `json:"no_ignore,omitempty"` // inclui o que o .gitignore e os padrões ignoram
+	Limit    int      `json:"limit,omitempty"`     // máximo de entradas por página
+	Cursor   string   `json:"cursor,omitempty"`    // continuação devolvida na página anterior
+	Details  bool     `json:"details,omitempty"`   // inclui tipo, tamanho e data de modificação
+	Format   string   `json:"format,omitempty"`    // "json" (padrão) ou "tree"
+}
+
+// FileEntry é uma entrada de list_files com details.
+type FileEntry struct {
+	Path    string    `json:"path"`
+	Type    string    `json:"type"` // file, dir ou symlink
+	Size    int64     `json:"size,omitempty"`
+	ModTime time.Time `json:"mtime"`
+
+	rel string // caminho relativo ao diretório listado, com barras
+}
+
+// listPage é a saída JSON quando há detalhes ou mais páginas.
+type listPage struct {
+	Entries    interface{} `json:"entries"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+}
+
+// lister percorre a árvore aplicando os filtros e guarda só a página pedida.
+type lister struct {
+	input   ListFilesInput
+	include globSet
+	exclude globSet
+	skip    int // entradas a pular (cursor)
+	limit   int
+	seen    int
+	entries []FileEntry
+	more    bool
+	display func(abs string) string
+}
+
+// listFiles é a função lógica que varre um diretório.
+func listFiles(input json.RawMessage) (string, error) {
+	var typedInput ListFilesInput
+	if len(input) > 0 && string(input) != "null" {
+		if err := json.Unmarshal(input, &typedInput); err != nil {
+			return "", fmt.Errorf("JSON inválido para argumentos: %w", err)
+		}
+	}
+	if typedInput.Format != "" && typedInput.Format != "json" && typedInput.Format != "tree" {
+		return "", fmt.Errorf("argumento inválido. 'format' deve ser json ou tree")
+	}
+	if typedInput.Depth < 0 || typedInput.Limit < 0 {
+		return "", fmt.Errorf("argumentos inválidos. 'depth' e 'limit' não podem ser negativos")
+	}
+
+	// Valor padrão: raiz do workspace
+	w, err := CurrentWorkspace()
+	if err != nil {
+		return "", err
+	}
+	dir, err := w.ResolveRead(typedInput.Path)
+	if err != nil {
+		return "", err
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("'%s' não é um diretório", typedInput.Path)
+	}
+
+	l := &lister{input: typedInput, limit: typedInput.Limit}
+	l.display = func(abs string) string { return w.Display(typedInput.Path, abs) }
+	if l.limit == 0 {
+		l.limit = defaultListLimit
+	}
+	if typedInput.Cursor != "" {
+		if l.skip, err = strconv.Atoi(typedInput.Cursor); err != nil || l.skip < 0 {
+			return "", fmt.Errorf("argumento inválido. 'cursor' deve ser o valor de next_cursor da página anterior")
+		}
+	}
+	if l.include, err = compileGlobs(typedInput.Include); err != nil {
+		return "", fmt.Errorf("padrão de include inválido: %w", err)
+	}
+	if l.exclude, err = compileGlobs(typedInput.Exclude); err != nil {
+		return "", fmt.Errorf("padrão de exclude inválido: %w", err)
+	}
+
+	tw := treeWalker{noIgnore: typedInput.NoIgnore, exclude: l.exclude, maxDepth: typedInput.Depth}
+	err = tw.walk(dir, func(abs, rel string, e os.DirEntry) error {
+		// Com include, só arquivos que casam são listados; diretórios só são percorridos.
+		if len(l.include) > 0 && (e.IsDir() || !l.include.match(rel)) {
+			return nil
+		}
+		return l.add(abs, rel, e)
+	})
+	if err != nil && err != errPageFull {
+		return "", err
+	}
+
+	if typedInput.Format == "tree" {
+		return l.tree(), nil
+	}
+	return l.json()
+}
+
+// errPageFull interrompe a varredura quando a página já está completa.
+var errPageFull = errors.New("página completa")
+
+// add registra uma entrada se ela estiver na página pedida.
+func (l *lister) add(abs, rel string, e os.DirEntry) error {
+	l.seen++
+	if l.seen <= l.skip {
+		return nil
+	}
+	if len(l.entries) == l.limit {
+		l.more = true
+		return errPageFull
+	}
+	entry := FileEntry{Path: l.display(abs), Type: "file", rel: rel}
+	switch {
+	case e.Type()&os.ModeSymlink != 0:
+		entry.Type = "symlink"
+	case e.IsDir():
+		entry.Type = "dir"
+	}
+	if l.input.Details {
+		if info, err := e.Info(); err == nil {
+			entry.ModTime = info.ModTime().Truncate(time.Second)
+			if entry.Type == "file" {
+				entry.Size = info.Size()
+			}
+		}
+	}
+	l.entries = append(l.entries, entry)
+	return nil
+}
+
+// nextCursor retorna o cursor da próxima página, ou "" se esta for a última.
+func (l *lister) nextCursor() string {
+	if !l.more {
+		return ""
+	}
+	return strconv.Itoa(l.skip + len(l.entries))
+}
+
+// json formata a página como JSON: uma lista de caminhos, ou um objeto com "entries" e
+// "next_cursor" quando há detalhes ou mais páginas.
+func (l *lister) json() (string, error) {
+	var entries interface{}
+	if l.input.Details {
+		entries = l.entries
+	} else {
+		paths := make([]string, 0, len(l.entries))
+		for _, e := range l.entries {
+			paths = append(paths, e.Path)
+		}
+		entries = paths
+	}
+
+	var out interface{} = entries
+	if l.input.Details || l.more {
+		out = listPage{Entries: entries, NextCursor: l.nextCursor()}
+	}
+	result, err := json.Marshal(out)
+	if err != nil {
+		return "", err
+	}
+	return string(result), nil
+}
+
+// tree formata a página como árvore indentada, com "/" no fim dos diretórios.
+// Diretórios intermediários que não estão na página aparecem para dar contexto.
+func (l *lister) tree() string {
+	var b strings.Builder
+	printed := make(map[string]bool)
+	for _, e := range l.entries {
+		parts := strings.Split(e.rel, "/")
+		for i := range parts[:len(parts)-1] {
+			dir := strings.Join(parts[:i+1], "/")
+			if !printed[dir] {
+				printed[dir] = true
+				fmt.Fprintf(&b, "%s%s/\n", strings.Repeat("  ", i), parts[i])
+			}
+		}
+		name := parts[len(parts)-1]
+		key := strings.Join(parts, "/")
+		if e.Type == "dir" {
+			if printed[key] {
+				continue
+			}
+			printed[key] = true
+			name += "/"
+		}
+		if e.Type == "symlink" {
+			name += "@"
+		}
+		line := strings.Repeat("  ", len(parts)-1) + name
+		if l.input.Details {
+			if e.Type == "file" {
+				line += fmt.Sprintf("  (%s, %s)", formatSize(e.Size), e.ModTime.Format("2006-01-02 15:04"))
+			} else {
+				line += fmt.Sprintf("  (%s)", e.ModTime.Format("2006-01-02 15:04"))
+			}
+		}
+		b.WriteString(line + "\n")
+	}
+	if cursor := l.nextCursor(); cursor != "" {
+		fmt.Fprintf(&b, "[há mais entradas; use \"cursor\": \"%s\" para a próxima página]\n", cursor)
+	}
+	if b.Len() == 0 {
+		return "(vazio)"
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// formatSize formata um tamanho em bytes de forma compacta (ex: 1.2K, 3.4M).
+func formatSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%c", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// ListFilesDef é a definição pública da nossa ferramenta.
+var ListFilesDef = toolkit.ToolDefinition{
+	Name:        "list_files",
+	Description: `Lista arquivos e diretórios em um caminho específico. Se nenhum caminho for fornecido, lista o conteúdo do workspace. Caminhos relativos partem da raiz do workspace. Por padrão é recursivo, respeita o .gitignore e pula .git, node_modules e vendor ("no_ignore": true desliga). Opcionais: "depth" (1 = só o conteúdo direto), "include"/"exclude" (globs, ex: ["*.go"], ["**/testdata"]), "limit" (padrão 1000) e "cursor" (valor de next_cursor) para paginar, "details": true para tipo, tamanho e data, "format": "tree" para uma árvore indentada que gasta menos tokens. Exemplo: {"path": "cmd", "depth": 2, "format": "tree"}`,
+	Function:    listFiles,
+}
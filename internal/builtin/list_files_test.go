@@ -0,0 +1,153 @@
+package builtin
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// setupTree cria uma árvore de teste e a define como workspace.
+func setupTree(t *testing.T) {
+	t.Helper()
+	newTestWorkspace(t, map[string]string{
+		".gitignore":                "*.log\n/build/\n!importante.log\n",
+		"main.go":                   "package main",
+		"app.log":                   "x",
+		"importante.log":            "x",
+		"build/saida":               "x",
+		"cmd/app/app.go":            "package app",
+		"cmd/app/app_test.go":       "package app",
+		"cmd/app/.gitignore":        "gerado.go\n",
+		"cmd/app/gerado.go":         "package app",
+		"node_modules/lib/index.js": "x",
+		"docs/guia.md":              "# guia",
+		"docs/build/ok.md":          "não é a /build da raiz",
+	})
+}
+
+// TestListFilesFilters testa profundidade, globs e regras de ignore.
+func TestListFilesFilters(t *testing.T) {
+	setupTree(t)
+
+	testCases := []struct {
+		name     string
+		input    ListFilesInput
+		expected []string
+	}{
+		{
+			name:  "Padrão respeita .gitignore e pula node_modules",
+			input: ListFilesInput{},
+			expected: []string{".gitignore", "cmd", "cmd/app", "cmd/app/.gitignore", "cmd/app/app.go", "cmd/app/app_test.go",
+				"docs", "docs/build", "docs/build/ok.md", "docs/guia.md", "importante.log", "main.go"},
+		},
+		{
+			name:     "Profundidade 1",
+			input:    ListFilesInput{Depth: 1},
+			expected: []string{".gitignore", "cmd", "docs", "importante.log", "main.go"},
+		},
+		{
+			name:     "Include",
+			input:    ListFilesInput{Include: []string{"*.go"}},
+			expected: []string{"cmd/app/app.go", "cmd/app/app_test.go", "main.go"},
+		},
+		{
+			name:     "Include e exclude",
+			input:    ListFilesInput{Include: []string{"*.go"}, Exclude: []string{"*_test.go", "cmd/**/x"}},
+			expected: []string{"cmd/app/app.go", "main.go"},
+		},
+		{
+			name:     "Exclude de diretório",
+			input:    ListFilesInput{Path: "docs", Exclude: []string{"build"}},
+			expected: []string{"docs/guia.md"},
+		},
+		{
+			name:     "Sem ignore",
+			input:    ListFilesInput{NoIgnore: true, Include: []string{"*.log", "**/node_modules/**/*.js", "gerado.go"}},
+			expected: []string{"app.log", "cmd/app/gerado.go", "importante.log", "node_modules/lib/index.js"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rawInput, _ := json.Marshal(tc.input)
+			result, err := listFiles(rawInput)
+			if err != nil {
+				t.Fatalf("listFiles() erro inesperado: %v", err)
+			}
+			var got []string
+			if err := json.Unmarshal([]byte(result), &got); err != nil {
+				t.Fatalf("resultado não é uma lista JSON: %s", result)
+			}
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("listFiles() =\n%v\nesperado\n%v", got, tc.expected)
+			}
+		})
+	}
+}
+
+// TestListFilesPagination testa o limite por página e o cursor.
+func TestListFilesPagination(t *testing.T) {
+	setupTree(t)
+
+	var all []string
+	cursor := ""
+	for pages := 0; pages < 10; pages++ {
+		rawInput, _ := json.Marshal(ListFilesInput{Limit: 5, Cursor: cursor})
+		result, err := listFiles(rawInput)
+		if err != nil {
+			t.Fatalf("listFiles() erro inesperado: %v", err)
+		}
+		var page struct {
+			Entries    []string `json:"entries"`
+			NextCursor string   `json:"next_cursor"`
+		}
+		if err := json.Unmarshal([]byte(result), &page); err != nil {
+			// A última página, sem continuação, é uma lista simples.
+			var last []string
+			if err := json.Unmarshal([]byte(result), &last); err != nil {
+				t.Fatalf("resultado inesperado: %s", result)
+			}
+			all = append(all, last...)
+			break
+		}
+		all = append(all, page.Entries...)
+		cursor = page.NextCursor
+	}
+	if len(all) != 12 {
+		t.Errorf("paginação retornou %d entradas, esperado 12: %v", len(all), all)
+	}
+}
+
+// TestListFilesTreeAndDetails testa o formato em árvore e os metadados.
+func TestListFilesTreeAndDetails(t *testing.T) {
+	setupTree(t)
+
+	rawInput, _ := json.Marshal(ListFilesInput{Path: "cmd", Format: "tree"})
+	result, err := listFiles(rawInput)
+	if err != nil {
+		t.Fatalf("listFiles() erro inesperado: %v", err)
+	}
+	expected := "app/\n  .gitignore\n  app.go\n  app_test.go"
+	if result != expected {
+		t.Errorf("árvore =\n%s\nesperado\n%s", result, expected)
+	}
+
+	rawInput, _ = json.Marshal(ListFilesInput{Include: []string{"main.go"}, Details: true})
+	result, err = listFiles(rawInput)
+	if err != nil {
+		t.Fatalf("listFiles() erro inesperado: %v", err)
+	}
+	var page struct {
+		Entries []FileEntry `json:"entries"`
+	}
+	if err := json.Unmarshal([]byte(result), &page); err != nil || len(page.Entries) != 1 {
+		t.Fatalf("resultado inesperado: %s", result)
+	}
+	if e := page.Entries[0]; e.Type != "file" || e.Size != int64(len("package main")) || e.ModTime.IsZero() {
+		t.Errorf("detalhes inesperados: %+v", e)
+	}
+	if strings.Contains(result, "next_cursor") {
+		t.Errorf("não deveria haver próxima página: %s", result)
+	}
+}
@@ -0,0 +1,466 @@
+package builtin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/matheusbuniotto/goagent/pkg/toolkit"
+)
+
+// describeKind retorna o tipo do caminho como mostrado ao modelo.
+func describeKind(info fs.FileInfo) string {
+	switch {
+	case info.Mode()&fs.ModeSymlink != 0:
+		return "link simbólico"
+	case info.IsDir():
+		return "diretório"
+	case info.Mode().IsRegular():
+		return "arquivo"
+	default:
+		return "arquivo especial"
+	}
+}
+
+// treeSize conta os itens (arquivos, diretórios e links) dentro de dir e o total de
+// bytes dos arquivos.
+func treeSize(dir string) (items int, size int64, err error) {
+	err = filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p == dir {
+			return nil
+		}
+		items++
+		if d.Type().IsRegular() {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			size += info.Size()
+		}
+		return nil
+	})
+	return items, size, err
+}
+
+// ::: Ferramenta: StatPath :::
+
+type StatPathInput struct {
+	Path string `json:"path"`
+}
+
+func statPath(input json.RawMessage) (string, error) {
+	var typedInput StatPathInput
+	if err := json.Unmarshal(input, &typedInput); err != nil {
+		return "", fmt.Errorf("JSON inválido para argumentos: %w", err)
+	}
+	if typedInput.Path == "" {
+		return "", fmt.Errorf("argumento inválido. 'path' é obrigatório")
+	}
+
+	w, err := CurrentWorkspace()
+	if err != nil {
+		return "", err
+	}
+	abs, err := w.ResolveRead(typedInput.Path)
+	if err != nil {
+		return "", err
+	}
+	display := w.Display(typedInput.Path, abs)
+	info, err := os.Lstat(abs)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Sprintf("'%s' não existe.", display), nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("erro ao consultar '%s': %w", typedInput.Path, err)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Caminho: %s\nTipo: %s", display, describeKind(info))
+	if info.Mode()&fs.ModeSymlink != 0 {
+		target, _ := os.Readlink(abs)
+		fmt.Fprintf(&b, " → %s", target)
+		// O restante descreve o destino do link.
+		if info, err = os.Stat(abs); err != nil {
+			return "", fmt.Errorf("erro ao consultar '%s': %w", typedInput.Path, err)
+		}
+		fmt.Fprintf(&b, " (%s)", describeKind(info))
+	}
+	if info.IsDir() {
+		entries, err := os.ReadDir(abs)
+		if err != nil {
+			return "", fmt.Errorf("erro ao listar '%s': %w", typedInput.Path, err)
+		}
+		fmt.Fprintf(&b, "\nItens: %d", len(entries))
+	} else {
+		fmt.Fprintf(&b, "\nTamanho: %d bytes", info.Size())
+	}
+	fmt.Fprintf(&b, "\nPermissões: %s\nModificado: %s", info.Mode().Perm(), info.ModTime().Format("2006-01-02 15:04:05 -0700"))
+	return b.String(), nil
+}
+
+var StatPathDef = toolkit.ToolDefinition{
+	Name:        "stat_path",
+	Description: `Informa se um caminho existe e, se existir, o tipo (arquivo, diretório ou link simbólico e seu destino), o tamanho ou número de itens, as permissões e a data de modificação, sem ler o conteúdo. Exemplo: {"path": "go.mod"}`,
+	Function:    statPath,
+}
+
+// ::: Ferramenta: DeletePath :::
+
+type DeletePathInput struct {
+	Path      string `json:"path"`
+	Recursive bool   `json:"recursive,omitempty"`
+}
+
+// deleteTarget é o caminho a apagar, já validado.
+type deleteTarget struct {
+	path    string
+	display string
+	info    fs.FileInfo
+	items   int // Itens dentro do diretório
+	size    int64
+}
+
+// planDelete valida o caminho. Diretórios com conteúdo exigem recursive, e a raiz do
+// workspace nunca pode ser apagada.
+func planDelete(input json.RawMessage) (deleteTarget, error) {
+	var typedInput DeletePathInput
+	if err := json.Unmarshal(input, &typedInput); err != nil {
+		return deleteTarget{}, fmt.Errorf("JSON inválido para argumentos: %w", err)
+	}
+	if typedInput.Path == "" {
+		return deleteTarget{}, fmt.Errorf("argumento inválido. 'path' é obrigatório")
+	}
+
+	w, err := CurrentWorkspace()
+	if err != nil {
+		return deleteTarget{}, err
+	}
+	abs, err := w.Resolve(typedInput.Path)
+	if err != nil {
+		return deleteTarget{}, err
+	}
+	target := deleteTarget{path: abs, display: w.Display(typedInput.Path, abs)}
+	if within(w.Root(), abs) {
+		return target, fmt.Errorf("não é permitido apagar a raiz do workspace")
+	}
+	target.info, err = os.Lstat(abs)
+	if err != nil {
+		return target, fmt.Errorf("erro ao apagar '%s': %w", typedInput.Path, err)
+	}
+
+	if target.info.IsDir() {
+		if target.items, target.size, err = treeSize(abs); err != nil {
+			return target, fmt.Errorf("erro ao ler o diretório '%s': %w", typedInput.Path, err)
+		}
+		if target.items > 0 && !typedInput.Recursive {
+			return target, fmt.Errorf("'%s' é um diretório com %d item(ns); use \"recursive\": true para apagá-lo com todo o conteúdo", target.display, target.items)
+		}
+	} else {
+		target.size = target.info.Size()
+	}
+	return target, nil
+}
+
+// describe descreve o que será apagado, ex: "o diretório 'x' com 3 item(ns)".
+func (t deleteTarget) describe() string {
+	switch kind := describeKind(t.info); {
+	case t.info.IsDir() && t.items > 0:
+		return fmt.Sprintf("o diretório '%s' com %d item(ns), %d bytes", t.display, t.items, t.size)
+	case t.info.Mode().IsRegular():
+		return fmt.Sprintf("o arquivo '%s' (%d bytes)", t.display, t.size)
+	default:
+		return fmt.Sprintf("o %s '%s'", kind, t.display)
+	}
+}
+
+func deletePath(input json.RawMessage) (string, error) {
+	target, err := planDelete(input)
+	if err != nil {
+		return "", err
+	}
+	if err := recordCheckpointNoFollow("delete_path", target.path); err != nil {
+		return "", err
+	}
+	if err := os.RemoveAll(target.path); err != nil {
+		return "", fmt.Errorf("erro ao apagar '%s': %w", target.display, err)
+	}
+	return fmt.Sprintf("Apagado %s.", target.describe()), nil
+}
+
+// previewDeletePath descreve o que deletePath faria, sem apagar nada.
+func previewDeletePath(input json.RawMessage) (string, error) {
+	target, err := planDelete(input)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Apagaria %s", target.describe()), nil
+}
+
+var DeletePathDef = toolkit.ToolDefinition{
+	Name:        "delete_path",
+	Description: `Apaga um arquivo, link simbólico ou diretório vazio do workspace. Diretórios com conteúdo só são apagados com "recursive": true. A alteração pode ser desfeita com /undo. Exemplo: {"path": "build/antigo.txt"} ou {"path": "tmp", "recursive": true}`,
+	Function:    deletePath,
+	Mutating:    true,
+	Preview:     previewDeletePath,
+}
+
+// ::: Ferramentas: MovePath e CopyPath :::
+
+type MovePathInput struct {
+	Source      string `json:"source"`
+	Destination string `json:"destination"`
+	Overwrite   bool   `json:"overwrite,omitempty"`
+}
+
+type CopyPathInput struct {
+	Source      string `json:"source"`
+	Destination string `json:"destination"`
+	Recursive   bool   `json:"recursive,omitempty"`
+	Overwrite   bool   `json:"overwrite,omitempty"`
+}
+
+// transfer é uma cópia ou movimentação já validada.
+type transfer struct {
+	src, dst               string
+	srcDisplay, dstDisplay string
+	srcInfo                fs.FileInfo
+	replaces               bool   // o destino existe e será substituído
+	missing                string // primeiro diretório do destino que ainda não existe
+}
+
+// planTransfer valida origem e destino. A origem de uma cópia pode estar nos diretórios
+// somente leitura; o destino sempre fica no workspace. Um destino existente só é
+// substituído com overwrite, e nunca se for um diretório.
+func planTransfer(verb, source, destination string, overwrite, readSource bool) (transfer, error) {
+	if source == "" || destination == "" {
+		return transfer{}, fmt.Errorf("argumentos inválidos. 'source' e 'destination' são obrigatórios")
+	}
+	w, err := CurrentWorkspace()
+	if err != nil {
+		return transfer{}, err
+	}
+	var t transfer
+	if readSource {
+		t.src, err = w.ResolveRead(source)
+	} else {
+		t.src, err = w.Resolve(source)
+	}
+	if err != nil {
+		return t, err
+	}
+	if t.dst, err = w.Resolve(destination); err != nil {
+		return t, err
+	}
+	t.srcDisplay, t.dstDisplay = w.Display(source, t.src), w.Display(destination, t.dst)
+
+	if t.srcInfo, err = os.Lstat(t.src); err != nil {
+		return t, fmt.Errorf("erro ao %s '%s': %w", verb, t.srcDisplay, err)
+	}
+	switch {
+	case t.src == t.dst:
+		return t, fmt.Errorf("origem e destino são o mesmo caminho: '%s'", t.srcDisplay)
+	case !readSource && within(w.Root(), t.src):
+		return t, fmt.Errorf("não é permitido %s a raiz do workspace", verb)
+	case t.srcInfo.IsDir() && within(t.dst, t.src):
+		return t, fmt.Errorf("não é possível %s o diretório '%s' para dentro dele mesmo", verb, t.srcDisplay)
+	}
+
+	if dstInfo, err := os.Lstat(t.dst); err == nil {
+		switch {
+		case dstInfo.IsDir():
+			return t, fmt.Errorf("o destino '%s' é um diretório; informe o caminho final (ex: '%s') ou apague-o antes com delete_path", t.dstDisplay, filepath.Join(t.dstDisplay, filepath.Base(t.src)))
+		case !overwrite:
+			return t, fmt.Errorf("o destino '%s' já existe; use \"overwrite\": true para substituí-lo", t.dstDisplay)
+		}
+		t.replaces = true
+	}
+	t.missing = firstMissing(filepath.Dir(t.dst))
+	return t, nil
+}
+
+// destinationCheckpoint retorna o caminho do destino a registrar no checkpoint: o
+// primeiro diretório criado, ou o próprio destino.
+func (t transfer) destinationCheckpoint() string {
+	if t.missing != "" {
+		return t.missing
+	}
+	return t.dst
+}
+
+func parseMove(input json.RawMessage) (transfer, error) {
+	var typedInput MovePathInput
+	if err := json.Unmarshal(input, &typedInput); err != nil {
+		return transfer{}, fmt.Errorf("JSON inválido para argumentos: %w", err)
+	}
+	return planTransfer("mover", typedInput.Source, typedInput.Destination, typedInput.Overwrite, false)
+}
+
+func movePath(input json.RawMessage) (string, error) {
+	t, err := parseMove(input)
+	if err != nil {
+		return "", err
+	}
+	if err := recordCheckpointNoFollow("move_path", t.src, t.destinationCheckpoint()); err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(t.dst), 0755); err != nil {
+		return "", fmt.Errorf("erro ao criar o diretório de destino: %w", err)
+	}
+	if err := os.Rename(t.src, t.dst); err != nil {
+		return "", fmt.Errorf("erro ao mover '%s': %w", t.srcDisplay, err)
+	}
+	result := fmt.Sprintf("'%s' movido para '%s'", t.srcDisplay, t.dstDisplay)
+	if t.replaces {
+		result += " (o arquivo anterior foi substituído)"
+	}
+	return result + ".", nil
+}
+
+// previewMovePath descreve o que movePath faria, sem mover nada.
+func previewMovePath(input json.RawMessage) (string, error) {
+	t, err := parseMove(input)
+	if err != nil {
+		return "", err
+	}
+	preview := fmt.Sprintf("Moveria o %s '%s' para '%s'", describeKind(t.srcInfo), t.srcDisplay, t.dstDisplay)
+	if t.replaces {
+		preview += ", substituindo o arquivo existente"
+	}
+	return preview, nil
+}
+
+var MovePathDef = toolkit.ToolDefinition{
+	Name:        "move_path",
+	Description: `Move ou renomeia um arquivo ou diretório dentro do workspace, criando os diretórios do destino se preciso. "destination" é o caminho final, não a pasta de destino. Um arquivo existente no destino só é substituído com "overwrite": true. A alteração pode ser desfeita com /undo. Exemplo: {"source": "util.go", "destination": "internal/util/util.go"}`,
+	Function:    movePath,
+	Mutating:    true,
+	Preview:     previewMovePath,
+}
+
+func parseCopy(input json.RawMessage) (transfer, error) {
+	var typedInput CopyPathInput
+	if err := json.Unmarshal(input, &typedInput); err != nil {
+		return transfer{}, fmt.Errorf("JSON inválido para argumentos: %w", err)
+	}
+	t, err := planTransfer("copiar", typedInput.Source, typedInput.Destination, typedInput.Overwrite, true)
+	if err != nil {
+		return t, err
+	}
+	if t.srcInfo.IsDir() && !typedInput.Recursive {
+		return t, fmt.Errorf("'%s' é um diretório; use \"recursive\": true para copiá-lo com todo o conteúdo", t.srcDisplay)
+	}
+	return t, nil
+}
+
+func copyPath(input json.RawMessage) (string, error) {
+	t, err := parseCopy(input)
+	if err != nil {
+		return "", err
+	}
+	if err := recordCheckpointNoFollow("copy_path", t.destinationCheckpoint()); err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(t.dst), 0755); err != nil {
+		return "", fmt.Errorf("erro ao criar o diretório de destino: %w", err)
+	}
+	if t.replaces {
+		// Remove antes de escrever para não seguir um link simbólico no destino.
+		if err := os.Remove(t.dst); err != nil {
+			return "", fmt.Errorf("erro ao substituir '%s': %w", t.dstDisplay, err)
+		}
+	}
+	files, size, err := copyTree(t.src, t.dst)
+	if err != nil {
+		return "", fmt.Errorf("erro ao copiar '%s': %w", t.srcDisplay, err)
+	}
+	return fmt.Sprintf("'%s' copiado para '%s' (%d arquivo(s), %d bytes).", t.srcDisplay, t.dstDisplay, files, size), nil
+}
+
+// copyTree copia src para dst mantendo as permissões. Links simbólicos são recriados
+// com o mesmo destino em vez de seguidos.
+func copyTree(src, dst string) (files int, size int64, err error) {
+	err = filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm())
+		case d.Type()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(p)
+			if err != nil {
+				return err
+			}
+			files++
+			return os.Symlink(link, target)
+		case d.Type().IsRegular():
+			files++
+			size += info.Size()
+			return copyFile(p, target, info.Mode().Perm())
+		default:
+			return fmt.Errorf("'%s' não é um arquivo regular", p)
+		}
+	})
+	return files, size, err
+}
+
+func copyFile(src, dst string, mode fs.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// previewCopyPath descreve o que copyPath faria, sem copiar nada.
+func previewCopyPath(input json.RawMessage) (string, error) {
+	t, err := parseCopy(input)
+	if err != nil {
+		return "", err
+	}
+	files, size := 1, t.srcInfo.Size()
+	if t.srcInfo.IsDir() {
+		if files, size, err = treeSize(t.src); err != nil {
+			return "", fmt.Errorf("erro ao ler o diretório '%s': %w", t.srcDisplay, err)
+		}
+	}
+	preview := fmt.Sprintf("Copiaria o %s '%s' para '%s' (%d item(ns), %d bytes)", describeKind(t.srcInfo), t.srcDisplay, t.dstDisplay, files, size)
+	if t.replaces {
+		preview += ", substituindo o arquivo existente"
+	}
+	return preview, nil
+}
+
+var CopyPathDef = toolkit.ToolDefinition{
+	Name:        "copy_path",
+	Description: `Copia um arquivo ou diretório para outro caminho do workspace, mantendo as permissões; a origem também pode estar em um diretório liberado para leitura. "destination" é o caminho final. Diretórios exigem "recursive": true e um arquivo existente no destino só é substituído com "overwrite": true. A alteração pode ser desfeita com /undo. Exemplo: {"source": "config.example.json", "destination": "config.json"}`,
+	Function:    copyPath,
+	Mutating:    true,
+	Preview:     previewCopyPath,
+}
@@ -0,0 +1,284 @@
+package builtin
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/matheusbuniotto/goagent/internal/checkpoint"
+)
+
+// setupPathTools cria um workspace próprio com alguns arquivos e um store de checkpoints.
+func setupPathTools(t *testing.T) (root string, store *checkpoint.Store) {
+	t.Helper()
+	root, store = newTestWorkspaceWithCheckpoints(t, map[string]string{
+		"a.txt":         "conteúdo a",
+		"b.txt":         "conteúdo b",
+		"dir/x.txt":     "x",
+		"dir/sub/y.txt": "yy",
+	})
+	_ = os.Mkdir(filepath.Join(root, "vazio"), 0755)
+	_ = os.Symlink("a.txt", filepath.Join(root, "link"))
+	return root, store
+}
+
+// TestPathTools testa stat, delete, move e copy, incluindo as recusas.
+func TestPathTools(t *testing.T) {
+	testCases := []struct {
+		name          string
+		tool          func(json.RawMessage) (string, error)
+		input         any
+		expected      string
+		expectedError string
+		check         func(t *testing.T, root string)
+	}{
+		{
+			name:     "Stat de arquivo",
+			tool:     statPath,
+			input:    StatPathInput{Path: "dir/x.txt"},
+			expected: "Caminho: dir/x.txt\nTipo: arquivo\nTamanho: 1 bytes\nPermissões: -rw-r--r--",
+		},
+		{
+			name:     "Stat de diretório",
+			tool:     statPath,
+			input:    StatPathInput{Path: "dir"},
+			expected: "Tipo: diretório\nItens: 2",
+		},
+		{
+			name:     "Stat de link",
+			tool:     statPath,
+			input:    StatPathInput{Path: "link"},
+			expected: "Tipo: link simbólico → a.txt (arquivo)\nTamanho: 11 bytes",
+		},
+		{
+			name:     "Stat de caminho inexistente",
+			tool:     statPath,
+			input:    StatPathInput{Path: "nada/aqui"},
+			expected: "'nada/aqui' não existe.",
+		},
+		{
+			name:          "Stat fora do workspace",
+			tool:          statPath,
+			input:         StatPathInput{Path: "/etc/passwd"},
+			expectedError: "fora do workspace",
+		},
+		{
+			name:     "Apaga arquivo",
+			tool:     deletePath,
+			input:    DeletePathInput{Path: "a.txt"},
+			expected: "Apagado o arquivo 'a.txt' (11 bytes).",
+			check:    assertMissing("a.txt"),
+		},
+		{
+			name:     "Apaga diretório vazio sem recursive",
+			tool:     deletePath,
+			input:    DeletePathInput{Path: "vazio"},
+			expected: "Apagado o diretório 'vazio'.",
+			check:    assertMissing("vazio"),
+		},
+		{
+			name:          "Recusa diretório com conteúdo sem recursive",
+			tool:          deletePath,
+			input:         DeletePathInput{Path: "dir"},
+			expectedError: "'dir' é um diretório com 3 item(ns); use \"recursive\": true",
+		},
+		{
+			name:     "Apaga diretório com recursive",
+			tool:     deletePath,
+			input:    DeletePathInput{Path: "dir", Recursive: true},
+			expected: "Apagado o diretório 'dir' com 3 item(ns), 3 bytes.",
+			check:    assertMissing("dir"),
+		},
+		{
+			name:     "Apaga só o link",
+			tool:     deletePath,
+			input:    DeletePathInput{Path: "link"},
+			expected: "Apagado o link simbólico 'link'.",
+			check: func(t *testing.T, root string) {
+				assertMissing("link")(t, root)
+				if _, err := os.Stat(filepath.Join(root, "a.txt")); err != nil {
+					t.Error("apagar o link não deveria apagar o destino")
+				}
+			},
+		},
+		{
+			name:          "Recusa apagar a raiz",
+			tool:          deletePath,
+			input:         DeletePathInput{Path: ".", Recursive: true},
+			expectedError: "raiz do workspace",
+		},
+		{
+			name:          "Apaga caminho inexistente",
+			tool:          deletePath,
+			input:         DeletePathInput{Path: "nada.txt"},
+			expectedError: "erro ao apagar 'nada.txt'",
+		},
+		{
+			name:     "Move criando diretórios",
+			tool:     movePath,
+			input:    MovePathInput{Source: "a.txt", Destination: "novo/pasta/a.txt"},
+			expected: "'a.txt' movido para 'novo/pasta/a.txt'.",
+			check:    assertContent("novo/pasta/a.txt", "conteúdo a"),
+		},
+		{
+			name:          "Recusa sobrescrever no move",
+			tool:          movePath,
+			input:         MovePathInput{Source: "a.txt", Destination: "b.txt"},
+			expectedError: "o destino 'b.txt' já existe",
+		},
+		{
+			name:     "Move com overwrite",
+			tool:     movePath,
+			input:    MovePathInput{Source: "a.txt", Destination: "b.txt", Overwrite: true},
+			expected: "'a.txt' movido para 'b.txt' (o arquivo anterior foi substituído).",
+			check:    assertContent("b.txt", "conteúdo a"),
+		},
+		{
+			name:          "Recusa destino que é diretório",
+			tool:          movePath,
+			input:         MovePathInput{Source: "a.txt", Destination: "dir", Overwrite: true},
+			expectedError: "informe o caminho final (ex: 'dir/a.txt')",
+		},
+		{
+			name:          "Recusa mover para dentro de si",
+			tool:          movePath,
+			input:         MovePathInput{Source: "dir", Destination: "dir/sub/dir"},
+			expectedError: "para dentro dele mesmo",
+		},
+		{
+			name:          "Move para fora do workspace",
+			tool:          movePath,
+			input:         MovePathInput{Source: "a.txt", Destination: "../fora.txt"},
+			expectedError: "fora do workspace",
+		},
+		{
+			name:     "Copia arquivo",
+			tool:     copyPath,
+			input:    CopyPathInput{Source: "a.txt", Destination: "c.txt"},
+			expected: "'a.txt' copiado para 'c.txt' (1 arquivo(s), 11 bytes).",
+			check:    assertContent("c.txt", "conteúdo a"),
+		},
+		{
+			name:          "Recusa copiar diretório sem recursive",
+			tool:          copyPath,
+			input:         CopyPathInput{Source: "dir", Destination: "dir2"},
+			expectedError: "use \"recursive\": true para copiá-lo",
+		},
+		{
+			name:     "Copia diretório com recursive",
+			tool:     copyPath,
+			input:    CopyPathInput{Source: "dir", Destination: "copia/dir", Recursive: true},
+			expected: "'dir' copiado para 'copia/dir' (2 arquivo(s), 3 bytes).",
+			check:    assertContent("copia/dir/sub/y.txt", "yy"),
+		},
+		{
+			name:     "Copia sobrescrevendo",
+			tool:     copyPath,
+			input:    CopyPathInput{Source: "a.txt", Destination: "b.txt", Overwrite: true},
+			expected: "'a.txt' copiado para 'b.txt' (1 arquivo(s), 11 bytes).",
+			check:    assertContent("b.txt", "conteúdo a"),
+		},
+		{
+			name:          "Copia sem origem",
+			tool:          copyPath,
+			input:         CopyPathInput{Destination: "b.txt"},
+			expectedError: "'source' e 'destination' são obrigatórios",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			root, _ := setupPathTools(t)
+			rawInput, _ := json.Marshal(tc.input)
+			result, err := tc.tool(rawInput)
+			if tc.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedError) {
+					t.Fatalf("erro = %v, esperado conter %q", err, tc.expectedError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+			if !strings.Contains(result, tc.expected) {
+				t.Errorf("resultado =\n%s\nesperado conter\n%s", result, tc.expected)
+			}
+			if tc.check != nil {
+				tc.check(t, root)
+			}
+		})
+	}
+}
+
+func assertMissing(name string) func(*testing.T, string) {
+	return func(t *testing.T, root string) {
+		t.Helper()
+		if _, err := os.Lstat(filepath.Join(root, name)); !os.IsNotExist(err) {
+			t.Errorf("'%s' deveria ter sido removido", name)
+		}
+	}
+}
+
+func assertContent(name, expected string) func(*testing.T, string) {
+	return func(t *testing.T, root string) {
+		t.Helper()
+		if data, err := os.ReadFile(filepath.Join(root, name)); err != nil || string(data) != expected {
+			t.Errorf("conteúdo de '%s' = %q (%v), esperado %q", name, data, err, expected)
+		}
+	}
+}
+
+// TestPathToolsUndo testa que apagar, mover e copiar podem ser desfeitos pelos checkpoints.
+func TestPathToolsUndo(t *testing.T) {
+	root, store := setupPathTools(t)
+
+	calls := []struct {
+		tool  func(json.RawMessage) (string, error)
+		input any
+	}{
+		{deletePath, DeletePathInput{Path: "dir", Recursive: true}},
+		{deletePath, DeletePathInput{Path: "link"}},
+		{movePath, MovePathInput{Source: "a.txt", Destination: "b.txt", Overwrite: true}},
+		{copyPath, CopyPathInput{Source: "b.txt", Destination: "novo/c.txt"}},
+	}
+	for _, c := range calls {
+		rawInput, _ := json.Marshal(c.input)
+		if _, err := c.tool(rawInput); err != nil {
+			t.Fatalf("erro inesperado: %v", err)
+		}
+	}
+
+	if _, err := store.Revert(1); err != nil {
+		t.Fatalf("Revert() erro inesperado: %v", err)
+	}
+	assertContent("a.txt", "conteúdo a")(t, root)
+	assertContent("b.txt", "conteúdo b")(t, root)
+	assertContent("dir/sub/y.txt", "yy")(t, root)
+	assertMissing("novo")(t, root)
+	if target, err := os.Readlink(filepath.Join(root, "link")); err != nil || target != "a.txt" {
+		t.Errorf("link deveria ser restaurado como link para a.txt: %q %v", target, err)
+	}
+}
+
+// TestPathToolsPreview testa as descrições do dry-run.
+func TestPathToolsPreview(t *testing.T) {
+	setupPathTools(t)
+	testCases := []struct {
+		preview  func(json.RawMessage) (string, error)
+		input    any
+		expected string
+	}{
+		{previewDeletePath, DeletePathInput{Path: "dir", Recursive: true}, "Apagaria o diretório 'dir' com 3 item(ns), 3 bytes"},
+		{previewMovePath, MovePathInput{Source: "a.txt", Destination: "b.txt", Overwrite: true}, "Moveria o arquivo 'a.txt' para 'b.txt', substituindo o arquivo existente"},
+		{previewCopyPath, CopyPathInput{Source: "dir", Destination: "d2", Recursive: true}, "Copiaria o diretório 'dir' para 'd2' (3 item(ns), 3 bytes)"},
+	}
+	for _, tc := range testCases {
+		rawInput, _ := json.Marshal(tc.input)
+		got, err := tc.preview(rawInput)
+		if err != nil || got != tc.expected {
+			t.Errorf("preview = %q (%v), esperado %q", got, err, tc.expected)
+		}
+	}
+}
@@ -0,0 +1,287 @@
+package builtin
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"image"
+	_ "image/gif"  // registra o decodificador para image.DecodeConfig
+	_ "image/jpeg" // registra o decodificador para image.DecodeConfig
+	_ "image/png"  // registra o decodificador para image.DecodeConfig
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/matheusbuniotto/goagent/pkg/toolkit"
+)
+
+// :::: Ferramenta: ReadFile :::
+
+// defaultReadMaxBytes é o limite de bytes devolvidos quando max_bytes não é informado.
+const defaultReadMaxBytes = 100_000
+
+// maxDecodeBytes é o maior arquivo UTF-16 convertido em memória.
+const maxDecodeBytes = 20 << 20
+
+// sniffBytes é quanto do início do arquivo é usado para detectar a codificação.
+const sniffBytes = 8192
+
+type ReadFileInput struct {
+	Path        string `json:"path"`
+	Offset      int    `json:"offset,omitempty"`       // primeira linha (a partir de 1)
+	Limit       int    `json:"limit,omitempty"`        // quantidade de linhas
+	MaxBytes    int    `json:"max_bytes,omitempty"`    // limite de bytes do resultado
+	LineNumbers bool   `json:"line_numbers,omitempty"` // prefixa cada linha com seu número
+}
+
+func readFile(input json.RawMessage) (string, error) {
+	var typedInput ReadFileInput
+	if err := json.Unmarshal(input, &typedInput); err != nil {
+		return "", fmt.Errorf("JSON inválido para argumentos: %w", err)
+	}
+
+	if typedInput.Path == "" {
+		return "", fmt.Errorf("argumento inválido. 'path' é obrigatório")
+	}
+	if typedInput.Offset < 0 || typedInput.Limit < 0 || typedInput.MaxBytes < 0 {
+		return "", fmt.Errorf("argumentos inválidos. 'offset', 'limit' e 'max_bytes' não podem ser negativos")
+	}
+
+	path, err := resolvePath(typedInput.Path, false)
+	if err != nil {
+		return "", err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("erro ao ler o arquivo '%s': %w", typedInput.Path, err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return "", fmt.Errorf("erro ao ler o arquivo '%s': %w", typedInput.Path, err)
+	}
+	if info.IsDir() {
+		return "", fmt.Errorf("'%s' é um diretório; use list_files", typedInput.Path)
+	}
+
+	br := bufio.NewReaderSize(f, sniffBytes)
+	sample, _ := br.Peek(sniffBytes)
+	encoding := detectEncoding(sample)
+
+	var text io.Reader = br
+	switch encoding {
+	case "binário":
+		return describeBinary(typedInput.Path, path, info.Size(), sample), nil
+	case "UTF-8 com BOM":
+		br.Discard(3)
+	case "UTF-16LE", "UTF-16BE":
+		if info.Size() > maxDecodeBytes {
+			return "", fmt.Errorf("arquivo '%s' em %s grande demais para converter (%d bytes)", typedInput.Path, encoding, info.Size())
+		}
+		data, err := io.ReadAll(br)
+		if err != nil {
+			return "", fmt.Errorf("erro ao ler o arquivo '%s': %w", typedInput.Path, err)
+		}
+		text = strings.NewReader(decodeUTF16(data[2:], encoding == "UTF-16BE"))
+	case "ISO-8859-1":
+		text = &latin1Reader{r: br}
+	}
+
+	maxBytes := typedInput.MaxBytes
+	if maxBytes == 0 {
+		maxBytes = defaultReadMaxBytes
+	}
+	content, notice, err := selectLines(text, typedInput.Offset, typedInput.Limit, maxBytes, typedInput.LineNumbers)
+	if err != nil {
+		return "", fmt.Errorf("erro ao ler o arquivo '%s': %w", typedInput.Path, err)
+	}
+
+	if encoding != "UTF-8" {
+		notice = append([]string{fmt.Sprintf("codificação detectada: %s, convertida para UTF-8", encoding)}, notice...)
+	}
+	if len(notice) > 0 {
+		content += "\n\n[" + strings.Join(notice, "; ") + "]"
+	}
+	return content, nil
+}
+
+// selectLines copia as linhas pedidas de r, respeitando o limite de bytes, e retorna os
+// avisos de truncamento. O restante do arquivo é percorrido só para contar as linhas.
+func selectLines(r io.Reader, offset, limit, maxBytes int, numbers bool) (string, []string, error) {
+	if offset == 0 {
+		offset = 1
+	}
+	br := bufio.NewReader(r)
+	var out strings.Builder
+	total, first, last := 0, 0, 0
+	cutByBytes, cutLine := false, false
+
+	for {
+		line, err := br.ReadString('\n')
+		if line != "" {
+			total++
+			selected := total >= offset && (limit == 0 || total < offset+limit)
+			if selected && !cutByBytes {
+				if numbers {
+					line = fmt.Sprintf("%6d\t%s", total, line)
+				}
+				if out.Len()+len(line) > maxBytes {
+					cutByBytes = true
+					// Uma única linha maior que o limite é cortada em vez de omitida.
+					if first == 0 {
+						out.WriteString(truncateUTF8(line, maxBytes))
+						first, last, cutLine = total, total, true
+					}
+				} else {
+					out.WriteString(line)
+					if first == 0 {
+						first = total
+					}
+					last = total
+				}
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", nil, err
+		}
+	}
+
+	if total > 0 && offset > total {
+		return "", nil, fmt.Errorf("offset %d além do fim do arquivo (%d linhas)", offset, total)
+	}
+
+	var notice []string
+	if cutLine {
+		notice = append(notice, fmt.Sprintf("linha %d cortada em %d bytes", first, maxBytes))
+	}
+	if first > 1 || last < total {
+		shown := fmt.Sprintf("exibindo linhas %d-%d de %d", first, last, total)
+		if cutByBytes && !cutLine {
+			shown += fmt.Sprintf(" (limite de %d bytes atingido)", maxBytes)
+		}
+		if last < total {
+			shown += fmt.Sprintf("; use \"offset\": %d para continuar", last+1)
+		}
+		notice = append(notice, shown)
+	}
+	return out.String(), notice, nil
+}
+
+// truncateUTF8 corta s em no máximo n bytes sem quebrar um caractere.
+func truncateUTF8(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+// detectEncoding identifica a codificação pelo início do arquivo: BOMs, bytes nulos
+// (binário), UTF-8 válido ou, como último recurso, ISO-8859-1 se parecer texto.
+func detectEncoding(sample []byte) string {
+	switch {
+	case bytes.HasPrefix(sample, []byte{0xEF, 0xBB, 0xBF}):
+		return "UTF-8 com BOM"
+	case bytes.HasPrefix(sample, []byte{0xFF, 0xFE}):
+		return "UTF-16LE"
+	case bytes.HasPrefix(sample, []byte{0xFE, 0xFF}):
+		return "UTF-16BE"
+	case bytes.IndexByte(sample, 0) >= 0:
+		return "binário"
+	}
+
+	// Um caractere cortado no fim da amostra não torna o arquivo inválido.
+	trimmed := sample
+	for i := 1; i <= utf8.UTFMax && i <= len(sample); i++ {
+		if utf8.RuneStart(sample[len(sample)-i]) {
+			if !utf8.FullRune(sample[len(sample)-i:]) {
+				trimmed = sample[:len(sample)-i]
+			}
+			break
+		}
+	}
+	if utf8.Valid(trimmed) {
+		return "UTF-8"
+	}
+
+	control := 0
+	for _, b := range sample {
+		if (b < 0x20 && b != '\t' && b != '\n' && b != '\r' && b != '\f') || b == 0x7F {
+			control++
+		}
+	}
+	if control*10 > len(sample) {
+		return "binário"
+	}
+	return "ISO-8859-1"
+}
+
+// decodeUTF16 converte UTF-16 (sem o BOM) para UTF-8.
+func decodeUTF16(data []byte, bigEndian bool) string {
+	order := binary.ByteOrder(binary.LittleEndian)
+	if bigEndian {
+		order = binary.BigEndian
+	}
+	units := make([]uint16, len(data)/2)
+	for i := range units {
+		units[i] = order.Uint16(data[2*i:])
+	}
+	return string(utf16.Decode(units))
+}
+
+// latin1Reader converte ISO-8859-1 para UTF-8 enquanto lê.
+type latin1Reader struct {
+	r       io.ByteReader
+	pending []byte
+}
+
+func (l *latin1Reader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		if len(l.pending) > 0 {
+			c := copy(p[n:], l.pending)
+			l.pending = l.pending[c:]
+			n += c
+			continue
+		}
+		b, err := l.r.ReadByte()
+		if err != nil {
+			if n > 0 {
+				return n, nil
+			}
+			return 0, err
+		}
+		l.pending = utf8.AppendRune(l.pending[:0], rune(b))
+	}
+	return n, nil
+}
+
+// describeBinary retorna metadados de um arquivo binário no lugar do conteúdo.
+func describeBinary(requested, path string, size int64, sample []byte) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[arquivo binário, conteúdo omitido]\ncaminho: %s\ntamanho: %d bytes\ntipo: %s\n", requested, size, http.DetectContentType(sample))
+	if f, err := os.Open(path); err == nil {
+		defer f.Close()
+		if cfg, format, err := image.DecodeConfig(f); err == nil {
+			fmt.Fprintf(&b, "imagem: %s, %dx%d pixels\n", format, cfg.Width, cfg.Height)
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+var ReadFileDef = toolkit.ToolDefinition{
+	Name:        "read_file",
+	Description: `Lê o conteúdo de um arquivo de texto. Requer um objeto JSON com a chave "path". Opcionais: "offset" (primeira linha, a partir de 1) e "limit" (quantidade de linhas) para ler trechos de arquivos grandes, "max_bytes" (padrão 100000) e "line_numbers": true para numerar as linhas. Arquivos binários retornam apenas metadados. Exemplo: {"path": "caminho/arquivo.txt", "offset": 100, "limit": 50}`,
+	Function:    readFile,
+}
@@ -0,0 +1,120 @@
+package builtin
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+// TestReadFileOptions testa trechos por linha, limites de bytes e numeração.
+func TestReadFileOptions(t *testing.T) {
+	var lines []string
+	for i := 1; i <= 10; i++ {
+		lines = append(lines, strings.Repeat("x", i))
+	}
+	content := strings.Join(lines, "\n") + "\n"
+
+	testCases := []struct {
+		name          string
+		input         ReadFileInput
+		expected      string
+		expectedError string
+	}{
+		{
+			name:     "Trecho com offset e limit",
+			input:    ReadFileInput{Offset: 3, Limit: 2},
+			expected: "xxx\nxxxx\n\n\n[exibindo linhas 3-4 de 10; use \"offset\": 5 para continuar]",
+		},
+		{
+			name:     "Até o fim a partir do offset",
+			input:    ReadFileInput{Offset: 10},
+			expected: "xxxxxxxxxx\n\n\n[exibindo linhas 10-10 de 10]",
+		},
+		{
+			name:     "Numeração de linhas",
+			input:    ReadFileInput{Limit: 2, LineNumbers: true},
+			expected: "     1\tx\n     2\txx\n\n\n[exibindo linhas 1-2 de 10; use \"offset\": 3 para continuar]",
+		},
+		{
+			name:     "Limite de bytes corta em linha inteira",
+			input:    ReadFileInput{MaxBytes: 7},
+			expected: "x\nxx\n\n\n[exibindo linhas 1-2 de 10 (limite de 7 bytes atingido); use \"offset\": 3 para continuar]",
+		},
+		{
+			name:     "Linha maior que o limite",
+			input:    ReadFileInput{Offset: 10, MaxBytes: 4},
+			expected: "xxxx\n\n[linha 10 cortada em 4 bytes; exibindo linhas 10-10 de 10]",
+		},
+		{
+			name:          "Offset além do fim",
+			input:         ReadFileInput{Offset: 11},
+			expectedError: "além do fim",
+		},
+		{
+			name:          "Valores negativos",
+			input:         ReadFileInput{Limit: -1},
+			expectedError: "não podem ser negativos",
+		},
+	}
+
+	newTestWorkspace(t, map[string]string{"linhas.txt": content})
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.input.Path = "linhas.txt"
+			rawInput, _ := json.Marshal(tc.input)
+			result, err := readFile(rawInput)
+
+			if (err != nil) != (tc.expectedError != "") {
+				t.Fatalf("readFile() erro = %v, esperado erro %q", err, tc.expectedError)
+			}
+			if err != nil {
+				if !strings.Contains(err.Error(), tc.expectedError) {
+					t.Errorf("readFile() erro = %v, esperado conter %q", err, tc.expectedError)
+				}
+				return
+			}
+			if result != tc.expected {
+				t.Errorf("readFile() = %q, esperado %q", result, tc.expected)
+			}
+		})
+	}
+}
+
+// TestReadFileEncodings testa a detecção de binários e de outras codificações.
+func TestReadFileEncodings(t *testing.T) {
+	var img bytes.Buffer
+	_ = png.Encode(&img, image.NewRGBA(image.Rect(0, 0, 3, 2)))
+
+	testCases := []struct {
+		name     string
+		data     []byte
+		contains []string
+	}{
+		{"PNG", img.Bytes(), []string{"arquivo binário", "image/png", "3x2 pixels"}},
+		{"Bytes nulos", []byte("abc\x00def"), []string{"arquivo binário"}},
+		{"UTF-8 com BOM", []byte("\xEF\xBB\xBFolá"), []string{"olá", "UTF-8 com BOM"}},
+		{"UTF-16LE", []byte{0xFF, 0xFE, 'o', 0, 'i', 0}, []string{"oi", "UTF-16LE"}},
+		{"ISO-8859-1", []byte("ol\xe1 mundo"), []string{"olá mundo", "ISO-8859-1"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			newTestWorkspace(t, map[string]string{"arquivo": string(tc.data)})
+			rawInput, _ := json.Marshal(ReadFileInput{Path: "arquivo"})
+
+			result, err := readFile(rawInput)
+			if err != nil {
+				t.Fatalf("readFile() erro inesperado: %v", err)
+			}
+			for _, want := range tc.contains {
+				if !strings.Contains(result, want) {
+					t.Errorf("readFile() = %q, esperado conter %q", result, want)
+				}
+			}
+		})
+	}
+}
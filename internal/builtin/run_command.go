@@ -0,0 +1,492 @@
+package builtin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/matheusbuniotto/goagent/pkg/toolkit"
+)
+
+const (
+	defaultCommandTimeout = 120 * time.Second
+	maxCommandTimeout     = 600 * time.Second
+	defaultOutputMaxBytes = 20_000 // por fluxo (stdout e stderr)
+)
+
+// DefaultAllowedCommands são os comandos executados sem pedir aprovação. Uma regra com
+// argumentos libera só aquele prefixo: "go test" libera os testes, mas não "go run".
+var DefaultAllowedCommands = []string{
+	"ls", "pwd", "cat", "head", "tail", "wc", "grep", "diff", "echo", "date", "which",
+	"go version", "go env", "go list", "go vet", "go test",
+	"git status", "git diff", "git log", "git show",
+}
+
+// DefaultDeniedCommands são os comandos que o run_command recusa sempre, mesmo com aprovação.
+var DefaultDeniedCommands = []string{
+	"sudo", "su", "doas", "shutdown", "reboot", "halt", "poweroff", "mkfs",
+}
+
+// commandWrappers executam o comando seguinte; as regras de bloqueio valem para ele também.
+var commandWrappers = map[string]bool{
+	"env": true, "nice": true, "nohup": true, "time": true, "timeout": true, "xargs": true, "command": true,
+}
+
+// unsafeCommandFlags são opções que fazem comandos de leitura escreverem arquivos, mudarem a
+// configuração ou executarem outros binários (ex: "git diff --output", "go env -w",
+// "go test -exec"). Com elas, mesmo um comando da lista de permitidos pede aprovação.
+var unsafeCommandFlags = map[string]bool{
+	"o": true, "output": true, "w": true, "u": true,
+	"vettool": true, "exec": true, "toolexec": true, "ext-diff": true, "outputdir": true,
+	"coverprofile": true, "cpuprofile": true, "memprofile": true, "blockprofile": true, "mutexprofile": true, "trace": true,
+}
+
+// commandShells interpretam o script passado em -c.
+var commandShells = map[string]bool{"sh": true, "bash": true, "zsh": true, "dash": true}
+
+// CommandPolicy define quais comandos o run_command executa sem aprovação (Allow) e
+// quais recusa sempre (Deny). Cada regra é um binário seguido, opcionalmente, dos
+// primeiros argumentos.
+type CommandPolicy struct {
+	Allow []string
+	Deny  []string
+}
+
+// NewCommandPolicy cria uma política com as listas padrão acrescidas das regras extras.
+func NewCommandPolicy(allow, deny []string) *CommandPolicy {
+	return &CommandPolicy{
+		Allow: append(append([]string{}, DefaultAllowedCommands...), allow...),
+		Deny:  append(append([]string{}, DefaultDeniedCommands...), deny...),
+	}
+}
+
+// Allowed informa se o comando pode rodar sem aprovação. Comandos encadeados por
+// wrappers (env, xargs...) ou com opções de unsafeCommandFlags nunca são liberados
+// automaticamente.
+func (p *CommandPolicy) Allowed(argv []string) bool {
+	if len(argv) == 0 || commandWrappers[filepath.Base(argv[0])] || unsafeFlag(argv) != "" {
+		return false
+	}
+	for _, rule := range p.Allow {
+		if matchCommand(rule, argv) {
+			return true
+		}
+	}
+	return false
+}
+
+// Denied retorna a regra que bloqueia o comando, ou "" se nenhuma bloqueia. Em
+// "sh -c '...'", cada comando do script é verificado.
+func (p *CommandPolicy) Denied(argv []string) string {
+	candidates := [][]string{argv, unwrapCommand(argv)}
+	if script, ok := shellScript(unwrapCommand(argv)); ok {
+		for _, part := range strings.FieldsFunc(script, func(r rune) bool { return strings.ContainsRune("|&;()`\n", r) }) {
+			words := strings.Fields(strings.NewReplacer("'", "", "\"", "").Replace(part))
+			candidates = append(candidates, unwrapCommand(words))
+		}
+	}
+	for _, rule := range p.Deny {
+		for _, c := range candidates {
+			if matchCommand(rule, c) {
+				return rule
+			}
+		}
+	}
+	return ""
+}
+
+// shellScript extrai o script de "sh -c '...'" (ou bash, zsh).
+func shellScript(argv []string) (string, bool) {
+	if len(argv) < 3 || !commandShells[filepath.Base(argv[0])] {
+		return "", false
+	}
+	for i := 1; i < len(argv)-1; i++ {
+		if argv[i] == "-c" {
+			return argv[i+1], true
+		}
+	}
+	return "", false
+}
+
+// matchCommand compara a regra com o início do comando. O binário é comparado pelo
+// nome, para que "/bin/ls" case com a regra "ls".
+func matchCommand(rule string, argv []string) bool {
+	words := strings.Fields(rule)
+	if len(words) == 0 || len(argv) < len(words) {
+		return false
+	}
+	if filepath.Base(argv[0]) != words[0] {
+		return false
+	}
+	for i := 1; i < len(words); i++ {
+		if argv[i] != words[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// unsafeFlag retorna a primeira opção de argv que está em unsafeCommandFlags, ou "".
+// Aceita as formas "-o", "--output" e "--output=arquivo".
+func unsafeFlag(argv []string) string {
+	for _, arg := range argv[1:] {
+		if arg == "--" {
+			break
+		}
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+		name, _, _ := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if unsafeCommandFlags[name] {
+			return arg
+		}
+	}
+	return ""
+}
+
+// commandArgsInWorkspace verifica se os argumentos do comando, executado em dir, não
+// apontam para fora do workspace. Todo argumento que não é opção, e o valor de opções
+// como "--file=x", é tratado como caminho e validado com ResolveRead.
+func commandArgsInWorkspace(w *Workspace, dir string, argv []string) error {
+	options := true
+	for _, arg := range argv[1:] {
+		path := arg
+		switch {
+		case options && arg == "--":
+			options = false
+			continue
+		case options && strings.HasPrefix(arg, "-"):
+			_, value, ok := strings.Cut(arg, "=")
+			if !ok {
+				continue
+			}
+			path = value
+		}
+		if path == "" {
+			continue
+		}
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		if _, err := w.ResolveRead(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unwrapCommand remove wrappers como "env FOO=1" ou "nice -n 10" do início do comando,
+// pulando opções, atribuições e números.
+func unwrapCommand(argv []string) []string {
+	for len(argv) > 0 && commandWrappers[filepath.Base(argv[0])] {
+		argv = argv[1:]
+		for len(argv) > 0 {
+			a := argv[0]
+			if !strings.HasPrefix(a, "-") && !strings.Contains(a, "=") && (a == "" || a[0] < '0' || a[0] > '9') {
+				break
+			}
+			argv = argv[1:]
+		}
+	}
+	return argv
+}
+
+var (
+	commandPolicyMu sync.RWMutex
+	commandPolicy   *CommandPolicy
+)
+
+// SetCommandPolicy define a política usada pelo run_command.
+func SetCommandPolicy(p *CommandPolicy) {
+	commandPolicyMu.Lock()
+	defer commandPolicyMu.Unlock()
+	commandPolicy = p
+}
+
+// CurrentCommandPolicy retorna a política em uso. Se nenhuma foi definida, usa as listas padrão.
+func CurrentCommandPolicy() *CommandPolicy {
+	commandPolicyMu.RLock()
+	defer commandPolicyMu.RUnlock()
+	if commandPolicy == nil {
+		return NewCommandPolicy(nil, nil)
+	}
+	return commandPolicy
+}
+
+// splitCommand separa a linha de comando em argumentos, respeitando aspas simples,
+// aspas duplas e barras invertidas. Não há shell: pipes, redirecionamentos e
+// encadeamentos são recusados em vez de passados como argumentos literais.
+func splitCommand(s string) ([]string, error) {
+	var (
+		args    []string
+		cur     strings.Builder
+		inWord  bool
+		quote   rune
+		escaped bool
+	)
+	for _, r := range s {
+		switch {
+		case escaped:
+			cur.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped, inWord = true, true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				cur.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inWord = r, true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				args = append(args, cur.String())
+				cur.Reset()
+				inWord = false
+			}
+		case strings.ContainsRune("|&;<>`", r) || (r == '$' && quote == 0):
+			return nil, fmt.Errorf("operador de shell '%c' não é interpretado; rode um comando por vez ou use \"sh -c '...'\" (exige aprovação)", r)
+		default:
+			cur.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 || escaped {
+		return nil, fmt.Errorf("aspas ou barra invertida sem fechamento no comando")
+	}
+	if inWord {
+		args = append(args, cur.String())
+	}
+	return args, nil
+}
+
+// scrubEnv remove do ambiente as variáveis que parecem credenciais, como as chaves de
+// API dos provedores, para que o comando não as veja nem as imprima.
+func scrubEnv(env []string) []string {
+	var kept []string
+	for _, kv := range env {
+		name, _, _ := strings.Cut(kv, "=")
+		upper := strings.ToUpper(name)
+		secret := strings.HasSuffix(upper, "_KEY") || strings.HasSuffix(upper, "APIKEY")
+		for _, s := range []string{"API_KEY", "TOKEN", "SECRET", "PASSWORD", "PASSWD", "CREDENTIAL"} {
+			secret = secret || strings.Contains(upper, s)
+		}
+		if !secret {
+			kept = append(kept, kv)
+		}
+	}
+	return kept
+}
+
+// cappedBuffer guarda o início e o fim de uma saída longa, descartando o meio: em
+// testes e compilações, as mensagens importantes costumam estar no final.
+type cappedBuffer struct {
+	max     int
+	head    []byte
+	tail    []byte
+	dropped int
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	n := len(p)
+	if room := b.max/2 - len(b.head); room > 0 {
+		if room > len(p) {
+			room = len(p)
+		}
+		b.head = append(b.head, p[:room]...)
+		p = p[room:]
+	}
+	b.tail = append(b.tail, p...)
+	if extra := len(b.tail) - (b.max - b.max/2); extra > 0 {
+		b.dropped += extra
+		b.tail = b.tail[:copy(b.tail, b.tail[extra:])]
+	}
+	return n, nil
+}
+
+func (b *cappedBuffer) String() string {
+	if b.dropped == 0 {
+		return string(b.head) + string(b.tail)
+	}
+	tail := b.tail
+	for len(tail) > 0 && !utf8.RuneStart(tail[0]) {
+		tail = tail[1:]
+	}
+	return fmt.Sprintf("%s\n[... %d bytes omitidos ...]\n%s", strings.ToValidUTF8(string(b.head), ""), b.dropped, tail)
+}
+
+// RunCommandInput define os argumentos de run_command.
+type RunCommandInput struct {
+	Command  string `json:"command"`             // Linha de comando, sem shell
+	Dir      string `json:"dir,omitempty"`       // Diretório de trabalho, relativo ao workspace
+	Timeout  int    `json:"timeout,omitempty"`   // Tempo limite em segundos
+	MaxBytes int    `json:"max_bytes,omitempty"` // Limite de bytes guardados por fluxo
+}
+
+// parseCommand valida a entrada e separa a linha de comando em argumentos.
+func parseCommand(input json.RawMessage) (RunCommandInput, []string, error) {
+	var typedInput RunCommandInput
+	if err := json.Unmarshal(input, &typedInput); err != nil {
+		return typedInput, nil, fmt.Errorf("JSON inválido para argumentos: %w", err)
+	}
+	if strings.TrimSpace(typedInput.Command) == "" {
+		return typedInput, nil, errors.New("argumento inválido. 'command' é obrigatório")
+	}
+	argv, err := splitCommand(typedInput.Command)
+	if err != nil {
+		return typedInput, nil, err
+	}
+	if len(argv) == 0 {
+		return typedInput, nil, errors.New("argumento inválido. 'command' é obrigatório")
+	}
+	return typedInput, argv, nil
+}
+
+// runCommandMutating decide se a chamada passa pela política de aprovação: só os
+// comandos da lista de permitidos cujos argumentos ficam dentro do workspace rodam direto.
+func runCommandMutating(input json.RawMessage) bool {
+	typedInput, argv, err := parseCommand(input)
+	if err != nil {
+		return false // A chamada falha de qualquer forma; não há o que aprovar.
+	}
+	policy := CurrentCommandPolicy()
+	if policy.Denied(argv) != "" {
+		return false
+	}
+	if !policy.Allowed(argv) {
+		return true
+	}
+	w, err := CurrentWorkspace()
+	if err != nil {
+		return false
+	}
+	dir, err := w.Resolve(typedInput.Dir)
+	if err != nil {
+		return false
+	}
+	return commandArgsInWorkspace(w, dir, argv) != nil
+}
+
+// commandTimeout converte o tempo limite pedido, em segundos, aplicando o padrão e o máximo.
+func commandTimeout(seconds int) time.Duration {
+	if seconds <= 0 {
+		return defaultCommandTimeout
+	}
+	return min(time.Duration(seconds)*time.Second, maxCommandTimeout)
+}
+
+// execResult descreve como terminou um processo executado por execInWorkspace.
+type execResult struct {
+	exitCode int
+	timedOut bool
+	elapsed  time.Duration
+}
+
+// execInWorkspace executa argv em dir com o ambiente sem credenciais, encerrando o
+// processo no tempo limite ou quando ctx é cancelado (nesse caso retorna ctx.Err()).
+func execInWorkspace(ctx context.Context, dir string, argv []string, timeout time.Duration, stdout, stderr io.Writer) (execResult, error) {
+	runCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	cmd := exec.CommandContext(runCtx, argv[0], argv[1:]...)
+	cmd.Dir = dir
+	cmd.Env = scrubEnv(os.Environ())
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+	// Processos filhos que herdam a saída não podem segurar o Wait depois do cancelamento.
+	cmd.WaitDelay = 2 * time.Second
+
+	start := time.Now()
+	err := cmd.Run()
+	res := execResult{elapsed: time.Since(start).Round(10 * time.Millisecond)}
+
+	var exitErr *exec.ExitError
+	switch {
+	case ctx.Err() != nil:
+		return res, ctx.Err()
+	case runCtx.Err() == context.DeadlineExceeded:
+		res.timedOut, res.exitCode = true, -1
+	case errors.As(err, &exitErr):
+		res.exitCode = exitErr.ExitCode()
+	case err != nil:
+		return res, fmt.Errorf("erro ao executar '%s': %w", argv[0], err)
+	}
+	return res, nil
+}
+
+func runCommand(ctx context.Context, input json.RawMessage) (string, error) {
+	typedInput, argv, err := parseCommand(input)
+	if err != nil {
+		return "", err
+	}
+	if rule := CurrentCommandPolicy().Denied(argv); rule != "" {
+		return "", fmt.Errorf("comando bloqueado pela regra '%s'", rule)
+	}
+
+	w, err := CurrentWorkspace()
+	if err != nil {
+		return "", err
+	}
+	dir, err := w.Resolve(typedInput.Dir)
+	if err != nil {
+		return "", err
+	}
+
+	maxBytes := typedInput.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = defaultOutputMaxBytes
+	}
+	stdout := &cappedBuffer{max: maxBytes}
+	stderr := &cappedBuffer{max: maxBytes}
+	timeout := commandTimeout(typedInput.Timeout)
+	res, err := execInWorkspace(ctx, dir, argv, timeout, stdout, stderr)
+	if err != nil {
+		return "", err
+	}
+	status := fmt.Sprintf("[código de saída %d em %s]", res.exitCode, res.elapsed)
+	if res.timedOut {
+		status = fmt.Sprintf("[tempo limite de %s excedido; processo encerrado após %s]", timeout, res.elapsed)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "$ %s\n%s", typedInput.Command, status)
+	if out := stdout.String(); out != "" {
+		fmt.Fprintf(&b, "\n--- stdout ---\n%s", strings.TrimRight(out, "\n"))
+	}
+	if out := stderr.String(); out != "" {
+		fmt.Fprintf(&b, "\n--- stderr ---\n%s", strings.TrimRight(out, "\n"))
+	}
+	if stdout.String() == "" && stderr.String() == "" {
+		b.WriteString("\n(sem saída)")
+	}
+	return b.String(), nil
+}
+
+func previewRunCommand(input json.RawMessage) (string, error) {
+	typedInput, _, err := parseCommand(input)
+	if err != nil {
+		return "", err
+	}
+	dir := typedInput.Dir
+	if dir == "" {
+		dir = "."
+	}
+	return fmt.Sprintf("Executaria o comando `%s` em %s", typedInput.Command, dir), nil
+}
+
+// RunCommandDef é a definição da ferramenta run_command.
+var RunCommandDef = toolkit.ToolDefinition{
+	Name:            "run_command",
+	Description:     `Executa um comando no workspace e retorna o código de saída, o stdout e o stderr (saídas longas mantêm o início e o fim). Não há shell: pipes, redirecionamentos e variáveis não são interpretados. Comandos fora da lista de permitidos (ex: "go test", "git status", "ls"), com caminhos fora do workspace ou com opções que escrevem arquivos ou executam binários (ex: "--output", "-exec") pedem aprovação; alguns, como sudo, são sempre bloqueados. Variáveis de ambiente com chaves de API e tokens são removidas. Opcionais: "dir" (relativo ao workspace), "timeout" (segundos, padrão 120, máximo 600), "max_bytes" (por fluxo, padrão 20000). Exemplo: {"command": "go test ./...", "timeout": 300}`,
+	ContextFunction: runCommand,
+	MutatingFunc:    runCommandMutating,
+	Preview:         previewRunCommand,
+}
@@ -0,0 +1,246 @@
+package builtin
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// TestSplitCommand testa a separação da linha de comando em argumentos.
+func TestSplitCommand(t *testing.T) {
+	testCases := []struct {
+		name          string
+		input         string
+		expected      []string
+		expectedError string
+	}{
+		{name: "Simples", input: "go test ./...", expected: []string{"go", "test", "./..."}},
+		{name: "Aspas", input: `grep -n "func main" 'a b.go'`, expected: []string{"grep", "-n", "func main", "a b.go"}},
+		{name: "Barra invertida", input: `echo a\ b "c\"d"`, expected: []string{"echo", "a b", `c"d`}},
+		{name: "Operador entre aspas", input: `sh -c 'go test | tail'`, expected: []string{"sh", "-c", "go test | tail"}},
+		{name: "Pipe", input: "go test | tail", expectedError: "operador de shell '|'"},
+		{name: "Redirecionamento", input: "go test 2>&1", expectedError: "operador de shell '>'"},
+		{name: "Variável", input: "echo $HOME", expectedError: "operador de shell '$'"},
+		{name: "Aspas sem fechamento", input: `echo "abc`, expectedError: "sem fechamento"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			args, err := splitCommand(tc.input)
+			if tc.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedError) {
+					t.Fatalf("splitCommand() erro = %v, esperado conter %q", err, tc.expectedError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("splitCommand() erro inesperado: %v", err)
+			}
+			if !reflect.DeepEqual(args, tc.expected) {
+				t.Errorf("splitCommand() = %q, esperado %q", args, tc.expected)
+			}
+		})
+	}
+}
+
+// TestCommandPolicy testa as listas de comandos permitidos e bloqueados.
+func TestCommandPolicy(t *testing.T) {
+	policy := NewCommandPolicy([]string{"make"}, []string{"git push"})
+
+	testCases := []struct {
+		command string
+		allowed bool
+		denied  string
+	}{
+		{command: "go test ./...", allowed: true},
+		{command: "go run main.go"},
+		{command: "/bin/ls -la", allowed: true},
+		{command: "make build", allowed: true},
+		{command: "rm -rf build"},
+		{command: "git push origin main", denied: "git push"},
+		{command: "sudo ls", denied: "sudo"},
+		{command: "env FOO=1 sudo ls", denied: "sudo"},
+		{command: "nice -n 10 sudo ls", denied: "sudo"},
+		{command: "env ls"},
+		{command: `sh -c "make && sudo reboot"`, denied: "sudo"},
+		{command: "git diff --output=/tmp/x"},
+		{command: "go env -w GOFLAGS=-mod=mod"},
+		{command: "go vet -vettool=/bin/x ./..."},
+		{command: "go test -exec /bin/x ./..."},
+		{command: "go test -run TestX -- -o x", allowed: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.command, func(t *testing.T) {
+			argv, err := splitCommand(tc.command)
+			if err != nil {
+				t.Fatalf("splitCommand() erro inesperado: %v", err)
+			}
+			if got := policy.Allowed(argv); got != tc.allowed {
+				t.Errorf("Allowed() = %v, esperado %v", got, tc.allowed)
+			}
+			if got := policy.Denied(argv); got != tc.denied {
+				t.Errorf("Denied() = %q, esperado %q", got, tc.denied)
+			}
+		})
+	}
+}
+
+// TestScrubEnv testa a remoção de credenciais do ambiente.
+func TestScrubEnv(t *testing.T) {
+	env := []string{"PATH=/bin", "OPENAI_API_KEY=sk", "GITHUB_TOKEN=t", "AWS_SECRET_ACCESS_KEY=s", "DB_PASSWORD=p", "HOME=/root", "GOPATH=/go"}
+	expected := []string{"PATH=/bin", "HOME=/root", "GOPATH=/go"}
+	if got := scrubEnv(env); !reflect.DeepEqual(got, expected) {
+		t.Errorf("scrubEnv() = %q, esperado %q", got, expected)
+	}
+}
+
+// TestCappedBuffer testa que saídas longas mantêm o início e o fim.
+func TestCappedBuffer(t *testing.T) {
+	b := &cappedBuffer{max: 10}
+	for _, chunk := range []string{"abc", "defgh", "ijklmnop", "qrstuvwxyz"} {
+		_, _ = b.Write([]byte(chunk))
+	}
+	expected := "abcde\n[... 16 bytes omitidos ...]\nvwxyz"
+	if got := b.String(); got != expected {
+		t.Errorf("String() = %q, esperado %q", got, expected)
+	}
+
+	short := &cappedBuffer{max: 10}
+	_, _ = short.Write([]byte("curto"))
+	if got := short.String(); got != "curto" {
+		t.Errorf("String() = %q, esperado %q", got, "curto")
+	}
+}
+
+// TestRunCommand testa a execução de comandos no workspace.
+func TestRunCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("os comandos do teste dependem de um sistema Unix")
+	}
+	t.Setenv("GOAGENT_TEST_API_KEY", "segredo")
+	newTestWorkspace(t, nil)
+
+	testCases := []struct {
+		name          string
+		input         RunCommandInput
+		contains      []string
+		excludes      []string
+		expectedError string
+	}{
+		{
+			name:     "Sucesso",
+			input:    RunCommandInput{Command: "echo olá mundo"},
+			contains: []string{"$ echo olá mundo\n[código de saída 0 em", "--- stdout ---\nolá mundo"},
+			excludes: []string{"stderr"},
+		},
+		{
+			name:     "Código de saída e stderr",
+			input:    RunCommandInput{Command: `sh -c 'echo falhou >&2; exit 3'`},
+			contains: []string{"[código de saída 3 em", "--- stderr ---\nfalhou"},
+		},
+		{
+			name:     "Ambiente sem credenciais",
+			input:    RunCommandInput{Command: "env"},
+			contains: []string{"PATH="},
+			excludes: []string{"GOAGENT_TEST_API_KEY"},
+		},
+		{
+			name:     "Sem saída",
+			input:    RunCommandInput{Command: "true"},
+			contains: []string{"(sem saída)"},
+		},
+		{
+			name:     "Tempo limite",
+			input:    RunCommandInput{Command: "sleep 5", Timeout: 1},
+			contains: []string{"[tempo limite de 1s excedido"},
+		},
+		{
+			name:     "Saída limitada",
+			input:    RunCommandInput{Command: `sh -c 'seq 1 1000'`, MaxBytes: 20},
+			contains: []string{"1\n2\n3\n4\n5", "bytes omitidos", "999\n1000"},
+		},
+		{
+			name:          "Comando bloqueado",
+			input:         RunCommandInput{Command: "sudo ls"},
+			expectedError: "bloqueado pela regra 'sudo'",
+		},
+		{
+			name:          "Comando inexistente",
+			input:         RunCommandInput{Command: "comando-que-nao-existe-123"},
+			expectedError: "erro ao executar",
+		},
+		{
+			name:          "Diretório fora do workspace",
+			input:         RunCommandInput{Command: "ls", Dir: "/"},
+			expectedError: "fora do workspace",
+		},
+		{
+			name:          "Comando vazio",
+			input:         RunCommandInput{Command: "  "},
+			expectedError: "'command' é obrigatório",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rawInput, _ := json.Marshal(tc.input)
+			result, err := runCommand(context.Background(), rawInput)
+			if tc.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedError) {
+					t.Fatalf("runCommand() erro = %v, esperado conter %q", err, tc.expectedError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("runCommand() erro inesperado: %v", err)
+			}
+			for _, s := range tc.contains {
+				if !strings.Contains(result, s) {
+					t.Errorf("runCommand() = %q, esperado conter %q", result, s)
+				}
+			}
+			for _, s := range tc.excludes {
+				if strings.Contains(result, s) {
+					t.Errorf("runCommand() = %q, não deveria conter %q", result, s)
+				}
+			}
+		})
+	}
+}
+
+// TestRunCommandMutating testa quais chamadas passam pela política de aprovação.
+func TestRunCommandMutating(t *testing.T) {
+	SetCommandPolicy(NewCommandPolicy([]string{"make"}, nil))
+	defer SetCommandPolicy(nil)
+	newTestWorkspace(t, map[string]string{"main.go": "package main", "sub/a.txt": "a"})
+
+	testCases := []struct {
+		command  string
+		dir      string
+		expected bool
+	}{
+		{"go test ./...", "", false},
+		{"make", "", false},
+		{"rm -rf build", "", true},
+		{"sudo ls", "", false}, // Bloqueado de qualquer forma, não há o que aprovar.
+		{"echo a | b", "", false},
+		{"cat main.go", "", false},
+		{"cat /etc/passwd", "", true},
+		{"grep -r --file=/etc/passwd .", "", true},
+		{"cat ../a.txt", "sub", false},
+		{"cat ../../a.txt", "sub", true},
+		{"git diff -- /etc/hosts", "", true},
+		{"git diff --output=out.txt", "", true},
+		{"go env -w GOFLAGS=-mod=mod", "", true},
+	}
+	for _, tc := range testCases {
+		rawInput, _ := json.Marshal(RunCommandInput{Command: tc.command, Dir: tc.dir})
+		if got := runCommandMutating(rawInput); got != tc.expected {
+			t.Errorf("runCommandMutating(%q) = %v, esperado %v", tc.command, got, tc.expected)
+		}
+	}
+}
@@ -0,0 +1,264 @@
+package builtin
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/matheusbuniotto/goagent/pkg/toolkit"
+)
+
+// ::: Ferramenta: SearchFiles :::
+
+const (
+	defaultMaxMatches = 200      // ocorrências devolvidas sem "max_matches"
+	maxSearchFileSize = 10 << 20 // arquivos maiores são pulados
+	maxMatchLineBytes = 300      // linhas mais longas são cortadas no resultado
+	maxContextLines   = 10
+)
+
+// SearchFilesInput define os parâmetros para a função searchFiles.
+type SearchFilesInput struct {
+	Pattern    string   `json:"pattern"`
+	Path       string   `json:"path,omitempty"`
+	Literal    bool     `json:"literal,omitempty"`     // trata pattern como texto, não regex
+	IgnoreCase bool     `json:"ignore_case,omitempty"` // ignora maiúsculas/minúsculas
+	Include    []string `json:"include,omitempty"`     // globs de arquivos a buscar
+	Exclude    []string `json:"exclude,omitempty"`     // globs de arquivos e diretórios a pular
+	Context    int      `json:"context,omitempty"`     // linhas de contexto antes e depois
+	MaxMatches int      `json:"max_matches,omitempty"` // limite de ocorrências
+	NoIgnore   bool     `json:"no_ignore,omitempty"`   // busca também no que o .gitignore ignora
+}
+
+// fileMatches são as ocorrências em um arquivo, já formatadas.
+type fileMatches struct {
+	path    string
+	count   int
+	lines   []string
+	skipped bool // binário ou grande demais
+}
+
+// errSearchDone interrompe a varredura quando o limite de ocorrências é atingido.
+var errSearchDone = errors.New("limite de ocorrências atingido")
+
+func searchFiles(input json.RawMessage) (string, error) {
+	var typedInput SearchFilesInput
+	if err := json.Unmarshal(input, &typedInput); err != nil {
+		return "", fmt.Errorf("JSON inválido para argumentos: %w", err)
+	}
+
+	if typedInput.Pattern == "" {
+		return "", fmt.Errorf("argumento inválido. 'pattern' é obrigatório")
+	}
+	if typedInput.Context < 0 || typedInput.MaxMatches < 0 {
+		return "", fmt.Errorf("argumentos inválidos. 'context' e 'max_matches' não podem ser negativos")
+	}
+	if typedInput.Context > maxContextLines {
+		typedInput.Context = maxContextLines
+	}
+	maxMatches := typedInput.MaxMatches
+	if maxMatches == 0 {
+		maxMatches = defaultMaxMatches
+	}
+
+	expr := typedInput.Pattern
+	if typedInput.Literal {
+		expr = regexp.QuoteMeta(expr)
+	}
+	if typedInput.IgnoreCase {
+		expr = "(?i)" + expr
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return "", fmt.Errorf("expressão regular inválida: %w (use \"literal\": true para buscar o texto exato)", err)
+	}
+
+	include, err := compileGlobs(typedInput.Include)
+	if err != nil {
+		return "", fmt.Errorf("padrão de include inválido: %w", err)
+	}
+	exclude, err := compileGlobs(typedInput.Exclude)
+	if err != nil {
+		return "", fmt.Errorf("padrão de exclude inválido: %w", err)
+	}
+
+	w, err := CurrentWorkspace()
+	if err != nil {
+		return "", err
+	}
+	root, err := w.ResolveRead(typedInput.Path)
+	if err != nil {
+		return "", err
+	}
+	info, err := os.Stat(root)
+	if err != nil {
+		return "", err
+	}
+
+	// Um arquivo específico é buscado diretamente, sem varredura.
+	if !info.IsDir() {
+		m := scanFile(root, w.Display(typedInput.Path, root), re, typedInput.Context)
+		return formatSearch([]fileMatches{m}, maxMatches, false), nil
+	}
+
+	// A varredura alimenta um grupo de workers que leem os arquivos em paralelo.
+	var total atomic.Int64
+	paths := make(chan [2]string)
+	results := make(chan fileMatches)
+	var wg sync.WaitGroup
+	for i := 0; i < runtime.NumCPU(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for p := range paths {
+				m := scanFile(p[0], p[1], re, typedInput.Context)
+				total.Add(int64(m.count))
+				results <- m
+			}
+		}()
+	}
+
+	var walkErr error
+	go func() {
+		tw := treeWalker{noIgnore: typedInput.NoIgnore, exclude: exclude}
+		walkErr = tw.walk(root, func(abs, rel string, e os.DirEntry) error {
+			if !e.Type().IsRegular() || (len(include) > 0 && !include.match(rel)) {
+				return nil
+			}
+			if total.Load() >= int64(maxMatches) {
+				return errSearchDone
+			}
+			paths <- [2]string{abs, w.Display(typedInput.Path, abs)}
+			return nil
+		})
+		close(paths)
+		wg.Wait()
+		close(results)
+	}()
+
+	var found []fileMatches
+	for m := range results {
+		found = append(found, m)
+	}
+	if walkErr != nil && walkErr != errSearchDone {
+		return "", walkErr
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].path < found[j].path })
+	return formatSearch(found, maxMatches, walkErr == errSearchDone), nil
+}
+
+// scanFile busca re nas linhas de um arquivo e formata as ocorrências no estilo do grep:
+// "caminho:linha:texto" para ocorrências e "caminho-linha-texto" para contexto, com "--"
+// entre trechos de contexto separados.
+func scanFile(abs, display string, re *regexp.Regexp, context int) fileMatches {
+	m := fileMatches{path: display}
+	f, err := os.Open(abs)
+	if err != nil {
+		m.skipped = true
+		return m
+	}
+	defer f.Close()
+	if info, err := f.Stat(); err != nil || info.Size() > maxSearchFileSize {
+		m.skipped = true
+		return m
+	}
+
+	br := bufio.NewReaderSize(f, sniffBytes)
+	sample, _ := br.Peek(sniffBytes)
+	if enc := detectEncoding(sample); enc == "binário" || strings.HasPrefix(enc, "UTF-16") {
+		m.skipped = true
+		return m
+	}
+
+	scanner := bufio.NewScanner(br)
+	scanner.Buffer(make([]byte, 64*1024), maxSearchFileSize)
+	var before []string // últimas linhas, para o contexto anterior
+	lineNo, lastPrinted, after := 0, 0, 0
+	for scanner.Scan() {
+		lineNo++
+		line := scanner.Text()
+		if re.MatchString(line) {
+			m.count++
+			start := lineNo - len(before)
+			if context > 0 && lastPrinted > 0 && start > lastPrinted+1 {
+				m.lines = append(m.lines, "--")
+			}
+			for i, b := range before {
+				m.lines = append(m.lines, fmt.Sprintf("%s-%d-%s", display, start+i, b))
+			}
+			m.lines = append(m.lines, fmt.Sprintf("%s:%d:%s", display, lineNo, truncateUTF8(line, maxMatchLineBytes)))
+			before, lastPrinted, after = before[:0], lineNo, context
+			continue
+		}
+		if after > 0 {
+			m.lines = append(m.lines, fmt.Sprintf("%s-%d-%s", display, lineNo, truncateUTF8(line, maxMatchLineBytes)))
+			lastPrinted = lineNo
+			after--
+			continue
+		}
+		if context > 0 {
+			before = append(before, truncateUTF8(line, maxMatchLineBytes))
+			if len(before) > context {
+				before = before[1:]
+			}
+		}
+	}
+	return m
+}
+
+// formatSearch junta as ocorrências dos arquivos, até maxMatches, com um resumo no fim.
+func formatSearch(found []fileMatches, maxMatches int, stopped bool) string {
+	var b strings.Builder
+	matches, files, skipped := 0, 0, 0
+	truncated := stopped
+	for _, m := range found {
+		if m.skipped {
+			skipped++
+			continue
+		}
+		if m.count == 0 {
+			continue
+		}
+		if matches >= maxMatches {
+			truncated = true
+			break
+		}
+		files++
+		for _, line := range m.lines {
+			if strings.HasPrefix(line, m.path+":") {
+				if matches == maxMatches {
+					truncated = true
+					break
+				}
+				matches++
+			}
+			b.WriteString(line + "\n")
+		}
+	}
+
+	if matches == 0 {
+		return "Nenhuma ocorrência encontrada."
+	}
+	summary := fmt.Sprintf("[%d ocorrência(s) em %d arquivo(s)", matches, files)
+	if truncated {
+		summary += fmt.Sprintf("; limite de %d atingido, refine o padrão ou use \"include\"", maxMatches)
+	}
+	if skipped > 0 {
+		summary += fmt.Sprintf("; %d arquivo(s) binário(s) ou grande(s) demais ignorado(s)", skipped)
+	}
+	return b.String() + summary + "]"
+}
+
+var SearchFilesDef = toolkit.ToolDefinition{
+	Name:        "search_files",
+	Description: `Busca um texto ou expressão regular (sintaxe RE2 do Go) no conteúdo dos arquivos do workspace, respeitando o .gitignore. Retorna linhas no formato "caminho:linha:texto". Opcionais: "path" (diretório ou arquivo), "literal": true (texto exato), "ignore_case": true, "include"/"exclude" (globs, ex: ["*.go"]), "context" (linhas antes e depois, até 10), "max_matches" (padrão 200), "no_ignore": true. Exemplo: {"pattern": "func New\\w+", "include": ["*.go"], "context": 2}`,
+	Function:    searchFiles,
+}
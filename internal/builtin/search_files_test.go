@@ -0,0 +1,108 @@
+package builtin
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestSearchFiles testa as opções de busca sobre a árvore de setupTree.
+func TestSearchFiles(t *testing.T) {
+	setupTree(t)
+	w, _ := CurrentWorkspace()
+	_ = os.WriteFile(filepath.Join(w.Root(), "dados.bin"), []byte("package\x00main"), 0644)
+	_ = os.WriteFile(filepath.Join(w.Root(), "texto.txt"), []byte("um\ndois\nPackage três\nquatro\ncinco\nseis\npackage sete\n"), 0644)
+
+	testCases := []struct {
+		name          string
+		input         SearchFilesInput
+		expected      string
+		expectedError string
+	}{
+		{
+			name:     "Regex com include",
+			input:    SearchFilesInput{Pattern: `^package \w+$`, Include: []string{"*.go"}},
+			expected: "cmd/app/app.go:1:package app\ncmd/app/app_test.go:1:package app\nmain.go:1:package main\n[3 ocorrência(s) em 3 arquivo(s)]",
+		},
+		{
+			name:     "Literal respeita .gitignore",
+			input:    SearchFilesInput{Pattern: "x", Literal: true, Include: []string{"*.log"}},
+			expected: "importante.log:1:x\n[1 ocorrência(s) em 1 arquivo(s)]",
+		},
+		{
+			name:     "Ignora maiúsculas e pula binários",
+			input:    SearchFilesInput{Pattern: "package t", IgnoreCase: true, Exclude: []string{"*.go"}},
+			expected: "texto.txt:3:Package três\n[1 ocorrência(s) em 1 arquivo(s); 1 arquivo(s) binário(s) ou grande(s) demais ignorado(s)]",
+		},
+		{
+			name:     "Contexto",
+			input:    SearchFilesInput{Pattern: "(?i)^package", Path: "texto.txt", Context: 1},
+			expected: "texto.txt-2-dois\ntexto.txt:3:Package três\ntexto.txt-4-quatro\n--\ntexto.txt-6-seis\ntexto.txt:7:package sete\n[2 ocorrência(s) em 1 arquivo(s)]",
+		},
+		{
+			name:     "Limite de ocorrências",
+			input:    SearchFilesInput{Pattern: "package", Include: []string{"*.go"}, MaxMatches: 2},
+			expected: "cmd/app/app.go:1:package app\ncmd/app/app_test.go:1:package app\n[2 ocorrência(s) em 2 arquivo(s); limite de 2 atingido, refine o padrão ou use \"include\"]",
+		},
+		{
+			name:     "Sem ocorrências",
+			input:    SearchFilesInput{Pattern: "inexistente"},
+			expected: "Nenhuma ocorrência encontrada.",
+		},
+		{
+			name:          "Regex inválida",
+			input:         SearchFilesInput{Pattern: "func("},
+			expectedError: "expressão regular inválida",
+		},
+		{
+			name:          "Fora do workspace",
+			input:         SearchFilesInput{Pattern: "root", Path: "/etc"},
+			expectedError: "fora do workspace",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rawInput, _ := json.Marshal(tc.input)
+			result, err := searchFiles(rawInput)
+			if (err != nil) != (tc.expectedError != "") {
+				t.Fatalf("searchFiles() erro = %v, esperado erro %q", err, tc.expectedError)
+			}
+			if err != nil {
+				if !strings.Contains(err.Error(), tc.expectedError) {
+					t.Errorf("searchFiles() erro = %v, esperado conter %q", err, tc.expectedError)
+				}
+				return
+			}
+			if result != tc.expected {
+				t.Errorf("searchFiles() =\n%s\nesperado\n%s", result, tc.expected)
+			}
+		})
+	}
+}
+
+// TestSearchFilesManyFiles testa a busca concorrente em muitos arquivos.
+func TestSearchFilesManyFiles(t *testing.T) {
+	setupTree(t)
+	w, _ := CurrentWorkspace()
+	dir := filepath.Join(w.Root(), "muitos")
+	_ = os.Mkdir(dir, 0755)
+	for i := 0; i < 100; i++ {
+		_ = os.WriteFile(filepath.Join(dir, fmt.Sprintf("f%03d.txt", i)), []byte(fmt.Sprintf("linha\nalvo %d\n", i)), 0644)
+	}
+
+	rawInput, _ := json.Marshal(SearchFilesInput{Pattern: "alvo", Path: "muitos", MaxMatches: 1000})
+	result, err := searchFiles(rawInput)
+	if err != nil {
+		t.Fatalf("searchFiles() erro inesperado: %v", err)
+	}
+	if !strings.HasSuffix(result, "[100 ocorrência(s) em 100 arquivo(s)]") {
+		t.Errorf("resumo inesperado: %s", result[strings.LastIndex(result, "["):])
+	}
+	if !strings.HasPrefix(result, "muitos/f000.txt:2:alvo 0\nmuitos/f001.txt:2:alvo 1\n") {
+		t.Errorf("resultados fora de ordem: %s", result[:80])
+	}
+}
@@ -0,0 +1,118 @@
+package builtin
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/matheusbuniotto/goagent/internal/todo"
+	"github.com/matheusbuniotto/goagent/pkg/toolkit"
+)
+
+// ::: Ferramenta: Lista de tarefas :::
+
+var (
+	todoMu   sync.RWMutex
+	todoList *todo.List
+)
+
+// SetTodoList define a lista de tarefas da sessão usada pela ferramenta todo. Com nil,
+// a ferramenta fica indisponível.
+func SetTodoList(l *todo.List) {
+	todoMu.Lock()
+	defer todoMu.Unlock()
+	todoList = l
+}
+
+// TodoList retorna a lista de tarefas em uso, ou nil.
+func TodoList() *todo.List {
+	todoMu.RLock()
+	defer todoMu.RUnlock()
+	return todoList
+}
+
+// TodoContext descreve a lista de tarefas atual para ser incluída em cada chamada ao LLM.
+// Retorna "" se não há tarefas.
+func TodoContext() string {
+	l := TodoList()
+	if l == nil || len(l.Items()) == 0 {
+		return ""
+	}
+	return "Sua lista de tarefas atual (mantenha-a atualizada com a ferramenta todo):\n" + l.String()
+}
+
+type TodoInput struct {
+	Action string   `json:"action"`
+	Items  []string `json:"items,omitempty"`
+	ID     int      `json:"id,omitempty"`
+	Status string   `json:"status,omitempty"`
+	Text   string   `json:"text,omitempty"`
+}
+
+func manageTodo(input json.RawMessage) (string, error) {
+	var typedInput TodoInput
+	if err := json.Unmarshal(input, &typedInput); err != nil {
+		return "", fmt.Errorf("JSON inválido para argumentos: %w", err)
+	}
+	l := TodoList()
+	if l == nil {
+		return "", fmt.Errorf("lista de tarefas indisponível nesta execução")
+	}
+
+	var message string
+	switch strings.ToLower(typedInput.Action) {
+	case "add":
+		if len(typedInput.Items) == 0 {
+			return "", fmt.Errorf("argumento inválido. 'items' é obrigatório para 'add'")
+		}
+		added, err := l.Add(typedInput.Items...)
+		if err != nil {
+			return "", err
+		}
+		ids := make([]string, len(added))
+		for i, item := range added {
+			ids[i] = fmt.Sprint(item.ID)
+		}
+		message = fmt.Sprintf("%d tarefa(s) adicionada(s) com ID(s) %s.", len(added), strings.Join(ids, ", "))
+	case "update":
+		if typedInput.ID == 0 || (typedInput.Status == "" && typedInput.Text == "") {
+			return "", fmt.Errorf("argumento inválido. 'id' e 'status' (ou 'text') são obrigatórios para 'update'")
+		}
+		var status todo.Status
+		if typedInput.Status != "" {
+			var err error
+			if status, err = todo.ParseStatus(typedInput.Status); err != nil {
+				return "", err
+			}
+		}
+		item, err := l.Update(typedInput.ID, status, typedInput.Text)
+		if err != nil {
+			return "", err
+		}
+		message = fmt.Sprintf("Tarefa %d atualizada (%s).", item.ID, item.Status)
+	case "remove":
+		if typedInput.ID == 0 {
+			return "", fmt.Errorf("argumento inválido. 'id' é obrigatório para 'remove'")
+		}
+		if err := l.Remove(typedInput.ID); err != nil {
+			return "", err
+		}
+		message = fmt.Sprintf("Tarefa %d removida.", typedInput.ID)
+	case "clear":
+		l.Clear()
+		message = "Lista de tarefas limpa."
+	case "list", "":
+		return l.String(), nil
+	default:
+		return "", fmt.Errorf("ação inválida '%s' (use add, update, remove, clear ou list)", typedInput.Action)
+	}
+	// A lista completa já vai no contexto de cada chamada; aqui basta o progresso.
+	return fmt.Sprintf("%s Progresso: %s.", message, l.Summary()), nil
+}
+
+var TodoDef = toolkit.ToolDefinition{
+	Name:        "todo",
+	Description: `Mantém a lista de tarefas da sessão, para acompanhar pedidos com várias etapas. Crie a lista no início, marque uma tarefa como in_progress ao começá-la e como done assim que terminar. A lista atual é mostrada ao usuário e incluída no seu contexto a cada mensagem. Requer um objeto JSON com "action": "add" (com "items", lista de textos), "update" (com "id" e "status": pending, in_progress, done ou cancelled; "text" opcional), "remove" (com "id"), "clear" ou "list". Exemplo: {"action": "update", "id": 2, "status": "done"}`,
+	Function:    manageTodo,
+}
@@ -0,0 +1,95 @@
+package builtin
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/matheusbuniotto/goagent/internal/todo"
+)
+
+// TestManageTodo testa as ações da ferramenta todo sobre a lista da sessão.
+func TestManageTodo(t *testing.T) {
+	previous := TodoList()
+	SetTodoList(todo.New(nil))
+	t.Cleanup(func() { SetTodoList(previous) })
+
+	if TodoContext() != "" {
+		t.Errorf("TodoContext() com lista vazia = %q, esperado vazio", TodoContext())
+	}
+
+	testCases := []struct {
+		name          string
+		input         string
+		expected      string
+		expectedError string
+	}{
+		{
+			name:     "Adiciona",
+			input:    `{"action": "add", "items": ["Ler o código", "Corrigir o bug", "Rodar os testes"]}`,
+			expected: "3 tarefa(s) adicionada(s) com ID(s) 1, 2, 3. Progresso: 0/3 concluídas.",
+		},
+		{
+			name:     "Conclui",
+			input:    `{"action": "update", "id": 1, "status": "done"}`,
+			expected: "Tarefa 1 atualizada (done). Progresso: 1/3 concluídas.",
+		},
+		{
+			name:     "Em andamento com novo texto",
+			input:    `{"action": "update", "id": 2, "status": "in_progress", "text": "Corrigir o bug no parser"}`,
+			expected: "Tarefa 2 atualizada (in_progress).",
+		},
+		{
+			name:     "Lista",
+			input:    `{"action": "list"}`,
+			expected: "Tarefas (1/3 concluídas):\n[x] 1. Ler o código\n[~] 2. Corrigir o bug no parser\n[ ] 3. Rodar os testes",
+		},
+		{
+			name:          "Status inválido",
+			input:         `{"action": "update", "id": 2, "status": "feito"}`,
+			expectedError: "status inválido 'feito'",
+		},
+		{
+			name:          "Tarefa inexistente",
+			input:         `{"action": "update", "id": 7, "status": "done"}`,
+			expectedError: "tarefa 7 não existe",
+		},
+		{
+			name:          "Add sem itens",
+			input:         `{"action": "add"}`,
+			expectedError: "'items' é obrigatório para 'add'",
+		},
+		{
+			name:          "Ação inválida",
+			input:         `{"action": "apagar"}`,
+			expectedError: "ação inválida 'apagar'",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := manageTodo(json.RawMessage(tc.input))
+			if tc.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedError) {
+					t.Fatalf("erro = %v, esperado conter %q", err, tc.expectedError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+			if !strings.Contains(result, tc.expected) {
+				t.Errorf("resultado =\n%s\nesperado conter\n%s", result, tc.expected)
+			}
+		})
+	}
+
+	if ctx := TodoContext(); !strings.Contains(ctx, "[~] 2. Corrigir o bug no parser") {
+		t.Errorf("TodoContext() = %q", ctx)
+	}
+
+	SetTodoList(nil)
+	if _, err := manageTodo(json.RawMessage(`{"action": "list"}`)); err == nil {
+		t.Error("manageTodo() sem lista deveria falhar")
+	}
+}
@@ -0,0 +1,191 @@
+package builtin
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// ErrOutsideWorkspace é retornado quando um caminho escapa do workspace.
+var ErrOutsideWorkspace = errors.New("caminho fora do workspace")
+
+// Workspace é o diretório raiz a partir do qual as ferramentas de arquivo resolvem
+// caminhos. Caminhos absolutos fora dele, ".." que escapa da raiz e links simbólicos que
+// apontam para fora são rejeitados. Diretórios extras podem ser liberados só para leitura.
+type Workspace struct {
+	root     string   // raiz como informada, em forma absoluta
+	realRoot string   // raiz com links simbólicos resolvidos
+	readOnly []string // diretórios extras liberados para leitura, já resolvidos
+}
+
+// NewWorkspace cria um workspace com raiz em root e os diretórios readOnly liberados
+// apenas para leitura.
+func NewWorkspace(root string, readOnly ...string) (*Workspace, error) {
+	abs, realRoot, err := resolveDir(root)
+	if err != nil {
+		return nil, fmt.Errorf("workspace inválido: %w", err)
+	}
+	w := &Workspace{root: abs, realRoot: realRoot}
+	for _, dir := range readOnly {
+		_, real, err := resolveDir(dir)
+		if err != nil {
+			return nil, fmt.Errorf("diretório somente leitura inválido: %w", err)
+		}
+		w.readOnly = append(w.readOnly, real)
+	}
+	return w, nil
+}
+
+// resolveDir retorna a forma absoluta e a forma com links resolvidos de um diretório existente.
+func resolveDir(dir string) (string, string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", "", err
+	}
+	real, err := filepath.EvalSymlinks(abs)
+	if err != nil {
+		return "", "", err
+	}
+	info, err := os.Stat(real)
+	if err != nil {
+		return "", "", err
+	}
+	if !info.IsDir() {
+		return "", "", fmt.Errorf("'%s' não é um diretório", dir)
+	}
+	return abs, real, nil
+}
+
+// Root retorna a raiz do workspace.
+func (w *Workspace) Root() string {
+	return w.root
+}
+
+// ReadOnlyDirs retorna os diretórios extras liberados para leitura.
+func (w *Workspace) ReadOnlyDirs() []string {
+	return append([]string(nil), w.readOnly...)
+}
+
+// Resolve valida um caminho para escrita e retorna sua forma absoluta. Caminhos
+// relativos são resolvidos a partir da raiz; vazio equivale à própria raiz.
+func (w *Workspace) Resolve(path string) (string, error) {
+	abs, real, err := w.resolve(path)
+	if err != nil {
+		return "", err
+	}
+	if !within(real, w.realRoot) {
+		for _, dir := range w.readOnly {
+			if within(real, dir) {
+				return "", fmt.Errorf("%w: '%s' está em um diretório somente leitura", ErrOutsideWorkspace, path)
+			}
+		}
+		return "", fmt.Errorf("%w: '%s' não está dentro de '%s'", ErrOutsideWorkspace, path, w.root)
+	}
+	return abs, nil
+}
+
+// ResolveRead é como Resolve, mas também aceita os diretórios somente leitura.
+func (w *Workspace) ResolveRead(path string) (string, error) {
+	abs, real, err := w.resolve(path)
+	if err != nil {
+		return "", err
+	}
+	if within(real, w.realRoot) {
+		return abs, nil
+	}
+	for _, dir := range w.readOnly {
+		if within(real, dir) {
+			return abs, nil
+		}
+	}
+	return "", fmt.Errorf("%w: '%s' não está dentro de '%s'", ErrOutsideWorkspace, path, w.root)
+}
+
+// Display converte um caminho absoluto para a forma mostrada ao modelo: relativo à raiz
+// quando o caminho pedido era relativo, absoluto caso contrário.
+func (w *Workspace) Display(requested, abs string) string {
+	if filepath.IsAbs(requested) {
+		return abs
+	}
+	if rel, err := filepath.Rel(w.root, abs); err == nil {
+		return rel
+	}
+	return abs
+}
+
+// resolve retorna a forma absoluta do caminho e a forma com links simbólicos resolvidos.
+func (w *Workspace) resolve(path string) (string, string, error) {
+	abs := filepath.Clean(path)
+	if !filepath.IsAbs(path) {
+		abs = filepath.Join(w.root, path)
+	}
+	real, err := resolveSymlinks(abs)
+	if err != nil {
+		return "", "", err
+	}
+	return abs, real, nil
+}
+
+// resolveSymlinks resolve os links simbólicos da parte existente do caminho, mantendo os
+// componentes que ainda não existem. Links quebrados são rejeitados, pois escrever
+// através deles criaria o arquivo no destino do link.
+func resolveSymlinks(path string) (string, error) {
+	existing := path
+	var rest []string
+	for {
+		real, err := filepath.EvalSymlinks(existing)
+		if err == nil {
+			return filepath.Join(append([]string{real}, rest...)...), nil
+		}
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+		if info, lerr := os.Lstat(existing); lerr == nil && info.Mode()&os.ModeSymlink != 0 {
+			return "", fmt.Errorf("%w: '%s' é um link simbólico quebrado", ErrOutsideWorkspace, existing)
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			return path, nil
+		}
+		rest = append([]string{filepath.Base(existing)}, rest...)
+		existing = parent
+	}
+}
+
+// within indica se path é root ou está dentro dele.
+func within(path, root string) bool {
+	rel, err := filepath.Rel(root, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+var (
+	workspaceMu sync.RWMutex
+	workspace   *Workspace
+)
+
+// SetWorkspace define o workspace usado pelas ferramentas de arquivo.
+func SetWorkspace(w *Workspace) {
+	workspaceMu.Lock()
+	defer workspaceMu.Unlock()
+	workspace = w
+}
+
+// CurrentWorkspace retorna o workspace em uso. Se nenhum foi definido, usa o diretório atual.
+func CurrentWorkspace() (*Workspace, error) {
+	workspaceMu.RLock()
+	w := workspace
+	workspaceMu.RUnlock()
+	if w != nil {
+		return w, nil
+	}
+
+	w, err := NewWorkspace(".")
+	if err != nil {
+		return nil, err
+	}
+	SetWorkspace(w)
+	return w, nil
+}
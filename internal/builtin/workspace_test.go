@@ -0,0 +1,98 @@
+package builtin
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/matheusbuniotto/goagent/internal/checkpoint"
+)
+
+// newTestWorkspace cria um diretório temporário com os arquivos informados (caminho com
+// "/" para o conteúdo) e o define como workspace até o fim do teste. Retorna a raiz.
+func newTestWorkspace(t testing.TB, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	w, err := NewWorkspace(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous, _ := CurrentWorkspace()
+	SetWorkspace(w)
+	t.Cleanup(func() { SetWorkspace(previous) })
+	return root
+}
+
+// newTestWorkspaceWithCheckpoints é como newTestWorkspace, mas também define um store de
+// checkpoints próprio.
+func newTestWorkspaceWithCheckpoints(t testing.TB, files map[string]string) (string, *checkpoint.Store) {
+	t.Helper()
+	root := newTestWorkspace(t, files)
+	store := checkpoint.NewStore(t.TempDir())
+	SetCheckpointStore(store)
+	t.Cleanup(func() { SetCheckpointStore(nil) })
+	return root, store
+}
+
+// TestWorkspaceResolve testa quais caminhos são aceitos para leitura e escrita.
+func TestWorkspaceResolve(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	shared := t.TempDir()
+	_ = os.WriteFile(filepath.Join(outside, "segredo.txt"), []byte("x"), 0644)
+	_ = os.Mkdir(filepath.Join(root, "src"), 0755)
+	_ = os.Symlink(outside, filepath.Join(root, "atalho"))
+	_ = os.Symlink(filepath.Join(outside, "novo.txt"), filepath.Join(root, "quebrado"))
+	_ = os.Symlink(filepath.Join(root, "src"), filepath.Join(root, "interno"))
+
+	w, err := NewWorkspace(root, shared)
+	if err != nil {
+		t.Fatalf("NewWorkspace() erro inesperado: %v", err)
+	}
+
+	testCases := []struct {
+		name      string
+		path      string
+		wantRead  bool
+		wantWrite bool
+	}{
+		{"Raiz", "", true, true},
+		{"Relativo", "src/main.go", true, true},
+		{"Absoluto dentro", filepath.Join(root, "src"), true, true},
+		{"Ponto-ponto que continua dentro", "src/../README.md", true, true},
+		{"Ponto-ponto que escapa", "../segredo.txt", false, false},
+		{"Absoluto fora", filepath.Join(outside, "segredo.txt"), false, false},
+		{"Link para fora", "atalho/segredo.txt", false, false},
+		{"Link quebrado para fora", "quebrado", false, false},
+		{"Link para dentro", "interno/main.go", true, true},
+		{"Diretório somente leitura", filepath.Join(shared, "dados.csv"), true, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := w.ResolveRead(tc.path)
+			if (err == nil) != tc.wantRead {
+				t.Errorf("ResolveRead(%q) erro = %v, esperado permitido = %v", tc.path, err, tc.wantRead)
+			}
+			if err != nil && !errors.Is(err, ErrOutsideWorkspace) {
+				t.Errorf("ResolveRead(%q) erro deveria envolver ErrOutsideWorkspace: %v", tc.path, err)
+			}
+
+			_, err = w.Resolve(tc.path)
+			if (err == nil) != tc.wantWrite {
+				t.Errorf("Resolve(%q) erro = %v, esperado permitido = %v", tc.path, err, tc.wantWrite)
+			}
+		})
+	}
+}
@@ -0,0 +1,339 @@
+// Package checkpoint guarda o estado anterior dos arquivos alterados pelas ferramentas,
+// permitindo desfazer alterações e voltar o workspace a um ponto anterior da sessão.
+package checkpoint
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrEmpty é retornado quando não há checkpoints para desfazer.
+var ErrEmpty = errors.New("nenhum checkpoint para desfazer")
+
+// Entry é o estado anterior de um caminho: inexistente, diretório ou arquivo com conteúdo.
+type Entry struct {
+	Path    string      `json:"path"`
+	Existed bool        `json:"existed"`
+	Dir     bool        `json:"dir,omitempty"`
+	Mode    fs.FileMode `json:"mode,omitempty"`
+	Blob    string      `json:"blob,omitempty"` // arquivo com o conteúdo anterior
+	Link    string      `json:"link,omitempty"` // destino, se era um link simbólico
+}
+
+// Checkpoint é o estado do workspace antes de uma chamada de ferramenta.
+type Checkpoint struct {
+	ID        int       `json:"id"`
+	Tool      string    `json:"tool"`
+	CreatedAt time.Time `json:"created_at"`
+	Entries   []Entry   `json:"entries"`
+}
+
+// Paths retorna os caminhos afetados pelo checkpoint, sem os descendentes de diretórios.
+func (c *Checkpoint) Paths() []string {
+	var paths []string
+	for _, e := range c.Entries {
+		covered := false
+		for _, p := range paths {
+			if strings.HasPrefix(e.Path, p+string(filepath.Separator)) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			paths = append(paths, e.Path)
+		}
+	}
+	return paths
+}
+
+// Store grava os checkpoints de uma sessão em um diretório, um subdiretório por checkpoint.
+type Store struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewStore cria um Store que grava em dir.
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// Dir retorna o diretório dos checkpoints.
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// Create registra o estado atual dos caminhos antes que tool os altere. Diretórios são
+// registrados com todo o seu conteúdo.
+func (s *Store) Create(tool string, paths ...string) (*Checkpoint, error) {
+	return s.create(tool, true, paths)
+}
+
+// CreateNoFollow é como Create, mas um link simbólico no próprio caminho é registrado
+// como link, para ferramentas que removem ou renomeiam o link em vez de escrever nele.
+func (s *Store) CreateNoFollow(tool string, paths ...string) (*Checkpoint, error) {
+	return s.create(tool, false, paths)
+}
+
+func (s *Store) create(tool string, follow bool, paths []string) (*Checkpoint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids, err := s.ids()
+	if err != nil {
+		return nil, err
+	}
+	id := 1
+	if len(ids) > 0 {
+		id = ids[len(ids)-1] + 1
+	}
+	cpDir := s.path(id)
+	if err := os.MkdirAll(filepath.Join(cpDir, "blobs"), 0700); err != nil {
+		return nil, fmt.Errorf("erro ao criar checkpoint: %w", err)
+	}
+
+	cp := &Checkpoint{ID: id, Tool: tool, CreatedAt: time.Now()}
+	for _, path := range paths {
+		if err := cp.snapshot(cpDir, path, follow); err != nil {
+			os.RemoveAll(cpDir)
+			return nil, fmt.Errorf("erro ao criar checkpoint de '%s': %w", path, err)
+		}
+	}
+
+	data, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		os.RemoveAll(cpDir)
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(cpDir, "checkpoint.json"), data, 0600); err != nil {
+		os.RemoveAll(cpDir)
+		return nil, fmt.Errorf("erro ao gravar checkpoint: %w", err)
+	}
+	return cp, nil
+}
+
+// snapshot registra o estado de path (e de seu conteúdo, se for diretório). Com follow,
+// um link simbólico no próprio path é seguido, como fazem as ferramentas ao escrever nele.
+func (c *Checkpoint) snapshot(cpDir, path string, follow bool) error {
+	stat := os.Stat
+	if !follow {
+		stat = os.Lstat
+	}
+	info, err := stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		c.Entries = append(c.Entries, Entry{Path: path})
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return c.snapshotFile(cpDir, path, info)
+	}
+	return filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			c.Entries = append(c.Entries, Entry{Path: p, Existed: true, Dir: true, Mode: info.Mode().Perm()})
+			return nil
+		}
+		return c.snapshotFile(cpDir, p, info)
+	})
+}
+
+// snapshotFile copia o conteúdo de um arquivo para o checkpoint.
+func (c *Checkpoint) snapshotFile(cpDir, path string, info fs.FileInfo) error {
+	if info.Mode()&fs.ModeSymlink != 0 {
+		target, err := os.Readlink(path)
+		if err != nil {
+			return err
+		}
+		c.Entries = append(c.Entries, Entry{Path: path, Existed: true, Link: target})
+		return nil
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("'%s' não é um arquivo regular", path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	blob := strconv.Itoa(len(c.Entries))
+	if err := os.WriteFile(filepath.Join(cpDir, "blobs", blob), data, 0600); err != nil {
+		return err
+	}
+	c.Entries = append(c.Entries, Entry{Path: path, Existed: true, Mode: info.Mode().Perm(), Blob: blob})
+	return nil
+}
+
+// List retorna os checkpoints em ordem crescente de ID.
+func (s *Store) List() ([]*Checkpoint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ids, err := s.ids()
+	if err != nil {
+		return nil, err
+	}
+	var cps []*Checkpoint
+	for _, id := range ids {
+		cp, err := s.load(id)
+		if err != nil {
+			return nil, err
+		}
+		cps = append(cps, cp)
+	}
+	return cps, nil
+}
+
+// Undo restaura o último checkpoint e o descarta.
+func (s *Store) Undo() (*Checkpoint, error) {
+	s.mu.Lock()
+	ids, err := s.ids()
+	s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, ErrEmpty
+	}
+	reverted, err := s.Revert(ids[len(ids)-1])
+	if err != nil {
+		return nil, err
+	}
+	return reverted[0], nil
+}
+
+// Revert volta o workspace ao estado de antes do checkpoint id, desfazendo-o junto com
+// todos os posteriores, do mais recente para o mais antigo. Retorna os checkpoints
+// desfeitos nessa ordem.
+func (s *Store) Revert(id int) ([]*Checkpoint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids, err := s.ids()
+	if err != nil {
+		return nil, err
+	}
+	i := sort.SearchInts(ids, id)
+	if i == len(ids) || ids[i] != id {
+		return nil, fmt.Errorf("checkpoint %d não encontrado", id)
+	}
+
+	var reverted []*Checkpoint
+	for j := len(ids) - 1; j >= i; j-- {
+		cp, err := s.load(ids[j])
+		if err != nil {
+			return reverted, err
+		}
+		if err := cp.restore(s.path(ids[j])); err != nil {
+			return reverted, fmt.Errorf("erro ao desfazer o checkpoint %d: %w", cp.ID, err)
+		}
+		if err := os.RemoveAll(s.path(ids[j])); err != nil {
+			return reverted, err
+		}
+		reverted = append(reverted, cp)
+	}
+	return reverted, nil
+}
+
+// restore devolve cada caminho ao estado registrado. Diretórios são recriados antes dos
+// arquivos; caminhos que não existiam são removidos junto com o que foi criado dentro deles.
+func (c *Checkpoint) restore(cpDir string) error {
+	for _, e := range c.Entries {
+		if e.Existed && e.Dir {
+			if err := os.MkdirAll(e.Path, e.Mode); err != nil {
+				return err
+			}
+		}
+	}
+	for _, e := range c.Entries {
+		if !e.Existed || e.Dir {
+			continue
+		}
+		if e.Link != "" {
+			if err := os.RemoveAll(e.Path); err != nil {
+				return err
+			}
+			if err := os.Symlink(e.Link, e.Path); err != nil {
+				return err
+			}
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(cpDir, "blobs", e.Blob))
+		if err != nil {
+			return err
+		}
+		if info, err := os.Lstat(e.Path); err == nil && info.IsDir() {
+			if err := os.RemoveAll(e.Path); err != nil {
+				return err
+			}
+		}
+		if err := os.WriteFile(e.Path, data, e.Mode); err != nil {
+			return err
+		}
+		if err := os.Chmod(e.Path, e.Mode); err != nil {
+			return err
+		}
+	}
+	for i := len(c.Entries) - 1; i >= 0; i-- {
+		e := c.Entries[i]
+		if e.Existed {
+			continue
+		}
+		// Tudo que estiver dentro de um caminho que não existia foi criado depois do checkpoint.
+		if err := os.RemoveAll(e.Path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// path retorna o diretório de um checkpoint.
+func (s *Store) path(id int) string {
+	return filepath.Join(s.dir, strconv.Itoa(id))
+}
+
+// load lê um checkpoint do disco.
+func (s *Store) load(id int) (*Checkpoint, error) {
+	data, err := os.ReadFile(filepath.Join(s.path(id), "checkpoint.json"))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler o checkpoint %d: %w", id, err)
+	}
+	var cp Checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("checkpoint %d corrompido: %w", id, err)
+	}
+	return &cp, nil
+}
+
+// ids lista os IDs dos checkpoints gravados, em ordem crescente.
+func (s *Store) ids() ([]int, error) {
+	entries, err := os.ReadDir(s.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar checkpoints: %w", err)
+	}
+	var ids []int
+	for _, e := range entries {
+		if id, err := strconv.Atoi(e.Name()); err == nil && e.IsDir() {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+	return ids, nil
+}
@@ -0,0 +1,106 @@
+package checkpoint
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestUndo testa que cada tipo de estado anterior é restaurado.
+func TestUndo(t *testing.T) {
+	ws := t.TempDir()
+	store := NewStore(filepath.Join(t.TempDir(), "checkpoints"))
+
+	existing := filepath.Join(ws, "existente.txt")
+	_ = os.WriteFile(existing, []byte("original"), 0600)
+	created := filepath.Join(ws, "novo.txt")
+	dir := filepath.Join(ws, "dir")
+
+	// 1: sobrescreve um arquivo; 2: cria um arquivo; 3: cria um diretório.
+	if _, err := store.Create("write_file", existing); err != nil {
+		t.Fatalf("Create() erro inesperado: %v", err)
+	}
+	_ = os.WriteFile(existing, []byte("alterado"), 0644)
+	if _, err := store.Create("write_file", created); err != nil {
+		t.Fatalf("Create() erro inesperado: %v", err)
+	}
+	_ = os.WriteFile(created, []byte("novo"), 0644)
+	if _, err := store.Create("create_directory", dir); err != nil {
+		t.Fatalf("Create() erro inesperado: %v", err)
+	}
+	_ = os.Mkdir(dir, 0755)
+
+	cp, err := store.Undo()
+	if err != nil || cp.ID != 3 {
+		t.Fatalf("Undo() = %v, %v; esperado checkpoint 3", cp, err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Error("Undo() não removeu o diretório criado")
+	}
+
+	if _, err := store.Undo(); err != nil {
+		t.Fatalf("Undo() erro inesperado: %v", err)
+	}
+	if _, err := os.Stat(created); !os.IsNotExist(err) {
+		t.Error("Undo() não removeu o arquivo criado")
+	}
+
+	if _, err := store.Undo(); err != nil {
+		t.Fatalf("Undo() erro inesperado: %v", err)
+	}
+	data, _ := os.ReadFile(existing)
+	if string(data) != "original" {
+		t.Errorf("conteúdo restaurado = %q, esperado %q", data, "original")
+	}
+	if info, _ := os.Stat(existing); info.Mode().Perm() != 0600 {
+		t.Errorf("permissões restauradas = %v, esperado 0600", info.Mode().Perm())
+	}
+
+	if _, err := store.Undo(); !errors.Is(err, ErrEmpty) {
+		t.Errorf("Undo() sem checkpoints erro = %v, esperado ErrEmpty", err)
+	}
+}
+
+// TestRevert testa a volta a um checkpoint anterior, incluindo um diretório removido.
+func TestRevert(t *testing.T) {
+	ws := t.TempDir()
+	store := NewStore(filepath.Join(t.TempDir(), "checkpoints"))
+	dir := filepath.Join(ws, "src")
+	_ = os.MkdirAll(filepath.Join(dir, "sub"), 0755)
+	_ = os.WriteFile(filepath.Join(dir, "sub", "a.go"), []byte("package a"), 0644)
+
+	file := filepath.Join(ws, "b.txt")
+	for i, content := range []string{"um", "dois", "três"} {
+		if _, err := store.Create("write_file", file); err != nil {
+			t.Fatalf("Create() %d erro inesperado: %v", i, err)
+		}
+		_ = os.WriteFile(file, []byte(content), 0644)
+	}
+	if _, err := store.Create("delete_path", dir); err != nil {
+		t.Fatalf("Create() erro inesperado: %v", err)
+	}
+	_ = os.RemoveAll(dir)
+
+	reverted, err := store.Revert(2)
+	if err != nil {
+		t.Fatalf("Revert() erro inesperado: %v", err)
+	}
+	if len(reverted) != 3 {
+		t.Errorf("Revert() desfez %d checkpoints, esperado 3", len(reverted))
+	}
+	if data, _ := os.ReadFile(file); string(data) != "um" {
+		t.Errorf("conteúdo após Revert(2) = %q, esperado %q", data, "um")
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "sub", "a.go")); string(data) != "package a" {
+		t.Errorf("diretório removido não foi restaurado: %q", data)
+	}
+
+	cps, _ := store.List()
+	if len(cps) != 1 || cps[0].ID != 1 {
+		t.Errorf("List() após Revert(2) = %d checkpoints, esperado apenas o 1", len(cps))
+	}
+	if _, err := store.Revert(5); err == nil {
+		t.Error("Revert() de checkpoint inexistente deveria retornar erro")
+	}
+}
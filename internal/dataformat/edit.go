@@ -0,0 +1,361 @@
+package dataformat
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// DecodeJSON converte um valor JSON nos tipos de Value: inteiros viram int64 (ou
+// json.Number, se não couberem) e objetos viram *Map, mantendo a ordem das chaves.
+func DecodeJSON(data []byte) (any, error) {
+	n, err := ParseJSON(string(data))
+	if err != nil {
+		return nil, err
+	}
+	return n.Value(), nil
+}
+
+// Lookup segue o caminho a partir de n. Índices negativos contam a partir do fim.
+func Lookup(n *Node, path []PathElem) (*Node, bool) {
+	for _, e := range path {
+		child, _, err := childAt(n, e)
+		if err != nil || child == nil {
+			return nil, false
+		}
+		n = child
+	}
+	return n, true
+}
+
+// childAt retorna o filho indicado por e e seu índice em Children. child é nil quando a
+// chave não existe ou o índice é o fim do array (uma posição para acrescentar).
+func childAt(n *Node, e PathElem) (child *Node, index int, err error) {
+	if e.IsIndex {
+		if n.Kind != Array {
+			return nil, 0, fmt.Errorf("%s não é um array", kindName(n.Kind))
+		}
+		i := e.Index
+		if i < 0 {
+			i += len(n.Children)
+		}
+		if i < 0 || i > len(n.Children) {
+			return nil, 0, fmt.Errorf("índice %d fora do intervalo (o array tem %d item(ns))", e.Index, len(n.Children))
+		}
+		if i == len(n.Children) {
+			return nil, i, nil
+		}
+		return n.Children[i], i, nil
+	}
+	if n.Kind != Object {
+		return nil, 0, fmt.Errorf("%s não é um objeto", kindName(n.Kind))
+	}
+	for i, k := range n.Keys {
+		if k == e.Key {
+			return n.Children[i], i, nil
+		}
+	}
+	return nil, len(n.Keys), nil
+}
+
+// kindName descreve um tipo nas mensagens de erro.
+func kindName(k Kind) string {
+	return [...]string{"null", "boolean", "número", "número", "string", "data", "array", "objeto"}[k]
+}
+
+// Set altera o valor no caminho do documento doc (índice entre os documentos do texto) e
+// retorna o novo texto. Só o trecho do valor é reescrito; chaves e itens que não existem
+// são acrescentados no estilo da coleção, e objetos intermediários que faltam são criados.
+func Set(format Format, src string, doc int, path []PathElem, value any) (string, error) {
+	docs, err := Parse(format, src)
+	if err != nil {
+		return "", err
+	}
+	if doc < 0 || doc >= len(docs) {
+		return "", fmt.Errorf("documento %d não existe (o arquivo tem %d)", doc, len(docs))
+	}
+	e := &editor{format: format, src: src}
+	out, err := e.set(docs[doc], nil, path, value)
+	if err != nil {
+		return "", err
+	}
+
+	// Confere que o novo texto continua válido e tem o valor esperado no caminho.
+	docs, err = Parse(format, out)
+	if err != nil {
+		return "", fmt.Errorf("a alteração produziria um arquivo inválido: %w", err)
+	}
+	got, ok := Lookup(docs[doc], path)
+	if !ok || EncodeJSON(got.Value()) != EncodeJSON(value) {
+		return "", fmt.Errorf("não foi possível alterar %s preservando o formato do arquivo", FormatPath(path))
+	}
+	return out, nil
+}
+
+type editor struct {
+	format Format
+	src    string
+}
+
+func (e *editor) splice(start, end int, text string) string {
+	return e.src[:start] + text + e.src[end:]
+}
+
+// set desce pelo caminho a partir de n, cujo pai é parent (nil na raiz).
+func (e *editor) set(n, parent *Node, path []PathElem, value any) (string, error) {
+	if len(path) == 0 || n.Kind == Null {
+		nested, err := nestedValue(path, value)
+		if err != nil {
+			return "", err
+		}
+		return e.replace(n, parent, nested)
+	}
+	child, index, err := childAt(n, path[0])
+	if err != nil {
+		return "", fmt.Errorf("não é possível acessar '%s': %w", FormatPath(path[:1]), err)
+	}
+	if child == nil {
+		nested, err := nestedValue(path[1:], value)
+		if err != nil {
+			return "", err
+		}
+		return e.insert(n, path[0], index, nested)
+	}
+	return e.set(child, n, path[1:], value)
+}
+
+// nestedValue cria os objetos e arrays que faltam no caminho até o valor.
+func nestedValue(path []PathElem, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	inner, err := nestedValue(path[1:], value)
+	if err != nil {
+		return nil, err
+	}
+	if path[0].IsIndex {
+		if path[0].Index != 0 {
+			return nil, fmt.Errorf("índice %d fora do intervalo (o array seria criado vazio)", path[0].Index)
+		}
+		return []any{inner}, nil
+	}
+	m := NewMap()
+	m.Set(path[0].Key, inner)
+	return m, nil
+}
+
+// replace troca o valor de n.
+func (e *editor) replace(n, parent *Node, value any) (string, error) {
+	switch e.format {
+	case JSON:
+		return e.splice(n.Start, n.End, e.jsonText(value, lineIndent(e.src, n.Start))), nil
+	case TOML:
+		if n.Style != Plain && n.Style != Flow {
+			return "", fmt.Errorf("não é possível substituir uma tabela inteira no TOML; altere as chaves uma a uma")
+		}
+		text, err := tomlValue(value)
+		if err != nil {
+			return "", err
+		}
+		return e.splice(n.Start, n.End, text), nil
+	}
+
+	// YAML: coleções em bloco são reescritas em bloco; valores vazios (chave sem nada
+	// depois do ":") e coleções em bloco são substituídos a partir do indicador.
+	style := n.Style
+	if n.Alias {
+		style = Plain
+	}
+	inFlow := parent != nil && parent.Style == Flow
+	block := !inFlow && style != Flow && nonEmptyCollection(value)
+	text := yamlFlow(value)
+	if inFlow {
+		text = yamlFlowItem(value)
+	}
+	switch {
+	case n.Lead < 0 && block:
+		return e.splice(n.Start, n.End, yamlBlock(value, max(n.Indent, 0))), nil
+	case n.Lead < 0:
+		return e.splice(n.Start, n.End, text), nil
+	case !block && (style == Block || n.Start == n.End):
+		return e.splice(n.Lead, n.End, " "+text), nil
+	case !block:
+		return e.splice(n.Start, n.End, text), nil
+	case style != Block && parent.Kind == Array:
+		return e.splice(n.Lead, n.End, " "+yamlBlock(value, parent.Indent+2)), nil
+	case style != Block:
+		return e.splice(n.Lead, n.End, yamlBlockValue(value, parent.Indent)), nil
+	}
+	indent := n.Indent
+	if _, isMap := value.(*Map); isMap && parent.Kind == Object && indent <= parent.Indent {
+		indent = parent.Indent + 2 // Lista na coluna da chave virando mapa
+	}
+	if !strings.Contains(e.src[n.Lead:n.Start], "\n") {
+		return e.splice(n.Lead, n.End, " "+yamlBlock(value, indent)), nil
+	}
+	return e.splice(n.Lead, n.End, "\n"+strings.Repeat(" ", indent)+yamlBlock(value, indent)), nil
+}
+
+func nonEmptyCollection(v any) bool {
+	switch v := v.(type) {
+	case []any:
+		return len(v) > 0
+	case *Map:
+		return v.Len() > 0
+	}
+	return false
+}
+
+// insert acrescenta a chave (ou o item, em arrays) à coleção n, na posição index.
+func (e *editor) insert(n *Node, elem PathElem, index int, value any) (string, error) {
+	if n.Alias {
+		return "", fmt.Errorf("o valor é um alias YAML; altere a âncora correspondente")
+	}
+	if elem.IsIndex && index < len(n.Children) {
+		return "", fmt.Errorf("só é possível acrescentar itens no fim do array")
+	}
+	switch {
+	case len(n.Children) == 0 && (n.Style == Plain || n.Style == Flow):
+		// Coleção vazia: reescreve a coleção inteira.
+		return e.replace(n, nil, appended(n, elem, value))
+	case e.format == JSON:
+		return e.insertInline(n, elem, value, func(v any, indent string) (string, error) {
+			return e.jsonText(v, indent), nil
+		}, ": ")
+	case e.format == YAML && n.Style == Flow:
+		return e.insertInline(n, elem, value, func(v any, _ string) (string, error) {
+			return yamlFlowItem(v), nil
+		}, ": ")
+	case e.format == YAML:
+		pad := strings.Repeat(" ", n.Indent)
+		line := pad + "- " + yamlBlock(value, n.Indent+2)
+		if !elem.IsIndex {
+			line = pad + yamlFlowItem(elem.Key) + ":" + yamlBlockValue(value, n.Indent)
+		}
+		at := lineEnd(e.src, n.End)
+		return e.splice(at, at, "\n"+line), nil
+	}
+	return e.insertTOML(n, elem, value)
+}
+
+// appended retorna o valor da coleção com a chave ou o item acrescentado.
+func appended(n *Node, elem PathElem, value any) any {
+	if elem.IsIndex {
+		return append(n.Value().([]any), value)
+	}
+	m := n.Value().(*Map)
+	m.Set(elem.Key, value)
+	return m
+}
+
+// insertInline acrescenta depois do último membro de uma coleção entre chaves ou
+// colchetes, seguindo o layout dela: um membro por linha ou todos na mesma linha.
+func (e *editor) insertInline(n *Node, elem PathElem, value any, render func(any, string) (string, error), sep string) (string, error) {
+	last := n.Children[len(n.Children)-1]
+	first := n.Children[0]
+	firstStart := first.Start
+	if first.KeyStart >= 0 {
+		firstStart = first.KeyStart
+	}
+	separator, indent := ", ", lineIndent(e.src, firstStart)
+	if strings.Contains(e.src[n.Start:firstStart], "\n") {
+		separator = ",\n" + indent
+	}
+	text, err := render(value, indent)
+	if err != nil {
+		return "", err
+	}
+	if !elem.IsIndex {
+		key := EncodeJSON(elem.Key)
+		switch {
+		case e.format == YAML:
+			key = yamlFlowItem(elem.Key)
+		case e.format == TOML:
+			key = tomlKey(elem.Key)
+		}
+		text = key + sep + text
+	}
+	return e.splice(last.End, last.End, separator+text), nil
+}
+
+// insertTOML acrescenta uma chave a uma tabela ou um item a um array TOML.
+func (e *editor) insertTOML(n *Node, elem PathElem, value any) (string, error) {
+	text, err := tomlValue(value)
+	if err != nil {
+		return "", err
+	}
+	switch n.Style {
+	case Flow:
+		return e.insertInline(n, elem, value, func(v any, _ string) (string, error) { return tomlValue(v) }, " = ")
+	case TableArray:
+		return "", fmt.Errorf("não é possível acrescentar itens a um array de tabelas ([[...]]); edite o arquivo diretamente")
+	case Implicit:
+		return "", fmt.Errorf("a tabela só existe implicitamente (por cabeçalhos de subtabelas); edite o arquivo diretamente")
+	}
+	line := tomlKey(elem.Key) + " = " + text
+	if n.Style == Dotted {
+		line = n.Prefix + "." + line
+	}
+	if n.Start == 0 && n.Style == Table && n.End == 0 {
+		// Raiz sem pares: o novo par vai antes do primeiro cabeçalho.
+		at := len(e.src)
+		for _, c := range n.Children {
+			at = min(at, c.Start)
+		}
+		if at < len(e.src) {
+			return e.splice(at, at, line+"\n\n"), nil
+		}
+		if e.src != "" && !strings.HasSuffix(e.src, "\n") {
+			line = "\n" + line
+		}
+		return e.splice(at, at, line+"\n"), nil
+	}
+	at := lineEnd(e.src, n.End)
+	return e.splice(at, at, "\n"+lineIndent(e.src, n.End)+line), nil
+}
+
+// jsonText escreve um valor JSON; coleções seguem a indentação do arquivo, a partir
+// da indentação da linha onde o valor fica.
+func (e *editor) jsonText(v any, prefix string) string {
+	unit := jsonIndentUnit(e.src)
+	if unit == "" || !nonEmptyCollection(v) {
+		return EncodeJSON(v)
+	}
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent(prefix, unit)
+	if err := enc.Encode(v); err != nil {
+		return EncodeJSON(v)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// jsonIndentUnit retorna a indentação usada no arquivo, ou "" se ele é compacto.
+func jsonIndentUnit(src string) string {
+	for _, line := range strings.Split(strings.TrimSpace(src), "\n")[1:] {
+		if indent := lineIndent(line, 0); indent != "" {
+			return indent
+		}
+	}
+	return ""
+}
+
+// lineIndent retorna os espaços no início da linha que contém pos.
+func lineIndent(src string, pos int) string {
+	start := strings.LastIndexByte(src[:pos], '\n') + 1
+	end := start
+	for end < len(src) && (src[end] == ' ' || src[end] == '\t') {
+		end++
+	}
+	return src[start:end]
+}
+
+// lineEnd retorna a posição do fim da linha que contém pos.
+func lineEnd(src string, pos int) int {
+	if i := strings.IndexByte(src[pos:], '\n'); i >= 0 {
+		return pos + i
+	}
+	return len(src)
+}
@@ -0,0 +1,233 @@
+package dataformat
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestSet testa que a alteração reescreve só o trecho do valor, mantendo o resto do
+// arquivo como estava.
+func TestSet(t *testing.T) {
+	testCases := []struct {
+		name          string
+		format        Format
+		src           string
+		doc           int
+		path          []PathElem
+		value         string
+		expected      string
+		expectedError string
+	}{
+		{
+			name:     "JSON troca escalar",
+			format:   JSON,
+			src:      "{\n  \"name\": \"app\",\n  \"port\": 80\n}\n",
+			path:     []PathElem{{Key: "port"}},
+			value:    `8080`,
+			expected: "{\n  \"name\": \"app\",\n  \"port\": 8080\n}\n",
+		},
+		{
+			name:     "JSON acrescenta chave com objeto indentado",
+			format:   JSON,
+			src:      "{\n    \"name\": \"app\"\n}\n",
+			path:     []PathElem{{Key: "db"}, {Key: "host"}},
+			value:    `"localhost"`,
+			expected: "{\n    \"name\": \"app\",\n    \"db\": {\n        \"host\": \"localhost\"\n    }\n}\n",
+		},
+		{
+			name:     "JSON compacto acrescenta no array",
+			format:   JSON,
+			src:      `{"tags":["a","b"]}`,
+			path:     []PathElem{{Key: "tags"}, {Index: 2, IsIndex: true}},
+			value:    `"c"`,
+			expected: `{"tags":["a","b", "c"]}`,
+		},
+		{
+			name:     "JSON objeto vazio",
+			format:   JSON,
+			src:      `{"a": {}}`,
+			path:     []PathElem{{Key: "a"}, {Key: "b"}},
+			value:    `true`,
+			expected: `{"a": {"b":true}}`,
+		},
+		{
+			name:          "JSON índice fora do intervalo",
+			format:        JSON,
+			src:           `{"tags":["a"]}`,
+			path:          []PathElem{{Key: "tags"}, {Index: 5, IsIndex: true}},
+			value:         `"c"`,
+			expectedError: "índice 5 fora do intervalo (o array tem 1 item(ns))",
+		},
+		{
+			name:          "JSON caminho através de escalar",
+			format:        JSON,
+			src:           `{"a": 1}`,
+			path:          []PathElem{{Key: "a"}, {Key: "b"}},
+			value:         `2`,
+			expectedError: "não é possível acessar '.b': número não é um objeto",
+		},
+		{
+			name:     "YAML troca escalar mantendo comentários",
+			format:   YAML,
+			src:      "# app\nimage: nginx:1.0 # versão\nreplicas: 1\n",
+			path:     []PathElem{{Key: "image"}},
+			value:    `"nginx:1.2"`,
+			expected: "# app\nimage: nginx:1.2 # versão\nreplicas: 1\n",
+		},
+		{
+			name:     "YAML acrescenta chave em mapa aninhado",
+			format:   YAML,
+			src:      "spec:\n  replicas: 1\n  selector: app\nother: x\n",
+			path:     []PathElem{{Key: "spec"}, {Key: "ports"}},
+			value:    `[80, 443]`,
+			expected: "spec:\n  replicas: 1\n  selector: app\n  ports:\n    - 80\n    - 443\nother: x\n",
+		},
+		{
+			name:     "YAML valor vazio vira mapa",
+			format:   YAML,
+			src:      "env:\nname: x\n",
+			path:     []PathElem{{Key: "env"}, {Key: "DEBUG"}},
+			value:    `"1"`,
+			expected: "env:\n  DEBUG: \"1\"\nname: x\n",
+		},
+		{
+			name:     "YAML acrescenta item na lista",
+			format:   YAML,
+			src:      "items:\n- a: 1\n- a: 2\n",
+			path:     []PathElem{{Key: "items"}, {Index: 2, IsIndex: true}},
+			value:    `{"a": 3, "b": "x"}`,
+			expected: "items:\n- a: 1\n- a: 2\n- a: 3\n  b: x\n",
+		},
+		{
+			name:     "YAML troca lista em bloco",
+			format:   YAML,
+			src:      "a:\n  - 1\n  - 2\nb: 0\n",
+			path:     []PathElem{{Key: "a"}},
+			value:    `["x", "z"]`,
+			expected: "a:\n  - x\n  - z\nb: 0\n",
+		},
+		{
+			name:     "YAML lista em bloco vira escalar",
+			format:   YAML,
+			src:      "a:\n  - 1\n  - 2\nb: 0\n",
+			path:     []PathElem{{Key: "a"}},
+			value:    `"nada"`,
+			expected: "a: nada\nb: 0\n",
+		},
+		{
+			name:     "YAML fluxo",
+			format:   YAML,
+			src:      "ports: [80, 443]\nmeta: {a: 1}\n",
+			path:     []PathElem{{Key: "meta"}, {Key: "b"}},
+			value:    `"x, y"`,
+			expected: "ports: [80, 443]\nmeta: {a: 1, b: \"x, y\"}\n",
+		},
+		{
+			name:     "YAML segundo documento",
+			format:   YAML,
+			src:      "kind: A\n---\nkind: B\n",
+			doc:      1,
+			path:     []PathElem{{Key: "kind"}},
+			value:    `"C"`,
+			expected: "kind: A\n---\nkind: C\n",
+		},
+		{
+			name:     "YAML string que parece número",
+			format:   YAML,
+			src:      "version: 1\n",
+			path:     []PathElem{{Key: "version"}},
+			value:    `"2"`,
+			expected: "version: \"2\"\n",
+		},
+		{
+			name:     "TOML troca valor em tabela",
+			format:   TOML,
+			src:      "[server]\nport = 80 # porta\nhost = \"x\"\n",
+			path:     []PathElem{{Key: "server"}, {Key: "port"}},
+			value:    `8080`,
+			expected: "[server]\nport = 8080 # porta\nhost = \"x\"\n",
+		},
+		{
+			name:     "TOML acrescenta chave na tabela",
+			format:   TOML,
+			src:      "[server]\nport = 80\n\n[db]\nurl = \"x\"\n",
+			path:     []PathElem{{Key: "server"}, {Key: "tls"}},
+			value:    `{"enabled": true}`,
+			expected: "[server]\nport = 80\ntls = { enabled = true }\n\n[db]\nurl = \"x\"\n",
+		},
+		{
+			name:     "TOML acrescenta na raiz antes dos cabeçalhos",
+			format:   TOML,
+			src:      "[server]\nport = 80\n",
+			path:     []PathElem{{Key: "name"}},
+			value:    `"app"`,
+			expected: "name = \"app\"\n\n[server]\nport = 80\n",
+		},
+		{
+			name:     "TOML chave com ponto",
+			format:   TOML,
+			src:      "tool.lint.strict = true\n",
+			path:     []PathElem{{Key: "tool"}, {Key: "lint"}, {Key: "level"}},
+			value:    `2`,
+			expected: "tool.lint.strict = true\ntool.lint.level = 2\n",
+		},
+		{
+			name:     "TOML array em várias linhas",
+			format:   TOML,
+			src:      "deps = [\n  \"a\",\n  \"b\",\n]\n",
+			path:     []PathElem{{Key: "deps"}, {Index: 2, IsIndex: true}},
+			value:    `"c"`,
+			expected: "deps = [\n  \"a\",\n  \"b\",\n  \"c\",\n]\n",
+		},
+		{
+			name:          "TOML não tem null",
+			format:        TOML,
+			src:           "a = 1\n",
+			path:          []PathElem{{Key: "a"}},
+			value:         `null`,
+			expectedError: "TOML não tem valor nulo",
+		},
+		{
+			name:          "TOML tabela inteira",
+			format:        TOML,
+			src:           "[a]\nx = 1\n",
+			path:          []PathElem{{Key: "a"}},
+			value:         `{"y": 2}`,
+			expectedError: "não é possível substituir uma tabela inteira",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			value, err := DecodeJSON([]byte(tc.value))
+			if err != nil {
+				t.Fatalf("valor inválido no teste: %v", err)
+			}
+			got, err := Set(tc.format, tc.src, tc.doc, tc.path, value)
+			if tc.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedError) {
+					t.Fatalf("erro = %v, esperado conter %q", err, tc.expectedError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+			if got != tc.expected {
+				t.Errorf("resultado =\n%s\nesperado\n%s", got, tc.expected)
+			}
+		})
+	}
+}
+
+// TestDecodeJSONNumbers testa que inteiros maiores que int64 voltam com os dígitos originais.
+func TestDecodeJSONNumbers(t *testing.T) {
+	src := `{"small":-12,"big":12345678901234567890,"negative":-98765432109876543210,"float":1.5}`
+	v, err := DecodeJSON([]byte(src))
+	if err != nil {
+		t.Fatalf("DecodeJSON() erro inesperado: %v", err)
+	}
+	if got := EncodeJSON(v); got != src {
+		t.Errorf("EncodeJSON() = %s, esperado %s", got, src)
+	}
+}
@@ -0,0 +1,205 @@
+package dataformat
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// jsonParser é um parser descendente de JSON que registra a posição de cada valor.
+type jsonParser struct {
+	src string
+	pos int
+}
+
+// ParseJSON lê um documento JSON.
+func ParseJSON(src string) (*Node, error) {
+	p := &jsonParser{src: src}
+	p.skip()
+	if p.pos == len(src) {
+		return nil, syntaxError(src, p.pos, "documento vazio")
+	}
+	n, err := p.value()
+	if err != nil {
+		return nil, err
+	}
+	p.skip()
+	if p.pos < len(src) {
+		return nil, syntaxError(src, p.pos, "conteúdo inesperado após o fim do documento")
+	}
+	return n, nil
+}
+
+func (p *jsonParser) skip() {
+	for p.pos < len(p.src) && strings.IndexByte(" \t\r\n", p.src[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+func (p *jsonParser) value() (*Node, error) {
+	if p.pos >= len(p.src) {
+		return nil, syntaxError(p.src, p.pos, "fim inesperado do documento")
+	}
+	start := p.pos
+	switch c := p.src[p.pos]; {
+	case c == '{':
+		return p.object()
+	case c == '[':
+		return p.array()
+	case c == '"':
+		s, err := p.str()
+		if err != nil {
+			return nil, err
+		}
+		n := newNode(String, Plain, start)
+		n.Scalar, n.End = s, p.pos
+		return n, nil
+	case c == '-' || (c >= '0' && c <= '9'):
+		for p.pos < len(p.src) && strings.IndexByte("+-.0123456789eE", p.src[p.pos]) >= 0 {
+			p.pos++
+		}
+		return numberNode(p.src, start, p.pos)
+	}
+	for _, lit := range []struct {
+		text  string
+		kind  Kind
+		value any
+	}{{"true", Bool, true}, {"false", Bool, false}, {"null", Null, nil}} {
+		if strings.HasPrefix(p.src[p.pos:], lit.text) {
+			p.pos += len(lit.text)
+			n := newNode(lit.kind, Plain, start)
+			n.Scalar, n.End = lit.value, p.pos
+			return n, nil
+		}
+	}
+	return nil, syntaxError(p.src, p.pos, "valor inválido")
+}
+
+// numberNode interpreta um número JSON: inteiro se couber em int64, float caso contrário.
+// Inteiros maiores que int64 mantêm o texto original como json.Number, sem perder dígitos.
+func numberNode(src string, start, end int) (*Node, error) {
+	text := src[start:end]
+	if !strings.ContainsAny(text, ".eE") {
+		i, err := strconv.ParseInt(text, 10, 64)
+		if err == nil || errors.Is(err, strconv.ErrRange) {
+			n := newNode(Int, Plain, start)
+			n.Scalar, n.End = i, end
+			if err != nil {
+				n.Scalar = json.Number(text)
+			}
+			return n, nil
+		}
+	}
+	f, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return nil, syntaxError(src, start, "número inválido '%s'", text)
+	}
+	n := newNode(Float, Plain, start)
+	n.Scalar, n.End = f, end
+	return n, nil
+}
+
+func (p *jsonParser) str() (string, error) {
+	start := p.pos
+	for p.pos++; p.pos < len(p.src); p.pos++ {
+		switch p.src[p.pos] {
+		case '\\':
+			p.pos++
+		case '"':
+			p.pos++
+			var s string
+			if err := json.Unmarshal([]byte(p.src[start:p.pos]), &s); err != nil {
+				return "", syntaxError(p.src, start, "string inválida")
+			}
+			return s, nil
+		case '\n':
+			return "", syntaxError(p.src, p.pos, "string sem fechamento")
+		}
+	}
+	return "", syntaxError(p.src, start, "string sem fechamento")
+}
+
+func (p *jsonParser) object() (*Node, error) {
+	n := newNode(Object, Plain, p.pos)
+	p.pos++
+	p.skip()
+	if p.pos < len(p.src) && p.src[p.pos] == '}' {
+		p.pos++
+		n.End = p.pos
+		return n, nil
+	}
+	for {
+		if p.pos >= len(p.src) || p.src[p.pos] != '"' {
+			return nil, syntaxError(p.src, p.pos, "esperava a chave entre aspas")
+		}
+		keyStart := p.pos
+		key, err := p.str()
+		if err != nil {
+			return nil, err
+		}
+		if _, dup := n.Get(key); dup {
+			return nil, syntaxError(p.src, keyStart, "chave duplicada '%s'", key)
+		}
+		p.skip()
+		if p.pos >= len(p.src) || p.src[p.pos] != ':' {
+			return nil, syntaxError(p.src, p.pos, "esperava ':' após a chave")
+		}
+		p.pos++
+		p.skip()
+		child, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		child.KeyStart = keyStart
+		n.set(key, child)
+		p.skip()
+		if p.pos >= len(p.src) {
+			return nil, syntaxError(p.src, p.pos, "objeto sem fechamento")
+		}
+		switch p.src[p.pos] {
+		case ',':
+			p.pos++
+			p.skip()
+		case '}':
+			p.pos++
+			n.End = p.pos
+			return n, nil
+		default:
+			return nil, syntaxError(p.src, p.pos, "esperava ',' ou '}'")
+		}
+	}
+}
+
+func (p *jsonParser) array() (*Node, error) {
+	n := newNode(Array, Plain, p.pos)
+	p.pos++
+	p.skip()
+	if p.pos < len(p.src) && p.src[p.pos] == ']' {
+		p.pos++
+		n.End = p.pos
+		return n, nil
+	}
+	for {
+		child, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		n.Children = append(n.Children, child)
+		p.skip()
+		if p.pos >= len(p.src) {
+			return nil, syntaxError(p.src, p.pos, "array sem fechamento")
+		}
+		switch p.src[p.pos] {
+		case ',':
+			p.pos++
+			p.skip()
+		case ']':
+			p.pos++
+			n.End = p.pos
+			return n, nil
+		default:
+			return nil, syntaxError(p.src, p.pos, "esperava ',' ou ']'")
+		}
+	}
+}
@@ -0,0 +1,330 @@
+// Package dataformat lê arquivos JSON, YAML e TOML em uma árvore que guarda a posição de
+// cada valor no texto original, permitindo consultar os dados e alterar um valor
+// reescrevendo só o trecho correspondente, sem perder comentários e formatação.
+package dataformat
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Format é um dos formatos suportados.
+type Format string
+
+const (
+	JSON Format = "json"
+	YAML Format = "yaml"
+	TOML Format = "toml"
+)
+
+// DetectFormat deduz o formato pela extensão do arquivo.
+func DetectFormat(path string) (Format, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return JSON, nil
+	case ".yaml", ".yml":
+		return YAML, nil
+	case ".toml":
+		return TOML, nil
+	}
+	return "", fmt.Errorf("formato de '%s' não reconhecido pela extensão; informe 'format' (json, yaml ou toml)", filepath.Base(path))
+}
+
+// ParseFormat valida o nome de um formato.
+func ParseFormat(name string) (Format, error) {
+	switch f := Format(strings.ToLower(name)); f {
+	case JSON, YAML, TOML:
+		return f, nil
+	case "yml":
+		return YAML, nil
+	}
+	return "", fmt.Errorf("formato inválido '%s'. Use json, yaml ou toml", name)
+}
+
+// Parse lê os documentos do texto. Só YAML pode ter mais de um documento.
+func Parse(format Format, src string) ([]*Node, error) {
+	switch format {
+	case JSON:
+		n, err := ParseJSON(src)
+		if err != nil {
+			return nil, err
+		}
+		return []*Node{n}, nil
+	case YAML:
+		return ParseYAML(src)
+	case TOML:
+		n, err := ParseTOML(src)
+		if err != nil {
+			return nil, err
+		}
+		return []*Node{n}, nil
+	}
+	return nil, fmt.Errorf("formato inválido '%s'", format)
+}
+
+// Kind é o tipo de um valor.
+type Kind int
+
+const (
+	Null Kind = iota
+	Bool
+	Int
+	Float
+	String
+	Datetime // TOML; consultado como string
+	Array
+	Object
+)
+
+// Style diz como o valor foi escrito, o que define como ele pode ser alterado.
+type Style int
+
+const (
+	Plain      Style = iota // Escalar ou coleção JSON
+	Flow                    // YAML [..] e {..}; arrays e tabelas inline do TOML
+	Block                   // Coleção YAML por indentação
+	Table                   // Tabela TOML com cabeçalho [x], ou a raiz
+	TableArray              // Array de tabelas TOML [[x]]
+	Dotted                  // Tabela TOML criada por chaves com ponto (a.b = 1)
+	Implicit                // Tabela TOML criada implicitamente por um cabeçalho
+)
+
+// Node é um valor do documento e sua posição no texto.
+type Node struct {
+	Kind     Kind
+	Style    Style
+	Scalar   any      // bool, int64, json.Number, float64 ou string, nos valores escalares
+	Keys     []string // Chaves de um objeto, na ordem do arquivo
+	Children []*Node  // Valores do objeto (na ordem de Keys) ou itens do array
+
+	// Start e End delimitam o valor no texto (End exclusivo). Em tabelas TOML, vão do
+	// cabeçalho até o fim do último par chave/valor.
+	Start, End int
+	// Lead é a posição logo após o ":" ou "-" que introduz um valor YAML, ou -1.
+	Lead int
+	// KeyStart é a posição da chave de um membro de objeto, ou -1.
+	KeyStart int
+	// Indent é a coluna das entradas de uma coleção YAML em bloco.
+	Indent int
+	// Prefix é a chave com pontos de uma tabela TOML Dotted, como escrita.
+	Prefix string
+	// Alias indica um alias YAML (*nome): o conteúdo é o da âncora, o texto é só o alias.
+	Alias bool
+}
+
+func newNode(kind Kind, style Style, start int) *Node {
+	return &Node{Kind: kind, Style: style, Start: start, End: start, Lead: -1, KeyStart: -1}
+}
+
+// Get retorna o valor da chave em um objeto.
+func (n *Node) Get(key string) (*Node, bool) {
+	for i, k := range n.Keys {
+		if k == key {
+			return n.Children[i], true
+		}
+	}
+	return nil, false
+}
+
+// set adiciona ou troca o valor de uma chave.
+func (n *Node) set(key string, child *Node) {
+	for i, k := range n.Keys {
+		if k == key {
+			n.Children[i] = child
+			return
+		}
+	}
+	n.Keys = append(n.Keys, key)
+	n.Children = append(n.Children, child)
+}
+
+// Value converte a árvore em valores Go: nil, bool, int64, json.Number (inteiros JSON
+// maiores que int64), float64, string, []any e *Map.
+// Chaves "<<" do YAML mesclam o mapa referenciado, sem sobrescrever chaves existentes.
+func (n *Node) Value() any {
+	switch n.Kind {
+	case Null:
+		return nil
+	case Array:
+		items := make([]any, len(n.Children))
+		for i, c := range n.Children {
+			items[i] = c.Value()
+		}
+		return items
+	case Object:
+		m := NewMap()
+		var merges []*Node
+		for i, k := range n.Keys {
+			if k == "<<" && n.Style != Table && (n.Children[i].Kind == Object || n.Children[i].Kind == Array) {
+				merges = append(merges, n.Children[i])
+				continue
+			}
+			m.Set(k, n.Children[i].Value())
+		}
+		for _, merge := range merges {
+			sources := []*Node{merge}
+			if merge.Kind == Array {
+				sources = merge.Children
+			}
+			for _, src := range sources {
+				if src.Kind != Object {
+					continue
+				}
+				v := src.Value().(*Map)
+				for _, k := range v.Keys() {
+					if _, ok := m.Get(k); !ok {
+						m.Set(k, v.values[k])
+					}
+				}
+			}
+		}
+		return m
+	}
+	return n.Scalar
+}
+
+// Map é um objeto que mantém a ordem das chaves.
+type Map struct {
+	keys   []string
+	values map[string]any
+}
+
+// NewMap cria um Map vazio.
+func NewMap() *Map {
+	return &Map{values: make(map[string]any)}
+}
+
+// Set define o valor de uma chave, mantendo a posição se ela já existir.
+func (m *Map) Set(key string, value any) {
+	if _, ok := m.values[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = value
+}
+
+// Get retorna o valor de uma chave.
+func (m *Map) Get(key string) (any, bool) {
+	v, ok := m.values[key]
+	return v, ok
+}
+
+// Delete remove uma chave.
+func (m *Map) Delete(key string) {
+	if _, ok := m.values[key]; !ok {
+		return
+	}
+	delete(m.values, key)
+	for i, k := range m.keys {
+		if k == key {
+			m.keys = append(m.keys[:i:i], m.keys[i+1:]...)
+			break
+		}
+	}
+}
+
+// Keys retorna as chaves na ordem de inserção.
+func (m *Map) Keys() []string {
+	return m.keys
+}
+
+// Len retorna o número de chaves.
+func (m *Map) Len() int {
+	return len(m.keys)
+}
+
+// MarshalJSON codifica o objeto mantendo a ordem das chaves.
+func (m *Map) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, k := range m.keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		key, _ := marshalJSON(k)
+		b.Write(key)
+		b.WriteByte(':')
+		v, err := marshalJSON(m.values[k])
+		if err != nil {
+			return nil, err
+		}
+		b.Write(v)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// marshalJSON codifica sem escapar <, > e &, que só atrapalham a leitura.
+func marshalJSON(v any) ([]byte, error) {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(b.Bytes(), "\n"), nil
+}
+
+// EncodeJSON codifica um valor em JSON compacto, mantendo a ordem das chaves.
+func EncodeJSON(v any) string {
+	data, err := marshalJSON(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(data)
+}
+
+// PathElem é um passo de um caminho: uma chave de objeto ou um índice de array.
+type PathElem struct {
+	Key     string
+	Index   int
+	IsIndex bool
+}
+
+// FormatPath escreve o caminho na sintaxe das consultas, ex: .spec.containers[0].image.
+func FormatPath(path []PathElem) string {
+	if len(path) == 0 {
+		return "."
+	}
+	var b strings.Builder
+	for _, e := range path {
+		switch {
+		case e.IsIndex:
+			fmt.Fprintf(&b, "[%d]", e.Index)
+		case isIdentifier(e.Key):
+			b.WriteString("." + e.Key)
+		default:
+			b.WriteString("." + strconv.Quote(e.Key))
+		}
+	}
+	return b.String()
+}
+
+func isIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, c := range s {
+		if c != '_' && !(c >= 'a' && c <= 'z') && !(c >= 'A' && c <= 'Z') && !(i > 0 && c >= '0' && c <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// position converte um deslocamento em linha e coluna, a partir de 1.
+func position(src string, offset int) (line, col int) {
+	offset = min(offset, len(src))
+	line = 1 + strings.Count(src[:offset], "\n")
+	col = offset - strings.LastIndexByte(src[:offset], '\n')
+	return line, col
+}
+
+// syntaxError formata um erro de sintaxe com a posição no texto.
+func syntaxError(src string, offset int, format string, args ...any) error {
+	line, col := position(src, offset)
+	return fmt.Errorf("linha %d, coluna %d: %s", line, col, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,520 @@
+package dataformat
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// tomlParser lê documentos TOML 1.0.
+type tomlParser struct {
+	src  string
+	pos  int
+	root *Node
+}
+
+var (
+	tomlBareKey   = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+	tomlDate      = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+	tomlDatetime  = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2}([Tt ]\d{2}:\d{2}:\d{2}(\.\d+)?([Zz]|[+-]\d{2}:\d{2})?)?|\d{2}:\d{2}:\d{2}(\.\d+)?)$`)
+	tomlDecimal   = regexp.MustCompile(`^[+-]?(0|[1-9](_?[0-9])*)$`)
+	tomlFloat     = regexp.MustCompile(`^[+-]?(0|[1-9](_?[0-9])*)(\.[0-9](_?[0-9])*)?([eE][+-]?[0-9](_?[0-9])*)?$`)
+	tomlPrefixInt = regexp.MustCompile(`^0(x[0-9A-Fa-f](_?[0-9A-Fa-f])*|o[0-7](_?[0-7])*|b[01](_?[01])*)$`)
+)
+
+// ParseTOML lê um documento TOML. A raiz é uma tabela cujo End é o fim do último par
+// chave/valor antes do primeiro cabeçalho (0 se não houver nenhum).
+func ParseTOML(src string) (*Node, error) {
+	p := &tomlParser{src: src, root: newNode(Object, Table, 0)}
+	current := p.root
+	for {
+		p.skipBlank()
+		if p.pos >= len(p.src) {
+			return p.root, nil
+		}
+		var err error
+		if p.src[p.pos] == '[' {
+			current, err = p.header()
+		} else {
+			err = p.keyValue(current, current)
+		}
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpaces()
+		if p.pos < len(p.src) && p.src[p.pos] == '#' {
+			p.skipComment()
+		}
+		if p.pos < len(p.src) && p.src[p.pos] != '\n' && !strings.HasPrefix(p.src[p.pos:], "\r\n") {
+			return nil, syntaxError(p.src, p.pos, "esperava o fim da linha")
+		}
+	}
+}
+
+func (p *tomlParser) skipSpaces() {
+	for p.pos < len(p.src) && (p.src[p.pos] == ' ' || p.src[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+func (p *tomlParser) skipComment() {
+	if i := strings.IndexByte(p.src[p.pos:], '\n'); i >= 0 {
+		p.pos += i
+	} else {
+		p.pos = len(p.src)
+	}
+}
+
+// skipBlank pula espaços, quebras de linha e comentários.
+func (p *tomlParser) skipBlank() {
+	for p.pos < len(p.src) {
+		switch p.src[p.pos] {
+		case ' ', '\t', '\r', '\n':
+			p.pos++
+		case '#':
+			p.skipComment()
+		default:
+			return
+		}
+	}
+}
+
+// key lê uma chave, possivelmente com pontos. Retorna as partes e onde cada uma termina.
+func (p *tomlParser) key() (parts []string, ends []int, err error) {
+	for {
+		p.skipSpaces()
+		if p.pos >= len(p.src) {
+			return nil, nil, syntaxError(p.src, p.pos, "esperava uma chave")
+		}
+		var part string
+		switch p.src[p.pos] {
+		case '"':
+			part, err = p.basicString()
+		case '\'':
+			part, err = p.literalString()
+		default:
+			start := p.pos
+			for p.pos < len(p.src) && tomlBareKey.MatchString(p.src[p.pos:p.pos+1]) {
+				p.pos++
+			}
+			if p.pos == start {
+				return nil, nil, syntaxError(p.src, p.pos, "chave inválida")
+			}
+			part = p.src[start:p.pos]
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		parts = append(parts, part)
+		ends = append(ends, p.pos)
+		p.skipSpaces()
+		if p.pos >= len(p.src) || p.src[p.pos] != '.' {
+			return parts, ends, nil
+		}
+		p.pos++
+	}
+}
+
+// keyValue lê "chave = valor" e o guarda em table. owner é a tabela cujo End acompanha
+// os pares lidos (a própria table, ou a tabela do cabeçalho atual).
+func (p *tomlParser) keyValue(table, owner *Node) error {
+	keyStart := p.pos
+	parts, ends, err := p.key()
+	if err != nil {
+		return err
+	}
+	if p.pos >= len(p.src) || p.src[p.pos] != '=' {
+		return syntaxError(p.src, p.pos, "esperava '=' após a chave")
+	}
+	p.pos++
+	p.skipSpaces()
+	value, err := p.value()
+	if err != nil {
+		return err
+	}
+	value.KeyStart = keyStart
+
+	t := table
+	for i, part := range parts[:len(parts)-1] {
+		child, ok := t.Get(part)
+		if !ok {
+			style := Dotted
+			if table.Style == Flow {
+				style = Implicit // Chaves com ponto dentro de tabela inline
+			}
+			child = newNode(Object, style, keyStart)
+			child.KeyStart = keyStart
+			child.Prefix = p.src[keyStart:ends[i]]
+			t.set(part, child)
+		} else if child.Kind != Object || (child.Style != Dotted && child.Style != Implicit) {
+			return syntaxError(p.src, keyStart, "a chave '%s' já foi definida", strings.Join(parts[:i+1], "."))
+		}
+		child.End = value.End
+		t = child
+	}
+	last := parts[len(parts)-1]
+	if _, dup := t.Get(last); dup {
+		return syntaxError(p.src, keyStart, "a chave '%s' já foi definida", strings.Join(parts, "."))
+	}
+	t.set(last, value)
+	owner.End = value.End
+	return nil
+}
+
+// header lê um cabeçalho [tabela] ou [[array]] e retorna a tabela que recebe os pares.
+func (p *tomlParser) header() (*Node, error) {
+	start := p.pos
+	array := strings.HasPrefix(p.src[p.pos:], "[[")
+	p.pos++
+	if array {
+		p.pos++
+	}
+	parts, _, err := p.key()
+	if err != nil {
+		return nil, err
+	}
+	closing := "]"
+	if array {
+		closing = "]]"
+	}
+	if !strings.HasPrefix(p.src[p.pos:], closing) {
+		return nil, syntaxError(p.src, p.pos, "esperava '%s' no fim do cabeçalho", closing)
+	}
+	p.pos += len(closing)
+	name := strings.Join(parts, ".")
+
+	t := p.root
+	for _, part := range parts[:len(parts)-1] {
+		child, ok := t.Get(part)
+		if !ok {
+			child = newNode(Object, Implicit, start)
+			t.set(part, child)
+		}
+		switch {
+		case child.Style == TableArray:
+			t = child.Children[len(child.Children)-1]
+		case child.Kind == Object && child.Style != Flow:
+			t = child
+		default:
+			return nil, syntaxError(p.src, start, "'%s' não é uma tabela", part)
+		}
+	}
+
+	last := parts[len(parts)-1]
+	existing, ok := t.Get(last)
+	if array {
+		if !ok {
+			existing = newNode(Array, TableArray, start)
+			existing.KeyStart = start
+			t.set(last, existing)
+		} else if existing.Style != TableArray {
+			return nil, syntaxError(p.src, start, "'%s' já foi definido e não é um array de tabelas", name)
+		}
+		table := newNode(Object, Table, start)
+		table.End = p.pos
+		existing.Children = append(existing.Children, table)
+		existing.End = p.pos
+		return table, nil
+	}
+	if ok {
+		if existing.Style != Implicit || existing.Kind != Object {
+			return nil, syntaxError(p.src, start, "a tabela '%s' já foi definida", name)
+		}
+		existing.Style, existing.Start, existing.End, existing.KeyStart = Table, start, p.pos, start
+		return existing, nil
+	}
+	table := newNode(Object, Table, start)
+	table.End, table.KeyStart = p.pos, start
+	t.set(last, table)
+	return table, nil
+}
+
+func (p *tomlParser) value() (*Node, error) {
+	start := p.pos
+	if p.pos >= len(p.src) {
+		return nil, syntaxError(p.src, p.pos, "esperava um valor")
+	}
+	switch p.src[p.pos] {
+	case '"', '\'':
+		var s string
+		var err error
+		switch {
+		case strings.HasPrefix(p.src[p.pos:], `"""`):
+			s, err = p.multilineString('"')
+		case strings.HasPrefix(p.src[p.pos:], `'''`):
+			s, err = p.multilineString('\'')
+		case p.src[p.pos] == '"':
+			s, err = p.basicString()
+		default:
+			s, err = p.literalString()
+		}
+		if err != nil {
+			return nil, err
+		}
+		n := newNode(String, Plain, start)
+		n.Scalar, n.End = s, p.pos
+		return n, nil
+	case '[':
+		return p.array()
+	case '{':
+		return p.inlineTable()
+	}
+
+	// Booleanos, números e datas: lê até um delimitador. Datas podem ter um espaço entre
+	// a data e a hora.
+	for p.pos < len(p.src) && strings.IndexByte(" \t\r\n,]}#", p.src[p.pos]) < 0 {
+		p.pos++
+	}
+	if p.pos+1 < len(p.src) && p.src[p.pos] == ' ' && p.src[p.pos+1] >= '0' && p.src[p.pos+1] <= '9' &&
+		tomlDate.MatchString(p.src[start:p.pos]) {
+		for p.pos++; p.pos < len(p.src) && strings.IndexByte(" \t\r\n,]}#", p.src[p.pos]) < 0; p.pos++ {
+		}
+	}
+	text := p.src[start:p.pos]
+	n := newNode(Null, Plain, start)
+	n.End = p.pos
+	clean := strings.ReplaceAll(text, "_", "")
+	switch {
+	case text == "true" || text == "false":
+		n.Kind, n.Scalar = Bool, text == "true"
+	case tomlDatetime.MatchString(text):
+		n.Kind, n.Scalar = Datetime, text
+	case tomlDecimal.MatchString(text):
+		i, err := strconv.ParseInt(clean, 10, 64)
+		if err != nil {
+			return nil, syntaxError(p.src, start, "inteiro fora do intervalo '%s'", text)
+		}
+		n.Kind, n.Scalar = Int, i
+	case tomlPrefixInt.MatchString(text):
+		i, err := strconv.ParseInt(clean, 0, 64)
+		if err != nil {
+			return nil, syntaxError(p.src, start, "inteiro fora do intervalo '%s'", text)
+		}
+		n.Kind, n.Scalar = Int, i
+	case text == "inf" || text == "+inf":
+		n.Kind, n.Scalar = Float, math.Inf(1)
+	case text == "-inf":
+		n.Kind, n.Scalar = Float, math.Inf(-1)
+	case text == "nan" || text == "+nan" || text == "-nan":
+		n.Kind, n.Scalar = Float, math.NaN()
+	case tomlFloat.MatchString(text):
+		f, err := strconv.ParseFloat(clean, 64)
+		if err != nil {
+			return nil, syntaxError(p.src, start, "número inválido '%s'", text)
+		}
+		n.Kind, n.Scalar = Float, f
+	default:
+		return nil, syntaxError(p.src, start, "valor inválido '%s'", text)
+	}
+	return n, nil
+}
+
+func (p *tomlParser) array() (*Node, error) {
+	n := newNode(Array, Flow, p.pos)
+	p.pos++
+	for {
+		p.skipBlank()
+		if p.pos >= len(p.src) {
+			return nil, syntaxError(p.src, n.Start, "array sem fechamento")
+		}
+		if p.src[p.pos] == ']' {
+			p.pos++
+			n.End = p.pos
+			return n, nil
+		}
+		child, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		n.Children = append(n.Children, child)
+		p.skipBlank()
+		if p.pos < len(p.src) && p.src[p.pos] == ',' {
+			p.pos++
+		} else if p.pos >= len(p.src) || p.src[p.pos] != ']' {
+			return nil, syntaxError(p.src, p.pos, "esperava ',' ou ']'")
+		}
+	}
+}
+
+func (p *tomlParser) inlineTable() (*Node, error) {
+	n := newNode(Object, Flow, p.pos)
+	p.pos++
+	p.skipSpaces()
+	if p.pos < len(p.src) && p.src[p.pos] == '}' {
+		p.pos++
+		n.End = p.pos
+		return n, nil
+	}
+	for {
+		p.skipSpaces()
+		if err := p.keyValue(n, newNode(Object, Flow, 0)); err != nil {
+			return nil, err
+		}
+		p.skipSpaces()
+		if p.pos >= len(p.src) {
+			return nil, syntaxError(p.src, n.Start, "tabela inline sem fechamento")
+		}
+		switch p.src[p.pos] {
+		case ',':
+			p.pos++
+		case '}':
+			p.pos++
+			n.End = p.pos
+			return n, nil
+		default:
+			return nil, syntaxError(p.src, p.pos, "esperava ',' ou '}' (tabelas inline ficam em uma linha)")
+		}
+	}
+}
+
+// basicString lê "..." com escapes.
+func (p *tomlParser) basicString() (string, error) {
+	start := p.pos
+	var b strings.Builder
+	for p.pos++; p.pos < len(p.src); p.pos++ {
+		switch c := p.src[p.pos]; c {
+		case '"':
+			p.pos++
+			return b.String(), nil
+		case '\n':
+			return "", syntaxError(p.src, start, "string sem fechamento")
+		case '\\':
+			if err := p.escape(&b); err != nil {
+				return "", err
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", syntaxError(p.src, start, "string sem fechamento")
+}
+
+// escape interpreta o escape na posição atual (a barra) e avança até seu último caractere.
+func (p *tomlParser) escape(b *strings.Builder) error {
+	start := p.pos
+	p.pos++
+	if p.pos >= len(p.src) {
+		return syntaxError(p.src, start, "escape incompleto")
+	}
+	simple := map[byte]string{'b': "\b", 't': "\t", 'n': "\n", 'f': "\f", 'r': "\r", 'e': "\x1b", '"': "\"", '\\': "\\"}
+	if s, ok := simple[p.src[p.pos]]; ok {
+		b.WriteString(s)
+		return nil
+	}
+	size := map[byte]int{'u': 4, 'U': 8}[p.src[p.pos]]
+	if size == 0 || p.pos+size >= len(p.src) {
+		return syntaxError(p.src, start, "escape inválido '\\%c'", p.src[p.pos])
+	}
+	code, err := strconv.ParseUint(p.src[p.pos+1:p.pos+1+size], 16, 32)
+	if err != nil {
+		return syntaxError(p.src, start, "escape inválido '%s'", p.src[start:p.pos+1+size])
+	}
+	b.WriteRune(rune(code))
+	p.pos += size
+	return nil
+}
+
+// literalString lê '...' sem escapes.
+func (p *tomlParser) literalString() (string, error) {
+	start := p.pos
+	end := strings.IndexAny(p.src[start+1:], "'\n")
+	if end < 0 || p.src[start+1+end] != '\'' {
+		return "", syntaxError(p.src, start, "string sem fechamento")
+	}
+	p.pos = start + 2 + end
+	return p.src[start+1 : start+1+end], nil
+}
+
+// multilineString lê """...""" ou ”'...”'. A quebra de linha logo após a abertura é
+// ignorada e, nas strings básicas, uma barra no fim da linha junta as linhas.
+func (p *tomlParser) multilineString(quote byte) (string, error) {
+	start := p.pos
+	delim := strings.Repeat(string(quote), 3)
+	p.pos += 3
+	if strings.HasPrefix(p.src[p.pos:], "\r\n") {
+		p.pos += 2
+	} else if strings.HasPrefix(p.src[p.pos:], "\n") {
+		p.pos++
+	}
+	var b strings.Builder
+	for p.pos < len(p.src) {
+		if strings.HasPrefix(p.src[p.pos:], delim) {
+			// Até duas aspas extras antes do fechamento fazem parte do conteúdo.
+			extra := 0
+			for extra < 2 && p.pos+3+extra < len(p.src) && p.src[p.pos+3+extra] == quote {
+				extra++
+			}
+			b.WriteString(p.src[p.pos : p.pos+extra])
+			p.pos += 3 + extra
+			return b.String(), nil
+		}
+		c := p.src[p.pos]
+		if c == '\\' && quote == '"' {
+			rest := strings.TrimLeft(p.src[p.pos+1:], " \t\r")
+			if strings.HasPrefix(rest, "\n") {
+				p.pos = len(p.src) - len(strings.TrimLeft(rest, " \t\r\n"))
+				continue
+			}
+			if err := p.escape(&b); err != nil {
+				return "", err
+			}
+			p.pos++
+			continue
+		}
+		b.WriteByte(c)
+		p.pos++
+	}
+	return "", syntaxError(p.src, start, "string sem fechamento")
+}
+
+// tomlValue escreve um valor em TOML; objetos viram tabelas inline.
+func tomlValue(v any) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "", fmt.Errorf("TOML não tem valor nulo")
+	case float64:
+		switch {
+		case math.IsInf(v, 1):
+			return "inf", nil
+		case math.IsInf(v, -1):
+			return "-inf", nil
+		case math.IsNaN(v):
+			return "nan", nil
+		}
+		return formatFloat(v), nil
+	case []any:
+		items := make([]string, len(v))
+		for i, item := range v {
+			s, err := tomlValue(item)
+			if err != nil {
+				return "", err
+			}
+			items[i] = s
+		}
+		return "[" + strings.Join(items, ", ") + "]", nil
+	case *Map:
+		items := make([]string, 0, v.Len())
+		for _, k := range v.Keys() {
+			s, err := tomlValue(v.values[k])
+			if err != nil {
+				return "", err
+			}
+			items = append(items, tomlKey(k)+" = "+s)
+		}
+		if len(items) == 0 {
+			return "{}", nil
+		}
+		return "{ " + strings.Join(items, ", ") + " }", nil
+	}
+	return EncodeJSON(v), nil
+}
+
+// tomlKey escreve uma chave, entre aspas quando não for uma chave simples.
+func tomlKey(k string) string {
+	if tomlBareKey.MatchString(k) {
+		return k
+	}
+	return EncodeJSON(k)
+}
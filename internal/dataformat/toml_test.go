@@ -0,0 +1,95 @@
+package dataformat
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestParseTOML testa a leitura de TOML comparando o valor em JSON.
+func TestParseTOML(t *testing.T) {
+	testCases := []struct {
+		name          string
+		src           string
+		expected      string
+		expectedError string
+	}{
+		{
+			name:     "Pares e tabelas",
+			src:      "title = \"app\" # nome\n\n[server]\nport = 8080\nhosts = [\"a\", 'b']\n\n[server.tls]\nenabled = true\n",
+			expected: `{"title":"app","server":{"port":8080,"hosts":["a","b"],"tls":{"enabled":true}}}`,
+		},
+		{
+			name:     "Chaves com ponto e entre aspas",
+			src:      "a.b.c = 1\na.b.d = 2\n\"x y\".z = 'lit'\n",
+			expected: `{"a":{"b":{"c":1,"d":2}},"x y":{"z":"lit"}}`,
+		},
+		{
+			name:     "Array de tabelas",
+			src:      "[[bin]]\nname = \"a\"\n\n[[bin]]\nname = \"b\"\n[bin.opts]\nx = 1\n",
+			expected: `{"bin":[{"name":"a"},{"name":"b","opts":{"x":1}}]}`,
+		},
+		{
+			name:     "Tabela implícita definida depois",
+			src:      "[a.b]\nx = 1\n[a]\ny = 2\n",
+			expected: `{"a":{"b":{"x":1},"y":2}}`,
+		},
+		{
+			name:     "Números",
+			src:      "a = 1_000\nb = 0xff\nc = 0o17\nd = 0b101\ne = -3.5e2\nf = +7\n",
+			expected: `{"a":1000,"b":255,"c":15,"d":5,"e":-350,"f":7}`,
+		},
+		{
+			name:     "Datas",
+			src:      "a = 1979-05-27T07:32:00Z\nb = 1979-05-27 07:32:00\nc = 1979-05-27\nd = 07:32:00\n",
+			expected: `{"a":"1979-05-27T07:32:00Z","b":"1979-05-27 07:32:00","c":"1979-05-27","d":"07:32:00"}`,
+		},
+		{
+			name:     "Strings de várias linhas",
+			src:      "a = \"\"\"\nlinha 1\nlinha 2\"\"\"\nb = \"\"\"junta \\\n    tudo\"\"\"\nc = '''\nsem \\escape'''\nd = \"tab\\tu\\u00e9\"\n",
+			expected: `{"a":"linha 1\nlinha 2","b":"junta tudo","c":"sem \\escape","d":"tab\tué"}`,
+		},
+		{
+			name:     "Array em várias linhas e tabela inline",
+			src:      "deps = [\n  \"a\", # comentário\n  \"b\",\n]\npoint = { x = 1, y.z = 2 }\n",
+			expected: `{"deps":["a","b"],"point":{"x":1,"y":{"z":2}}}`,
+		},
+		{
+			name:          "Chave duplicada",
+			src:           "a = 1\na = 2\n",
+			expectedError: "linha 2, coluna 1: a chave 'a' já foi definida",
+		},
+		{
+			name:          "Tabela duplicada",
+			src:           "[a]\nx = 1\n[a]\ny = 2\n",
+			expectedError: "a tabela 'a' já foi definida",
+		},
+		{
+			name:          "Valor inválido",
+			src:           "a = nada\n",
+			expectedError: "valor inválido 'nada'",
+		},
+		{
+			name:          "Conteúdo depois do valor",
+			src:           "a = 1 2\n",
+			expectedError: "esperava o fim da linha",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			doc, err := ParseTOML(tc.src)
+			if tc.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedError) {
+					t.Fatalf("erro = %v, esperado conter %q", err, tc.expectedError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+			if got := EncodeJSON(doc.Value()); got != tc.expected {
+				t.Errorf("valor =\n%s\nesperado\n%s", got, tc.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,991 @@
+package dataformat
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// yamlParser lê o subconjunto de YAML usado em arquivos de configuração: mapas e listas
+// em bloco e em fluxo, escalares simples, entre aspas e em bloco (| e >), vários
+// documentos, âncoras e aliases. Chaves complexas (?) não são suportadas.
+type yamlParser struct {
+	src     string
+	pos     int
+	anchors map[string]*Node
+}
+
+// maxYAMLNodes limita o tamanho de um documento com os aliases expandidos, para que
+// aliases aninhados ("billion laughs") não travem quem percorre o valor.
+const maxYAMLNodes = 1_000_000
+
+var (
+	yamlIntRegex   = regexp.MustCompile(`^[-+]?[0-9]+$`)
+	yamlFloatRegex = regexp.MustCompile(`^[-+]?(\.[0-9]+|[0-9]+(\.[0-9]*)?)([eE][-+]?[0-9]+)?$`)
+)
+
+// ParseYAML lê todos os documentos do texto. Um texto vazio tem um documento nulo.
+func ParseYAML(src string) ([]*Node, error) {
+	p := &yamlParser{src: src}
+	if strings.HasPrefix(src, "\ufeff") {
+		p.pos = len("\ufeff")
+	}
+	var docs []*Node
+	for {
+		p.anchors = make(map[string]*Node)
+		p.skipBlank()
+		for p.pos < len(p.src) && p.src[p.pos] == '%' && p.column() == 0 {
+			p.skipLine()
+			p.skipBlank()
+		}
+		explicit := p.docMarker("---")
+		if explicit {
+			p.pos += 3
+			p.skipInline()
+		}
+		if p.pos >= len(p.src) || p.docMarker("---") || p.docMarker("...") {
+			if explicit || len(docs) == 0 {
+				docs = append(docs, newNode(Null, Plain, p.pos))
+			}
+		} else {
+			var doc *Node
+			var err error
+			if p.atLineEnd() {
+				doc, err = p.nextLines(-1, -1, true)
+			} else {
+				doc, err = p.block(-1)
+			}
+			if err != nil {
+				return nil, err
+			}
+			if expandedSize(doc, make(map[*Node]int)) > maxYAMLNodes {
+				return nil, syntaxError(p.src, doc.Start, "o documento passa de %d valores com os aliases expandidos", maxYAMLNodes)
+			}
+			docs = append(docs, doc)
+			p.skipBlank()
+		}
+		if p.docMarker("...") {
+			p.pos += 3
+			p.skipBlank()
+		}
+		if p.pos >= len(p.src) {
+			return docs, nil
+		}
+		if !p.docMarker("---") {
+			return nil, syntaxError(p.src, p.pos, "conteúdo inesperado; esperava '---' ou o fim do documento")
+		}
+	}
+}
+
+// column retorna a coluna (a partir de 0) da posição atual.
+func (p *yamlParser) column() int {
+	return p.pos - (strings.LastIndexByte(p.src[:p.pos], '\n') + 1)
+}
+
+// docMarker informa se a posição atual é um marcador de documento no início da linha.
+func (p *yamlParser) docMarker(marker string) bool {
+	if p.column() != 0 || !strings.HasPrefix(p.src[p.pos:], marker) {
+		return false
+	}
+	rest := p.src[p.pos+3:]
+	return rest == "" || strings.IndexByte(" \t\r\n", rest[0]) >= 0
+}
+
+// skipBlank pula espaços, quebras de linha e comentários.
+func (p *yamlParser) skipBlank() {
+	for p.pos < len(p.src) {
+		switch p.src[p.pos] {
+		case ' ', '\t', '\r', '\n':
+			p.pos++
+		case '#':
+			p.skipLine()
+		default:
+			return
+		}
+	}
+}
+
+// skipInline pula espaços e um comentário até o fim da linha, sem passar dela.
+func (p *yamlParser) skipInline() {
+	for p.pos < len(p.src) && (p.src[p.pos] == ' ' || p.src[p.pos] == '\t') {
+		p.pos++
+	}
+	if p.pos < len(p.src) && p.src[p.pos] == '#' {
+		p.skipLine()
+	}
+}
+
+func (p *yamlParser) skipLine() {
+	if i := strings.IndexByte(p.src[p.pos:], '\n'); i >= 0 {
+		p.pos += i
+	} else {
+		p.pos = len(p.src)
+	}
+}
+
+func (p *yamlParser) atLineEnd() bool {
+	return p.pos >= len(p.src) || p.src[p.pos] == '\n' || p.src[p.pos] == '\r'
+}
+
+// seqIndicator informa se a posição atual é um "- " que inicia um item de lista.
+func (p *yamlParser) seqIndicator() bool {
+	if p.pos >= len(p.src) || p.src[p.pos] != '-' {
+		return false
+	}
+	return p.pos+1 == len(p.src) || strings.IndexByte(" \t\r\n", p.src[p.pos+1]) >= 0
+}
+
+// mappingKey lê uma chave de mapa em bloco ("chave:" seguido de espaço ou fim de linha)
+// sem consumi-la. Retorna a chave, a posição do ":" e se a linha é mesmo uma entrada.
+func (p *yamlParser) mappingKey() (key string, colon int, ok bool, err error) {
+	i := p.pos
+	if i >= len(p.src) {
+		return "", 0, false, nil
+	}
+	switch c := p.src[i]; {
+	case c == '"' || c == '\'':
+		save := p.pos
+		if c == '"' {
+			key, err = p.doubleQuoted()
+		} else {
+			key, err = p.singleQuoted()
+		}
+		end := p.pos
+		p.pos = save
+		if err != nil {
+			return "", 0, false, err
+		}
+		for end < len(p.src) && (p.src[end] == ' ' || p.src[end] == '\t') {
+			end++
+		}
+		if end < len(p.src) && p.src[end] == ':' && (end+1 == len(p.src) || strings.IndexByte(" \t\r\n", p.src[end+1]) >= 0) {
+			return key, end, true, nil
+		}
+		return "", 0, false, nil
+	case strings.IndexByte("[]{},#&*!|>%@`", c) >= 0:
+		return "", 0, false, nil
+	case c == '?' && (i+1 == len(p.src) || p.src[i+1] == ' '):
+		return "", 0, false, syntaxError(p.src, i, "chaves complexas ('?') não são suportadas")
+	}
+	for ; i < len(p.src) && p.src[i] != '\n'; i++ {
+		if p.src[i] == '#' && i > p.pos && (p.src[i-1] == ' ' || p.src[i-1] == '\t') {
+			break
+		}
+		if p.src[i] == ':' && (i+1 == len(p.src) || strings.IndexByte(" \t\r\n", p.src[i+1]) >= 0) {
+			return strings.TrimRight(p.src[p.pos:i], " \t"), i, true, nil
+		}
+	}
+	return "", 0, false, nil
+}
+
+// properties lê âncora (&nome) e tag (!tag) antes de um valor.
+func (p *yamlParser) properties() (anchor, tag string) {
+	for p.pos < len(p.src) && (p.src[p.pos] == '&' || p.src[p.pos] == '!') {
+		start := p.pos
+		for p.pos < len(p.src) && strings.IndexByte(" \t\r\n,[]{}", p.src[p.pos]) < 0 {
+			p.pos++
+		}
+		if p.src[start] == '&' {
+			anchor = p.src[start+1 : p.pos]
+			p.anchors[anchor] = nil // Em definição até withProperties registrar o nó.
+		} else {
+			tag = p.src[start:p.pos]
+		}
+		for p.pos < len(p.src) && (p.src[p.pos] == ' ' || p.src[p.pos] == '\t') {
+			p.pos++
+		}
+	}
+	if p.pos < len(p.src) && p.src[p.pos] == '#' {
+		p.skipLine()
+	}
+	return anchor, tag
+}
+
+// alias retorna uma cópia do nó da âncora cujo alias (*nome) vai de start até a posição
+// atual. A cópia compartilha os filhos com a âncora.
+func (p *yamlParser) alias(start int) (*Node, error) {
+	name := p.src[start+1 : p.pos]
+	target, ok := p.anchors[name]
+	if !ok {
+		return nil, syntaxError(p.src, start, "alias '*%s' sem âncora correspondente", name)
+	}
+	if target == nil {
+		return nil, syntaxError(p.src, start, "alias '*%s' aponta para a própria âncora, que ainda está sendo definida", name)
+	}
+	alias := *target
+	alias.Start, alias.End, alias.Alias = start, p.pos, true
+	return &alias, nil
+}
+
+// expandedSize conta os nós de n com os aliases expandidos, como Value os percorre. A
+// contagem para ao passar de maxYAMLNodes; memo evita percorrer de novo os filhos
+// compartilhados entre uma âncora e seus aliases.
+func expandedSize(n *Node, memo map[*Node]int) int {
+	if size, ok := memo[n]; ok {
+		return size
+	}
+	size := 1
+	for _, c := range n.Children {
+		if size += expandedSize(c, memo); size > maxYAMLNodes {
+			break
+		}
+	}
+	memo[n] = size
+	return size
+}
+
+// withProperties registra a âncora e aplica a tag ao nó lido.
+func (p *yamlParser) withProperties(n *Node, anchor, tag string) *Node {
+	if tag == "!!str" && n.Kind != String && n.Kind != Array && n.Kind != Object {
+		n.Kind, n.Scalar = String, p.src[n.Start:n.End]
+	}
+	if anchor != "" {
+		p.anchors[anchor] = n
+	}
+	return n
+}
+
+// nextLines lê um valor que começa nas linhas seguintes, mais indentado que parentCol.
+// Uma lista na mesma coluna da chave também é aceita como valor. Sem valor, retorna
+// um nulo vazio na posição lead.
+func (p *yamlParser) nextLines(parentCol, lead int, sameColSeq bool) (*Node, error) {
+	save := p.pos
+	p.skipBlank()
+	if p.pos < len(p.src) && !p.docMarker("---") && !p.docMarker("...") {
+		col := p.column()
+		if col > parentCol || (sameColSeq && col == parentCol && p.seqIndicator()) {
+			return p.block(parentCol)
+		}
+	}
+	p.pos = save
+	if lead < 0 {
+		lead = save
+	}
+	n := newNode(Null, Plain, lead)
+	return n, nil
+}
+
+// block lê um valor em contexto de bloco: lista, mapa ou escalar.
+func (p *yamlParser) block(parentCol int) (*Node, error) {
+	anchor, tag := p.properties()
+	if anchor != "" || tag != "" {
+		if p.atLineEnd() {
+			n, err := p.nextLines(parentCol, -1, true)
+			if err != nil {
+				return nil, err
+			}
+			return p.withProperties(n, anchor, tag), nil
+		}
+	}
+	col := p.column()
+	var n *Node
+	var err error
+	if p.seqIndicator() {
+		n, err = p.blockSeq(col)
+	} else if _, _, isKey, kerr := p.mappingKey(); kerr != nil {
+		return nil, kerr
+	} else if isKey {
+		n, err = p.blockMap(col)
+	} else {
+		n, err = p.scalar(parentCol)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return p.withProperties(n, anchor, tag), nil
+}
+
+// inline lê o valor escrito na mesma linha de "chave:".
+func (p *yamlParser) inline(parentCol, lead int) (*Node, error) {
+	anchor, tag := p.properties()
+	var n *Node
+	var err error
+	if p.atLineEnd() {
+		n, err = p.nextLines(parentCol, lead, true)
+	} else {
+		n, err = p.scalar(parentCol)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return p.withProperties(n, anchor, tag), nil
+}
+
+func (p *yamlParser) blockMap(col int) (*Node, error) {
+	n := newNode(Object, Block, p.pos)
+	n.Indent = col
+	for {
+		keyStart := p.pos
+		key, colon, ok, err := p.mappingKey()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, syntaxError(p.src, p.pos, "esperava 'chave: valor'")
+		}
+		if _, dup := n.Get(key); dup {
+			return nil, syntaxError(p.src, keyStart, "chave duplicada '%s'", key)
+		}
+		p.pos = colon + 1
+		lead := p.pos
+		p.skipInline()
+		child, err := p.inline(col, lead)
+		if err != nil {
+			return nil, err
+		}
+		child.Lead, child.KeyStart = lead, keyStart
+		if child.Kind == Null && child.Start == child.End {
+			child.Start, child.End = lead, lead
+		}
+		n.set(key, child)
+		n.End = max(child.End, lead)
+
+		if more, err := p.nextEntry(col, false); !more || err != nil {
+			return n, err
+		}
+	}
+}
+
+func (p *yamlParser) blockSeq(col int) (*Node, error) {
+	n := newNode(Array, Block, p.pos)
+	n.Indent = col
+	for {
+		p.pos++ // "-"
+		lead := p.pos
+		p.skipInline()
+		var child *Node
+		var err error
+		if p.atLineEnd() {
+			child, err = p.nextLines(col, lead, false)
+		} else {
+			child, err = p.block(col)
+		}
+		if err != nil {
+			return nil, err
+		}
+		child.Lead = lead
+		if child.Kind == Null && child.Start == child.End {
+			child.Start, child.End = lead, lead
+		}
+		n.Children = append(n.Children, child)
+		n.End = max(child.End, lead)
+
+		if more, err := p.nextEntry(col, true); !more || err != nil {
+			return n, err
+		}
+	}
+}
+
+// nextEntry avança até a próxima entrada da coleção na coluna col. Retorna false, sem
+// sair da posição atual, quando a coleção terminou.
+func (p *yamlParser) nextEntry(col int, seq bool) (bool, error) {
+	save := p.pos
+	p.skipBlank()
+	if p.pos >= len(p.src) || p.docMarker("---") || p.docMarker("...") || p.column() < col {
+		p.pos = save
+		return false, nil
+	}
+	if p.column() > col {
+		return false, syntaxError(p.src, p.pos, "indentação inesperada")
+	}
+	if p.seqIndicator() != seq {
+		p.pos = save
+		return false, nil
+	}
+	return true, nil
+}
+
+// scalar lê um escalar, uma coleção em fluxo, um alias ou um escalar em bloco.
+func (p *yamlParser) scalar(parentCol int) (*Node, error) {
+	start := p.pos
+	switch p.src[p.pos] {
+	case '*':
+		for p.pos++; p.pos < len(p.src) && strings.IndexByte(" \t\r\n,[]{}", p.src[p.pos]) < 0; p.pos++ {
+		}
+		alias, err := p.alias(start)
+		if err != nil {
+			return nil, err
+		}
+		p.skipInline()
+		return alias, nil
+	case '[', '{':
+		n, err := p.flow()
+		if err != nil {
+			return nil, err
+		}
+		p.skipInline()
+		if !p.atLineEnd() {
+			return nil, syntaxError(p.src, p.pos, "conteúdo inesperado após a coleção")
+		}
+		return n, nil
+	case '|', '>':
+		return p.blockScalar(parentCol)
+	case '"', '\'':
+		var s string
+		var err error
+		if p.src[p.pos] == '"' {
+			s, err = p.doubleQuoted()
+		} else {
+			s, err = p.singleQuoted()
+		}
+		if err != nil {
+			return nil, err
+		}
+		n := newNode(String, Plain, start)
+		n.Scalar, n.End = s, p.pos
+		p.skipInline()
+		if !p.atLineEnd() {
+			return nil, syntaxError(p.src, p.pos, "conteúdo inesperado após a string")
+		}
+		return n, nil
+	}
+
+	// Escalar simples, que pode continuar nas linhas seguintes mais indentadas.
+	var b strings.Builder
+	end := p.pos
+	sep := ""
+	for {
+		lineEnd := p.pos
+		for lineEnd < len(p.src) && p.src[lineEnd] != '\n' {
+			if p.src[lineEnd] == '#' && lineEnd > p.pos && (p.src[lineEnd-1] == ' ' || p.src[lineEnd-1] == '\t') {
+				break
+			}
+			lineEnd++
+		}
+		text := strings.TrimRight(p.src[p.pos:lineEnd], " \t\r")
+		b.WriteString(sep + text)
+		end = p.pos + len(text)
+		p.pos = lineEnd
+		p.skipLine()
+
+		// Continuação: próxima linha não vazia, mais indentada e sem ser comentário.
+		// Linhas em branco no meio viram quebras de linha.
+		blank := 0
+		for p.pos < len(p.src) && p.src[p.pos] == '\n' {
+			p.pos++
+			for p.pos < len(p.src) && strings.IndexByte(" \t\r", p.src[p.pos]) >= 0 {
+				p.pos++
+			}
+			if p.pos < len(p.src) && p.src[p.pos] == '\n' {
+				blank++
+			}
+		}
+		stop := p.pos >= len(p.src) || p.column() <= parentCol || p.src[p.pos] == '#' ||
+			p.docMarker("---") || p.docMarker("...") || p.seqIndicator()
+		if !stop {
+			_, _, isKey, _ := p.mappingKey()
+			stop = isKey
+		}
+		if stop {
+			p.pos = end
+			break
+		}
+		sep = " "
+		if blank > 0 {
+			sep = strings.Repeat("\n", blank)
+		}
+	}
+	n := newNode(String, Plain, start)
+	n.End = end
+	n.Kind, n.Scalar = resolvePlain(b.String())
+	return n, nil
+}
+
+// resolvePlain converte um escalar simples no tipo correspondente (esquema core do YAML 1.2).
+func resolvePlain(s string) (Kind, any) {
+	switch s {
+	case "", "~", "null", "Null", "NULL":
+		return Null, nil
+	case "true", "True", "TRUE":
+		return Bool, true
+	case "false", "False", "FALSE":
+		return Bool, false
+	case ".inf", ".Inf", ".INF", "+.inf", "+.Inf", "+.INF":
+		return Float, math.Inf(1)
+	case "-.inf", "-.Inf", "-.INF":
+		return Float, math.Inf(-1)
+	case ".nan", ".NaN", ".NAN":
+		return Float, math.NaN()
+	}
+	switch {
+	case yamlIntRegex.MatchString(s):
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return Int, i
+		}
+		f, _ := strconv.ParseFloat(s, 64)
+		return Float, f
+	case strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0o"):
+		base := 16
+		if s[1] == 'o' {
+			base = 8
+		}
+		if i, err := strconv.ParseInt(s[2:], base, 64); err == nil {
+			return Int, i
+		}
+	case yamlFloatRegex.MatchString(s):
+		f, _ := strconv.ParseFloat(s, 64)
+		return Float, f
+	}
+	return String, s
+}
+
+// flow lê uma coleção em fluxo ([...] ou {...}), que pode ocupar várias linhas.
+func (p *yamlParser) flow() (*Node, error) {
+	start := p.pos
+	open := p.src[p.pos]
+	closing := byte(']')
+	n := newNode(Array, Flow, start)
+	if open == '{' {
+		closing = '}'
+		n.Kind = Object
+	}
+	p.pos++
+	for {
+		p.skipBlank()
+		if p.pos >= len(p.src) {
+			return nil, syntaxError(p.src, start, "coleção sem fechamento '%c'", closing)
+		}
+		if p.src[p.pos] == closing {
+			p.pos++
+			n.End = p.pos
+			return n, nil
+		}
+
+		if n.Kind == Object {
+			keyStart := p.pos
+			keyNode, err := p.flowScalar(true)
+			if err != nil {
+				return nil, err
+			}
+			key, _ := keyNode.Scalar.(string)
+			if keyNode.Kind != String {
+				key = p.src[keyNode.Start:keyNode.End]
+			}
+			if _, dup := n.Get(key); dup {
+				return nil, syntaxError(p.src, keyStart, "chave duplicada '%s'", key)
+			}
+			p.skipBlank()
+			var child *Node
+			if p.pos < len(p.src) && p.src[p.pos] == ':' {
+				p.pos++
+				p.skipBlank()
+				if child, err = p.flowNode(); err != nil {
+					return nil, err
+				}
+			} else {
+				child = newNode(Null, Plain, p.pos)
+			}
+			child.KeyStart = keyStart
+			n.set(key, child)
+		} else {
+			child, err := p.flowNode()
+			if err != nil {
+				return nil, err
+			}
+			n.Children = append(n.Children, child)
+		}
+
+		p.skipBlank()
+		if p.pos >= len(p.src) {
+			return nil, syntaxError(p.src, start, "coleção sem fechamento '%c'", closing)
+		}
+		if p.src[p.pos] == ',' {
+			p.pos++
+		} else if p.src[p.pos] != closing {
+			return nil, syntaxError(p.src, p.pos, "esperava ',' ou '%c'", closing)
+		}
+	}
+}
+
+// flowNode lê um valor dentro de uma coleção em fluxo.
+func (p *yamlParser) flowNode() (*Node, error) {
+	anchor, tag := p.properties()
+	p.skipBlank()
+	var n *Node
+	var err error
+	switch {
+	case p.pos >= len(p.src):
+		return nil, syntaxError(p.src, p.pos, "fim inesperado dentro da coleção")
+	case p.src[p.pos] == '[' || p.src[p.pos] == '{':
+		n, err = p.flow()
+	case p.src[p.pos] == '*':
+		start := p.pos
+		for p.pos++; p.pos < len(p.src) && strings.IndexByte(" \t\r\n,[]{}", p.src[p.pos]) < 0; p.pos++ {
+		}
+		n, err = p.alias(start)
+	default:
+		n, err = p.flowScalar(false)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return p.withProperties(n, anchor, tag), nil
+}
+
+// flowScalar lê um escalar dentro de uma coleção em fluxo; em chaves, para no ":".
+func (p *yamlParser) flowScalar(key bool) (*Node, error) {
+	start := p.pos
+	if p.src[p.pos] == '"' || p.src[p.pos] == '\'' {
+		var s string
+		var err error
+		if p.src[p.pos] == '"' {
+			s, err = p.doubleQuoted()
+		} else {
+			s, err = p.singleQuoted()
+		}
+		if err != nil {
+			return nil, err
+		}
+		n := newNode(String, Plain, start)
+		n.Scalar, n.End = s, p.pos
+		return n, nil
+	}
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		if strings.IndexByte(",[]{}\r\n", c) >= 0 || c == '#' && p.pos > start && p.src[p.pos-1] == ' ' {
+			break
+		}
+		if c == ':' && (p.pos+1 == len(p.src) || strings.IndexByte(" \t\r\n,[]{}", p.src[p.pos+1]) >= 0 || key) {
+			break
+		}
+		p.pos++
+	}
+	text := strings.TrimRight(p.src[start:p.pos], " \t")
+	n := newNode(String, Plain, start)
+	n.End = start + len(text)
+	n.Kind, n.Scalar = resolvePlain(text)
+	return n, nil
+}
+
+// doubleQuoted lê uma string entre aspas duplas, com escapes e quebras de linha dobradas.
+func (p *yamlParser) doubleQuoted() (string, error) {
+	start := p.pos
+	var b strings.Builder
+	for p.pos++; p.pos < len(p.src); p.pos++ {
+		c := p.src[p.pos]
+		switch c {
+		case '"':
+			p.pos++
+			return b.String(), nil
+		case '\n', '\r':
+			p.foldLine(&b)
+			p.pos--
+		case '\\':
+			p.pos++
+			if p.pos >= len(p.src) {
+				break
+			}
+			e := p.src[p.pos]
+			if e == '\n' || e == '\r' {
+				// Quebra escapada: junta as linhas sem espaço.
+				for p.pos+1 < len(p.src) && strings.IndexByte(" \t\r\n", p.src[p.pos+1]) >= 0 {
+					p.pos++
+				}
+				continue
+			}
+			if r, ok := yamlEscapes[e]; ok {
+				b.WriteString(r)
+				continue
+			}
+			size := map[byte]int{'x': 2, 'u': 4, 'U': 8}[e]
+			if size == 0 || p.pos+size >= len(p.src) {
+				return "", syntaxError(p.src, p.pos-1, "escape inválido '\\%c'", e)
+			}
+			code, err := strconv.ParseUint(p.src[p.pos+1:p.pos+1+size], 16, 32)
+			if err != nil {
+				return "", syntaxError(p.src, p.pos-1, "escape inválido '\\%c'", e)
+			}
+			b.WriteRune(rune(code))
+			p.pos += size
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", syntaxError(p.src, start, "string sem fechamento")
+}
+
+var yamlEscapes = map[byte]string{
+	'0': "\x00", 'a': "\a", 'b': "\b", 't': "\t", '\t': "\t", 'n': "\n", 'v': "\v", 'f': "\f",
+	'r': "\r", 'e': "\x1b", ' ': " ", '"': "\"", '/': "/", '\\': "\\", 'N': "\u0085",
+	'_': " ", 'L': " ", 'P': " ",
+}
+
+// singleQuoted lê uma string entre aspas simples, onde ” representa uma aspa.
+func (p *yamlParser) singleQuoted() (string, error) {
+	start := p.pos
+	var b strings.Builder
+	for p.pos++; p.pos < len(p.src); p.pos++ {
+		switch c := p.src[p.pos]; c {
+		case '\'':
+			if p.pos+1 < len(p.src) && p.src[p.pos+1] == '\'' {
+				b.WriteByte('\'')
+				p.pos++
+				continue
+			}
+			p.pos++
+			return b.String(), nil
+		case '\n', '\r':
+			p.foldLine(&b)
+			p.pos--
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", syntaxError(p.src, start, "string sem fechamento")
+}
+
+// foldLine trata uma quebra de linha dentro de aspas: vira espaço, ou "\n" para cada
+// linha em branco. Deixa a posição no primeiro caractere da próxima linha com conteúdo.
+func (p *yamlParser) foldLine(b *strings.Builder) {
+	s := strings.TrimRight(b.String(), " \t")
+	b.Reset()
+	b.WriteString(s)
+	breaks := 0
+	for p.pos < len(p.src) && strings.IndexByte(" \t\r\n", p.src[p.pos]) >= 0 {
+		if p.src[p.pos] == '\n' {
+			breaks++
+		}
+		p.pos++
+	}
+	if breaks <= 1 {
+		b.WriteByte(' ')
+	}
+	for ; breaks > 1; breaks-- {
+		b.WriteByte('\n')
+	}
+}
+
+// blockScalar lê um escalar literal (|) ou dobrado (>), com indicadores de chomping
+// (+ e -) e de indentação.
+func (p *yamlParser) blockScalar(parentCol int) (*Node, error) {
+	start := p.pos
+	folded := p.src[p.pos] == '>'
+	chomp, explicit := byte(0), 0
+	for p.pos++; p.pos < len(p.src); p.pos++ {
+		c := p.src[p.pos]
+		if c == '+' || c == '-' {
+			chomp = c
+		} else if c >= '1' && c <= '9' {
+			explicit = int(c - '0')
+		} else {
+			break
+		}
+	}
+	p.skipInline()
+	if !p.atLineEnd() {
+		return nil, syntaxError(p.src, p.pos, "conteúdo inesperado após o indicador de bloco")
+	}
+
+	indent := -1
+	if explicit > 0 {
+		indent = max(parentCol, 0) + explicit
+	}
+	var lines []string
+	end := p.pos
+	for p.pos < len(p.src) {
+		lineStart := p.pos + 1
+		lineEnd := strings.IndexByte(p.src[lineStart:], '\n')
+		if lineEnd < 0 {
+			lineEnd = len(p.src)
+		} else {
+			lineEnd += lineStart
+		}
+		line := strings.TrimRight(p.src[lineStart:lineEnd], "\r")
+		spaces := len(line) - len(strings.TrimLeft(line, " "))
+		if strings.TrimSpace(line) == "" {
+			lines = append(lines, "")
+			p.pos = lineEnd
+			continue
+		}
+		if indent < 0 {
+			indent = spaces
+			if indent <= parentCol {
+				break
+			}
+		}
+		if spaces < indent {
+			break
+		}
+		lines = append(lines, line[indent:])
+		p.pos = lineEnd
+		end = lineEnd
+	}
+	// Linhas em branco depois do conteúdo só contam com chomping "+".
+	trailing := 0
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+		trailing++
+	}
+	p.pos = end
+
+	var text string
+	if folded {
+		text = foldLines(lines)
+	} else {
+		text = strings.Join(lines, "\n")
+	}
+	switch {
+	case len(lines) == 0 || chomp == '-':
+	case chomp == '+':
+		text += strings.Repeat("\n", trailing+1)
+	default:
+		text += "\n"
+	}
+	n := newNode(String, Plain, start)
+	n.Scalar, n.End = text, end
+	return n, nil
+}
+
+// foldLines junta as linhas de um escalar dobrado: linhas seguidas viram uma só, linhas
+// em branco viram quebras e linhas mais indentadas são mantidas como estão.
+func foldLines(lines []string) string {
+	var b strings.Builder
+	for i, line := range lines {
+		if i > 0 {
+			prev := lines[i-1]
+			switch {
+			case line == "":
+				b.WriteByte('\n')
+			case prev == "":
+				// A quebra já foi escrita pelas linhas em branco.
+			case strings.HasPrefix(line, " ") || strings.HasPrefix(prev, " "):
+				b.WriteByte('\n')
+			default:
+				b.WriteByte(' ')
+			}
+		}
+		b.WriteString(line)
+	}
+	return b.String()
+}
+
+// yamlScalar escreve um escalar em YAML: simples quando não há ambiguidade, entre aspas
+// duplas caso contrário.
+func yamlScalar(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		if yamlPlainSafe(v) {
+			return v
+		}
+		return EncodeJSON(v)
+	case float64:
+		switch {
+		case math.IsInf(v, 1):
+			return ".inf"
+		case math.IsInf(v, -1):
+			return "-.inf"
+		case math.IsNaN(v):
+			return ".nan"
+		}
+		return formatFloat(v)
+	}
+	return EncodeJSON(v)
+}
+
+// yamlPlainSafe informa se a string pode ser escrita sem aspas sem mudar de tipo ou sentido.
+func yamlPlainSafe(s string) bool {
+	if s == "" || strings.TrimSpace(s) != s || !utf8.ValidString(s) {
+		return false
+	}
+	if kind, _ := resolvePlain(s); kind != String {
+		return false
+	}
+	switch strings.ToLower(s) {
+	case "yes", "no", "on", "off", "y", "n":
+		return false // Booleanos do YAML 1.1, ainda interpretados por muitos parsers
+	}
+	if strings.IndexByte("-?:,[]{}#&*!|>'\"%@`", s[0]) >= 0 {
+		return false
+	}
+	if strings.Contains(s, ": ") || strings.Contains(s, " #") || strings.HasSuffix(s, ":") {
+		return false
+	}
+	for _, r := range s {
+		if r < ' ' || r == 0x7f {
+			return false
+		}
+	}
+	return true
+}
+
+// formatFloat escreve um float sempre com parte decimal ou expoente, para continuar float.
+func formatFloat(f float64) string {
+	s := strconv.FormatFloat(f, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".eEn") {
+		s += ".0"
+	}
+	return s
+}
+
+// yamlFlow escreve um valor em estilo de fluxo, em uma linha.
+func yamlFlow(v any) string {
+	switch v := v.(type) {
+	case []any:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = yamlFlowItem(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case *Map:
+		items := make([]string, 0, v.Len())
+		for _, k := range v.Keys() {
+			items = append(items, yamlFlowItem(k)+": "+yamlFlowItem(v.values[k]))
+		}
+		return "{" + strings.Join(items, ", ") + "}"
+	}
+	return yamlScalar(v)
+}
+
+// yamlFlowItem é como yamlFlow, mas aspas também strings com indicadores de fluxo.
+func yamlFlowItem(v any) string {
+	if s, ok := v.(string); ok && strings.ContainsAny(s, ",[]{}") {
+		return EncodeJSON(s)
+	}
+	return yamlFlow(v)
+}
+
+// yamlBlock escreve um valor em estilo de bloco com as entradas na coluna indent. A
+// primeira linha não é indentada, pois segue a posição onde o valor é inserido.
+func yamlBlock(v any, indent int) string {
+	pad := strings.Repeat(" ", indent)
+	var lines []string
+	switch v := v.(type) {
+	case []any:
+		if len(v) == 0 {
+			return "[]"
+		}
+		for _, item := range v {
+			lines = append(lines, "- "+yamlBlock(item, indent+2))
+		}
+	case *Map:
+		if v.Len() == 0 {
+			return "{}"
+		}
+		for _, k := range v.Keys() {
+			lines = append(lines, yamlFlowItem(k)+":"+yamlBlockValue(v.values[k], indent))
+		}
+	default:
+		return yamlScalar(v)
+	}
+	return strings.Join(lines, "\n"+pad)
+}
+
+// yamlBlockValue escreve o valor de uma chave: na mesma linha se for escalar ou coleção
+// vazia, nas linhas seguintes caso contrário.
+func yamlBlockValue(v any, indent int) string {
+	switch c := v.(type) {
+	case []any:
+		if len(c) > 0 {
+			return "\n" + strings.Repeat(" ", indent+2) + yamlBlock(c, indent+2)
+		}
+	case *Map:
+		if c.Len() > 0 {
+			return "\n" + strings.Repeat(" ", indent+2) + yamlBlock(c, indent+2)
+		}
+	}
+	return " " + yamlBlock(v, indent)
+}
@@ -0,0 +1,178 @@
+package dataformat
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// TestParseYAML testa a leitura de YAML comparando o valor em JSON.
+func TestParseYAML(t *testing.T) {
+	testCases := []struct {
+		name          string
+		src           string
+		expected      string
+		expectedError string
+	}{
+		{
+			name:     "Mapa aninhado com comentários",
+			src:      "# config\nname: app # nome\nserver:\n  port: 8080\n  debug: true\n",
+			expected: `{"name":"app","server":{"port":8080,"debug":true}}`,
+		},
+		{
+			name:     "Lista na coluna da chave e compacta",
+			src:      "items:\n- a: 1\n  b: x\n- - 1\n  - 2\n- ~\nlast: 1.5",
+			expected: `{"items":[{"a":1,"b":"x"},[1,2],null],"last":1.5}`,
+		},
+		{
+			name:     "Valores vazios",
+			src:      "a:\nb:\n  -\n  - 2\n",
+			expected: `{"a":null,"b":[null,2]}`,
+		},
+		{
+			name:     "Tipos do esquema core",
+			src:      "a: 0x1F\nb: +12\nc: \"123\"\nd: yes\ne: !!str 42\nf: 1e3\ng: 2024-01-02",
+			expected: `{"a":31,"b":12,"c":"123","d":"yes","e":"42","f":1000,"g":"2024-01-02"}`,
+		},
+		{
+			name:     "Coleções em fluxo",
+			src:      "a: [1, \"dois\", {x: y, z: [3]}]\nb: {url: http://x.com/a, 'k k': v}\nc: []",
+			expected: `{"a":[1,"dois",{"x":"y","z":[3]}],"b":{"url":"http://x.com/a","k k":"v"},"c":[]}`,
+		},
+		{
+			name:     "Strings entre aspas",
+			src:      "a: \"linha\\ttab\\u00e9\"\nb: 'it''s'\nc: \"quebra\n  dobrada\"",
+			expected: `{"a":"linha\ttabé","b":"it's","c":"quebra dobrada"}`,
+		},
+		{
+			name:     "Escalar simples em várias linhas",
+			src:      "a: uma frase\n  que continua\n\n  e outra\nb: 1",
+			expected: `{"a":"uma frase que continua\ne outra","b":1}`,
+		},
+		{
+			name:     "Escalares em bloco",
+			src:      "lit: |\n  linha 1\n    recuada\n  linha 3\n\nfold: >-\n  uma\n  frase\n\n  nova\nkeep: |+\n  x\n\nz: 1",
+			expected: `{"lit":"linha 1\n  recuada\nlinha 3\n","fold":"uma frase\nnova","keep":"x\n\n","z":1}`,
+		},
+		{
+			name:     "Âncoras, aliases e merge",
+			src:      "base: &b\n  x: 1\n  y: 2\nfilho:\n  <<: *b\n  y: 3\nlista: [&v 7, *v]",
+			expected: `{"base":{"x":1,"y":2},"filho":{"y":3,"x":1},"lista":[7,7]}`,
+		},
+		{
+			name:     "Raiz escalar",
+			src:      "--- just text\n",
+			expected: `"just text"`,
+		},
+		{
+			name:     "Documento vazio",
+			src:      "# só comentário\n",
+			expected: `null`,
+		},
+		{
+			name:          "Chave duplicada",
+			src:           "a: 1\nb: 2\na: 3",
+			expectedError: "linha 3, coluna 1: chave duplicada 'a'",
+		},
+		{
+			name:          "Indentação inválida",
+			src:           "a:\n  b: 1\n    c: 2",
+			expectedError: "linha 3, coluna 5: indentação inesperada",
+		},
+		{
+			name:          "Alias desconhecido",
+			src:           "a: *nada",
+			expectedError: "alias '*nada' sem âncora",
+		},
+		{
+			name:          "Alias para a âncora em definição",
+			src:           "a: &a [1, *a]",
+			expectedError: "ainda está sendo definida",
+		},
+		{
+			name:          "Alias para o mapa em definição",
+			src:           "a: &a\n  b: *a",
+			expectedError: "ainda está sendo definida",
+		},
+		{
+			name:          "Aliases aninhados acima do limite",
+			src:           nestedAliases(9),
+			expectedError: "com os aliases expandidos",
+		},
+		{
+			name:          "Fluxo sem fechamento",
+			src:           "a: [1, 2",
+			expectedError: "coleção sem fechamento ']'",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			docs, err := ParseYAML(tc.src)
+			if tc.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedError) {
+					t.Fatalf("erro = %v, esperado conter %q", err, tc.expectedError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+			if len(docs) != 1 {
+				t.Fatalf("esperado 1 documento, obtido %d", len(docs))
+			}
+			if got := EncodeJSON(docs[0].Value()); got != tc.expected {
+				t.Errorf("valor =\n%s\nesperado\n%s", got, tc.expected)
+			}
+		})
+	}
+}
+
+// TestParseYAMLDocuments testa arquivos com vários documentos.
+func TestParseYAMLDocuments(t *testing.T) {
+	src := "kind: A\n---\nkind: B\nitems: [1]\n...\n---\n- x\n"
+	docs, err := ParseYAML(src)
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	expected := []string{`{"kind":"A"}`, `{"kind":"B","items":[1]}`, `["x"]`}
+	if len(docs) != len(expected) {
+		t.Fatalf("esperado %d documentos, obtido %d", len(expected), len(docs))
+	}
+	for i, doc := range docs {
+		if got := EncodeJSON(doc.Value()); got != expected[i] {
+			t.Errorf("documento %d = %s, esperado %s", i, got, expected[i])
+		}
+	}
+}
+
+// TestYAMLScalar testa quando strings precisam de aspas.
+func TestYAMLScalar(t *testing.T) {
+	testCases := map[string]string{
+		"texto simples": "texto simples",
+		"123":           `"123"`,
+		"true":          `"true"`,
+		"no":            `"no"`,
+		"a: b":          `"a: b"`,
+		"- item":        `"- item"`,
+		"":              `""`,
+		"linha\nnova":   `"linha\nnova"`,
+	}
+	for input, expected := range testCases {
+		if got := yamlScalar(input); got != expected {
+			t.Errorf("yamlScalar(%q) = %s, esperado %s", input, got, expected)
+		}
+	}
+}
+
+// nestedAliases monta um documento de levels listas de dez aliases para a lista
+// anterior ("billion laughs"): pequeno no texto, 10^levels valores expandido.
+func nestedAliases(levels int) string {
+	var sb strings.Builder
+	sb.WriteString("l0: &l0 [x, x, x, x, x, x, x, x, x, x]\n")
+	for i := 1; i <= levels; i++ {
+		prev := fmt.Sprintf("*l%d", i-1)
+		fmt.Fprintf(&sb, "l%d: &l%d [%s]\n", i, i, strings.Repeat(prev+", ", 9)+prev)
+	}
+	return sb.String()
+}
@@ -0,0 +1,234 @@
+// Package diff gera e aplica diffs unificados de arquivos de texto, linha a linha.
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// contextLines é o número de linhas de contexto em volta de cada alteração.
+const contextLines = 3
+
+// noNewline é o marcador de arquivo que não termina com quebra de linha.
+const noNewline = "\\ No newline at end of file\n"
+
+// SplitLines divide o texto em linhas, cada uma com seu "\n" (a última pode não ter).
+func SplitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// opKind é o tipo de uma operação de edição.
+type opKind byte
+
+const (
+	opEqual  opKind = ' '
+	opDelete opKind = '-'
+	opInsert opKind = '+'
+)
+
+// op é uma linha do diff, com sua posição no texto antigo (a) e no novo (b).
+type op struct {
+	kind opKind
+	text string
+	a, b int
+}
+
+// Unified retorna o diff unificado entre oldText e newText, ou "" se forem iguais.
+func Unified(oldName, newName, oldText, newText string) string {
+	if oldText == newText {
+		return ""
+	}
+	ops := lineOps(SplitLines(oldText), SplitLines(newText))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", oldName, newName)
+	for _, h := range hunks(ops) {
+		writeHunk(&b, ops[h[0]:h[1]])
+	}
+	return b.String()
+}
+
+// lineOps calcula a sequência de operações que transforma a em b. Prefixo e sufixo
+// comuns são separados antes do algoritmo de Myers, que assim só trabalha no trecho
+// alterado.
+func lineOps(a, b []string) []op {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var ops []op
+	for i := 0; i < prefix; i++ {
+		ops = append(ops, op{opEqual, a[i], i, i})
+	}
+	for _, o := range myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]) {
+		o.a += prefix
+		o.b += prefix
+		ops = append(ops, o)
+	}
+	for i := suffix; i > 0; i-- {
+		ops = append(ops, op{opEqual, a[len(a)-i], len(a) - i, len(b) - i})
+	}
+	return ops
+}
+
+// myers implementa o algoritmo de diff de Myers (caminho de edição mínimo).
+func myers(a, b []string) []op {
+	n, m := len(a), len(b)
+	max := n + m
+	if max == 0 {
+		return nil
+	}
+	off := max + 1
+	v := make([]int, 2*max+3)
+	var trace [][]int
+
+search:
+	for d := 0; d <= max; d++ {
+		trace = append(trace, append([]int(nil), v...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[off+k-1] < v[off+k+1]) {
+				x = v[off+k+1]
+			} else {
+				x = v[off+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[off+k] = x
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+
+	// Reconstrói o caminho do fim para o começo.
+	var rev []op
+	x, y := n, m
+	for d := len(trace) - 1; d > 0; d-- {
+		v := trace[d]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && v[off+k-1] < v[off+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[off+prevK]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x--
+			y--
+			rev = append(rev, op{opEqual, a[x], x, y})
+		}
+		if x == prevX {
+			y--
+			rev = append(rev, op{opInsert, b[y], x, y})
+		} else {
+			x--
+			rev = append(rev, op{opDelete, a[x], x, y})
+		}
+	}
+	for x > 0 && y > 0 {
+		x--
+		y--
+		rev = append(rev, op{opEqual, a[x], x, y})
+	}
+
+	ops := make([]op, len(rev))
+	for i, o := range rev {
+		ops[len(rev)-1-i] = o
+	}
+	return ops
+}
+
+// hunks agrupa as alterações em trechos [início, fim) de ops, com contexto em volta.
+// Alterações próximas o bastante para compartilhar contexto ficam no mesmo trecho.
+func hunks(ops []op) [][2]int {
+	var result [][2]int
+	for i := 0; i < len(ops); i++ {
+		if ops[i].kind == opEqual {
+			continue
+		}
+		start := i - contextLines
+		if start < 0 {
+			start = 0
+		}
+		end := i
+		for end < len(ops) {
+			if ops[end].kind != opEqual {
+				end++
+				continue
+			}
+			// Conta as linhas iguais seguintes; se outra alteração vier logo, continua.
+			run := end
+			for run < len(ops) && ops[run].kind == opEqual {
+				run++
+			}
+			if run < len(ops) && run-end <= 2*contextLines {
+				end = run
+				continue
+			}
+			end += contextLines
+			if end > len(ops) {
+				end = len(ops)
+			}
+			break
+		}
+		if n := len(result); n > 0 && start <= result[n-1][1] {
+			result[n-1][1] = end
+		} else {
+			result = append(result, [2]int{start, end})
+		}
+		i = end - 1
+	}
+	return result
+}
+
+// writeHunk escreve um trecho com seu cabeçalho "@@ -a,n +b,m @@".
+func writeHunk(b *strings.Builder, ops []op) {
+	oldStart, newStart := ops[0].a+1, ops[0].b+1
+	oldCount, newCount := 0, 0
+	for _, o := range ops {
+		if o.kind != opInsert {
+			oldCount++
+		}
+		if o.kind != opDelete {
+			newCount++
+		}
+	}
+	// Por convenção, um lado vazio aponta para a linha anterior ao trecho.
+	if oldCount == 0 {
+		oldStart--
+	}
+	if newCount == 0 {
+		newStart--
+	}
+	fmt.Fprintf(b, "@@ -%s +%s @@\n", hunkRange(oldStart, oldCount), hunkRange(newStart, newCount))
+	for _, o := range ops {
+		b.WriteByte(byte(o.kind))
+		b.WriteString(o.text)
+		if !strings.HasSuffix(o.text, "\n") {
+			b.WriteString("\n" + noNewline)
+		}
+	}
+}
+
+// hunkRange formata "início,quantidade", omitindo a quantidade quando é 1.
+func hunkRange(start, count int) string {
+	if count == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
@@ -0,0 +1,149 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestUnifiedRoundTrip testa que aplicar o diff gerado reproduz o texto novo.
+func TestUnifiedRoundTrip(t *testing.T) {
+	long := strings.Repeat("linha\n", 20)
+	testCases := []struct {
+		name     string
+		old, new string
+	}{
+		{"Troca uma linha", "a\nb\nc\n", "a\nB\nc\n"},
+		{"Insere no começo", "a\nb\n", "novo\na\nb\n"},
+		{"Remove no fim", "a\nb\nc\n", "a\nb\n"},
+		{"Arquivo novo", "", "a\nb\n"},
+		{"Esvazia arquivo", "a\nb\n", ""},
+		{"Sem quebra de linha final", "a\nb", "a\nc"},
+		{"Adiciona quebra final", "a\nb", "a\nb\n"},
+		{"Dois hunks distantes", "x\n" + long + "y\n", "X\n" + long + "Y\n"},
+		{"Alterações próximas", "1\n2\n3\n4\n5\n6\n7\n8\n", "1\nII\n3\n4\n5\n6\nVII\n8\n"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			patch := Unified("a/f", "b/f", tc.old, tc.new)
+			files, err := Parse(patch)
+			if err != nil {
+				t.Fatalf("Parse() erro = %v\n%s", err, patch)
+			}
+			got, _, err := Apply(tc.old, files[0].Hunks)
+			if err != nil {
+				t.Fatalf("Apply() erro = %v\n%s", err, patch)
+			}
+			if got != tc.new {
+				t.Errorf("Apply() = %q, esperado %q\n%s", got, tc.new, patch)
+			}
+		})
+	}
+
+	if Unified("a", "b", "igual\n", "igual\n") != "" {
+		t.Error("Unified() de textos iguais deveria ser vazio")
+	}
+}
+
+// TestUnifiedFormat testa o formato de um diff simples.
+func TestUnifiedFormat(t *testing.T) {
+	got := Unified("a/f.txt", "b/f.txt", "1\n2\n3\n4\n5\n", "1\n2\ntrês\n4\n5\n")
+	want := "--- a/f.txt\n+++ b/f.txt\n@@ -1,5 +1,5 @@\n 1\n 2\n-3\n+três\n 4\n 5\n"
+	if got != want {
+		t.Errorf("Unified() =\n%s\nesperado\n%s", got, want)
+	}
+}
+
+// TestApplyFuzz testa hunks deslocados, com espaços diferentes e com contexto desatualizado.
+func TestApplyFuzz(t *testing.T) {
+	content := "cabeçalho\nextra\nfunc main() {\n\tfmt.Println(\"oi\")\n}\n"
+	testCases := []struct {
+		name       string
+		patch      string
+		want       string
+		wantOffset int
+		wantFuzz   int
+		wantErr    bool
+	}{
+		{
+			name:       "Deslocado",
+			patch:      "--- a/m.go\n+++ b/m.go\n@@ -1,3 +1,3 @@\n func main() {\n-\tfmt.Println(\"oi\")\n+\tfmt.Println(\"olá\")\n }\n",
+			want:       "cabeçalho\nextra\nfunc main() {\n\tfmt.Println(\"olá\")\n}\n",
+			wantOffset: 2,
+		},
+		{
+			name:     "Espaços diferentes",
+			patch:    "--- a/m.go\n+++ b/m.go\n@@ -3,3 +3,3 @@\n func main() {\n-    fmt.Println(\"oi\")\n+\tfmt.Println(\"olá\")\n }\n",
+			want:     "cabeçalho\nextra\nfunc main() {\n\tfmt.Println(\"olá\")\n}\n",
+			wantFuzz: 1,
+		},
+		{
+			name:     "Contexto desatualizado",
+			patch:    "--- a/m.go\n+++ b/m.go\n@@ -3,3 +3,3 @@\n func Main() {\n-\tfmt.Println(\"oi\")\n+\tfmt.Println(\"olá\")\n }\n",
+			want:     "cabeçalho\nextra\nfunc main() {\n\tfmt.Println(\"olá\")\n}\n",
+			wantFuzz: 2,
+		},
+		{
+			name:    "Não corresponde",
+			patch:   "--- a/m.go\n+++ b/m.go\n@@ -3,1 +3,1 @@\n-inexistente\n+x\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			files, err := Parse(tc.patch)
+			if err != nil {
+				t.Fatalf("Parse() erro inesperado: %v", err)
+			}
+			got, reports, err := Apply(content, files[0].Hunks)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Apply() erro = %v, wantErr %v", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			if got != tc.want {
+				t.Errorf("Apply() = %q, esperado %q", got, tc.want)
+			}
+			if reports[0].Offset != tc.wantOffset || reports[0].Fuzz != tc.wantFuzz {
+				t.Errorf("Report = %+v, esperado deslocamento %d e fuzz %d", reports[0], tc.wantOffset, tc.wantFuzz)
+			}
+		})
+	}
+}
+
+// TestParseMultipleFiles testa diffs com vários arquivos, criação e remoção.
+func TestParseMultipleFiles(t *testing.T) {
+	patch := `diff --git a/x.txt b/x.txt
+index 123..456 100644
+--- a/x.txt
++++ b/x.txt
+@@ -1 +1 @@
+-a
++b
+--- /dev/null
++++ b/novo.txt
+@@ -0,0 +1,2 @@
++um
++dois
+--- a/velho.txt
++++ /dev/null
+@@ -1 +0,0 @@
+-tchau
+`
+	files, err := Parse(patch)
+	if err != nil {
+		t.Fatalf("Parse() erro inesperado: %v", err)
+	}
+	if len(files) != 3 {
+		t.Fatalf("Parse() = %d arquivos, esperado 3", len(files))
+	}
+	if files[0].Path() != "x.txt" || !files[1].IsNew() || files[1].Path() != "novo.txt" || !files[2].IsDelete() || files[2].Path() != "velho.txt" {
+		t.Errorf("Parse() nomes inesperados: %+v", files)
+	}
+
+	if _, err := Parse("texto qualquer"); err == nil {
+		t.Error("Parse() deveria rejeitar texto sem cabeçalhos")
+	}
+}
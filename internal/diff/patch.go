@@ -0,0 +1,266 @@
+package diff
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DevNull é o nome usado no diff para arquivos criados ou removidos.
+const DevNull = "/dev/null"
+
+// FilePatch é o trecho de um diff unificado referente a um arquivo.
+type FilePatch struct {
+	OldName string
+	NewName string
+	Hunks   []Hunk
+}
+
+// Hunk é um bloco "@@ ... @@" de um diff unificado.
+type Hunk struct {
+	OldStart int
+	NewStart int
+	Lines    []Line
+}
+
+// Line é uma linha de um hunk: ' ' contexto, '-' removida ou '+' adicionada. Text
+// inclui o "\n", exceto quando a linha é a última de um arquivo sem quebra final.
+type Line struct {
+	Kind byte
+	Text string
+}
+
+var hunkHeader = regexp.MustCompile(`^@@ -(\d+)(?:,\d+)? \+(\d+)(?:,\d+)? @@`)
+
+// Parse lê um diff unificado com um ou mais arquivos. As contagens de linhas dos
+// cabeçalhos "@@" são ignoradas: o hunk vai até o próximo cabeçalho, o que tolera diffs
+// escritos à mão. Linhas vazias dentro de um hunk contam como contexto vazio.
+func Parse(patch string) ([]FilePatch, error) {
+	lines := SplitLines(strings.ReplaceAll(patch, "\r\n", "\n"))
+	var files []FilePatch
+	var file *FilePatch
+	var hunk *Hunk
+
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSuffix(lines[i], "\n")
+		switch {
+		case strings.HasPrefix(line, "--- ") && i+1 < len(lines) && strings.HasPrefix(lines[i+1], "+++ "):
+			files = append(files, FilePatch{
+				OldName: headerName(line[4:]),
+				NewName: headerName(strings.TrimSuffix(lines[i+1], "\n")[4:]),
+			})
+			file, hunk = &files[len(files)-1], nil
+			i++
+		case strings.HasPrefix(line, "@@"):
+			if file == nil {
+				return nil, fmt.Errorf("linha %d: hunk sem cabeçalho '--- ' / '+++ '", i+1)
+			}
+			m := hunkHeader.FindStringSubmatch(line)
+			if m == nil {
+				return nil, fmt.Errorf("linha %d: cabeçalho de hunk inválido: %s", i+1, line)
+			}
+			oldStart, _ := strconv.Atoi(m[1])
+			newStart, _ := strconv.Atoi(m[2])
+			file.Hunks = append(file.Hunks, Hunk{OldStart: oldStart, NewStart: newStart})
+			hunk = &file.Hunks[len(file.Hunks)-1]
+		case hunk == nil:
+			// Texto antes do primeiro hunk (diff --git, index, etc.) é ignorado.
+		case strings.HasPrefix(line, "\\"):
+			if n := len(hunk.Lines); n > 0 {
+				hunk.Lines[n-1].Text = strings.TrimSuffix(hunk.Lines[n-1].Text, "\n")
+			}
+		case line == "":
+			hunk.Lines = append(hunk.Lines, Line{Kind: ' ', Text: "\n"})
+		case line[0] == ' ' || line[0] == '-' || line[0] == '+':
+			hunk.Lines = append(hunk.Lines, Line{Kind: line[0], Text: line[1:] + "\n"})
+		default:
+			hunk = nil
+		}
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("nenhum arquivo encontrado no diff (faltam as linhas '--- ' e '+++ ')")
+	}
+	for _, f := range files {
+		if len(f.Hunks) == 0 {
+			return nil, fmt.Errorf("o diff de '%s' não tem hunks", f.Path())
+		}
+	}
+	return files, nil
+}
+
+// headerName extrai o nome do arquivo de "--- a/nome\tdata", sem os prefixos a/ e b/.
+func headerName(s string) string {
+	if i := strings.IndexByte(s, '\t'); i >= 0 {
+		s = s[:i]
+	}
+	s = strings.TrimSpace(s)
+	if s == DevNull {
+		return s
+	}
+	if strings.HasPrefix(s, "a/") || strings.HasPrefix(s, "b/") {
+		return s[2:]
+	}
+	return s
+}
+
+// Path retorna o caminho afetado: o novo nome, ou o antigo se o arquivo for removido.
+func (f FilePatch) Path() string {
+	if f.NewName == DevNull {
+		return f.OldName
+	}
+	return f.NewName
+}
+
+// IsNew indica se o patch cria o arquivo.
+func (f FilePatch) IsNew() bool { return f.OldName == DevNull }
+
+// IsDelete indica se o patch remove o arquivo.
+func (f FilePatch) IsDelete() bool { return f.NewName == DevNull }
+
+// Report descreve onde e como um hunk foi aplicado.
+type Report struct {
+	Hunk   int // número do hunk, a partir de 1
+	Line   int // linha do arquivo original onde foi aplicado
+	Offset int // diferença entre a linha do cabeçalho e a usada
+	Fuzz   int // 0 exato, 1 ignorando espaços, 2+ descartando linhas de contexto
+}
+
+func (r Report) String() string {
+	s := fmt.Sprintf("hunk #%d aplicado na linha %d", r.Hunk, r.Line)
+	if r.Offset != 0 {
+		s += fmt.Sprintf(" (deslocamento de %+d linhas)", r.Offset)
+	}
+	switch {
+	case r.Fuzz == 1:
+		s += " com fuzz 1 (diferenças de espaços ignoradas)"
+	case r.Fuzz > 1:
+		s += fmt.Sprintf(" com fuzz %d (%d linhas de contexto ignoradas em cada ponta)", r.Fuzz, r.Fuzz-1)
+	}
+	return s
+}
+
+// maxFuzz é o maior nível de fuzz tentado.
+const maxFuzz = 3
+
+// Apply aplica os hunks ao conteúdo. Cada hunk é procurado primeiro na linha indicada e
+// depois nas vizinhas; se não houver correspondência exata, tenta de novo ignorando
+// diferenças de espaços e, por fim, descartando linhas de contexto das pontas.
+func Apply(content string, hunks []Hunk) (string, []Report, error) {
+	lines := SplitLines(content)
+	var out []string
+	var reports []Report
+	cursor, delta := 0, 0
+
+	for i, h := range hunks {
+		insertOnly := len(oldSide(h.Lines)) == 0
+		var used []Line
+		var pos, lead, fuzz int
+		found := false
+		for ; fuzz <= maxFuzz; fuzz++ {
+			used, lead = trimContext(h.Lines, fuzz-1)
+			if !insertOnly && len(oldSide(used)) == 0 {
+				break
+			}
+			expected := h.OldStart - 1 + delta + lead
+			if insertOnly {
+				expected = h.OldStart + delta
+			}
+			if pos, found = search(lines, oldSide(used), expected, cursor, fuzz > 0); found {
+				break
+			}
+			if insertOnly {
+				break
+			}
+		}
+		if !found {
+			return "", reports, fmt.Errorf("hunk #%d (linha %d) não corresponde ao conteúdo do arquivo", i+1, h.OldStart)
+		}
+
+		out = append(out, lines[cursor:pos]...)
+		at := pos
+		for _, l := range used {
+			switch l.Kind {
+			case ' ':
+				out = append(out, lines[at]) // mantém a linha original do arquivo
+				at++
+			case '-':
+				at++
+			case '+':
+				out = append(out, l.Text)
+			}
+		}
+		cursor = at
+
+		start := pos - lead // posição da primeira linha antiga do hunk completo
+		delta = start - (h.OldStart - 1)
+		line := start + 1
+		if insertOnly {
+			delta = pos - h.OldStart
+			line = pos
+		}
+		reports = append(reports, Report{Hunk: i + 1, Line: line, Offset: delta, Fuzz: fuzz})
+	}
+	out = append(out, lines[cursor:]...)
+	return strings.Join(out, ""), reports, nil
+}
+
+// oldSide retorna as linhas que o hunk espera encontrar no arquivo (contexto e removidas).
+func oldSide(lines []Line) []Line {
+	var old []Line
+	for _, l := range lines {
+		if l.Kind != '+' {
+			old = append(old, l)
+		}
+	}
+	return old
+}
+
+// trimContext descarta até n linhas de contexto do começo e do fim do hunk e retorna
+// quantas foram descartadas do começo.
+func trimContext(lines []Line, n int) ([]Line, int) {
+	lead := 0
+	for lead < n && lead < len(lines) && lines[lead].Kind == ' ' {
+		lead++
+	}
+	end := len(lines)
+	for trail := 0; trail < n && end > lead && lines[end-1].Kind == ' '; trail++ {
+		end--
+	}
+	return lines[lead:end], lead
+}
+
+// search procura old em lines a partir de cursor, começando pela posição esperada e
+// alternando para cima e para baixo. Com loose, espaços nas pontas são ignorados.
+func search(lines []string, old []Line, expected, cursor int, loose bool) (int, bool) {
+	last := len(lines) - len(old)
+	if len(old) == 0 {
+		return min(max(expected, cursor), len(lines)), true
+	}
+	for d := 0; ; d++ {
+		below, above := expected+d, expected-d
+		if below > last && above < cursor {
+			return 0, false
+		}
+		if below >= cursor && below <= last && matches(lines[below:], old, loose) {
+			return below, true
+		}
+		if d > 0 && above >= cursor && above <= last && matches(lines[above:], old, loose) {
+			return above, true
+		}
+	}
+}
+
+// matches compara as primeiras linhas de lines com old.
+func matches(lines []string, old []Line, loose bool) bool {
+	for j, l := range old {
+		if lines[j] == l.Text {
+			continue
+		}
+		if !loose || strings.TrimSpace(lines[j]) != strings.TrimSpace(l.Text) {
+			return false
+		}
+	}
+	return true
+}
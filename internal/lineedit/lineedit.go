@@ -0,0 +1,551 @@
+// Package lineedit implementa um editor de linha para o terminal sem dependências externas:
+// edição com setas e atalhos do emacs, histórico persistente, modo multilinha e bracketed
+// paste. Fora de um terminal (pipes, arquivos) ele lê linhas simples, sem limite de tamanho.
+package lineedit
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrInterrupted é retornado por ReadLine quando o usuário pressiona ctrl-c.
+var ErrInterrupted = errors.New("entrada interrompida")
+
+// DefaultMaxHistory é o número de entradas mantidas no histórico.
+const DefaultMaxHistory = 1000
+
+// continuationPrompt é mostrado nas linhas seguintes de uma mensagem multilinha.
+const continuationPrompt = "\u001b[90m...\u001b[0m "
+
+// Sequências de controle do terminal.
+const (
+	pasteStart    = "200~"
+	pasteEnd      = "\x1b[201~"
+	enablePaste   = "\x1b[?2004h"
+	disablePaste  = "\x1b[?2004l"
+	clearScreen   = "\x1b[H\x1b[2J"
+	clearToEOL    = "\x1b[0K"
+	multilineMark = `"""`
+)
+
+// Editor lê mensagens do usuário. Uma mensagem pode ocupar várias linhas: termine a linha
+// com "\" para continuar na próxima, ou delimite um bloco com """ no início e no fim.
+type Editor struct {
+	in          *bufio.Reader
+	out         io.Writer
+	fd          int  // descritor do terminal; -1 quando não há terminal para configurar
+	tty         bool // se true, usa o modo raw com edição de linha
+	history     []string
+	historyPath string
+	maxHistory  int
+}
+
+// New cria um editor sobre a entrada e a saída informadas. A edição de linha só é ativada
+// quando in é um terminal.
+func New(in *os.File, out io.Writer) *Editor {
+	fd := int(in.Fd())
+	return &Editor{
+		in:         bufio.NewReader(in),
+		out:        out,
+		fd:         fd,
+		tty:        isTerminal(fd),
+		maxHistory: DefaultMaxHistory,
+	}
+}
+
+// LoadHistory carrega o histórico do arquivo e passa a gravar nele as novas entradas.
+// Um arquivo inexistente não é erro: ele será criado na primeira entrada.
+func (e *Editor) LoadHistory(path string) error {
+	e.historyPath = path
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("erro ao ler histórico: %w", err)
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if line == "" {
+			continue
+		}
+		// Cada entrada é gravada entre aspas para preservar quebras de linha.
+		if entry, err := strconv.Unquote(line); err == nil {
+			line = entry
+		}
+		e.history = append(e.history, line)
+	}
+	if len(e.history) > e.maxHistory {
+		e.history = e.history[len(e.history)-e.maxHistory:]
+	}
+	return nil
+}
+
+// History retorna uma cópia das entradas do histórico, da mais antiga para a mais recente.
+func (e *Editor) History() []string {
+	return append([]string(nil), e.history...)
+}
+
+// ReadLine lê uma mensagem completa, tratando continuação com "\" e blocos com """.
+// Retorna io.EOF quando a entrada termina (ou ctrl-d em linha vazia) e ErrInterrupted no ctrl-c.
+func (e *Editor) ReadLine(prompt string) (string, error) {
+	line, err := e.readPhysicalLine(prompt, true)
+	if err != nil {
+		return "", err
+	}
+
+	var message string
+	switch {
+	case strings.HasPrefix(strings.TrimSpace(line), multilineMark):
+		message, err = e.readBlock(strings.TrimPrefix(strings.TrimSpace(line), multilineMark))
+	case strings.HasSuffix(line, `\`) && !strings.Contains(line, "\n"):
+		message, err = e.readContinuation(line)
+	default:
+		message = line
+	}
+	if err != nil {
+		return "", err
+	}
+
+	e.addHistory(message)
+	return message, nil
+}
+
+// Prompt lê uma única linha sem multilinha e sem registrar no histórico. É usado para
+// perguntas rápidas, como confirmações.
+func (e *Editor) Prompt(prompt string) (string, error) {
+	return e.readPhysicalLine(prompt, false)
+}
+
+// readBlock lê linhas até encontrar o delimitador """ de fechamento.
+func (e *Editor) readBlock(first string) (string, error) {
+	if strings.HasSuffix(first, multilineMark) {
+		return strings.TrimSuffix(first, multilineMark), nil
+	}
+	var lines []string
+	if first != "" {
+		lines = append(lines, first)
+	}
+	for {
+		line, err := e.readPhysicalLine(continuationPrompt, false)
+		if err != nil {
+			return "", err
+		}
+		if trimmed := strings.TrimRight(line, " \t"); strings.HasSuffix(trimmed, multilineMark) {
+			if rest := strings.TrimSuffix(trimmed, multilineMark); rest != "" {
+				lines = append(lines, rest)
+			}
+			return strings.Join(lines, "\n"), nil
+		}
+		lines = append(lines, line)
+	}
+}
+
+// readContinuation junta linhas enquanto terminarem com "\".
+func (e *Editor) readContinuation(line string) (string, error) {
+	var lines []string
+	for strings.HasSuffix(line, `\`) {
+		lines = append(lines, strings.TrimSuffix(line, `\`))
+		next, err := e.readPhysicalLine(continuationPrompt, false)
+		if err != nil {
+			return "", err
+		}
+		line = next
+	}
+	lines = append(lines, line)
+	return strings.Join(lines, "\n"), nil
+}
+
+// readPhysicalLine lê uma linha do terminal (com edição) ou da entrada simples.
+// withHistory habilita a navegação pelo histórico com as setas.
+func (e *Editor) readPhysicalLine(prompt string, withHistory bool) (string, error) {
+	if e.tty {
+		return e.readRaw(prompt, withHistory)
+	}
+
+	fmt.Fprint(e.out, prompt)
+	line, err := e.in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// addHistory registra uma entrada e atualiza o arquivo de histórico.
+func (e *Editor) addHistory(entry string) {
+	if strings.TrimSpace(entry) == "" {
+		return
+	}
+	if n := len(e.history); n > 0 && e.history[n-1] == entry {
+		return
+	}
+	e.history = append(e.history, entry)
+	if len(e.history) > e.maxHistory {
+		e.history = e.history[len(e.history)-e.maxHistory:]
+	}
+	e.saveHistory()
+}
+
+// saveHistory grava o histórico; falhas são ignoradas para não interromper o chat.
+func (e *Editor) saveHistory() {
+	if e.historyPath == "" {
+		return
+	}
+	var b strings.Builder
+	for _, entry := range e.history {
+		b.WriteString(strconv.Quote(entry))
+		b.WriteByte('\n')
+	}
+	if err := os.MkdirAll(filepath.Dir(e.historyPath), 0700); err != nil {
+		return
+	}
+	_ = os.WriteFile(e.historyPath, []byte(b.String()), 0600)
+}
+
+// ::: Edição no terminal :::
+
+func ctrl(r rune) rune { return r & 0x1f }
+
+// readRaw lê uma linha com o terminal em modo raw, interpretando as teclas de edição.
+func (e *Editor) readRaw(prompt string, withHistory bool) (string, error) {
+	if e.fd >= 0 {
+		state, err := makeRaw(e.fd)
+		if err != nil {
+			return "", fmt.Errorf("erro ao configurar o terminal: %w", err)
+		}
+		defer restore(e.fd, state)
+	}
+	fmt.Fprint(e.out, enablePaste)
+	defer fmt.Fprint(e.out, disablePaste)
+
+	ls := &lineState{
+		prompt:      prompt,
+		promptWidth: visibleWidth(prompt),
+		cols:        terminalWidth(e.fd),
+	}
+	hist := historyCursor{entries: e.history, index: len(e.history)}
+	if !withHistory {
+		hist.entries = nil
+		hist.index = 0
+	}
+	ls.refresh(e.out)
+
+	for {
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			fmt.Fprint(e.out, "\r\n")
+			return "", err
+		}
+
+		switch r {
+		case '\r', '\n':
+			ls.pos = len(ls.buf)
+			ls.refresh(e.out)
+			fmt.Fprint(e.out, "\r\n")
+			return string(ls.buf), nil
+		case ctrl('C'):
+			fmt.Fprint(e.out, "^C\r\n")
+			return "", ErrInterrupted
+		case ctrl('D'):
+			if len(ls.buf) == 0 {
+				fmt.Fprint(e.out, "\r\n")
+				return "", io.EOF
+			}
+			ls.deleteForward()
+		case 127, ctrl('H'):
+			ls.backspace()
+		case ctrl('A'):
+			ls.pos = 0
+		case ctrl('E'):
+			ls.pos = len(ls.buf)
+		case ctrl('B'):
+			ls.moveLeft()
+		case ctrl('F'):
+			ls.moveRight()
+		case ctrl('K'):
+			ls.buf = ls.buf[:ls.pos]
+		case ctrl('U'):
+			ls.buf = append([]rune{}, ls.buf[ls.pos:]...)
+			ls.pos = 0
+		case ctrl('W'):
+			ls.deleteWordBack()
+		case ctrl('L'):
+			fmt.Fprint(e.out, clearScreen)
+		case ctrl('P'):
+			hist.prev(ls)
+		case ctrl('N'):
+			hist.next(ls)
+		case 27:
+			if err := e.handleEscape(ls, &hist); err != nil {
+				return "", err
+			}
+		default:
+			if r == '\t' || !unicode.IsControl(r) {
+				ls.insert([]rune{r})
+			}
+		}
+		ls.refresh(e.out)
+	}
+}
+
+// handleEscape interpreta sequências de escape: setas, home/end, delete, alt+b/f e paste.
+func (e *Editor) handleEscape(ls *lineState, hist *historyCursor) error {
+	r, _, err := e.in.ReadRune()
+	if err != nil {
+		return err
+	}
+	switch r {
+	case 'b':
+		ls.moveWordLeft()
+		return nil
+	case 'f':
+		ls.moveWordRight()
+		return nil
+	case 127:
+		ls.deleteWordBack()
+		return nil
+	case 'O':
+		final, _, err := e.in.ReadRune()
+		if err != nil {
+			return err
+		}
+		e.applySequence(string(final), ls, hist)
+		return nil
+	case '[':
+	default:
+		return nil
+	}
+
+	// CSI: parâmetros seguidos de um byte final entre '@' e '~'.
+	var seq []rune
+	for {
+		c, _, err := e.in.ReadRune()
+		if err != nil {
+			return err
+		}
+		seq = append(seq, c)
+		if c >= '@' && c <= '~' {
+			break
+		}
+	}
+	if string(seq) == pasteStart {
+		text, err := e.readPaste()
+		if err != nil {
+			return err
+		}
+		ls.insert([]rune(text))
+		return nil
+	}
+	e.applySequence(string(seq), ls, hist)
+	return nil
+}
+
+// applySequence executa a ação de uma sequência de escape já lida.
+func (e *Editor) applySequence(seq string, ls *lineState, hist *historyCursor) {
+	switch seq {
+	case "A":
+		hist.prev(ls)
+	case "B":
+		hist.next(ls)
+	case "C":
+		ls.moveRight()
+	case "D":
+		ls.moveLeft()
+	case "H", "1~", "7~":
+		ls.pos = 0
+	case "F", "4~", "8~":
+		ls.pos = len(ls.buf)
+	case "3~":
+		ls.deleteForward()
+	case "1;5C", "1;3C":
+		ls.moveWordRight()
+	case "1;5D", "1;3D":
+		ls.moveWordLeft()
+	}
+}
+
+// readPaste lê o texto colado até a sequência de fim do bracketed paste. Quebras de linha
+// são preservadas, de modo que o texto inteiro vira uma única mensagem.
+func (e *Editor) readPaste() (string, error) {
+	var b strings.Builder
+	for {
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			return "", err
+		}
+		b.WriteRune(r)
+		if s := b.String(); strings.HasSuffix(s, pasteEnd) {
+			s = strings.TrimSuffix(s, pasteEnd)
+			s = strings.ReplaceAll(s, "\r\n", "\n")
+			return strings.ReplaceAll(s, "\r", "\n"), nil
+		}
+	}
+}
+
+// lineState é o conteúdo em edição e a posição do cursor.
+type lineState struct {
+	prompt      string
+	promptWidth int
+	cols        int
+	buf         []rune
+	pos         int
+}
+
+func (ls *lineState) insert(text []rune) {
+	buf := make([]rune, 0, len(ls.buf)+len(text))
+	buf = append(buf, ls.buf[:ls.pos]...)
+	buf = append(buf, text...)
+	buf = append(buf, ls.buf[ls.pos:]...)
+	ls.buf = buf
+	ls.pos += len(text)
+}
+
+func (ls *lineState) backspace() {
+	if ls.pos == 0 {
+		return
+	}
+	ls.buf = append(ls.buf[:ls.pos-1], ls.buf[ls.pos:]...)
+	ls.pos--
+}
+
+func (ls *lineState) deleteForward() {
+	if ls.pos >= len(ls.buf) {
+		return
+	}
+	ls.buf = append(ls.buf[:ls.pos], ls.buf[ls.pos+1:]...)
+}
+
+func (ls *lineState) moveLeft() {
+	if ls.pos > 0 {
+		ls.pos--
+	}
+}
+
+func (ls *lineState) moveRight() {
+	if ls.pos < len(ls.buf) {
+		ls.pos++
+	}
+}
+
+func (ls *lineState) moveWordLeft() {
+	for ls.pos > 0 && unicode.IsSpace(ls.buf[ls.pos-1]) {
+		ls.pos--
+	}
+	for ls.pos > 0 && !unicode.IsSpace(ls.buf[ls.pos-1]) {
+		ls.pos--
+	}
+}
+
+func (ls *lineState) moveWordRight() {
+	for ls.pos < len(ls.buf) && unicode.IsSpace(ls.buf[ls.pos]) {
+		ls.pos++
+	}
+	for ls.pos < len(ls.buf) && !unicode.IsSpace(ls.buf[ls.pos]) {
+		ls.pos++
+	}
+}
+
+func (ls *lineState) deleteWordBack() {
+	end := ls.pos
+	ls.moveWordLeft()
+	ls.buf = append(ls.buf[:ls.pos], ls.buf[end:]...)
+}
+
+// set substitui todo o conteúdo, colocando o cursor no fim.
+func (ls *lineState) set(text string) {
+	ls.buf = []rune(text)
+	ls.pos = len(ls.buf)
+}
+
+// refresh redesenha a linha. Conteúdo maior que o terminal rola horizontalmente para manter
+// o cursor visível; quebras de linha de textos colados aparecem como ⏎.
+func (ls *lineState) refresh(w io.Writer) {
+	avail := ls.cols - ls.promptWidth - 1
+	if avail < 10 {
+		avail = 10
+	}
+	start := 0
+	if ls.pos >= avail {
+		start = ls.pos - avail + 1
+	}
+	end := start + avail
+	if end > len(ls.buf) {
+		end = len(ls.buf)
+	}
+
+	var b strings.Builder
+	b.WriteString("\r")
+	b.WriteString(ls.prompt)
+	for _, r := range ls.buf[start:end] {
+		switch {
+		case r == '\n':
+			b.WriteRune('⏎')
+		case r == '\t':
+			b.WriteRune(' ')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteString(clearToEOL)
+	b.WriteString("\r")
+	if col := ls.promptWidth + ls.pos - start; col > 0 {
+		fmt.Fprintf(&b, "\x1b[%dC", col)
+	}
+	io.WriteString(w, b.String())
+}
+
+// historyCursor navega pelo histórico preservando o texto que estava sendo digitado.
+type historyCursor struct {
+	entries []string
+	index   int
+	pending string
+}
+
+func (h *historyCursor) prev(ls *lineState) {
+	if h.index == 0 {
+		return
+	}
+	if h.index == len(h.entries) {
+		h.pending = string(ls.buf)
+	}
+	h.index--
+	ls.set(h.entries[h.index])
+}
+
+func (h *historyCursor) next(ls *lineState) {
+	if h.index >= len(h.entries) {
+		return
+	}
+	h.index++
+	if h.index == len(h.entries) {
+		ls.set(h.pending)
+		return
+	}
+	ls.set(h.entries[h.index])
+}
+
+// visibleWidth conta as colunas ocupadas por um texto, ignorando sequências de cor ANSI.
+func visibleWidth(s string) int {
+	width := 0
+	for i := 0; i < len(s); {
+		if s[i] == 0x1b && i+1 < len(s) && s[i+1] == '[' {
+			i += 2
+			for i < len(s) && (s[i] < '@' || s[i] > '~') {
+				i++
+			}
+			i++
+			continue
+		}
+		_, size := utf8.DecodeRuneInString(s[i:])
+		width++
+		i += size
+	}
+	return width
+}
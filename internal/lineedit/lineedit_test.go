@@ -0,0 +1,153 @@
+package lineedit
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newTestEditor cria um editor em modo de edição (como em um terminal) lendo de input.
+func newTestEditor(input string, tty bool) *Editor {
+	return &Editor{
+		in:         bufio.NewReader(strings.NewReader(input)),
+		out:        &bytes.Buffer{},
+		fd:         -1,
+		tty:        tty,
+		maxHistory: DefaultMaxHistory,
+	}
+}
+
+// TestReadLineEditing testa as teclas de edição no modo terminal
+func TestReadLineEditing(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"Texto simples", "olá mundo\r", "olá mundo"},
+		{"Backspace", "abcd\x7f\x7fx\r", "abx"},
+		{"Seta esquerda e inserção", "ac\x1b[Db\r", "abc"},
+		{"Home e End", "bc\x1b[Ha\x1b[Fd\r", "abcd"},
+		{"Ctrl-A e Ctrl-K", "abc def\x01\x1b[C\x1b[C\x1b[C\x0b\r", "abc"},
+		{"Ctrl-U apaga até o início", "abc def\x15xyz\r", "xyz"},
+		{"Ctrl-W apaga a palavra anterior", "um dois tres\x17quatro\r", "um dois quatro"},
+		{"Delete", "abc\x01\x1b[3~\r", "bc"},
+		{"Alt-b move por palavra", "um dois\x1bbX\r", "um Xdois"},
+		{"Bracketed paste com várias linhas", "\x1b[200~linha 1\r\nlinha 2\nlinha 3\x1b[201~\r", "linha 1\nlinha 2\nlinha 3"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := newTestEditor(tc.input, true)
+			got, err := e.ReadLine("> ")
+			if err != nil {
+				t.Fatalf("ReadLine() erro inesperado: %v", err)
+			}
+			if got != tc.expected {
+				t.Errorf("ReadLine() = %q, esperado %q", got, tc.expected)
+			}
+		})
+	}
+}
+
+// TestReadLineMultiline testa os modos multilinha em terminal e em entrada redirecionada
+func TestReadLineMultiline(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		tty      bool
+		expected string
+	}{
+		{"Barra invertida (terminal)", "primeira \\\rsegunda\r", true, "primeira \nsegunda"},
+		{"Barra invertida (pipe)", "a\\\nb\\\nc\n", false, "a\nb\nc"},
+		{"Bloco com aspas triplas", "\"\"\"\nfunc main() {\n}\n\"\"\"\n", false, "func main() {\n}"},
+		{"Bloco com texto na abertura e no fechamento", "\"\"\"início\nmeio\nfim\"\"\"\n", false, "início\nmeio\nfim"},
+		{"Bloco em uma linha", "\"\"\"só isso\"\"\"\n", false, "só isso"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := newTestEditor(tc.input, tc.tty)
+			got, err := e.ReadLine("> ")
+			if err != nil {
+				t.Fatalf("ReadLine() erro inesperado: %v", err)
+			}
+			if got != tc.expected {
+				t.Errorf("ReadLine() = %q, esperado %q", got, tc.expected)
+			}
+		})
+	}
+}
+
+// TestReadLineLongInput garante que linhas maiores que 64KB são lidas inteiras
+func TestReadLineLongInput(t *testing.T) {
+	long := strings.Repeat("x", 200*1024)
+	e := newTestEditor(long+"\n", false)
+	got, err := e.ReadLine("> ")
+	if err != nil {
+		t.Fatalf("ReadLine() erro inesperado: %v", err)
+	}
+	if len(got) != len(long) {
+		t.Errorf("ReadLine() leu %d bytes, esperado %d", len(got), len(long))
+	}
+}
+
+// TestReadLineControlKeys testa ctrl-c e ctrl-d
+func TestReadLineControlKeys(t *testing.T) {
+	e := newTestEditor("abc\x03", true)
+	if _, err := e.ReadLine("> "); !errors.Is(err, ErrInterrupted) {
+		t.Errorf("ctrl-c: erro = %v, esperado ErrInterrupted", err)
+	}
+
+	e = newTestEditor("\x04", true)
+	if _, err := e.ReadLine("> "); err != io.EOF {
+		t.Errorf("ctrl-d em linha vazia: erro = %v, esperado io.EOF", err)
+	}
+
+	e = newTestEditor("", false)
+	if _, err := e.ReadLine("> "); err != io.EOF {
+		t.Errorf("fim da entrada: erro = %v, esperado io.EOF", err)
+	}
+}
+
+// TestHistory testa a navegação e a persistência do histórico, inclusive de entradas multilinha
+func TestHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+
+	// Duas entradas e depois: seta para cima três vezes (para na primeira) e uma para baixo
+	e := newTestEditor("primeira\r\x1b[200~a\nb\x1b[201~\r\x1b[A\x1b[A\x1b[A\x1b[B\r", true)
+	if err := e.LoadHistory(path); err != nil {
+		t.Fatalf("LoadHistory() erro inesperado: %v", err)
+	}
+	for _, want := range []string{"primeira", "a\nb", "a\nb"} {
+		got, err := e.ReadLine("> ")
+		if err != nil {
+			t.Fatalf("ReadLine() erro inesperado: %v", err)
+		}
+		if got != want {
+			t.Fatalf("ReadLine() = %q, esperado %q", got, want)
+		}
+	}
+	if len(e.History()) != 2 {
+		t.Errorf("History() = %q, entradas repetidas não deveriam ser duplicadas", e.History())
+	}
+
+	reloaded := newTestEditor("", true)
+	if err := reloaded.LoadHistory(path); err != nil {
+		t.Fatalf("LoadHistory() erro inesperado: %v", err)
+	}
+	if strings.Join(reloaded.History(), "|") != strings.Join(e.History(), "|") {
+		t.Errorf("histórico persistido = %q, esperado %q", reloaded.History(), e.History())
+	}
+}
+
+// TestVisibleWidth testa a largura de prompts com cores ANSI
+func TestVisibleWidth(t *testing.T) {
+	if w := visibleWidth("\u001b[94mHumano\u001b[0m: "); w != 8 {
+		t.Errorf("visibleWidth() = %d, esperado 8", w)
+	}
+}
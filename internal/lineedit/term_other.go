@@ -0,0 +1,19 @@
+//go:build !linux && !darwin && !freebsd && !netbsd && !openbsd
+
+package lineedit
+
+import "errors"
+
+// Em plataformas sem suporte a termios o editor usa sempre a leitura simples por linha.
+
+type terminalState struct{}
+
+func isTerminal(fd int) bool { return false }
+
+func makeRaw(fd int) (*terminalState, error) {
+	return nil, errors.New("modo raw não suportado nesta plataforma")
+}
+
+func restore(fd int, state *terminalState) error { return nil }
+
+func terminalWidth(fd int) int { return 80 }
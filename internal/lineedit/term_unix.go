@@ -0,0 +1,62 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd
+
+package lineedit
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// terminalState guarda a configuração do terminal para ser restaurada depois do modo raw.
+type terminalState struct {
+	termios syscall.Termios
+}
+
+func ioctl(fd int, req uintptr, arg unsafe.Pointer) error {
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), req, uintptr(arg)); errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// isTerminal indica se o descritor é um terminal.
+func isTerminal(fd int) bool {
+	var t syscall.Termios
+	return ioctl(fd, ioctlGetTermios, unsafe.Pointer(&t)) == nil
+}
+
+// makeRaw coloca o terminal em modo raw (sem eco, sem buffer de linha e sem sinais) e
+// retorna o estado anterior. A saída continua processada para que "\n" vire "\r\n".
+func makeRaw(fd int) (*terminalState, error) {
+	var old syscall.Termios
+	if err := ioctl(fd, ioctlGetTermios, unsafe.Pointer(&old)); err != nil {
+		return nil, err
+	}
+	raw := old
+	raw.Iflag &^= syscall.IGNBRK | syscall.BRKINT | syscall.PARMRK | syscall.ISTRIP | syscall.INLCR | syscall.IGNCR | syscall.ICRNL | syscall.IXON
+	raw.Lflag &^= syscall.ECHO | syscall.ECHONL | syscall.ICANON | syscall.ISIG | syscall.IEXTEN
+	raw.Cflag &^= syscall.CSIZE | syscall.PARENB
+	raw.Cflag |= syscall.CS8
+	raw.Cc[syscall.VMIN] = 1
+	raw.Cc[syscall.VTIME] = 0
+	if err := ioctl(fd, ioctlSetTermios, unsafe.Pointer(&raw)); err != nil {
+		return nil, err
+	}
+	return &terminalState{termios: old}, nil
+}
+
+// restore devolve o terminal ao estado salvo por makeRaw.
+func restore(fd int, state *terminalState) error {
+	return ioctl(fd, ioctlSetTermios, unsafe.Pointer(&state.termios))
+}
+
+// terminalWidth retorna o número de colunas do terminal ou 80 se não for possível descobrir.
+func terminalWidth(fd int) int {
+	var ws struct {
+		Row, Col, Xpixel, Ypixel uint16
+	}
+	if err := ioctl(fd, syscall.TIOCGWINSZ, unsafe.Pointer(&ws)); err != nil || ws.Col == 0 {
+		return 80
+	}
+	return int(ws.Col)
+}
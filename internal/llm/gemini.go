@@ -7,14 +7,20 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"time"
 
 	"github.com/matheusbuniotto/goagent/pkg/agent"
 )
 
+// DefaultGeminiModel é o modelo usado quando nenhum outro é informado.
+const DefaultGeminiModel = "gemini-2.0-flash-lite"
+
 type geminiClient struct {
 	apiKey     string
 	httpClient *http.Client
+	model      string
+	lastUsage  agent.Usage
 }
 
 type geminiRequest struct {
@@ -35,15 +41,30 @@ type geminiResponse struct {
 	Candidates []struct {
 		Content geminiContent `json:"content"`
 	} `json:"candidates"`
+	UsageMetadata struct {
+		PromptTokenCount     int `json:"promptTokenCount"`
+		CandidatesTokenCount int `json:"candidatesTokenCount"`
+	} `json:"usageMetadata"`
 }
 
 func NewGeminiClient(apiKey string) agent.LLMClient {
+	return NewGeminiClientWithModel(apiKey, DefaultGeminiModel)
+}
+
+// NewGeminiClientWithModel cria um cliente do Gemini para um modelo específico.
+func NewGeminiClientWithModel(apiKey string, model string) agent.LLMClient {
 	return &geminiClient{
 		apiKey:     apiKey,
 		httpClient: &http.Client{Timeout: 30 * time.Second},
+		model:      model,
 	}
 }
 
+// LastUsage retorna o consumo de tokens da última chamada. Parte da interface agent.UsageReporter.
+func (c *geminiClient) LastUsage() agent.Usage {
+	return c.lastUsage
+}
+
 func (c *geminiClient) GenerateResponse(ctx context.Context, history []agent.Message, tools []agent.Tool) (string, error) {
 	systemPrompt := agent.BuildSystemPrompt(tools)
 	fullPrompt := systemPrompt + "\n\nAqui está o histórico da conversa:\n"
@@ -77,7 +98,7 @@ func (c *geminiClient) GenerateResponse(ctx context.Context, history []agent.Mes
 		return "", fmt.Errorf("erro ao codificar requisição para Gemini: %w", err)
 	}
 
-	apiURL := fmt.Sprintf("https://generativelanguage.googleapis.com/v1beta/models/gemini-2.0-flash-lite:generateContent?key=%s", c.apiKey)
+	apiURL := fmt.Sprintf("https://generativelanguage.googleapis.com/v1beta/models/%s:generateContent?key=%s", url.PathEscape(c.model), c.apiKey)
 	req, err := http.NewRequestWithContext(ctx, "POST", apiURL, bytes.NewBuffer(reqBody))
 	if err != nil {
 		return "", fmt.Errorf("erro ao criar requisição para Gemini: %w", err)
@@ -100,6 +121,12 @@ func (c *geminiClient) GenerateResponse(ctx context.Context, history []agent.Mes
 		return "", fmt.Errorf("erro ao decodificar resposta do Gemini: %w", err)
 	}
 
+	c.lastUsage = agent.Usage{
+		PromptTokens:     geminiResp.UsageMetadata.PromptTokenCount,
+		CompletionTokens: geminiResp.UsageMetadata.CandidatesTokenCount,
+		Calls:            1,
+	}
+
 	if len(geminiResp.Candidates) == 0 || len(geminiResp.Candidates[0].Content.Parts) == 0 {
 		return "", fmt.Errorf("resposta do Gemini está vazia ou em formato inesperado")
 	}
@@ -18,10 +18,15 @@ type Message = agent.Message
 
 type Tool = agent.Tool
 
+// DefaultOpenAIModel é o modelo usado quando nenhum outro é informado.
+const DefaultOpenAIModel = "gpt-4.1-nano"
+
 // OpenAI Client
 type openAIClient struct {
 	apiKey     string
 	httpClient *http.Client
+	model      string
+	lastUsage  agent.Usage
 }
 
 type openAIRequest struct {
@@ -34,22 +39,45 @@ type openAIResponse struct {
 	Choices []struct {
 		Message agent.Message `json:"message"`
 	} `json:"choices"`
+	Usage openAIUsage `json:"usage"`
+}
+
+// openAIUsage é o consumo de tokens no formato da API de chat completions,
+// compartilhado com o OpenRouter.
+type openAIUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+}
+
+func (u openAIUsage) toUsage() agent.Usage {
+	return agent.Usage{PromptTokens: u.PromptTokens, CompletionTokens: u.CompletionTokens, Calls: 1}
 }
 
 func NewOpenAIClient(apiKey string) LLMClient {
+	return NewOpenAIClientWithModel(apiKey, DefaultOpenAIModel)
+}
+
+// NewOpenAIClientWithModel cria um cliente da OpenAI para um modelo específico.
+func NewOpenAIClientWithModel(apiKey string, model string) LLMClient {
 	return &openAIClient{
 		apiKey:     apiKey,
 		httpClient: &http.Client{Timeout: 30 * time.Second},
+		model:      model,
 	}
 }
 
+// LastUsage retorna o consumo de tokens da última chamada. Parte da interface agent.UsageReporter.
+func (c *openAIClient) LastUsage() agent.Usage {
+	return c.lastUsage
+}
+
 func (c *openAIClient) GenerateResponse(ctx context.Context, history []Message, tools []Tool) (string, error) {
 	systemPrompt := agent.BuildSystemPrompt(tools)
 	messages := []agent.Message{{Role: "system", Content: systemPrompt}}
 	messages = append(messages, history...)
 
 	reqBody, err := json.Marshal(openAIRequest{
-		Model:     "gpt-4.1-nano",
+		Model:     c.model,
 		Messages:  messages,
 		MaxTokens: 9060,
 	})
@@ -81,6 +109,8 @@ func (c *openAIClient) GenerateResponse(ctx context.Context, history []Message,
 		return "", fmt.Errorf("erro ao decodificar resposta da OpenAI: %w", err)
 	}
 
+	c.lastUsage = openAIResp.Usage.toUsage()
+
 	if len(openAIResp.Choices) == 0 {
 		return "", fmt.Errorf("resposta da OpenAI não contém escolhas")
 	}
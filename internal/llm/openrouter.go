@@ -67,11 +67,15 @@ func SelectOpenRouterModel() string {
 	}
 }
 
+// DefaultOpenRouterModel é o modelo usado quando nenhum outro é informado.
+const DefaultOpenRouterModel = "meta-llama/llama-3.1-8b-instruct"
+
 // OpenRouter Client
 type openRouterClient struct {
 	apiKey     string
 	httpClient *http.Client
 	model      string // Modelo padrão a ser usado
+	lastUsage  agent.Usage
 }
 
 type openRouterRequest struct {
@@ -85,6 +89,7 @@ type openRouterResponse struct {
 	Choices []struct {
 		Message agent.Message `json:"message"`
 	} `json:"choices"`
+	Usage openAIUsage `json:"usage"`
 	Error *struct {
 		Message string `json:"message"`
 		Type    string `json:"type"`
@@ -93,7 +98,7 @@ type openRouterResponse struct {
 
 func NewOpenRouterClient(apiKey string) LLMClient {
 	// Usa modelo padrão, mas pode ser alterado via seleção interativa
-	model := "meta-llama/llama-3.1-8b-instruct" // Modelo barato para testes
+	model := DefaultOpenRouterModel // Modelo barato para testes
 	return &openRouterClient{
 		apiKey:     apiKey,
 		httpClient: &http.Client{Timeout: 60 * time.Second}, // Timeout maior para gateway
@@ -109,6 +114,11 @@ func NewOpenRouterClientWithModel(apiKey string, model string) LLMClient {
 	}
 }
 
+// LastUsage retorna o consumo de tokens da última chamada. Parte da interface agent.UsageReporter.
+func (c *openRouterClient) LastUsage() agent.Usage {
+	return c.lastUsage
+}
+
 func (c *openRouterClient) GenerateResponse(ctx context.Context, history []Message, tools []Tool) (string, error) {
 	systemPrompt := agent.BuildSystemPrompt(tools)
 	messages := []agent.Message{{Role: "system", Content: systemPrompt}}
@@ -156,6 +166,8 @@ func (c *openRouterClient) GenerateResponse(ctx context.Context, history []Messa
 		return "", fmt.Errorf("erro ao fazer unmarshal da resposta: %w", err)
 	}
 
+	c.lastUsage = openRouterResp.Usage.toUsage()
+
 	if openRouterResp.Error != nil {
 		return "", fmt.Errorf("erro da API OpenRouter: %s", openRouterResp.Error.Message)
 	}
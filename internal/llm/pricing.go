@@ -0,0 +1,47 @@
+package llm
+
+import "github.com/matheusbuniotto/goagent/pkg/agent"
+
+// ModelPrice é o preço de um modelo em dólares por milhão de tokens.
+type ModelPrice struct {
+	Prompt     float64
+	Completion float64
+}
+
+// modelPrices guarda preços de referência dos modelos conhecidos. São estimativas
+// para acompanhar gastos e podem divergir da cobrança real do provedor.
+var modelPrices = map[string]ModelPrice{
+	DefaultOpenAIModel:                  {Prompt: 0.10, Completion: 0.40},
+	DefaultGeminiModel:                  {Prompt: 0.075, Completion: 0.30},
+	"openai/gpt-4.1-nano":               {Prompt: 0.10, Completion: 0.40},
+	"openai/gpt-4.1":                    {Prompt: 2.00, Completion: 8.00},
+	"anthropic/claude-3.7-sonnet":       {Prompt: 3.00, Completion: 15.00},
+	"google/gemini-2.5-flash":           {Prompt: 0.30, Completion: 2.50},
+	"google/gemini-2.5-flash-lite":      {Prompt: 0.10, Completion: 0.40},
+	"meta-llama/llama-3.1-8b-instruct":  {Prompt: 0.02, Completion: 0.03},
+	"meta-llama/llama-3.1-70b-instruct": {Prompt: 0.10, Completion: 0.28},
+}
+
+// DefaultModel retorna o modelo usado por um provedor quando nenhum é informado.
+func DefaultModel(provider string) string {
+	switch provider {
+	case "gemini":
+		return DefaultGeminiModel
+	case "openai":
+		return DefaultOpenAIModel
+	case "openrouter":
+		return DefaultOpenRouterModel
+	}
+	return ""
+}
+
+// EstimateCost calcula o custo estimado em dólares do consumo informado.
+// ok é false quando o preço do modelo não é conhecido.
+func EstimateCost(model string, usage agent.Usage) (cost float64, ok bool) {
+	price, ok := modelPrices[model]
+	if !ok {
+		return 0, false
+	}
+	cost = float64(usage.PromptTokens)*price.Prompt/1e6 + float64(usage.CompletionTokens)*price.Completion/1e6
+	return cost, true
+}
@@ -51,3 +51,67 @@ const SystemPrompt = `
 	As ferramentas disponíveis estão listadas abaixo com sua descrição:
 	CUIDADO: **Somente use a ferramenta ask_user quando for  necessário para sanar dúvidas em ações críticas.**
 	`
+
+// PlannerPrompt pede ao modelo um plano numerado para o último pedido do usuário, sem
+// executar nada ainda.
+const PlannerPrompt = `
+Antes de agir, planeje. Divida o último pedido do usuário em passos curtos, concretos e verificáveis, na ordem em que devem ser executados.
+NÃO chame ferramentas agora. Responda SOMENTE com a lista numerada, um passo por linha, no formato:
+1. <passo>
+2. <passo>
+Use poucos passos (em geral de 2 a 7). Cada passo deve dizer o que fazer e qual resultado esperar.
+`
+
+// PlanStepPrompt pede a execução de um passo do plano. Recebe o número do passo, a
+// descrição e o plano atual.
+const PlanStepPrompt = `Execute agora SOMENTE o passo %d do plano: %s
+Use as ferramentas necessárias. Quando o passo estiver concluído, responda sem chamar ferramentas com um resumo curto do que foi feito e do resultado obtido.
+
+%s`
+
+// PlanVerifyPrompt pede que o modelo confira se o passo foi de fato concluído. Recebe o
+// número do passo e a descrição.
+const PlanVerifyPrompt = `
+Verifique, com base nos resultados das ferramentas acima, se o passo %d ("%s") foi realmente concluído e produziu o resultado esperado. Não confie apenas no resumo: procure erros, saídas vazias ou inconsistências.
+NÃO chame ferramentas. Responda exatamente no formato:
+VERIFICADO: sim ou não
+MOTIVO: <uma frase>
+`
+
+// PlanReplanPrompt pede um novo plano depois de uma falha. Recebe o plano atual, o
+// número do passo que falhou e o motivo.
+const PlanReplanPrompt = `
+O plano abaixo falhou no passo %[2]d: %[3]s
+
+%[1]s
+
+Considere o que já foi feito e por que o passo falhou, e escreva os passos que faltam para concluir o pedido do usuário, contornando a falha (por outro caminho, com outra ferramenta ou corrigindo a causa).
+NÃO chame ferramentas agora e não repita passos já concluídos. Responda SOMENTE com a lista numerada, um passo por linha, no formato:
+1. <passo>
+2. <passo>
+`
+
+// PlanFinishPrompt pede a resposta final depois que todos os passos foram concluídos.
+const PlanFinishPrompt = `Todos os passos do plano foram concluídos e verificados. Responda agora ao usuário, sem chamar ferramentas, com o resultado final do pedido original.
+
+%s`
+
+// ReActPrompt define o formato Thought/Action/Observation do modo ReAct. Substitui o
+// formato TOOL_CALL do prompt do sistema.
+const ReActPrompt = `
+Trabalhe no formato ReAct: a cada resposta, pense e então faça UMA ação ou dê a resposta final. NÃO use o formato TOOL_CALL.
+Cada resposta deve ter EXATAMENTE uma destas duas formas:
+
+Thought: <seu raciocínio sobre o que já sabe e o que falta>
+Action: nome_da_ferramenta({"arg": "valor"})
+
+ou
+
+Thought: <por que você já pode responder>
+Final Answer: <resposta para o usuário>
+
+Regras:
+- O argumento da Action DEVE ser um objeto JSON válido; sem argumentos, use {}.
+- Depois de uma Action, PARE: o resultado chegará na próxima mensagem como "Observation: ...". Nunca escreva a Observation você mesmo.
+- Uma Observation que começa com "ERRO:" indica que a ação falhou ou que a resposta não seguiu o formato; corrija e continue.
+`
@@ -0,0 +1,235 @@
+// Package session persiste conversas do GoAgent em disco para que possam ser
+// listadas, inspecionadas e retomadas depois.
+package session
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/matheusbuniotto/goagent/internal/todo"
+	"github.com/matheusbuniotto/goagent/pkg/agent"
+)
+
+// ErrNotFound é retornado quando nenhuma sessão corresponde ao ID informado.
+var ErrNotFound = errors.New("sessão não encontrada")
+
+// Session é uma conversa salva.
+type Session struct {
+	ID         string            `json:"id"`
+	CreatedAt  time.Time         `json:"created_at"`
+	UpdatedAt  time.Time         `json:"updated_at"`
+	Provider   string            `json:"provider"`
+	Model      string            `json:"model,omitempty"`
+	History    []agent.Message   `json:"history"`
+	Todos      []todo.Item       `json:"todos,omitempty"`
+	TaskPlan   *agent.TaskPlan   `json:"task_plan,omitempty"`  // plano do último pedido no modo planner
+	Trajectory *agent.Trajectory `json:"trajectory,omitempty"` // trajetória do último pedido no modo react
+}
+
+// New cria uma sessão vazia com um ID novo.
+func New(provider, model string) *Session {
+	now := time.Now()
+	return &Session{
+		ID:        newID(now),
+		CreatedAt: now,
+		UpdatedAt: now,
+		Provider:  provider,
+		Model:     model,
+		History:   []agent.Message{},
+	}
+}
+
+// Title resume a sessão pela primeira mensagem do usuário.
+func (s *Session) Title() string {
+	for _, msg := range s.History {
+		if msg.Role != "user" || strings.HasPrefix(msg.Content, "TOOL_") {
+			continue
+		}
+		title := strings.Join(strings.Fields(msg.Content), " ")
+		if r := []rune(title); len(r) > 60 {
+			title = string(r[:57]) + "..."
+		}
+		return title
+	}
+	return "(vazia)"
+}
+
+// newID gera um ID ordenável por data com um sufixo aleatório, ex: 20250102-150405-a1b2.
+func newID(t time.Time) string {
+	suffix := make([]byte, 2)
+	_, _ = rand.Read(suffix)
+	return t.Format("20060102-150405") + "-" + hex.EncodeToString(suffix)
+}
+
+// DefaultDir retorna o diretório base do GoAgent: $GOAGENT_HOME ou ~/.goagent.
+func DefaultDir() (string, error) {
+	if dir := os.Getenv("GOAGENT_HOME"); dir != "" {
+		return dir, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("erro ao localizar o diretório home: %w", err)
+	}
+	return filepath.Join(home, ".goagent"), nil
+}
+
+// Store guarda sessões como arquivos JSON em um diretório.
+type Store struct {
+	dir string
+}
+
+// NewStore cria um Store no diretório informado. O diretório é criado no primeiro Save.
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// DefaultStore retorna o Store em <DefaultDir>/sessions.
+func DefaultStore() (*Store, error) {
+	base, err := DefaultDir()
+	if err != nil {
+		return nil, err
+	}
+	return NewStore(filepath.Join(base, "sessions")), nil
+}
+
+// Dir retorna o diretório onde as sessões são gravadas.
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+func (s *Store) path(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
+
+// Save grava a sessão de forma atômica e atualiza UpdatedAt.
+func (s *Store) Save(sess *Session) error {
+	if err := validateID(sess.ID); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return fmt.Errorf("erro ao criar diretório de sessões: %w", err)
+	}
+	sess.UpdatedAt = time.Now()
+	data, err := json.MarshalIndent(sess, "", "  ")
+	if err != nil {
+		return fmt.Errorf("erro ao codificar sessão: %w", err)
+	}
+
+	tmp := s.path(sess.ID) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("erro ao salvar sessão '%s': %w", sess.ID, err)
+	}
+	if err := os.Rename(tmp, s.path(sess.ID)); err != nil {
+		return fmt.Errorf("erro ao salvar sessão '%s': %w", sess.ID, err)
+	}
+	return nil
+}
+
+// Load carrega uma sessão pelo ID completo ou por um prefixo único dele.
+func (s *Store) Load(id string) (*Session, error) {
+	fullID, err := s.resolve(id)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(s.path(fullID))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler sessão '%s': %w", fullID, err)
+	}
+	var sess Session
+	if err := json.Unmarshal(data, &sess); err != nil {
+		return nil, fmt.Errorf("sessão '%s' corrompida: %w", fullID, err)
+	}
+	return &sess, nil
+}
+
+// List retorna todas as sessões, da mais recente para a mais antiga.
+func (s *Store) List() ([]*Session, error) {
+	ids, err := s.ids()
+	if err != nil {
+		return nil, err
+	}
+	sessions := make([]*Session, 0, len(ids))
+	for _, id := range ids {
+		sess, err := s.Load(id)
+		if err != nil {
+			continue // Ignora arquivos corrompidos em vez de esconder as demais sessões
+		}
+		sessions = append(sessions, sess)
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].UpdatedAt.After(sessions[j].UpdatedAt) })
+	return sessions, nil
+}
+
+// Delete remove uma sessão pelo ID completo ou por um prefixo único dele.
+func (s *Store) Delete(id string) error {
+	fullID, err := s.resolve(id)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(s.path(fullID)); err != nil {
+		return fmt.Errorf("erro ao remover sessão '%s': %w", fullID, err)
+	}
+	return nil
+}
+
+// ids lista os IDs das sessões gravadas no diretório.
+func (s *Store) ids() ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar sessões: %w", err)
+	}
+	var ids []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		ids = append(ids, strings.TrimSuffix(name, ".json"))
+	}
+	return ids, nil
+}
+
+// resolve transforma um ID ou prefixo no ID completo de uma sessão existente.
+func (s *Store) resolve(id string) (string, error) {
+	if err := validateID(id); err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(s.path(id)); err == nil {
+		return id, nil
+	}
+	ids, err := s.ids()
+	if err != nil {
+		return "", err
+	}
+	var matches []string
+	for _, candidate := range ids {
+		if strings.HasPrefix(candidate, id) {
+			matches = append(matches, candidate)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("%w: '%s'", ErrNotFound, id)
+	case 1:
+		return matches[0], nil
+	}
+	return "", fmt.Errorf("ID '%s' é ambíguo: corresponde a %s", id, strings.Join(matches, ", "))
+}
+
+func validateID(id string) error {
+	if id == "" || strings.ContainsAny(id, `/\`) || strings.Contains(id, "..") {
+		return fmt.Errorf("ID de sessão inválido: '%s'", id)
+	}
+	return nil
+}
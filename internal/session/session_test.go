@@ -0,0 +1,58 @@
+package session
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/matheusbuniotto/goagent/pkg/agent"
+)
+
+// TestStoreRoundTrip testa salvar, listar, carregar por prefixo e remover sessões
+func TestStoreRoundTrip(t *testing.T) {
+	store := NewStore(t.TempDir())
+
+	sess := New("openrouter", "openai/gpt-4.1-nano")
+	sess.History = append(sess.History,
+		agent.Message{Role: "user", Content: "Liste os arquivos do projeto"},
+		agent.Message{Role: "assistant", Content: "Pronto."},
+	)
+	if err := store.Save(sess); err != nil {
+		t.Fatalf("Save() erro inesperado: %v", err)
+	}
+
+	sessions, err := store.List()
+	if err != nil {
+		t.Fatalf("List() erro inesperado: %v", err)
+	}
+	if len(sessions) != 1 || sessions[0].ID != sess.ID {
+		t.Fatalf("List() = %v, esperado apenas %s", sessions, sess.ID)
+	}
+
+	loaded, err := store.Load(sess.ID[:10])
+	if err != nil {
+		t.Fatalf("Load() por prefixo erro inesperado: %v", err)
+	}
+	if loaded.Title() != "Liste os arquivos do projeto" {
+		t.Errorf("Title() = %q", loaded.Title())
+	}
+	if len(loaded.History) != 2 || loaded.Model != sess.Model {
+		t.Errorf("Load() sessão diferente da salva: %+v", loaded)
+	}
+
+	if err := store.Delete(sess.ID); err != nil {
+		t.Fatalf("Delete() erro inesperado: %v", err)
+	}
+	if _, err := store.Load(sess.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Load() após Delete() erro = %v, esperado ErrNotFound", err)
+	}
+}
+
+// TestStoreRejectsInvalidIDs garante que IDs não escapem do diretório de sessões
+func TestStoreRejectsInvalidIDs(t *testing.T) {
+	store := NewStore(t.TempDir())
+	for _, id := range []string{"", "../etc/passwd", "a/b"} {
+		if _, err := store.Load(id); err == nil {
+			t.Errorf("Load(%q) deveria retornar erro", id)
+		}
+	}
+}
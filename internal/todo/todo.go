@@ -0,0 +1,215 @@
+// Package todo mantém a lista de tarefas que o agente usa para acompanhar pedidos com
+// várias etapas. A lista pertence à sessão e é gravada junto com ela.
+package todo
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Status é a situação de uma tarefa.
+type Status string
+
+const (
+	Pending    Status = "pending"
+	InProgress Status = "in_progress"
+	Done       Status = "done"
+	Cancelled  Status = "cancelled"
+)
+
+// ParseStatus valida o status informado pelo modelo. Aceita também alguns sinônimos.
+func ParseStatus(s string) (Status, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "pending", "todo", "pendente":
+		return Pending, nil
+	case "in_progress", "in-progress", "doing", "em_andamento":
+		return InProgress, nil
+	case "done", "completed", "concluida", "concluída":
+		return Done, nil
+	case "cancelled", "canceled", "cancelada":
+		return Cancelled, nil
+	}
+	return "", fmt.Errorf("status inválido '%s' (use pending, in_progress, done ou cancelled)", s)
+}
+
+// marker é o símbolo de cada status na lista renderizada.
+func (s Status) marker() string {
+	switch s {
+	case InProgress:
+		return "[~]"
+	case Done:
+		return "[x]"
+	case Cancelled:
+		return "[-]"
+	}
+	return "[ ]"
+}
+
+// Item é uma tarefa da lista.
+type Item struct {
+	ID     int    `json:"id"`
+	Text   string `json:"text"`
+	Status Status `json:"status"`
+}
+
+// List é a lista de tarefas de uma sessão. É segura para uso concorrente.
+type List struct {
+	mu       sync.Mutex
+	items    []Item
+	nextID   int
+	onChange func(*List)
+}
+
+// New cria uma lista com as tarefas informadas, por exemplo as de uma sessão retomada.
+func New(items []Item) *List {
+	l := &List{nextID: 1}
+	for _, item := range items {
+		l.items = append(l.items, item)
+		if item.ID >= l.nextID {
+			l.nextID = item.ID + 1
+		}
+	}
+	return l
+}
+
+// OnChange registra uma função chamada depois de cada alteração da lista.
+func (l *List) OnChange(fn func(*List)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.onChange = fn
+}
+
+// changed avisa o observador, fora do lock para que ele possa ler a lista.
+func (l *List) changed() {
+	l.mu.Lock()
+	fn := l.onChange
+	l.mu.Unlock()
+	if fn != nil {
+		fn(l)
+	}
+}
+
+// Add acrescenta tarefas pendentes ao fim da lista e as retorna com seus IDs.
+func (l *List) Add(texts ...string) ([]Item, error) {
+	var added []Item
+	l.mu.Lock()
+	for _, text := range texts {
+		text = strings.Join(strings.Fields(text), " ")
+		if text == "" {
+			l.mu.Unlock()
+			return nil, fmt.Errorf("o texto da tarefa não pode ser vazio")
+		}
+		added = append(added, Item{ID: l.nextID + len(added), Text: text, Status: Pending})
+	}
+	l.items = append(l.items, added...)
+	l.nextID += len(added)
+	l.mu.Unlock()
+
+	if len(added) > 0 {
+		l.changed()
+	}
+	return added, nil
+}
+
+// Update altera o status e, se text não for vazio, o texto da tarefa id.
+func (l *List) Update(id int, status Status, text string) (Item, error) {
+	l.mu.Lock()
+	i := l.index(id)
+	if i < 0 {
+		l.mu.Unlock()
+		return Item{}, fmt.Errorf("tarefa %d não existe", id)
+	}
+	if status != "" {
+		l.items[i].Status = status
+	}
+	if text = strings.Join(strings.Fields(text), " "); text != "" {
+		l.items[i].Text = text
+	}
+	item := l.items[i]
+	l.mu.Unlock()
+
+	l.changed()
+	return item, nil
+}
+
+// Remove apaga a tarefa id da lista.
+func (l *List) Remove(id int) error {
+	l.mu.Lock()
+	i := l.index(id)
+	if i < 0 {
+		l.mu.Unlock()
+		return fmt.Errorf("tarefa %d não existe", id)
+	}
+	l.items = append(l.items[:i], l.items[i+1:]...)
+	l.mu.Unlock()
+
+	l.changed()
+	return nil
+}
+
+// Clear apaga todas as tarefas. Os IDs continuam crescendo para não confundir o modelo.
+func (l *List) Clear() {
+	l.mu.Lock()
+	empty := len(l.items) == 0
+	l.items = nil
+	l.mu.Unlock()
+
+	if !empty {
+		l.changed()
+	}
+}
+
+func (l *List) index(id int) int {
+	for i, item := range l.items {
+		if item.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// Items retorna uma cópia das tarefas, na ordem em que foram criadas.
+func (l *List) Items() []Item {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]Item(nil), l.items...)
+}
+
+// Progress retorna quantas tarefas foram concluídas e quantas contam para o total.
+// Tarefas canceladas não contam.
+func (l *List) Progress() (done, total int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, item := range l.items {
+		switch item.Status {
+		case Done:
+			done++
+			total++
+		case Cancelled:
+		default:
+			total++
+		}
+	}
+	return done, total
+}
+
+// Summary resume o progresso, ex: "3/7 concluídas".
+func (l *List) Summary() string {
+	done, total := l.Progress()
+	return fmt.Sprintf("%d/%d concluídas", done, total)
+}
+
+// String renderiza a lista, uma tarefa por linha, com o resumo do progresso no início.
+func (l *List) String() string {
+	items := l.Items()
+	if len(items) == 0 {
+		return "Lista de tarefas vazia."
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Tarefas (%s):", l.Summary())
+	for _, item := range items {
+		fmt.Fprintf(&b, "\n%s %d. %s", item.Status.marker(), item.ID, item.Text)
+	}
+	return b.String()
+}
@@ -0,0 +1,67 @@
+package todo
+
+import "testing"
+
+// TestList testa inclusão, atualização, progresso e renderização da lista.
+func TestList(t *testing.T) {
+	l := New(nil)
+	changes := 0
+	l.OnChange(func(*List) { changes++ })
+
+	added, err := l.Add("Ler o código", "  Escrever\ntestes ", "Atualizar o README")
+	if err != nil || len(added) != 3 || added[2].ID != 3 || added[1].Text != "Escrever testes" {
+		t.Fatalf("Add() = %v, %v", added, err)
+	}
+	if _, err := l.Update(1, Done, ""); err != nil {
+		t.Fatalf("Update() erro inesperado: %v", err)
+	}
+	if _, err := l.Update(2, InProgress, "Escrever testes de unidade"); err != nil {
+		t.Fatalf("Update() erro inesperado: %v", err)
+	}
+	if _, err := l.Update(3, Cancelled, ""); err != nil {
+		t.Fatalf("Update() erro inesperado: %v", err)
+	}
+	if _, err := l.Update(9, Done, ""); err == nil || err.Error() != "tarefa 9 não existe" {
+		t.Errorf("Update(9) erro = %v", err)
+	}
+	if _, err := l.Add(" "); err == nil {
+		t.Error("Add() com texto vazio deveria falhar")
+	}
+
+	expected := "Tarefas (1/2 concluídas):\n" +
+		"[x] 1. Ler o código\n" +
+		"[~] 2. Escrever testes de unidade\n" +
+		"[-] 3. Atualizar o README"
+	if got := l.String(); got != expected {
+		t.Errorf("String() =\n%s\nesperado\n%s", got, expected)
+	}
+	if changes != 4 {
+		t.Errorf("OnChange chamado %d vezes, esperado 4", changes)
+	}
+
+	// Ao retomar a sessão, os IDs continuam de onde pararam.
+	resumed := New(l.Items())
+	if err := resumed.Remove(2); err != nil {
+		t.Fatalf("Remove() erro inesperado: %v", err)
+	}
+	if added, _ := resumed.Add("Publicar"); added[0].ID != 4 {
+		t.Errorf("ID após retomar = %d, esperado 4", added[0].ID)
+	}
+	resumed.Clear()
+	if got := resumed.String(); got != "Lista de tarefas vazia." {
+		t.Errorf("String() após Clear = %q", got)
+	}
+}
+
+// TestParseStatus testa os status aceitos e a mensagem de erro.
+func TestParseStatus(t *testing.T) {
+	testCases := map[string]Status{"done": Done, "Concluída": Done, "in-progress": InProgress, "todo": Pending, "canceled": Cancelled}
+	for input, expected := range testCases {
+		if got, err := ParseStatus(input); err != nil || got != expected {
+			t.Errorf("ParseStatus(%q) = %q, %v; esperado %q", input, got, err, expected)
+		}
+	}
+	if _, err := ParseStatus("feito"); err == nil {
+		t.Error("ParseStatus(\"feito\") deveria falhar")
+	}
+}
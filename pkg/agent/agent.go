@@ -2,8 +2,12 @@ package agent
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"os"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
 
@@ -27,11 +31,49 @@ type Tool interface {
 	Execute(args string) (string, error)
 }
 
+// ContextTool é implementado pelas ferramentas que aceitam cancelamento. O agente usa
+// ExecuteContext quando disponível, para que o ctrl-c interrompa a ferramenta em andamento.
+type ContextTool interface {
+	Tool
+	ExecuteContext(ctx context.Context, args string) (string, error)
+}
+
 // LLMClient é a interface para comunicação com qualquer Large Language Model.
 type LLMClient interface {
 	GenerateResponse(ctx context.Context, history []Message, tools []Tool) (string, error)
 }
 
+// Usage registra o consumo de tokens das chamadas ao LLM.
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	Calls            int `json:"calls"`
+}
+
+// Add soma o consumo de outra medição.
+func (u Usage) Add(other Usage) Usage {
+	return Usage{
+		PromptTokens:     u.PromptTokens + other.PromptTokens,
+		CompletionTokens: u.CompletionTokens + other.CompletionTokens,
+		Calls:            u.Calls + other.Calls,
+	}
+}
+
+// Sub retorna a diferença entre duas medições acumuladas.
+func (u Usage) Sub(other Usage) Usage {
+	return Usage{
+		PromptTokens:     u.PromptTokens - other.PromptTokens,
+		CompletionTokens: u.CompletionTokens - other.CompletionTokens,
+		Calls:            u.Calls - other.Calls,
+	}
+}
+
+// UsageReporter é implementado pelos clientes que informam o consumo de tokens
+// da última chamada a GenerateResponse.
+type UsageReporter interface {
+	LastUsage() Usage
+}
+
 // BuildSystemPrompt cria o prompt do sistema que instrui o LLM.
 func BuildSystemPrompt(tools []Tool) string {
 	prompt := prompts.SystemPrompt + "\n"
@@ -55,12 +97,30 @@ func BuildReasoningPrompt(tools []Tool) string {
 // Implementação do Agente
 // =================================================================================================
 
+// DefaultMaxIterations é o número máximo de chamadas ao LLM permitidas em um único turno.
+const DefaultMaxIterations = 25
+
+// ErrMaxIterations é retornado quando o agente excede o limite de iterações de um turno,
+// normalmente porque o modelo entrou em um loop de chamadas de ferramentas.
+var ErrMaxIterations = errors.New("limite de iterações atingido sem resposta final")
+
 // Agent é a estrutura principal que orquestra todo o processo.
 type Agent struct {
 	llmClient     LLMClient
 	tools         map[string]Tool
 	history       []Message
 	toolCallRegex *regexp.Regexp
+	out           io.Writer
+	maxIterations int
+	disabled      map[string]bool
+	usage         Usage
+	approval      *ApprovalPolicy
+	dryRun        bool
+	plan          []PlannedChange
+	extraContext  func() string
+	taskPlan      *TaskPlan
+	maxReplans    int
+	trajectory    *Trajectory
 }
 
 // NewAgent cria uma nova instância do agente.
@@ -76,64 +136,272 @@ func NewAgent(client LLMClient, tools []Tool) *Agent {
 		history:   []Message{},
 		// Regex atualizado para ser "ganancioso" e capturar objetos JSON complexos
 		toolCallRegex: regexp.MustCompile(`TOOL_CALL:\s*(\w+)\((.*)\)`),
+		out:           os.Stdout,
+		maxIterations: DefaultMaxIterations,
+		disabled:      make(map[string]bool),
+		maxReplans:    DefaultMaxReplans,
+	}
+}
+
+// SetOutput define onde o agente escreve o progresso e as respostas (padrão: os.Stdout).
+func (a *Agent) SetOutput(w io.Writer) {
+	if w == nil {
+		w = io.Discard
+	}
+	a.out = w
+}
+
+// SetMaxIterations define o limite de chamadas ao LLM por turno. Valores <= 0 restauram o padrão.
+func (a *Agent) SetMaxIterations(n int) {
+	if n <= 0 {
+		n = DefaultMaxIterations
+	}
+	a.maxIterations = n
+}
+
+// SetLLMClient troca o cliente do LLM (por exemplo, outro modelo) mantendo o histórico.
+func (a *Agent) SetLLMClient(client LLMClient) {
+	a.llmClient = client
+}
+
+// SetApprovalPolicy define a política que decide se cada chamada de ferramenta pode ser
+// executada. Sem política, todas as chamadas são executadas.
+func (a *Agent) SetApprovalPolicy(p *ApprovalPolicy) {
+	a.approval = p
+}
+
+// ApprovalPolicy retorna a política de aprovação em uso, ou nil.
+func (a *Agent) ApprovalPolicy() *ApprovalPolicy {
+	return a.approval
+}
+
+// SetContextProvider define uma função chamada antes de cada chamada ao LLM cujo texto,
+// se não for vazio, é enviado como mensagem de sistema no fim do histórico sem ser gravado
+// nele. É usado, por exemplo, para mostrar ao modelo a lista de tarefas atual.
+func (a *Agent) SetContextProvider(fn func() string) {
+	a.extraContext = fn
+}
+
+// requestHistory retorna uma cópia do histórico enviado ao LLM: o da conversa mais o
+// contexto extra.
+func (a *Agent) requestHistory() []Message {
+	history := a.History()
+	if a.extraContext == nil {
+		return history
+	}
+	if extra := a.extraContext(); extra != "" {
+		history = append(history, Message{Role: "system", Content: extra})
+	}
+	return history
+}
+
+// Usage retorna o consumo de tokens acumulado desde a criação do agente.
+func (a *Agent) Usage() Usage {
+	return a.usage
+}
+
+// recordUsage acumula o consumo da última chamada, se o cliente o informar.
+func (a *Agent) recordUsage() {
+	if reporter, ok := a.llmClient.(UsageReporter); ok {
+		a.usage = a.usage.Add(reporter.LastUsage())
+	}
+}
+
+// ToolNames retorna o nome de todas as ferramentas registradas, ativas ou não, em ordem alfabética.
+func (a *Agent) ToolNames() []string {
+	names := make([]string, 0, len(a.tools))
+	for name := range a.tools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ToolEnabled indica se a ferramenta está disponível para o modelo.
+func (a *Agent) ToolEnabled(name string) bool {
+	_, ok := a.tools[name]
+	return ok && !a.disabled[name]
+}
+
+// SetToolEnabled ativa ou desativa uma ferramenta. Ferramentas desativadas somem do prompt
+// e chamadas a elas retornam erro para o modelo.
+func (a *Agent) SetToolEnabled(name string, enabled bool) error {
+	if _, ok := a.tools[name]; !ok {
+		return fmt.Errorf("ferramenta '%s' não encontrada", name)
+	}
+	if enabled {
+		delete(a.disabled, name)
+	} else {
+		a.disabled[name] = true
+	}
+	return nil
+}
+
+// History retorna uma cópia do histórico da conversa.
+func (a *Agent) History() []Message {
+	return append([]Message(nil), a.history...)
+}
+
+// SetHistory substitui o histórico da conversa, por exemplo ao retomar uma sessão salva.
+func (a *Agent) SetHistory(history []Message) {
+	a.history = append([]Message{}, history...)
+}
+
+// toolList retorna as ferramentas ativas em ordem alfabética, para que o prompt seja estável.
+func (a *Agent) toolList() []Tool {
+	allTools := make([]Tool, 0, len(a.tools))
+	for name, t := range a.tools {
+		if !a.disabled[name] {
+			allTools = append(allTools, t)
+		}
 	}
+	sort.Slice(allTools, func(i, j int) bool { return allTools[i].Name() < allTools[j].Name() })
+	return allTools
 }
 
 // Run inicia o loop de interação principal do agente.
 func (a *Agent) Run(ctx context.Context, getUserInput func() (string, bool)) error {
 	for {
-		fmt.Print("\u001b[94mHumano\u001b[0m: ")
+		fmt.Fprint(a.out, "\u001b[94mHumano\u001b[0m: ")
 		userInput, ok := getUserInput()
 		if !ok {
 			break
 		}
 
 		a.history = append(a.history, Message{Role: "user", Content: userInput})
+		if _, err := a.runTurn(ctx); err != nil {
+			fmt.Fprintf(a.out, "\u001b[91mErro: %v\u001b[0m\n", err)
+		}
+	}
+	return nil
+}
+
+// RunOnce executa uma única tarefa até a resposta final, sem ler entrada do usuário.
+// É usado pelo modo não interativo (scripts e CI).
+func (a *Agent) RunOnce(ctx context.Context, prompt string) (string, error) {
+	a.history = append(a.history, Message{Role: "user", Content: prompt})
+	return a.runTurn(ctx)
+}
+
+// RunOnceWithReasoning é como RunOnce, mas gera um trace de raciocínio antes de executar a tarefa.
+func (a *Agent) RunOnceWithReasoning(ctx context.Context, prompt string) (string, error) {
+	if err := a.addReasoning(ctx, prompt); err != nil {
+		return "", fmt.Errorf("erro ao gerar raciocínio: %w", err)
+	}
+	return a.RunOnce(ctx, prompt)
+}
+
+// runTurn executa o loop LLM -> ferramenta -> LLM até que o modelo responda sem chamar
+// ferramentas. Retorna a resposta final ou o erro que interrompeu o turno.
+func (a *Agent) runTurn(ctx context.Context) (string, error) {
+	allTools := a.toolList()
+	for i := 0; i < a.maxIterations; i++ {
+		if err := ctx.Err(); err != nil {
+			return "", fmt.Errorf("turno cancelado: %w", err)
+		}
 
-		for {
-			fmt.Println("\u001b[90mGoAgent está processando a mensagem...\u001b[0m")
-			allTools := make([]Tool, 0, len(a.tools))
-			for _, t := range a.tools {
-				allTools = append(allTools, t)
+		fmt.Fprintln(a.out, "\u001b[90mGoAgent está processando a mensagem...\u001b[0m")
+		llmResponse, err := a.llmClient.GenerateResponse(ctx, a.requestHistory(), allTools)
+		if err != nil {
+			if ctx.Err() != nil {
+				return "", fmt.Errorf("turno cancelado: %w", ctx.Err())
 			}
+			return "", fmt.Errorf("erro ao chamar LLM: %w", err)
+		}
+		a.recordUsage()
 
-			llmResponse, err := a.llmClient.GenerateResponse(ctx, a.history, allTools)
+		matches := a.toolCallRegex.FindStringSubmatch(llmResponse)
+		if len(matches) == 3 {
+			a.history = append(a.history, Message{Role: "assistant", Content: llmResponse})
+			content, failed, err := a.dispatchTool(ctx, matches[1], matches[2])
+			if failed {
+				a.history = append(a.history, Message{Role: "user", Content: "TOOL_ERROR: " + content})
+			} else {
+				a.history = append(a.history, Message{Role: "user", Content: "TOOL_RESULT: " + content})
+			}
 			if err != nil {
-				fmt.Printf("\u001b[91mErro ao chamar LLM: %v\u001b[0m\n", err)
-				break
+				return "", err
 			}
+			continue
+		}
 
-			matches := a.toolCallRegex.FindStringSubmatch(llmResponse)
-			if len(matches) == 3 {
-				toolName := matches[1]
-				toolArgs := matches[2]
-
-				fmt.Printf("\u001b[95mGoAgent quer usar a ferramenta: %s(%s)\u001b[0m\n", toolName, toolArgs)
-				a.history = append(a.history, Message{Role: "assistant", Content: llmResponse})
-
-				tool, ok := a.tools[toolName]
-				if !ok {
-					fmt.Printf("\u001b[91mErro: Agente tentou usar uma ferramenta desconhecida: %s\u001b[0m\n", toolName)
-					a.history = append(a.history, Message{Role: "user", Content: fmt.Sprintf("TOOL_ERROR: Ferramenta '%s' não encontrada.", toolName)})
-					continue
-				}
-
-				toolResult, err := tool.Execute(toolArgs)
-				if err != nil {
-					fmt.Printf("\u001b[91mErro ao executar a ferramenta '%s': %v\u001b[0m\n", toolName, err)
-					a.history = append(a.history, Message{Role: "user", Content: fmt.Sprintf("TOOL_ERROR: %v", err)})
-					continue
-				}
-
-				fmt.Printf("\u001b[96mResultado da ferramenta: %s\u001b[0m\n", toolResult)
-				a.history = append(a.history, Message{Role: "user", Content: fmt.Sprintf("TOOL_RESULT: %s", toolResult)})
-				continue
-			}
+		fmt.Fprintf(a.out, "\u001b[92mGoAgent\u001b[0m: %s\n", llmResponse)
+		a.history = append(a.history, Message{Role: "assistant", Content: llmResponse})
+		return llmResponse, nil
+	}
+	return "", fmt.Errorf("%w (%d iterações)", ErrMaxIterations, a.maxIterations)
+}
 
-			fmt.Printf("\u001b[92mGoAgent\u001b[0m: %s\n", llmResponse)
-			a.history = append(a.history, Message{Role: "assistant", Content: llmResponse})
-			break
+// dispatchTool executa a chamada de ferramenta pedida pelo modelo, passando pelo dry-run e
+// pela política de aprovação, e mostra o progresso. Retorna o resultado ou, com failed, a
+// mensagem de erro para o modelo. err só é retornado quando o turno deve ser interrompido.
+func (a *Agent) dispatchTool(ctx context.Context, toolName, toolArgs string) (content string, failed bool, err error) {
+	fmt.Fprintf(a.out, "\u001b[95mGoAgent quer usar a ferramenta: %s(%s)\u001b[0m\n", toolName, toolArgs)
+
+	tool, ok := a.tools[toolName]
+	if ok && a.disabled[toolName] {
+		fmt.Fprintf(a.out, "\u001b[91mErro: Agente tentou usar uma ferramenta desativada: %s\u001b[0m\n", toolName)
+		return fmt.Sprintf("Ferramenta '%s' está desativada nesta sessão.", toolName), true, nil
+	}
+	if !ok {
+		fmt.Fprintf(a.out, "\u001b[91mErro: Agente tentou usar uma ferramenta desconhecida: %s\u001b[0m\n", toolName)
+		return fmt.Sprintf("Ferramenta '%s' não encontrada.", toolName), true, nil
+	}
+
+	if a.dryRun && isMutating(tool, toolArgs) {
+		toolResult, err := a.simulate(tool, toolArgs)
+		if err != nil {
+			fmt.Fprintf(a.out, "\u001b[91mErro ao simular a ferramenta '%s': %v\u001b[0m\n", toolName, err)
+			return err.Error(), true, nil
 		}
+		fmt.Fprintf(a.out, "\u001b[93m%s\u001b[0m\n", toolResult)
+		return toolResult, false, nil
+	}
+
+	if a.approval != nil {
+		if err := a.approval.Check(ctx, tool, toolArgs); err != nil {
+			if ctx.Err() != nil {
+				return fmt.Sprintf("execução de '%s' cancelada pelo usuário", toolName), true, fmt.Errorf("turno cancelado: %w", ctx.Err())
+			}
+			fmt.Fprintf(a.out, "\u001b[93mChamada negada: %v\u001b[0m\n", err)
+			return fmt.Sprintf("%v. A ação não foi executada; não repita a mesma chamada, explique ao usuário ou siga por outro caminho.", err), true, nil
+		}
+	}
+
+	toolResult, err := executeTool(ctx, tool, toolArgs)
+	if err != nil && ctx.Err() != nil {
+		fmt.Fprintf(a.out, "\u001b[93mFerramenta '%s' interrompida.\u001b[0m\n", toolName)
+		return fmt.Sprintf("execução de '%s' cancelada pelo usuário", toolName), true, fmt.Errorf("turno cancelado: %w", ctx.Err())
+	}
+	if err != nil {
+		fmt.Fprintf(a.out, "\u001b[91mErro ao executar a ferramenta '%s': %v\u001b[0m\n", toolName, err)
+		return err.Error(), true, nil
+	}
+
+	fmt.Fprintf(a.out, "\u001b[96mResultado da ferramenta: %s\u001b[0m\n", toolResult)
+	return toolResult, false, nil
+}
+
+// executeTool executa a ferramenta repassando o contexto quando ela o suporta.
+func executeTool(ctx context.Context, tool Tool, args string) (string, error) {
+	if ct, ok := tool.(ContextTool); ok {
+		return ct.ExecuteContext(ctx, args)
+	}
+	return tool.Execute(args)
+}
+
+// addReasoning gera um raciocínio para a entrada do usuário e o insere no histórico.
+func (a *Agent) addReasoning(ctx context.Context, userInput string) error {
+	reasoning, err := GenerateReasoningTrace(ctx, a.llmClient, userInput, a.requestHistory(), a.toolList())
+	if err != nil {
+		return err
+	}
+	a.recordUsage()
+	if reasoning != "" {
+		fmt.Fprintln(a.out, "\u001b[96mRaciocínio do agente:\u001b[0m")
+		fmt.Fprintln(a.out, reasoning)
+		// Adiciona o raciocínio ao histórico como mensagem de sistema
+		a.history = append(a.history, Message{Role: "system", Content: "Raciocínio para solução:\n" + reasoning})
 	}
 	return nil
 }
@@ -141,71 +409,24 @@ func (a *Agent) Run(ctx context.Context, getUserInput func() (string, bool)) err
 // RunWithReasoning executa o agente "padrão", mas antes insere um raciocínio gerado no histórico.
 func (a *Agent) RunWithReasoning(ctx context.Context, getUserInput func() (string, bool)) error {
 	for {
-		fmt.Print("\u001b[94mHumano\u001b[0m: ") // Garante o mesmo prompt do modo regular
+		fmt.Fprint(a.out, "\u001b[94mHumano\u001b[0m: ") // Garante o mesmo prompt do modo regular
 		userInput, ok := getUserInput()
 		if !ok {
 			break
 		}
 
 		// 1. Gera raciocínio usando o helper do ReasoningAgent
-		allTools := make([]Tool, 0, len(a.tools))
-		for _, t := range a.tools {
-			allTools = append(allTools, t)
-		}
-		reasoning, err := GenerateReasoningTrace(ctx, a.llmClient, userInput, a.history, allTools)
-		if err != nil {
-			fmt.Printf("\u001b[91mErro ao gerar raciocínio: %v\u001b[0m\n", err)
+		if err := a.addReasoning(ctx, userInput); err != nil {
+			fmt.Fprintf(a.out, "\u001b[91mErro ao gerar raciocínio: %v\u001b[0m\n", err)
 			continue
 		}
-		if reasoning != "" {
-			fmt.Println("\u001b[96mRaciocínio do agente:\u001b[0m")
-			fmt.Println(reasoning)
-			// Adiciona o raciocínio ao histórico como mensagem de sistema
-			a.history = append(a.history, Message{Role: "system", Content: "Raciocínio para solução:\n" + reasoning})
-		}
 
 		// 2. Adiciona a pergunta do usuário
 		a.history = append(a.history, Message{Role: "user", Content: userInput})
 
 		// 3. Executa o loop normal do agente
-		for {
-			fmt.Println("\u001b[90mGoAgent está processando a mensagem...\u001b[0m")
-			llmResponse, err := a.llmClient.GenerateResponse(ctx, a.history, allTools)
-			if err != nil {
-				fmt.Printf("\u001b[91mErro ao chamar LLM: %v\u001b[0m\n", err)
-				break
-			}
-
-			matches := a.toolCallRegex.FindStringSubmatch(llmResponse)
-			if len(matches) == 3 {
-				toolName := matches[1]
-				toolArgs := matches[2]
-
-				fmt.Printf("\u001b[95mGoAgent quer usar a ferramenta: %s(%s)\u001b[0m\n", toolName, toolArgs)
-				a.history = append(a.history, Message{Role: "assistant", Content: llmResponse})
-
-				tool, ok := a.tools[toolName]
-				if !ok {
-					fmt.Printf("\u001b[91mErro: Agente tentou usar uma ferramenta desconhecida: %s\u001b[0m\n", toolName)
-					a.history = append(a.history, Message{Role: "user", Content: fmt.Sprintf("TOOL_ERROR: Ferramenta '%s' não encontrada.", toolName)})
-					continue
-				}
-
-				toolResult, err := tool.Execute(toolArgs)
-				if err != nil {
-					fmt.Printf("\u001b[91mErro ao executar a ferramenta '%s': %v\u001b[0m\n", toolName, err)
-					a.history = append(a.history, Message{Role: "user", Content: fmt.Sprintf("TOOL_ERROR: %v", err)})
-					continue
-				}
-
-				fmt.Printf("\u001b[96mResultado da ferramenta: %s\u001b[0m\n", toolResult)
-				a.history = append(a.history, Message{Role: "user", Content: fmt.Sprintf("TOOL_RESULT: %s", toolResult)})
-				continue
-			}
-
-			fmt.Printf("\u001b[92mGoAgent\u001b[0m: %s\n", llmResponse)
-			a.history = append(a.history, Message{Role: "assistant", Content: llmResponse})
-			break
+		if _, err := a.runTurn(ctx); err != nil {
+			fmt.Fprintf(a.out, "\u001b[91mErro: %v\u001b[0m\n", err)
 		}
 	}
 	return nil
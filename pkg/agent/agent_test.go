@@ -0,0 +1,54 @@
+package agent
+
+import (
+	"context"
+	"testing"
+)
+
+// recordingClient guarda o histórico recebido em cada chamada.
+type recordingClient struct {
+	scriptedClient
+	requests [][]Message
+}
+
+func (c *recordingClient) GenerateResponse(ctx context.Context, history []Message, tools []Tool) (string, error) {
+	c.requests = append(c.requests, append([]Message(nil), history...))
+	return c.scriptedClient.GenerateResponse(ctx, history, tools)
+}
+
+// TestContextProvider testa que o contexto extra vai em cada chamada, sem entrar no histórico.
+func TestContextProvider(t *testing.T) {
+	client := &recordingClient{scriptedClient: scriptedClient{responses: []string{
+		`TOOL_CALL: read_file({"path": "a.txt"})`,
+		"Pronto.",
+	}}}
+	a := NewAgent(client, []Tool{fakeTool{name: "read_file"}})
+	a.SetOutput(nil)
+	calls := 0
+	a.SetContextProvider(func() string {
+		calls++
+		if calls == 1 {
+			return ""
+		}
+		return "Tarefas (0/1 concluídas)"
+	})
+
+	if _, err := a.RunOnce(context.Background(), "leia a.txt"); err != nil {
+		t.Fatalf("RunOnce() erro inesperado: %v", err)
+	}
+	if len(client.requests) != 2 {
+		t.Fatalf("%d chamadas ao LLM, esperado 2", len(client.requests))
+	}
+	if first := client.requests[0]; len(first) != 1 || first[0].Role != "user" {
+		t.Errorf("primeira chamada = %v, esperado só a mensagem do usuário", first)
+	}
+	second := client.requests[1]
+	if last := second[len(second)-1]; last.Role != "system" || last.Content != "Tarefas (0/1 concluídas)" {
+		t.Errorf("última mensagem da segunda chamada = %v", last)
+	}
+	for _, msg := range a.History() {
+		if msg.Role == "system" {
+			t.Errorf("contexto extra gravado no histórico: %v", msg)
+		}
+	}
+}
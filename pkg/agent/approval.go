@@ -0,0 +1,182 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrToolDenied é retornado quando a política de aprovação bloqueia uma chamada.
+var ErrToolDenied = errors.New("chamada de ferramenta negada")
+
+// ApprovalMode define o que acontece quando o modelo chama uma ferramenta.
+type ApprovalMode string
+
+const (
+	ApprovalAllow ApprovalMode = "allow" // executa sem perguntar
+	ApprovalAsk   ApprovalMode = "ask"   // pergunta ao usuário antes de executar
+	ApprovalDeny  ApprovalMode = "deny"  // nunca executa
+)
+
+// ParseApprovalMode converte o nome de um modo (allow, ask ou deny).
+func ParseApprovalMode(s string) (ApprovalMode, error) {
+	switch mode := ApprovalMode(strings.ToLower(strings.TrimSpace(s))); mode {
+	case ApprovalAllow, ApprovalAsk, ApprovalDeny:
+		return mode, nil
+	}
+	return "", fmt.Errorf("modo de aprovação inválido '%s' (use allow, ask ou deny)", s)
+}
+
+// ParseApprovalRules converte regras no formato "write_file=allow,create_directory=deny".
+func ParseApprovalRules(spec string) (map[string]ApprovalMode, error) {
+	rules := make(map[string]ApprovalMode)
+	for _, rule := range strings.Split(spec, ",") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+		name, modeName, ok := strings.Cut(rule, "=")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("regra de aprovação inválida '%s' (use ferramenta=modo)", rule)
+		}
+		mode, err := ParseApprovalMode(modeName)
+		if err != nil {
+			return nil, err
+		}
+		rules[strings.TrimSpace(name)] = mode
+	}
+	return rules, nil
+}
+
+// MutatingTool é implementada pelas ferramentas que informam se uma chamada altera o
+// sistema. Ferramentas que não a implementam são tratadas como somente leitura.
+type MutatingTool interface {
+	IsMutating(args string) bool
+}
+
+// isMutating informa se a chamada altera o sistema.
+func isMutating(tool Tool, args string) bool {
+	mt, ok := tool.(MutatingTool)
+	return ok && mt.IsMutating(args)
+}
+
+// ToolCall é uma chamada de ferramenta aguardando aprovação.
+type ToolCall struct {
+	Name string
+	Args string
+}
+
+// ApprovalDecision é a resposta do usuário a um pedido de aprovação.
+type ApprovalDecision int
+
+const (
+	DecisionAllow        ApprovalDecision = iota // permite esta chamada
+	DecisionAllowSession                         // permite a ferramenta até o fim da sessão
+	DecisionDeny                                 // nega esta chamada
+	DecisionDenySession                          // nega a ferramenta até o fim da sessão
+)
+
+// Approver pergunta ao usuário se uma chamada pode ser executada.
+type Approver func(ctx context.Context, call ToolCall) (ApprovalDecision, error)
+
+// ApprovalPolicy decide se cada chamada de ferramenta pode ser executada.
+//
+// A ordem de decisão é: escolhas "para esta sessão", depois o modo configurado para a
+// ferramenta e, por fim, o modo padrão para ferramentas que alteram o sistema. Chamadas
+// somente leitura são sempre permitidas, exceto se a ferramenta estiver em "deny".
+// Sem um Approver (modo não interativo), "ask" é tratado como "deny".
+type ApprovalPolicy struct {
+	mu       sync.Mutex
+	fallback ApprovalMode
+	tools    map[string]ApprovalMode
+	session  map[string]ApprovalMode
+	approver Approver
+}
+
+// NewApprovalPolicy cria uma política cujo modo padrão para ferramentas que alteram o
+// sistema é fallback.
+func NewApprovalPolicy(fallback ApprovalMode, approver Approver) *ApprovalPolicy {
+	return &ApprovalPolicy{
+		fallback: fallback,
+		tools:    make(map[string]ApprovalMode),
+		session:  make(map[string]ApprovalMode),
+		approver: approver,
+	}
+}
+
+// SetMode define o modo de uma ferramenta específica e descarta escolhas de sessão dela.
+func (p *ApprovalPolicy) SetMode(tool string, mode ApprovalMode) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.tools[tool] = mode
+	delete(p.session, tool)
+}
+
+// Rules descreve os modos configurados, no formato "ferramenta=modo", em ordem alfabética.
+func (p *ApprovalPolicy) Rules() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var rules []string
+	for name, mode := range p.tools {
+		rules = append(rules, fmt.Sprintf("%s=%s", name, mode))
+	}
+	for name, mode := range p.session {
+		rules = append(rules, fmt.Sprintf("%s=%s (sessão)", name, mode))
+	}
+	sort.Strings(rules)
+	return append([]string{fmt.Sprintf("padrão=%s", p.fallback)}, rules...)
+}
+
+// mode retorna o modo efetivo para uma chamada.
+func (p *ApprovalPolicy) mode(tool Tool, args string) ApprovalMode {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if mode, ok := p.session[tool.Name()]; ok {
+		return mode
+	}
+	mode, explicit := p.tools[tool.Name()]
+	if !explicit {
+		mode = p.fallback
+	}
+	if mode == ApprovalDeny && explicit {
+		return ApprovalDeny
+	}
+	if !isMutating(tool, args) {
+		return ApprovalAllow
+	}
+	return mode
+}
+
+// Check retorna nil se a chamada pode ser executada ou um erro que envolve ErrToolDenied.
+func (p *ApprovalPolicy) Check(ctx context.Context, tool Tool, args string) error {
+	switch p.mode(tool, args) {
+	case ApprovalAllow:
+		return nil
+	case ApprovalDeny:
+		return fmt.Errorf("%w: a política de aprovação não permite '%s'", ErrToolDenied, tool.Name())
+	}
+
+	if p.approver == nil {
+		return fmt.Errorf("%w: '%s' requer aprovação, mas não há ninguém para aprovar (modo não interativo)", ErrToolDenied, tool.Name())
+	}
+	decision, err := p.approver(ctx, ToolCall{Name: tool.Name(), Args: args})
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrToolDenied, err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	switch decision {
+	case DecisionAllowSession:
+		p.session[tool.Name()] = ApprovalAllow
+		fallthrough
+	case DecisionAllow:
+		return nil
+	case DecisionDenySession:
+		p.session[tool.Name()] = ApprovalDeny
+	}
+	return fmt.Errorf("%w: o usuário não aprovou '%s'", ErrToolDenied, tool.Name())
+}
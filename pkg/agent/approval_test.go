@@ -0,0 +1,93 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// fakeTool é uma ferramenta mínima para os testes de aprovação.
+type fakeTool struct {
+	name     string
+	mutating bool
+}
+
+func (f fakeTool) Name() string                        { return f.name }
+func (f fakeTool) Description() string                 { return "ferramenta de teste" }
+func (f fakeTool) Execute(args string) (string, error) { return "ok", nil }
+func (f fakeTool) IsMutating(args string) bool         { return f.mutating }
+
+// TestApprovalPolicyCheck testa a decisão da política para cada combinação de modo.
+func TestApprovalPolicyCheck(t *testing.T) {
+	write := fakeTool{name: "write_file", mutating: true}
+	read := fakeTool{name: "read_file"}
+	yes := func(context.Context, ToolCall) (ApprovalDecision, error) { return DecisionAllow, nil }
+	no := func(context.Context, ToolCall) (ApprovalDecision, error) { return DecisionDeny, nil }
+
+	testCases := []struct {
+		name     string
+		fallback ApprovalMode
+		rules    string
+		approver Approver
+		tool     Tool
+		wantDeny bool
+	}{
+		{"Somente leitura sempre permitida", ApprovalDeny, "", nil, read, false},
+		{"Deny explícito bloqueia leitura", ApprovalAllow, "read_file=deny", nil, read, true},
+		{"Allow padrão", ApprovalAllow, "", nil, write, false},
+		{"Deny padrão", ApprovalDeny, "", nil, write, true},
+		{"Regra por ferramenta vence o padrão", ApprovalDeny, "write_file=allow", nil, write, false},
+		{"Ask sem approver nega", ApprovalAsk, "", nil, write, true},
+		{"Ask com usuário aprovando", ApprovalAsk, "", yes, write, false},
+		{"Ask com usuário negando", ApprovalAsk, "", no, write, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			policy := NewApprovalPolicy(tc.fallback, tc.approver)
+			rules, err := ParseApprovalRules(tc.rules)
+			if err != nil {
+				t.Fatalf("ParseApprovalRules() erro = %v", err)
+			}
+			for name, mode := range rules {
+				policy.SetMode(name, mode)
+			}
+
+			err = policy.Check(context.Background(), tc.tool, "{}")
+			if (err != nil) != tc.wantDeny {
+				t.Fatalf("Check() erro = %v, wantDeny %v", err, tc.wantDeny)
+			}
+			if err != nil && !errors.Is(err, ErrToolDenied) {
+				t.Errorf("Check() erro deveria envolver ErrToolDenied, got %v", err)
+			}
+		})
+	}
+}
+
+// TestApprovalPolicySessionDecision testa que "sempre nesta sessão" não pergunta de novo.
+func TestApprovalPolicySessionDecision(t *testing.T) {
+	asked := 0
+	policy := NewApprovalPolicy(ApprovalAsk, func(context.Context, ToolCall) (ApprovalDecision, error) {
+		asked++
+		return DecisionAllowSession, nil
+	})
+	tool := fakeTool{name: "write_file", mutating: true}
+
+	for i := 0; i < 3; i++ {
+		if err := policy.Check(context.Background(), tool, "{}"); err != nil {
+			t.Fatalf("Check() erro inesperado: %v", err)
+		}
+	}
+	if asked != 1 {
+		t.Errorf("approver chamado %d vezes, esperado 1", asked)
+	}
+}
+
+// TestParseApprovalRulesInvalid testa regras mal formadas.
+func TestParseApprovalRulesInvalid(t *testing.T) {
+	for _, spec := range []string{"write_file", "=allow", "write_file=talvez"} {
+		if _, err := ParseApprovalRules(spec); err == nil {
+			t.Errorf("ParseApprovalRules(%q) deveria retornar erro", spec)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PreviewTool é implementada pelas ferramentas que sabem descrever o efeito de uma
+// chamada sem executá-la.
+type PreviewTool interface {
+	Preview(args string) (string, error)
+}
+
+// PlannedChange é uma alteração que o agente teria feito no modo dry-run.
+type PlannedChange struct {
+	Tool   string `json:"tool"`
+	Args   string `json:"args"`
+	Effect string `json:"effect"`
+}
+
+// SetDryRun liga ou desliga o modo dry-run. Nele, ferramentas que alteram o sistema não
+// são executadas: o efeito pretendido é registrado no plano e o modelo recebe um sucesso
+// simulado. Ferramentas somente leitura continuam funcionando normalmente.
+func (a *Agent) SetDryRun(enabled bool) {
+	a.dryRun = enabled
+}
+
+// DryRun indica se o modo dry-run está ligado.
+func (a *Agent) DryRun() bool {
+	return a.dryRun
+}
+
+// PlannedChanges retorna uma cópia das alterações registradas no modo dry-run.
+func (a *Agent) PlannedChanges() []PlannedChange {
+	return append([]PlannedChange(nil), a.plan...)
+}
+
+// ClearPlan descarta as alterações registradas.
+func (a *Agent) ClearPlan() {
+	a.plan = nil
+}
+
+// simulate registra o efeito de uma chamada no plano e retorna o resultado simulado.
+func (a *Agent) simulate(tool Tool, args string) (string, error) {
+	effect := fmt.Sprintf("Executaria %s(%s)", tool.Name(), args)
+	if pt, ok := tool.(PreviewTool); ok {
+		var err error
+		if effect, err = pt.Preview(args); err != nil {
+			return "", err
+		}
+	}
+	a.plan = append(a.plan, PlannedChange{Tool: tool.Name(), Args: args, Effect: effect})
+	return fmt.Sprintf("[dry-run] %s. Nada foi alterado de fato; continue como se a ação tivesse sido aplicada.", effect), nil
+}
+
+// FormatPlan monta o plano consolidado das alterações, numeradas na ordem em que o
+// agente as teria feito.
+func FormatPlan(changes []PlannedChange) string {
+	if len(changes) == 0 {
+		return "Nenhuma alteração planejada."
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Plano de alterações (%d):\n", len(changes))
+	for i, c := range changes {
+		fmt.Fprintf(&b, "  %d. [%s] %s\n", i+1, c.Tool, c.Effect)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
@@ -0,0 +1,71 @@
+package agent
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+)
+
+// scriptedClient devolve as respostas na ordem, simulando o LLM.
+type scriptedClient struct {
+	responses []string
+}
+
+func (c *scriptedClient) GenerateResponse(ctx context.Context, history []Message, tools []Tool) (string, error) {
+	response := c.responses[0]
+	c.responses = c.responses[1:]
+	return response, nil
+}
+
+// countingTool conta quantas vezes foi realmente executada.
+type countingTool struct {
+	fakeTool
+	calls int
+}
+
+func (c *countingTool) Execute(args string) (string, error) {
+	c.calls++
+	return "executado", nil
+}
+
+// TestDryRunSimulatesMutatingTools testa que, no dry-run, só as ferramentas de leitura executam.
+func TestDryRunSimulatesMutatingTools(t *testing.T) {
+	write := &countingTool{fakeTool: fakeTool{name: "write_file", mutating: true}}
+	read := &countingTool{fakeTool: fakeTool{name: "read_file"}}
+	client := &scriptedClient{responses: []string{
+		`TOOL_CALL: read_file({"path": "a.txt"})`,
+		`TOOL_CALL: write_file({"path": "a.txt", "content": "x"})`,
+		"Pronto.",
+	}}
+
+	a := NewAgent(client, []Tool{write, read})
+	a.SetOutput(io.Discard)
+	a.SetDryRun(true)
+	// Mesmo com a política negando tudo, o dry-run registra o plano sem perguntar.
+	a.SetApprovalPolicy(NewApprovalPolicy(ApprovalDeny, nil))
+
+	if _, err := a.RunOnce(context.Background(), "altere a.txt"); err != nil {
+		t.Fatalf("RunOnce() erro inesperado: %v", err)
+	}
+
+	if read.calls != 1 {
+		t.Errorf("read_file executada %d vezes, esperado 1", read.calls)
+	}
+	if write.calls != 0 {
+		t.Errorf("write_file não deveria executar no dry-run, executada %d vezes", write.calls)
+	}
+
+	plan := a.PlannedChanges()
+	if len(plan) != 1 || plan[0].Tool != "write_file" {
+		t.Fatalf("PlannedChanges() = %+v, esperado uma alteração de write_file", plan)
+	}
+	if !strings.Contains(FormatPlan(plan), "1. [write_file]") {
+		t.Errorf("FormatPlan() não lista a alteração: %s", FormatPlan(plan))
+	}
+
+	history := a.History()
+	if last := history[len(history)-2].Content; !strings.HasPrefix(last, "TOOL_RESULT: [dry-run]") {
+		t.Errorf("resultado simulado inesperado: %s", last)
+	}
+}
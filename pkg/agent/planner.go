@@ -0,0 +1,295 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/matheusbuniotto/goagent/internal/prompts"
+)
+
+// DefaultMaxReplans é quantas vezes o modo planner refaz o plano antes de desistir.
+const DefaultMaxReplans = 3
+
+// ErrPlanFailed é retornado quando um passo falha e o limite de revisões do plano já foi atingido.
+var ErrPlanFailed = errors.New("o plano falhou e o limite de revisões foi atingido")
+
+// StepStatus é a situação de um passo do plano.
+type StepStatus string
+
+const (
+	StepPending StepStatus = "pending"
+	StepRunning StepStatus = "running"
+	StepDone    StepStatus = "done"
+	StepFailed  StepStatus = "failed"
+)
+
+// PlanStep é um passo do plano do modo planner.
+type PlanStep struct {
+	Number       int        `json:"number"`
+	Description  string     `json:"description"`
+	Status       StepStatus `json:"status"`
+	Result       string     `json:"result,omitempty"`       // resumo do modelo ao terminar o passo
+	Verification string     `json:"verification,omitempty"` // motivo da verificação ou da falha
+}
+
+// TaskPlan é o plano gerado para um pedido do usuário no modo planner. Passos que falharam
+// continuam no plano; uma revisão troca só os passos pendentes.
+type TaskPlan struct {
+	Goal      string     `json:"goal"`
+	Steps     []PlanStep `json:"steps"`
+	Revisions int        `json:"revisions"`
+}
+
+// Progress retorna quantos passos foram concluídos e quantos contam para o total. Passos
+// que falharam não contam, pois foram substituídos pela revisão.
+func (p *TaskPlan) Progress() (done, total int) {
+	for _, step := range p.Steps {
+		switch step.Status {
+		case StepDone:
+			done++
+			total++
+		case StepFailed:
+		default:
+			total++
+		}
+	}
+	return done, total
+}
+
+// String renderiza o plano com o status de cada passo.
+func (p *TaskPlan) String() string {
+	var b strings.Builder
+	done, total := p.Progress()
+	fmt.Fprintf(&b, "Plano (%d/%d passos concluídos", done, total)
+	if p.Revisions > 0 {
+		fmt.Fprintf(&b, ", revisão %d", p.Revisions)
+	}
+	b.WriteString("):")
+	for _, step := range p.Steps {
+		marker := "[ ]"
+		switch step.Status {
+		case StepRunning:
+			marker = "[~]"
+		case StepDone:
+			marker = "[x]"
+		case StepFailed:
+			marker = "[!]"
+		}
+		fmt.Fprintf(&b, "\n%s %d. %s", marker, step.Number, step.Description)
+		if step.Status == StepFailed && step.Verification != "" {
+			fmt.Fprintf(&b, " (falhou: %s)", step.Verification)
+		}
+	}
+	return b.String()
+}
+
+// next retorna o índice do primeiro passo pendente, ou -1.
+func (p *TaskPlan) next() int {
+	for i, step := range p.Steps {
+		if step.Status == StepPending {
+			return i
+		}
+	}
+	return -1
+}
+
+// revise troca os passos pendentes pelos novos, numerados depois dos já existentes.
+func (p *TaskPlan) revise(descriptions []string) {
+	kept := p.Steps[:0]
+	for _, step := range p.Steps {
+		if step.Status != StepPending {
+			kept = append(kept, step)
+		}
+	}
+	p.Steps = kept
+	p.add(descriptions)
+	p.Revisions++
+}
+
+func (p *TaskPlan) add(descriptions []string) {
+	for _, desc := range descriptions {
+		p.Steps = append(p.Steps, PlanStep{Number: len(p.Steps) + 1, Description: desc, Status: StepPending})
+	}
+}
+
+// TaskPlan retorna uma cópia do plano do último pedido no modo planner, ou nil.
+func (a *Agent) TaskPlan() *TaskPlan {
+	if a.taskPlan == nil {
+		return nil
+	}
+	plan := *a.taskPlan
+	plan.Steps = append([]PlanStep(nil), a.taskPlan.Steps...)
+	return &plan
+}
+
+// SetTaskPlan substitui o plano, por exemplo ao retomar uma sessão salva.
+func (a *Agent) SetTaskPlan(plan *TaskPlan) {
+	a.taskPlan = plan
+}
+
+// SetMaxReplans define quantas revisões do plano são permitidas por pedido. Valores < 0
+// restauram o padrão.
+func (a *Agent) SetMaxReplans(n int) {
+	if n < 0 {
+		n = DefaultMaxReplans
+	}
+	a.maxReplans = n
+}
+
+// RunOnceWithPlan executa o pedido no modo planner: o modelo escreve um plano numerado, cada
+// passo é executado com seu próprio loop de ferramentas e verificado, e um passo que falha
+// leva a uma revisão dos passos restantes. Por fim o modelo dá a resposta final.
+func (a *Agent) RunOnceWithPlan(ctx context.Context, prompt string) (string, error) {
+	a.history = append(a.history, Message{Role: "user", Content: prompt})
+
+	steps, err := a.requestPlan(ctx, prompts.PlannerPrompt)
+	if err != nil {
+		return "", fmt.Errorf("erro ao gerar o plano: %w", err)
+	}
+	a.taskPlan = &TaskPlan{Goal: prompt}
+	a.taskPlan.add(steps)
+	a.history = append(a.history, Message{Role: "assistant", Content: a.taskPlan.String()})
+	a.showTaskPlan()
+
+	for {
+		i := a.taskPlan.next()
+		if i < 0 {
+			break
+		}
+		step := &a.taskPlan.Steps[i]
+		step.Status = StepRunning
+		fmt.Fprintf(a.out, "\u001b[94mPasso %d: %s\u001b[0m\n", step.Number, step.Description)
+		a.history = append(a.history, Message{Role: "user", Content: fmt.Sprintf(prompts.PlanStepPrompt, step.Number, step.Description, a.taskPlan)})
+
+		reason, err := a.runStep(ctx, step)
+		if err != nil {
+			step.Status = StepFailed
+			step.Verification = err.Error()
+			return "", err
+		}
+		if reason == "" {
+			step.Status = StepDone
+			a.showTaskPlan()
+			continue
+		}
+
+		step.Status = StepFailed
+		step.Verification = reason
+		fmt.Fprintf(a.out, "\u001b[93mPasso %d falhou: %s\u001b[0m\n", step.Number, reason)
+		if a.taskPlan.Revisions >= a.maxReplans {
+			a.showTaskPlan()
+			return "", fmt.Errorf("%w (%d revisões): passo %d: %s", ErrPlanFailed, a.taskPlan.Revisions, step.Number, reason)
+		}
+		steps, err := a.requestPlan(ctx, fmt.Sprintf(prompts.PlanReplanPrompt, a.taskPlan, step.Number, reason))
+		if err != nil {
+			return "", fmt.Errorf("erro ao revisar o plano: %w", err)
+		}
+		a.taskPlan.revise(steps)
+		a.history = append(a.history, Message{Role: "assistant", Content: a.taskPlan.String()})
+		a.showTaskPlan()
+	}
+
+	a.history = append(a.history, Message{Role: "user", Content: fmt.Sprintf(prompts.PlanFinishPrompt, a.taskPlan)})
+	return a.runTurn(ctx)
+}
+
+// runStep executa o passo e o verifica. Retorna o motivo da falha, ou "" se o passo foi
+// concluído. Erros que não são do passo (cancelamento, falha do LLM) interrompem o plano.
+func (a *Agent) runStep(ctx context.Context, step *PlanStep) (reason string, err error) {
+	result, err := a.runTurn(ctx)
+	if errors.Is(err, ErrMaxIterations) {
+		return err.Error(), nil
+	}
+	if err != nil {
+		return "", err
+	}
+	step.Result = result
+
+	ok, why, err := a.verifyStep(ctx, step)
+	if err != nil {
+		return "", fmt.Errorf("erro ao verificar o passo %d: %w", step.Number, err)
+	}
+	step.Verification = why
+	if !ok {
+		return why, nil
+	}
+	return "", nil
+}
+
+// verdictRegex reconhece a resposta da verificação de um passo.
+var verdictRegex = regexp.MustCompile(`(?i)VERIFICADO:\s*\**\s*(sim|não|nao|yes|no)\b`)
+
+// reasonRegex extrai o motivo da verificação.
+var reasonRegex = regexp.MustCompile(`(?i)MOTIVO:\s*(.+)`)
+
+// verifyStep pede ao modelo que confira o resultado do passo, sem gravar a conversa no histórico.
+func (a *Agent) verifyStep(ctx context.Context, step *PlanStep) (ok bool, reason string, err error) {
+	messages := append(a.requestHistory(), Message{Role: "system", Content: fmt.Sprintf(prompts.PlanVerifyPrompt, step.Number, step.Description)})
+	response, err := a.llmClient.GenerateResponse(ctx, messages, a.toolList())
+	if err != nil {
+		return false, "", err
+	}
+	a.recordUsage()
+
+	if m := reasonRegex.FindStringSubmatch(response); m != nil {
+		reason = strings.TrimSpace(m[1])
+	}
+	m := verdictRegex.FindStringSubmatch(response)
+	if m == nil {
+		return false, "a verificação não trouxe um veredito (VERIFICADO: sim/não)", nil
+	}
+	verdict := strings.ToLower(m[1])
+	ok = verdict == "sim" || verdict == "yes"
+	if !ok && reason == "" {
+		reason = "a verificação reprovou o passo"
+	}
+	return ok, reason, nil
+}
+
+// planStepRegex reconhece uma linha de plano numerada, ex: "1. Ler o arquivo" ou "2) ...".
+var planStepRegex = regexp.MustCompile(`(?im)^\s*(?:[-*]\s*)?(?:\*\*)?(?:passo\s+)?\d+\s*[.):-](?:\*\*)?\s+(.+?)\s*$`)
+
+// requestPlan pede um plano ao modelo com a instrução informada e extrai os passos.
+func (a *Agent) requestPlan(ctx context.Context, instruction string) ([]string, error) {
+	messages := append(a.requestHistory(), Message{Role: "system", Content: instruction})
+	response, err := a.llmClient.GenerateResponse(ctx, messages, a.toolList())
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("turno cancelado: %w", ctx.Err())
+		}
+		return nil, err
+	}
+	a.recordUsage()
+	return parsePlan(response)
+}
+
+// parsePlan extrai os passos de uma lista numerada. A numeração do modelo é ignorada.
+func parsePlan(response string) ([]string, error) {
+	var steps []string
+	for _, m := range planStepRegex.FindAllStringSubmatch(response, -1) {
+		if step := strings.TrimSpace(strings.Trim(m[1], "*")); step != "" {
+			steps = append(steps, step)
+		}
+	}
+	if len(steps) == 0 {
+		return nil, fmt.Errorf("o modelo não respondeu com um plano numerado: %q", truncateForError(response))
+	}
+	return steps, nil
+}
+
+// truncateForError encurta a resposta do modelo para caber em uma mensagem de erro.
+func truncateForError(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if r := []rune(s); len(r) > 120 {
+		return string(r[:117]) + "..."
+	}
+	return s
+}
+
+// showTaskPlan mostra o plano e o status de cada passo.
+func (a *Agent) showTaskPlan() {
+	fmt.Fprintf(a.out, "\u001b[96m%s\u001b[0m\n", a.taskPlan)
+}
@@ -0,0 +1,98 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestRunOnceWithPlan testa planejamento, execução, verificação e revisão após uma falha.
+func TestRunOnceWithPlan(t *testing.T) {
+	read := &countingTool{fakeTool: fakeTool{name: "read_file"}}
+	client := &scriptedClient{responses: []string{
+		"Plano:\n1. Ler a.txt\n2) Resumir o conteúdo",
+		`TOOL_CALL: read_file({"path": "a.txt"})`,
+		"Li o arquivo.",
+		"VERIFICADO: sim\nMOTIVO: o arquivo foi lido",
+		"Resumo pronto.",
+		"VERIFICADO: não\nMOTIVO: o resumo não cita o título",
+		"1. Resumir citando o título",
+		"Resumo com o título.",
+		"VERIFICADO: sim\nMOTIVO: o título aparece",
+		"Aqui está o resumo.",
+	}}
+	a := NewAgent(client, []Tool{read})
+	a.SetOutput(nil)
+
+	answer, err := a.RunOnceWithPlan(context.Background(), "resuma a.txt")
+	if err != nil {
+		t.Fatalf("RunOnceWithPlan() erro inesperado: %v", err)
+	}
+	if answer != "Aqui está o resumo." {
+		t.Errorf("resposta = %q", answer)
+	}
+	if read.calls != 1 {
+		t.Errorf("read_file executada %d vezes, esperado 1", read.calls)
+	}
+
+	plan := a.TaskPlan()
+	expected := "Plano (2/2 passos concluídos, revisão 1):\n" +
+		"[x] 1. Ler a.txt\n" +
+		"[!] 2. Resumir o conteúdo (falhou: o resumo não cita o título)\n" +
+		"[x] 3. Resumir citando o título"
+	if plan == nil || plan.String() != expected {
+		t.Fatalf("plano =\n%v\nesperado\n%s", plan, expected)
+	}
+	if plan.Steps[0].Result != "Li o arquivo." || plan.Steps[2].Verification != "o título aparece" {
+		t.Errorf("passos = %+v", plan.Steps)
+	}
+	for _, msg := range a.History() {
+		if strings.Contains(msg.Content, "VERIFICADO") {
+			t.Errorf("verificação gravada no histórico: %v", msg)
+		}
+	}
+}
+
+// TestRunOnceWithPlanGivesUp testa que o plano desiste quando as revisões se esgotam.
+func TestRunOnceWithPlanGivesUp(t *testing.T) {
+	client := &scriptedClient{responses: []string{
+		"1. Compilar o projeto",
+		"Não consegui compilar.",
+		"VERIFICADO: não\nMOTIVO: a compilação falhou",
+	}}
+	a := NewAgent(client, nil)
+	a.SetOutput(nil)
+	a.SetMaxReplans(0)
+
+	_, err := a.RunOnceWithPlan(context.Background(), "compile")
+	if !errors.Is(err, ErrPlanFailed) || !strings.Contains(err.Error(), "passo 1: a compilação falhou") {
+		t.Fatalf("erro = %v, esperado ErrPlanFailed", err)
+	}
+	if plan := a.TaskPlan(); plan.Steps[0].Status != StepFailed {
+		t.Errorf("status do passo = %s, esperado failed", plan.Steps[0].Status)
+	}
+}
+
+// TestParsePlan testa os formatos de lista numerada aceitos.
+func TestParsePlan(t *testing.T) {
+	testCases := []struct {
+		response string
+		expected []string
+	}{
+		{response: "1. Ler\n2. Escrever", expected: []string{"Ler", "Escrever"}},
+		{response: "Vou fazer assim:\n\n1) Ler\n  2 - Escrever\nPronto.", expected: []string{"Ler", "Escrever"}},
+		{response: "**1.** Ler o código\n- 2. **Testar**", expected: []string{"Ler o código", "Testar"}},
+		{response: "Passo 1: Ler", expected: []string{"Ler"}},
+	}
+	for _, tc := range testCases {
+		steps, err := parsePlan(tc.response)
+		if err != nil || !reflect.DeepEqual(steps, tc.expected) {
+			t.Errorf("parsePlan(%q) = %q, %v; esperado %q", tc.response, steps, err, tc.expected)
+		}
+	}
+	if _, err := parsePlan("Posso responder direto."); err == nil {
+		t.Error("parsePlan() sem lista numerada deveria falhar")
+	}
+}
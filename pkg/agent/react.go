@@ -0,0 +1,223 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/matheusbuniotto/goagent/internal/prompts"
+)
+
+// ReActStep é uma resposta do modelo no modo ReAct: o pensamento e a ação executada com
+// sua observação, ou a resposta final.
+type ReActStep struct {
+	Thought     string `json:"thought"`
+	Action      string `json:"action,omitempty"`       // nome da ferramenta
+	ActionInput string `json:"action_input,omitempty"` // argumentos JSON da ferramenta
+	Observation string `json:"observation,omitempty"`
+	Error       bool   `json:"error,omitempty"` // a ação falhou ou a resposta não seguiu o formato
+	FinalAnswer string `json:"final_answer,omitempty"`
+}
+
+// Trajectory é a sequência de passos do modo ReAct para um pedido do usuário.
+type Trajectory struct {
+	Question string      `json:"question"`
+	Steps    []ReActStep `json:"steps"`
+	Answer   string      `json:"answer,omitempty"`
+}
+
+// maxObservationDisplay limita o tamanho de cada observação em Trajectory.String.
+const maxObservationDisplay = 300
+
+// String renderiza a trajetória para leitura, encurtando observações longas.
+func (t *Trajectory) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Pergunta: %s", t.Question)
+	for i, step := range t.Steps {
+		fmt.Fprintf(&b, "\n%d. Thought: %s", i+1, step.Thought)
+		if step.Action != "" {
+			fmt.Fprintf(&b, "\n   Action: %s(%s)", step.Action, step.ActionInput)
+		}
+		if step.FinalAnswer != "" {
+			fmt.Fprintf(&b, "\n   Final Answer: %s", step.FinalAnswer)
+		}
+		if step.Observation != "" {
+			observation := strings.Join(strings.Fields(step.Observation), " ")
+			if r := []rune(observation); len(r) > maxObservationDisplay {
+				observation = string(r[:maxObservationDisplay-3]) + "..."
+			}
+			fmt.Fprintf(&b, "\n   Observation: %s", observation)
+		}
+	}
+	return b.String()
+}
+
+// Trajectory retorna uma cópia da trajetória do último pedido no modo ReAct, ou nil.
+func (a *Agent) Trajectory() *Trajectory {
+	if a.trajectory == nil {
+		return nil
+	}
+	t := *a.trajectory
+	t.Steps = append([]ReActStep(nil), a.trajectory.Steps...)
+	return &t
+}
+
+// SetTrajectory substitui a trajetória, por exemplo ao retomar uma sessão salva.
+func (a *Agent) SetTrajectory(t *Trajectory) {
+	a.trajectory = t
+}
+
+// RunOnceWithReAct executa o pedido no modo ReAct: cada resposta do modelo traz um Thought e
+// uma Action ou a Final Answer, e o resultado de cada ação volta como "Observation: ...".
+// Respostas fora do formato voltam como observação de erro para que o modelo se corrija.
+func (a *Agent) RunOnceWithReAct(ctx context.Context, prompt string) (string, error) {
+	a.history = append(a.history, Message{Role: "user", Content: prompt})
+	a.trajectory = &Trajectory{Question: prompt}
+	allTools := a.toolList()
+
+	for i := 0; i < a.maxIterations; i++ {
+		if err := ctx.Err(); err != nil {
+			return "", fmt.Errorf("turno cancelado: %w", err)
+		}
+
+		fmt.Fprintln(a.out, "\u001b[90mGoAgent está processando a mensagem...\u001b[0m")
+		messages := append(a.requestHistory(), Message{Role: "system", Content: prompts.ReActPrompt})
+		response, err := a.llmClient.GenerateResponse(ctx, messages, allTools)
+		if err != nil {
+			if ctx.Err() != nil {
+				return "", fmt.Errorf("turno cancelado: %w", ctx.Err())
+			}
+			return "", fmt.Errorf("erro ao chamar LLM: %w", err)
+		}
+		a.recordUsage()
+
+		// O modelo às vezes inventa a Observation; ela é descartada antes de tudo.
+		response = cutObservation(response)
+		a.history = append(a.history, Message{Role: "assistant", Content: response})
+
+		step, err := parseReActStep(response)
+		if step.Thought != "" {
+			fmt.Fprintf(a.out, "\u001b[90mThought: %s\u001b[0m\n", step.Thought)
+		}
+		if err != nil {
+			fmt.Fprintf(a.out, "\u001b[91mResposta fora do formato ReAct: %v\u001b[0m\n", err)
+			step.Observation = fmt.Sprintf("formato inválido: %v. Responda com Thought e Action, ou Thought e Final Answer.", err)
+			step.Error = true
+			a.observe(step)
+			continue
+		}
+
+		if step.FinalAnswer != "" {
+			a.trajectory.Steps = append(a.trajectory.Steps, step)
+			a.trajectory.Answer = step.FinalAnswer
+			fmt.Fprintf(a.out, "\u001b[92mGoAgent\u001b[0m: %s\n", step.FinalAnswer)
+			return step.FinalAnswer, nil
+		}
+
+		content, failed, err := a.dispatchTool(ctx, step.Action, step.ActionInput)
+		step.Observation, step.Error = content, failed
+		a.observe(step)
+		if err != nil {
+			return "", err
+		}
+	}
+	return "", fmt.Errorf("%w (%d iterações)", ErrMaxIterations, a.maxIterations)
+}
+
+// observe registra o passo na trajetória e devolve a observação ao modelo.
+func (a *Agent) observe(step ReActStep) {
+	a.trajectory.Steps = append(a.trajectory.Steps, step)
+	observation := step.Observation
+	if step.Error {
+		observation = "ERRO: " + observation
+	}
+	a.history = append(a.history, Message{Role: "user", Content: "Observation: " + observation})
+}
+
+// reactLabelRegex reconhece os rótulos do formato ReAct no início de uma linha, também em
+// português e com negrito de markdown. Rótulos mais longos vêm antes na alternância.
+var reactLabelRegex = regexp.MustCompile(`(?im)^[ \t]*\**[ \t]*(thought|pensamento|action input|action|ação|acao|final answer|resposta final|observation|observação|observacao)[ \t]*\**[ \t]*:\**`)
+
+// reactActionRegex separa o nome da ferramenta dos argumentos em "nome({...})".
+var reactActionRegex = regexp.MustCompile(`(?s)^(\w+)\s*\((.*)\)`)
+
+// reactLabel normaliza um rótulo para thought, action, input, final ou observation.
+func reactLabel(label string) string {
+	switch strings.ToLower(label) {
+	case "thought", "pensamento":
+		return "thought"
+	case "action input":
+		return "input"
+	case "action", "ação", "acao":
+		return "action"
+	case "final answer", "resposta final":
+		return "final"
+	}
+	return "observation"
+}
+
+// cutObservation remove da resposta tudo a partir de uma Observation escrita pelo modelo.
+func cutObservation(response string) string {
+	for _, loc := range reactLabelRegex.FindAllStringSubmatchIndex(response, -1) {
+		if reactLabel(response[loc[2]:loc[3]]) == "observation" {
+			return strings.TrimSpace(response[:loc[0]])
+		}
+	}
+	return strings.TrimSpace(response)
+}
+
+// reactSections separa a resposta pelos rótulos, guardando a primeira ocorrência de cada um.
+func reactSections(response string) map[string]string {
+	sections := make(map[string]string)
+	locs := reactLabelRegex.FindAllStringSubmatchIndex(response, -1)
+	for i, loc := range locs {
+		end := len(response)
+		if i+1 < len(locs) {
+			end = locs[i+1][0]
+		}
+		label := reactLabel(response[loc[2]:loc[3]])
+		if _, seen := sections[label]; !seen {
+			sections[label] = strings.TrimSpace(response[loc[1]:end])
+		}
+	}
+	return sections
+}
+
+// parseReActStep interpreta uma resposta do modelo. Mesmo com erro, o Thought encontrado
+// é retornado para que fique na trajetória.
+func parseReActStep(response string) (ReActStep, error) {
+	sections := reactSections(response)
+	step := ReActStep{Thought: sections["thought"]}
+	action, hasAction := sections["action"]
+	final, hasFinal := sections["final"]
+
+	switch {
+	case step.Thought == "":
+		return step, fmt.Errorf("falta o 'Thought:'")
+	case hasAction && hasFinal:
+		return step, fmt.Errorf("use 'Action:' ou 'Final Answer:', não os dois")
+	case hasFinal:
+		if final == "" {
+			return step, fmt.Errorf("'Final Answer:' está vazia")
+		}
+		step.FinalAnswer = final
+		return step, nil
+	case !hasAction:
+		return step, fmt.Errorf("falta 'Action:' ou 'Final Answer:'")
+	}
+
+	action = strings.TrimSpace(strings.TrimPrefix(strings.Trim(action, "`"), "TOOL_CALL:"))
+	if m := reactActionRegex.FindStringSubmatch(action); m != nil {
+		step.Action, step.ActionInput = m[1], strings.TrimSpace(m[2])
+	} else if name := strings.Fields(action); len(name) == 1 && reactActionRegex.MatchString(name[0]+"()") {
+		// Formato com "Action Input:" em linha separada.
+		step.Action, step.ActionInput = name[0], strings.Trim(sections["input"], "` \n")
+	} else {
+		return step, fmt.Errorf("ação inválida '%s': use nome_da_ferramenta({...})", truncateForError(action))
+	}
+	if step.ActionInput == "" {
+		step.ActionInput = "{}"
+	}
+	return step, nil
+}
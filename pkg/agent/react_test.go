@@ -0,0 +1,102 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestRunOnceWithReAct testa o ciclo Thought/Action/Observation, a correção de formato e a trajetória.
+func TestRunOnceWithReAct(t *testing.T) {
+	read := &countingTool{fakeTool: fakeTool{name: "read_file"}}
+	client := &recordingClient{scriptedClient: scriptedClient{responses: []string{
+		"Thought: preciso ler o arquivo\nAction: read_file({\"path\": \"a.txt\"})\nObservation: conteúdo inventado",
+		"Vou responder direto.",
+		"Thought: o formato estava errado\nAction: write_file({})",
+		"Thought: já sei o conteúdo\nFinal Answer: O arquivo diz executado.",
+	}}}
+	a := NewAgent(client, []Tool{read})
+	a.SetOutput(nil)
+
+	answer, err := a.RunOnceWithReAct(context.Background(), "o que diz a.txt?")
+	if err != nil {
+		t.Fatalf("RunOnceWithReAct() erro inesperado: %v", err)
+	}
+	if answer != "O arquivo diz executado." {
+		t.Errorf("resposta = %q", answer)
+	}
+	if read.calls != 1 {
+		t.Errorf("read_file executada %d vezes, esperado 1", read.calls)
+	}
+
+	expected := []ReActStep{
+		{Thought: "preciso ler o arquivo", Action: "read_file", ActionInput: `{"path": "a.txt"}`, Observation: "executado"},
+		{Observation: "formato inválido: falta o 'Thought:'. Responda com Thought e Action, ou Thought e Final Answer.", Error: true},
+		{Thought: "o formato estava errado", Action: "write_file", ActionInput: "{}", Observation: "Ferramenta 'write_file' não encontrada.", Error: true},
+		{Thought: "já sei o conteúdo", FinalAnswer: "O arquivo diz executado."},
+	}
+	trajectory := a.Trajectory()
+	if !reflect.DeepEqual(trajectory.Steps, expected) {
+		t.Fatalf("passos =\n%+v\nesperado\n%+v", trajectory.Steps, expected)
+	}
+	if trajectory.Answer != answer || trajectory.Question != "o que diz a.txt?" {
+		t.Errorf("trajetória = %+v", trajectory)
+	}
+	if data, err := json.Marshal(trajectory); err != nil || !strings.Contains(string(data), `"final_answer":"O arquivo diz executado."`) {
+		t.Errorf("json.Marshal() = %s, %v", data, err)
+	}
+
+	history := a.History()
+	if history[1].Content != "Thought: preciso ler o arquivo\nAction: read_file({\"path\": \"a.txt\"})" {
+		t.Errorf("Observation inventada não foi descartada: %q", history[1].Content)
+	}
+	if history[2].Content != "Observation: executado" || history[6].Content != "Observation: ERRO: Ferramenta 'write_file' não encontrada." {
+		t.Errorf("observações no histórico = %q, %q", history[2].Content, history[6].Content)
+	}
+	if last := client.requests[0][len(client.requests[0])-1]; last.Role != "system" || !strings.Contains(last.Content, "Final Answer:") {
+		t.Errorf("instrução ReAct ausente da chamada: %v", last)
+	}
+}
+
+// TestParseReActStep testa os formatos aceitos e os erros de formato.
+func TestParseReActStep(t *testing.T) {
+	testCases := []struct {
+		response      string
+		expected      ReActStep
+		expectedError string
+	}{
+		{
+			response: "Thought: ler\nvárias linhas\nAction: read_file({\"path\": \"x(1).txt\"})",
+			expected: ReActStep{Thought: "ler\nvárias linhas", Action: "read_file", ActionInput: `{"path": "x(1).txt"}`},
+		},
+		{
+			response: "**Pensamento:** listar\n**Ação:** list_files\n**Action Input:** {\"path\": \".\"}",
+			expected: ReActStep{Thought: "listar", Action: "list_files", ActionInput: `{"path": "."}`},
+		},
+		{
+			response: "thought: pronto\nFinal Answer: 42",
+			expected: ReActStep{Thought: "pronto", FinalAnswer: "42"},
+		},
+		{
+			response: "Thought: x\nAction: TOOL_CALL: git({})",
+			expected: ReActStep{Thought: "x", Action: "git", ActionInput: "{}"},
+		},
+		{response: "Thought: x", expected: ReActStep{Thought: "x"}, expectedError: "falta 'Action:' ou 'Final Answer:'"},
+		{response: "Thought: x\nAction: a({})\nFinal Answer: y", expected: ReActStep{Thought: "x"}, expectedError: "não os dois"},
+		{response: "Thought: x\nAction: ler o arquivo", expected: ReActStep{Thought: "x"}, expectedError: "ação inválida 'ler o arquivo'"},
+	}
+	for _, tc := range testCases {
+		step, err := parseReActStep(tc.response)
+		if tc.expectedError != "" && (err == nil || !strings.Contains(err.Error(), tc.expectedError)) {
+			t.Errorf("parseReActStep(%q) erro = %v, esperado conter %q", tc.response, err, tc.expectedError)
+		}
+		if tc.expectedError == "" && err != nil {
+			t.Errorf("parseReActStep(%q) erro inesperado: %v", tc.response, err)
+		}
+		if !reflect.DeepEqual(step, tc.expected) {
+			t.Errorf("parseReActStep(%q) = %+v, esperado %+v", tc.response, step, tc.expected)
+		}
+	}
+}
@@ -1,7 +1,11 @@
 // pkg/toolkit/adapter.go
 package toolkit
 
-import "encoding/json"
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
 
 // ToolAdapter faz a "ponte" entre o ToolDefinition e a interface
 type ToolAdapter struct {
@@ -24,5 +28,35 @@ func (a *ToolAdapter) Execute(args string) (string, error) {
 	// O LLM é instruído a fornecer argumentos como um JSON.
 	// Ex: `{"path": "meu_dir", "content": "olá"}` em vez de "meu_dir,olá"
 	rawJSON := json.RawMessage(args)
+	if a.Definition.Function == nil && a.Definition.ContextFunction != nil {
+		return a.Definition.ContextFunction(context.Background(), rawJSON)
+	}
 	return a.Definition.Function(rawJSON)
 }
+
+// ExecuteContext é como Execute, mas repassa o contexto para ferramentas canceláveis.
+// Parte da interface agent.ContextTool.
+func (a *ToolAdapter) ExecuteContext(ctx context.Context, args string) (string, error) {
+	if a.Definition.ContextFunction != nil {
+		return a.Definition.ContextFunction(ctx, json.RawMessage(args))
+	}
+	return a.Execute(args)
+}
+
+// IsMutating informa se a chamada com estes argumentos altera o sistema.
+// Parte da interface agent.MutatingTool.
+func (a *ToolAdapter) IsMutating(args string) bool {
+	if a.Definition.MutatingFunc != nil {
+		return a.Definition.MutatingFunc(json.RawMessage(args))
+	}
+	return a.Definition.Mutating
+}
+
+// Preview descreve o efeito da chamada sem executá-la. Sem uma função de Preview na
+// definição, descreve a própria chamada. Parte da interface agent.PreviewTool.
+func (a *ToolAdapter) Preview(args string) (string, error) {
+	if a.Definition.Preview != nil {
+		return a.Definition.Preview(json.RawMessage(args))
+	}
+	return fmt.Sprintf("Executaria %s(%s)", a.Definition.Name, args), nil
+}
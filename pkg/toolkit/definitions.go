@@ -1,14 +1,37 @@
 package toolkit
 
-import "encoding/json"
+import (
+	"context"
+	"encoding/json"
+)
 
 // ToolFunction define a estrutura da função principal de uma ferramenta
 // Ela recebe os argumentos como um JSON "cru" e retorna o resultado ou um erro
 type ToolFunction func(input json.RawMessage) (string, error)
 
+// ContextToolFunction é a variante de ToolFunction para ferramentas que podem ser
+// canceladas, como comandos demorados.
+type ContextToolFunction func(ctx context.Context, input json.RawMessage) (string, error)
+
 // ToolDefinition é a estruturada de definir uma ferramenta.
 type ToolDefinition struct {
 	Name        string       // Nome da ferramenta
 	Description string       // Descrição da ferramenta, usada para informar o agente
 	Function    ToolFunction // A função que implementa a lógica da ferramenta
+
+	// Mutating indica que a ferramenta altera o sistema (arquivos, processos, rede) e
+	// por isso passa pela política de aprovação antes de executar.
+	Mutating bool
+
+	// MutatingFunc, se definida, decide chamada a chamada se ela altera o sistema,
+	// refinando Mutating (ex: um comando de leitura vs. um de escrita).
+	MutatingFunc func(input json.RawMessage) bool
+
+	// Preview, se definida, descreve o efeito que a chamada teria sem executá-la.
+	// É usada no modo dry-run no lugar de Function para ferramentas que alteram o sistema.
+	Preview ToolFunction
+
+	// ContextFunction, se definida, é usada no lugar de Function quando o agente
+	// fornece um contexto, permitindo interromper a ferramenta com ctrl-c.
+	ContextFunction ContextToolFunction
 }